@@ -1,40 +1,274 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"gopkg.in/yaml.v3"
 
 	"github.com/fleshka4/1inch-test-task/internal/config"
+	"github.com/fleshka4/1inch-test-task/internal/indexer"
 	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap"
+	"github.com/fleshka4/1inch-test-task/internal/safety"
 	"github.com/fleshka4/1inch-test-task/internal/service"
 	"github.com/fleshka4/1inch-test-task/internal/transport/http"
 )
 
 func main() {
-	path := os.Getenv("CONFIG_PATH")
-	if path == "" {
-		path = "cfg/config.yaml"
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	loader := config.NewLoader(fs, os.LookupEnv)
+	checkConfig := fs.Bool("check-config", false, "validate the config, print it with secrets redacted and exit")
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		log.Fatalf("fs.Parse: %v", err)
 	}
 
-	cfg, err := config.Load(path)
+	cfg, err := loader.Load()
+	if *checkConfig {
+		os.Exit(printConfig(cfg, err))
+	}
 	if err != nil {
-		log.Fatalf("config.Load: %v", err)
+		log.Fatalf("loader.Load: %v", err)
 	}
 
-	client, err := uniswap.NewClient(cfg.RPCURL, cfg.CallTimeout)
+	policies, err := buildPolicies(cfg)
 	if err != nil {
-		log.Fatalf("uniswap.NewClient: %v", err)
+		log.Fatalf("buildPolicies: %v", err)
 	}
 
-	estimator := service.NewEstimatorService(client)
+	chains := make([]service.Chain, 0, len(cfg.Chains))
+	for _, chainCfg := range cfg.Chains {
+		client, err := uniswap.NewClient(chainCfg.RPCURLs, cfg.CallTimeout)
+		if err != nil {
+			log.Fatalf("uniswap.NewClient(%s): %v", chainCfg.Name, err)
+		}
+		if len(chainCfg.Indexer.Pools) > 0 {
+			client, err = startIndexer(chainCfg, client)
+			if err != nil {
+				log.Fatalf("startIndexer(%s): %v", chainCfg.Name, err)
+			}
+		}
+
+		chains = append(chains, service.Chain{
+			ID:     chainCfg.ChainID,
+			Name:   chainCfg.Name,
+			Client: client,
+			FeeBps: chainCfg.FeeBps,
+			Safety: safety.NewGuard(policies[chainCfg.ChainID]),
+
+			Factories:           factories(chainCfg.Factories),
+			PairVerification:    pairVerification(chainCfg.PairVerification),
+			Tokens:              tokenBehaviours(chainCfg.Tokens),
+			DetectTokenTax:      chainCfg.DetectTokenTax,
+			CrossCheckRatio:     chainCfg.CrossCheckRatio,
+			TrackedPools:        chainCfg.TrackedPools,
+			MaxTWAPDeviationBps: chainCfg.MaxTWAPDeviationBps,
+			TWAPWindow:          chainCfg.TWAPWindow,
+			MaxPoolAge:          chainCfg.MaxPoolAge,
+			PoolTypes:           poolTypes(chainCfg.Pools),
+		})
+	}
+
+	estimator := service.NewEstimatorService(chains...)
 
 	srv, err := http.NewServer(estimator, cfg)
 	if err != nil {
 		log.Fatalf("http.NewServer: %v", err)
 	}
 
+	store := config.NewStore(loader, cfg)
+	store.OnReload(srv.ApplyConfig)
+	store.AddCheck(func(next *config.Config) error {
+		_, err := buildPolicies(next)
+		return err
+	})
+	store.OnReload(func(next *config.Config) {
+		reconfigureClients(chains, next)
+		reloadPolicies(chains, next)
+	})
+	srv.EnableReload(store.Reload)
+	go reloadOnSignal(store)
+
+	if tracksPools(cfg) {
+		scanner := service.NewArbitrageScanner(estimator, service.ArbitrageConfig{
+			Interval:       cfg.Arbitrage.Interval,
+			MinProfitBps:   cfg.Arbitrage.MinProfitBps,
+			MaxCycleLength: cfg.Arbitrage.MaxCycleLength,
+		})
+		go scanner.Run(context.Background())
+		srv.EnableArbitrage(scanner)
+	}
+
 	err = srv.ListenAndServe(cfg.ListenAddr)
 	if err != nil {
 		log.Fatalf("srv.ListenAndServe: %v", err)
 	}
 }
+
+// reloadOnSignal reloads the config on every SIGHUP.
+func reloadOnSignal(store *config.Store) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	for range hup {
+		// Store.Reload logs the outcome.
+		_, _ = store.Reload()
+	}
+}
+
+// startIndexer opens the reserve history of a chain, keeps it up to date in
+// the background and returns the chain client reading past reserves from it.
+func startIndexer(cfg config.ChainConfig, client uniswap.Client) (uniswap.Client, error) {
+	history, err := indexer.OpenStore(cfg.Indexer.File)
+	if err != nil {
+		return nil, errors.Wrap(err, "indexer.OpenStore")
+	}
+
+	ix := indexer.New(cfg.Name, client, history, indexer.Config{
+		Pools:         cfg.Indexer.Pools,
+		StartBlock:    cfg.Indexer.StartBlock,
+		ChunkSize:     cfg.Indexer.ChunkSize,
+		Confirmations: cfg.Indexer.Confirmations,
+		Interval:      cfg.Indexer.Interval,
+	})
+	go ix.Run(context.Background())
+
+	return indexer.NewClient(cfg.Name, client, ix), nil
+}
+
+// tracksPools reports whether any chain has pools to scan for arbitrage.
+func tracksPools(cfg *config.Config) bool {
+	for _, chainCfg := range cfg.Chains {
+		if len(chainCfg.TrackedPools) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// reconfigureClients applies reloaded RPC endpoints and call timeout to the chain clients.
+func reconfigureClients(chains []service.Chain, cfg *config.Config) {
+	for _, chain := range chains {
+		r, ok := chain.Client.(uniswap.Reconfigurer)
+		if !ok {
+			continue
+		}
+
+		for _, chainCfg := range cfg.Chains {
+			if chainCfg.ChainID != chain.ID {
+				continue
+			}
+			if err := r.Reconfigure(chainCfg.RPCURLs, cfg.CallTimeout); err != nil {
+				log.Printf("chain %s: Reconfigure: %v", chain.Name, err)
+			}
+		}
+	}
+}
+
+func factories(cfgs []config.FactoryConfig) []service.Factory {
+	out := make([]service.Factory, 0, len(cfgs))
+	for _, f := range cfgs {
+		out = append(out, service.Factory{
+			Name:         f.Name,
+			Type:         service.PoolType(f.Type),
+			Address:      f.Address,
+			InitCodeHash: f.InitCodeHash,
+			FeeBps:       f.FeeBps,
+			Router:       f.Router,
+		})
+	}
+	return out
+}
+
+func tokenBehaviours(cfgs []config.TokenConfig) map[common.Address]service.TokenBehaviour {
+	out := make(map[common.Address]service.TokenBehaviour, len(cfgs))
+	for _, t := range cfgs {
+		out[t.Address] = service.TokenBehaviour{
+			InputTaxBps:  t.InputTaxBps,
+			OutputTaxBps: t.OutputTaxBps,
+			Rebasing:     t.Rebasing,
+		}
+	}
+	return out
+}
+
+func poolTypes(cfgs []config.PoolConfig) map[common.Address]service.PoolType {
+	out := make(map[common.Address]service.PoolType, len(cfgs))
+	for _, p := range cfgs {
+		out[p.Address] = service.PoolType(p.Type)
+	}
+	return out
+}
+
+func pairVerification(mode string) service.PairVerification {
+	switch mode {
+	case config.PairVerificationOff:
+		return service.PairVerificationOff
+	case config.PairVerificationStrict:
+		return service.PairVerificationStrict
+	default:
+		return service.PairVerificationPermissive
+	}
+}
+
+// buildPolicies reads the safety policy of every chain, including token list files.
+func buildPolicies(cfg *config.Config) (map[uint64]*safety.Policy, error) {
+	policies := make(map[uint64]*safety.Policy, len(cfg.Chains))
+	for _, chainCfg := range cfg.Chains {
+		policy, err := safety.NewPolicy(safety.Rules{
+			ChainID:        chainCfg.ChainID,
+			PoolAllowlist:  chainCfg.Safety.PoolAllowlist,
+			PoolDenylist:   chainCfg.Safety.PoolDenylist,
+			TokenAllowlist: chainCfg.Safety.TokenAllowlist,
+			TokenDenylist:  chainCfg.Safety.TokenDenylist,
+			TokenListPath:  chainCfg.Safety.TokenList,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "chain %s: safety.NewPolicy", chainCfg.Name)
+		}
+		policies[chainCfg.ChainID] = policy
+	}
+	return policies, nil
+}
+
+// reloadPolicies activates the reloaded safety policies, keeping the previous
+// ones if the token lists changed on disk since the reload was checked.
+func reloadPolicies(chains []service.Chain, cfg *config.Config) {
+	policies, err := buildPolicies(cfg)
+	if err != nil {
+		log.Printf("keeping previous safety policies: buildPolicies: %v", err)
+		return
+	}
+
+	for _, chain := range chains {
+		chain.Safety.Store(policies[chain.ID])
+	}
+}
+
+// printConfig reports every config problem or prints the effective config
+// with secrets redacted, and returns the process exit code.
+func printConfig(cfg *config.Config, loadErr error) int {
+	if loadErr != nil {
+		fmt.Fprintln(os.Stderr, "config is invalid:")
+		for _, err := range multierr.Errors(errors.Cause(loadErr)) {
+			fmt.Fprintf(os.Stderr, "  - %v\n", err)
+		}
+		return 1
+	}
+
+	out, err := yaml.Marshal(cfg.Redacted())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "yaml.Marshal: %v\n", err)
+		return 1
+	}
+
+	fmt.Print(string(out))
+	return 0
+}
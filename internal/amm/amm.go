@@ -0,0 +1,113 @@
+// Package amm puts the pools of the AMMs the estimator quotes behind one Pool
+// interface, so that a new kind of pool is a new implementation rather than a
+// new service flow.
+package amm
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Direction is a swap from one of a pool's tokens to another, by their index in Tokens.
+type Direction struct {
+	In  int
+	Out int
+}
+
+var (
+	// ZeroForOne sells token0 for token1.
+	ZeroForOne = Direction{In: 0, Out: 1}
+	// OneForZero sells token1 for token0.
+	OneForZero = Direction{In: 1, Out: 0}
+)
+
+// Pool is a pool at the state of one block. Pools are immutable: StateAt
+// returns a new Pool rather than updating the receiver.
+type Pool interface {
+	// Address is the pool's contract.
+	Address() common.Address
+	// Tokens are the pool's tokens in the pool's order.
+	Tokens() []common.Address
+	// BlockNumber is the block the state was read at, zero for the latest
+	// block or state that was not read from the chain.
+	BlockNumber() uint64
+	// Quote returns the output of selling amountIn in the given direction.
+	//
+	// Returns false if the pool has no state or cannot make the swap.
+	Quote(amountIn *big.Int, dir Direction) (*big.Int, bool)
+	// QuoteExactOut returns the input needed to buy amountOut in the given direction.
+	//
+	// Returns false if the pool has no state or cannot make the swap.
+	QuoteExactOut(amountOut *big.Int, dir Direction) (*big.Int, bool)
+	// StateAt reads the pool's state at the given block, or the latest one if nil.
+	StateAt(ctx context.Context, blockNumber *big.Int) (Pool, error)
+}
+
+// Holding is a Pool whose balances of its tokens are known. The estimator
+// sizes its token tax detection transfers with them.
+type Holding interface {
+	Pool
+	// Holder is the contract holding the pool's tokens: the pool itself, or
+	// the vault of pools whose tokens a vault holds.
+	Holder() common.Address
+	// Balance returns the pool's balance of the token at an index of Tokens,
+	// nil if the pool has no state.
+	Balance(token int) *big.Int
+}
+
+// ContractQuoter is a Pool whose contract quotes swaps itself.
+type ContractQuoter interface {
+	Pool
+	// QuoteContract returns the contract's output for selling amountIn, at the
+	// block the pool's state was read at. Errors name the contract function.
+	QuoteContract(ctx context.Context, amountIn *big.Int, dir Direction) (*big.Int, error)
+}
+
+// Expander is a Pool whose state covers only part of the pool, such as the
+// ticks of a V3 pool near its current price.
+type Expander interface {
+	Pool
+	// Expand reads more of the pool in a swap's direction, at the block the
+	// state was read at, for swaps Quote cannot make. It returns false when
+	// there is no more to read.
+	Expand(ctx context.Context, dir Direction) (Pool, bool, error)
+}
+
+// DirectionOf finds src and dst among a pool's tokens.
+func DirectionOf(p Pool, src, dst common.Address) (Direction, bool) {
+	dir := Direction{In: -1, Out: -1}
+	for i, token := range p.Tokens() {
+		switch token {
+		case src:
+			dir.In = i
+		case dst:
+			dir.Out = i
+		}
+	}
+	return dir, dir.In >= 0 && dir.Out >= 0 && dir.In != dir.Out
+}
+
+// Selector returns the 4-byte selector of a function signature such as "getReserves()".
+func Selector(signature string) [4]byte {
+	var sel [4]byte
+	copy(sel[:], crypto.Keccak256([]byte(signature)))
+	return sel
+}
+
+// HasSelectors reports whether bytecode has every selector. Function
+// dispatchers embed the selectors of the functions a contract has.
+func HasSelectors(code []byte, selectors ...[4]byte) bool {
+	if len(code) == 0 {
+		return false
+	}
+	for _, sel := range selectors {
+		if !bytes.Contains(code, sel[:]) {
+			return false
+		}
+	}
+	return true
+}
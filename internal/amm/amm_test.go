@@ -0,0 +1,42 @@
+package amm
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelector(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, [4]byte{0x09, 0x02, 0xf1, 0xac}, Selector("getReserves()"))
+	require.Equal(t, [4]byte{0x0d, 0xfe, 0x16, 0x81}, Selector("token0()"))
+}
+
+func TestHasSelectors(t *testing.T) {
+	t.Parallel()
+
+	getReserves, token0 := Selector("getReserves()"), Selector("token0()")
+	code := append(common.FromHex("0x608060405263"), getReserves[:]...)
+
+	require.True(t, HasSelectors(code, getReserves))
+	require.False(t, HasSelectors(code, getReserves, token0))
+	require.False(t, HasSelectors(nil))
+}
+
+func TestDirectionOf(t *testing.T) {
+	t.Parallel()
+
+	a, b, c := common.HexToAddress("0xa"), common.HexToAddress("0xb"), common.HexToAddress("0xc")
+	pair := NewV2Pair(nil, common.HexToAddress("0x1234"), a, b, 30)
+
+	dir, ok := DirectionOf(pair, b, a)
+	require.True(t, ok)
+	require.Equal(t, OneForZero, dir)
+
+	_, ok = DirectionOf(pair, a, c)
+	require.False(t, ok)
+	_, ok = DirectionOf(pair, a, a)
+	require.False(t, ok)
+}
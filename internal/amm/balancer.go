@@ -0,0 +1,122 @@
+package amm
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/weightedmath"
+)
+
+// WeightedPool is a Balancer weighted pool, whose tokens the Balancer Vault holds.
+type WeightedPool struct {
+	client  uniswap.BalancerReader
+	address common.Address
+
+	// state is nil until the pool's state is read.
+	state       *uniswapdto.BalancerWeightedPoolState
+	blockNumber uint64
+}
+
+var (
+	_ Holding        = (*WeightedPool)(nil)
+	_ ContractQuoter = (*WeightedPool)(nil)
+)
+
+// NewWeightedPool returns a pool without state. Its state is read with StateAt.
+func NewWeightedPool(client uniswap.BalancerReader, address common.Address) *WeightedPool {
+	return &WeightedPool{client: client, address: address}
+}
+
+// Address is the pool's contract.
+func (p *WeightedPool) Address() common.Address { return p.address }
+
+// Tokens are the pool's tokens, nil if the pool has no state.
+func (p *WeightedPool) Tokens() []common.Address {
+	if p.state == nil {
+		return nil
+	}
+	return p.state.Tokens
+}
+
+// BlockNumber is the block the state was read at.
+func (p *WeightedPool) BlockNumber() uint64 { return p.blockNumber }
+
+// Holder is the Vault, the zero address if the pool has no state.
+func (p *WeightedPool) Holder() common.Address {
+	if p.state == nil {
+		return common.Address{}
+	}
+	return p.state.Vault
+}
+
+// Balance returns the pool's balance of a token in the Vault.
+func (p *WeightedPool) Balance(token int) *big.Int {
+	if p.state == nil || token < 0 || token >= len(p.state.Balances) {
+		return nil
+	}
+	return p.state.Balances[token]
+}
+
+// Quote returns the output of selling amountIn, as the Vault's swap pays it.
+func (p *WeightedPool) Quote(amountIn *big.Int, dir Direction) (*big.Int, bool) {
+	if p.state == nil {
+		return new(big.Int), false
+	}
+
+	pool := weightedmath.Pool{
+		Balances:       p.state.Balances,
+		ScalingFactors: p.state.ScalingFactors,
+		Weights:        p.state.Weights,
+		SwapFee:        p.state.SwapFee,
+		ExactPow:       p.state.ExactPow,
+	}
+	return pool.QuoteExactIn(dir.In, dir.Out, amountIn)
+}
+
+// QuoteExactOut is not supported, and always returns false.
+func (p *WeightedPool) QuoteExactOut(*big.Int, Direction) (*big.Int, bool) {
+	return new(big.Int), false
+}
+
+// QuoteContract calls the Vault's queryBatchSwap for a swap through the pool.
+func (p *WeightedPool) QuoteContract(ctx context.Context, amountIn *big.Int, dir Direction) (*big.Int, error) {
+	tokens := p.Tokens()
+	if dir.In < 0 || dir.In >= len(tokens) || dir.Out < 0 || dir.Out >= len(tokens) {
+		return nil, errors.New("queryBatchSwap: no such token")
+	}
+
+	var blockNumber *big.Int
+	if p.blockNumber != 0 {
+		blockNumber = new(big.Int).SetUint64(p.blockNumber)
+	}
+
+	out, err := p.client.GetBalancerAmountOut(
+		ctx, p.state.Vault, p.state.PoolID, tokens[dir.In], tokens[dir.Out], amountIn, blockNumber,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "queryBatchSwap")
+	}
+	return out, nil
+}
+
+// StateAt reads the pool's tokens, Vault balances, weights and swap fee at the
+// given block, or the latest one if nil.
+func (p *WeightedPool) StateAt(ctx context.Context, blockNumber *big.Int) (Pool, error) {
+	state, err := p.client.GetBalancerWeightedPoolState(ctx, p.address, blockNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "p.client.GetBalancerWeightedPoolState")
+	}
+
+	next := *p
+	next.state = state
+	next.blockNumber = 0
+	if blockNumber != nil {
+		next.blockNumber = blockNumber.Uint64()
+	}
+	return &next, nil
+}
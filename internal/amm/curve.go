@@ -0,0 +1,113 @@
+package amm
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/dexmath"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+)
+
+// CurvePool is a Curve StableSwap pool, plain or meta. A metapool's tokens
+// are its own coins, not those of its base pool.
+type CurvePool struct {
+	client  uniswap.CurveReader
+	address common.Address
+
+	// state is nil until the pool's state is read.
+	state       *uniswapdto.CurvePoolState
+	blockNumber uint64
+}
+
+var (
+	_ Holding        = (*CurvePool)(nil)
+	_ ContractQuoter = (*CurvePool)(nil)
+)
+
+// NewCurvePool returns a pool without state. Its state is read with StateAt.
+func NewCurvePool(client uniswap.CurveReader, address common.Address) *CurvePool {
+	return &CurvePool{client: client, address: address}
+}
+
+// Address is the pool's contract.
+func (p *CurvePool) Address() common.Address { return p.address }
+
+// Tokens are the pool's coins, nil if the pool has no state.
+func (p *CurvePool) Tokens() []common.Address {
+	if p.state == nil {
+		return nil
+	}
+	return p.state.Coins
+}
+
+// BlockNumber is the block the state was read at.
+func (p *CurvePool) BlockNumber() uint64 { return p.blockNumber }
+
+// Holder is the pool, which holds its coins.
+func (p *CurvePool) Holder() common.Address { return p.address }
+
+// Balance returns the pool's balance of a coin.
+func (p *CurvePool) Balance(token int) *big.Int {
+	if p.state == nil || token < 0 || token >= len(p.state.Balances) {
+		return nil
+	}
+	return p.state.Balances[token]
+}
+
+// Quote returns the output of selling amountIn, as the pool's get_dy computes it.
+func (p *CurvePool) Quote(amountIn *big.Int, dir Direction) (*big.Int, bool) {
+	if p.state == nil {
+		return new(big.Int), false
+	}
+
+	pool := dexmath.CurvePool{
+		Balances:            p.state.Balances,
+		Rates:               p.state.Rates,
+		Amp:                 p.state.Amp,
+		APrecision:          p.state.APrecision,
+		Fee:                 p.state.Fee,
+		OffpegFeeMultiplier: p.state.OffpegFeeMultiplier,
+		NextGen:             p.state.NextGen,
+	}
+	return pool.GetDy(dir.In, dir.Out, amountIn)
+}
+
+// QuoteExactOut is not supported, and always returns false.
+func (p *CurvePool) QuoteExactOut(*big.Int, Direction) (*big.Int, bool) {
+	return new(big.Int), false
+}
+
+// QuoteContract calls the pool's get_dy.
+func (p *CurvePool) QuoteContract(ctx context.Context, amountIn *big.Int, dir Direction) (*big.Int, error) {
+	var blockNumber *big.Int
+	if p.blockNumber != 0 {
+		blockNumber = new(big.Int).SetUint64(p.blockNumber)
+	}
+
+	out, err := p.client.GetCurveAmountOut(ctx, p.address, dir.In, dir.Out, amountIn, blockNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "get_dy")
+	}
+	return out, nil
+}
+
+// StateAt reads the pool's coins, balances, rates, amplification and fee at
+// the given block, or the latest one if nil.
+func (p *CurvePool) StateAt(ctx context.Context, blockNumber *big.Int) (Pool, error) {
+	state, err := p.client.GetCurvePoolState(ctx, p.address, blockNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "p.client.GetCurvePoolState")
+	}
+
+	next := *p
+	next.state = state
+	next.blockNumber = 0
+	if blockNumber != nil {
+		next.blockNumber = blockNumber.Uint64()
+	}
+	return &next, nil
+}
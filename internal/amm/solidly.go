@@ -0,0 +1,199 @@
+package amm
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/dexmath"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+)
+
+// SolidlyClient is what a SolidlyPair reads: the pair, and its factory's fee
+// from the factory() the pair reports.
+type SolidlyClient interface {
+	uniswap.SolidlyReader
+	GetPairFactory(ctx context.Context, pair common.Address) (common.Address, error)
+}
+
+// SolidlyPair is a Solidly style pair, stable or volatile, such as those of
+// Velodrome and Aerodrome. Its fee is set by its factory.
+type SolidlyPair struct {
+	client  SolidlyClient
+	address common.Address
+
+	// metadata is nil until the pair's state is read.
+	metadata    *uniswapdto.SolidlyMetadata
+	blockNumber uint64
+	// factory is the factory the fee is read from, once it has been read.
+	factory common.Address
+	feeBps  uint32
+	hasFee  bool
+}
+
+var (
+	_ Holding        = (*SolidlyPair)(nil)
+	_ ContractQuoter = (*SolidlyPair)(nil)
+)
+
+// NewSolidlyPair returns a pair without state. Its state is read with StateAt,
+// or with MetadataAt and then FeeAt.
+func NewSolidlyPair(client SolidlyClient, address common.Address) *SolidlyPair {
+	return &SolidlyPair{client: client, address: address}
+}
+
+// Address is the pair's contract.
+func (p *SolidlyPair) Address() common.Address { return p.address }
+
+// Tokens are token0 and token1, nil if the pair has no state.
+func (p *SolidlyPair) Tokens() []common.Address {
+	if p.metadata == nil {
+		return nil
+	}
+	return []common.Address{p.metadata.Token0, p.metadata.Token1}
+}
+
+// BlockNumber is the block the metadata was read at.
+func (p *SolidlyPair) BlockNumber() uint64 { return p.blockNumber }
+
+// Stable reports whether the pair is a stable one.
+func (p *SolidlyPair) Stable() bool { return p.metadata != nil && p.metadata.Stable }
+
+// Holder is the pair, which holds its reserves.
+func (p *SolidlyPair) Holder() common.Address { return p.address }
+
+// Balance returns the pair's reserve of a token.
+func (p *SolidlyPair) Balance(token int) *big.Int {
+	switch {
+	case p.metadata == nil:
+		return nil
+	case token == 0:
+		return p.metadata.Reserve0
+	case token == 1:
+		return p.metadata.Reserve1
+	default:
+		return nil
+	}
+}
+
+// Quote returns the output of selling amountIn, as the pair's getAmountOut computes it.
+//
+// Returns false until the fee is read.
+func (p *SolidlyPair) Quote(amountIn *big.Int, dir Direction) (*big.Int, bool) {
+	if p.metadata == nil || !p.hasFee {
+		return new(big.Int), false
+	}
+
+	var mathDir dexmath.Direction
+	switch dir {
+	case ZeroForOne:
+		mathDir = dexmath.ZeroForOne
+	case OneForZero:
+		mathDir = dexmath.OneForZero
+	default:
+		return new(big.Int), false
+	}
+
+	pool := dexmath.SolidlyPool{
+		Reserve0:  p.metadata.Reserve0,
+		Reserve1:  p.metadata.Reserve1,
+		Decimals0: p.metadata.Decimals0,
+		Decimals1: p.metadata.Decimals1,
+		Stable:    p.metadata.Stable,
+		FeeBps:    p.feeBps,
+	}
+	return pool.GetAmountOut(amountIn, mathDir)
+}
+
+// QuoteExactOut is not supported: Solidly pairs have no getAmountIn, and
+// always return false.
+func (p *SolidlyPair) QuoteExactOut(*big.Int, Direction) (*big.Int, bool) {
+	return new(big.Int), false
+}
+
+// QuoteContract calls the pair's getAmountOut.
+func (p *SolidlyPair) QuoteContract(ctx context.Context, amountIn *big.Int, dir Direction) (*big.Int, error) {
+	tokens := p.Tokens()
+	if dir.In < 0 || dir.In >= len(tokens) {
+		return nil, errors.New("getAmountOut: no such token")
+	}
+
+	out, err := p.client.GetSolidlyAmountOut(ctx, p.address, amountIn, tokens[dir.In], p.block())
+	if err != nil {
+		return nil, errors.Wrap(err, "getAmountOut")
+	}
+	return out, nil
+}
+
+// StateAt reads the pair's metadata at the given block, or the latest one if
+// nil, and its fee from the factory it was last read from, or else the one
+// the pair reports.
+func (p *SolidlyPair) StateAt(ctx context.Context, blockNumber *big.Int) (Pool, error) {
+	next, err := p.MetadataAt(ctx, blockNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "p.MetadataAt")
+	}
+	next, err = next.FeeAt(ctx, p.factory)
+	if err != nil {
+		return nil, errors.Wrap(err, "next.FeeAt")
+	}
+	return next, nil
+}
+
+// MetadataAt reads the pair's tokens, decimals and reserves at the given
+// block, or the latest one if nil. The pair has no fee until FeeAt reads it.
+func (p *SolidlyPair) MetadataAt(ctx context.Context, blockNumber *big.Int) (*SolidlyPair, error) {
+	m, err := p.client.GetSolidlyMetadata(ctx, p.address, blockNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "p.client.GetSolidlyMetadata")
+	}
+
+	next := *p
+	next.metadata = m
+	next.blockNumber = 0
+	if blockNumber != nil {
+		next.blockNumber = blockNumber.Uint64()
+	}
+	next.feeBps, next.hasFee = 0, false
+	return &next, nil
+}
+
+// FeeAt reads the fee the pair pays at the block of its metadata from a
+// factory, or from the one the pair reports if factory is the zero address.
+func (p *SolidlyPair) FeeAt(ctx context.Context, factory common.Address) (*SolidlyPair, error) {
+	if p.metadata == nil {
+		return nil, errors.New("pair has no metadata")
+	}
+
+	if factory == (common.Address{}) {
+		var err error
+		factory, err = p.client.GetPairFactory(ctx, p.address)
+		if err != nil {
+			return nil, errors.Wrap(err, "p.client.GetPairFactory")
+		}
+	}
+
+	fee, err := p.client.GetSolidlyFee(ctx, factory, p.address, p.metadata.Stable, p.block())
+	if err != nil {
+		return nil, errors.Wrap(err, "p.client.GetSolidlyFee")
+	}
+	if !fee.IsUint64() || fee.Uint64() >= 10000 {
+		return nil, errors.Errorf("fee of %s bps out of range", fee)
+	}
+
+	next := *p
+	next.factory = factory
+	next.feeBps, next.hasFee = uint32(fee.Uint64()), true
+	return &next, nil
+}
+
+// block is the block the metadata was read at, nil if it is unknown.
+func (p *SolidlyPair) block() *big.Int {
+	if p.blockNumber == 0 {
+		return nil
+	}
+	return new(big.Int).SetUint64(p.blockNumber)
+}
@@ -0,0 +1,81 @@
+package amm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+)
+
+func TestSolidlyPair_StateAt(t *testing.T) {
+	t.Parallel()
+
+	address := common.HexToAddress("0x1234")
+	factory := common.HexToAddress("0xfac7")
+	token0, token1 := common.HexToAddress("0xa"), common.HexToAddress("0xb")
+	block := big.NewInt(120_000_000)
+	metadata := &uniswapdto.SolidlyMetadata{
+		Decimals0: big.NewInt(1e18),
+		Decimals1: big.NewInt(1e18),
+		Reserve0:  big.NewInt(10000),
+		Reserve1:  big.NewInt(20000),
+		Token0:    token0,
+		Token1:    token1,
+	}
+
+	tests := []struct {
+		name      string
+		mockSetup func(mc *mock.MockClient)
+		wantOut   *big.Int
+		wantErr   string
+	}{
+		{
+			name: "fee of the pair's factory",
+			mockSetup: func(mc *mock.MockClient) {
+				mc.EXPECT().GetPairFactory(gomock.Any(), address).Return(factory, nil)
+				mc.EXPECT().GetSolidlyFee(gomock.Any(), factory, address, false, block).Return(big.NewInt(30), nil)
+			},
+			wantOut: big.NewInt(1813),
+		},
+		{
+			name: "fee out of range",
+			mockSetup: func(mc *mock.MockClient) {
+				mc.EXPECT().GetPairFactory(gomock.Any(), address).Return(factory, nil)
+				mc.EXPECT().GetSolidlyFee(gomock.Any(), factory, address, false, block).Return(big.NewInt(10000), nil)
+			},
+			wantErr: "fee of 10000 bps out of range",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockClient(ctrl)
+			mockClient.EXPECT().GetSolidlyMetadata(gomock.Any(), address, block).Return(metadata, nil)
+			tt.mockSetup(mockClient)
+
+			state, err := NewSolidlyPair(mockClient, address).StateAt(context.Background(), block)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []common.Address{token0, token1}, state.Tokens())
+			require.Equal(t, uint64(120_000_000), state.BlockNumber())
+
+			out, ok := state.Quote(big.NewInt(1000), ZeroForOne)
+			require.True(t, ok)
+			require.Equal(t, tt.wantOut, out)
+		})
+	}
+}
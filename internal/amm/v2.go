@@ -0,0 +1,155 @@
+package amm
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/dexmath"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+)
+
+// V2Pair is a Uniswap V2 pair, or a fork of it with another swap fee.
+type V2Pair struct {
+	client  uniswap.V2Reader
+	address common.Address
+	tokens  []common.Address
+	feeBps  uint32
+
+	// reserves are nil until the pair's state is read or supplied.
+	reserves    *uniswapdto.PairReserves
+	blockNumber uint64
+}
+
+var _ Holding = (*V2Pair)(nil)
+
+// NewV2Pair returns a pair of token0 and token1 with a swap fee in basis
+// points, without state. Its state is read with StateAt or ReservesAt.
+func NewV2Pair(client uniswap.V2Reader, address, token0, token1 common.Address, feeBps uint32) *V2Pair {
+	return &V2Pair{
+		client:  client,
+		address: address,
+		tokens:  []common.Address{token0, token1},
+		feeBps:  feeBps,
+	}
+}
+
+// Address is the pair's contract.
+func (p *V2Pair) Address() common.Address { return p.address }
+
+// Tokens are token0 and token1.
+func (p *V2Pair) Tokens() []common.Address { return p.tokens }
+
+// BlockNumber is the block the reserves were read at.
+func (p *V2Pair) BlockNumber() uint64 { return p.blockNumber }
+
+// FeeBps is the pair's swap fee in basis points.
+func (p *V2Pair) FeeBps() uint32 { return p.feeBps }
+
+// Holder is the pair, which holds its reserves.
+func (p *V2Pair) Holder() common.Address { return p.address }
+
+// Balance returns the pair's reserve of a token.
+func (p *V2Pair) Balance(token int) *big.Int {
+	r0, r1 := p.Reserves()
+	switch token {
+	case 0:
+		return r0
+	case 1:
+		return r1
+	default:
+		return nil
+	}
+}
+
+// Reserves returns reserve0 and reserve1, nil if the pair has no state.
+func (p *V2Pair) Reserves() (*big.Int, *big.Int) {
+	if p.reserves == nil {
+		return nil, nil
+	}
+	return p.reserves.Reserve0, p.reserves.Reserve1
+}
+
+// UpdatedAt is when the reserves read from the chain were last updated, by the
+// pair's last trade, mint, burn or sync. It is zero if the pair has no state.
+func (p *V2Pair) UpdatedAt() time.Time {
+	if p.reserves == nil {
+		return time.Time{}
+	}
+	return time.Unix(int64(p.reserves.BlockTimestampLast), 0)
+}
+
+// Quote returns the output of selling amountIn, as the pair's swap pays it.
+func (p *V2Pair) Quote(amountIn *big.Int, dir Direction) (*big.Int, bool) {
+	reserveIn, reserveOut, ok := p.sides(dir)
+	if !ok {
+		return new(big.Int), false
+	}
+
+	out := new(big.Int)
+	ok = dexmath.GetAmountOutWithFeeInto(out, amountIn, reserveIn, reserveOut, p.feeBps)
+	return out, ok
+}
+
+// QuoteExactOut returns the input needed to buy amountOut, as the router's getAmountsIn computes it.
+func (p *V2Pair) QuoteExactOut(amountOut *big.Int, dir Direction) (*big.Int, bool) {
+	reserveIn, reserveOut, ok := p.sides(dir)
+	if !ok {
+		return new(big.Int), false
+	}
+
+	return dexmath.GetAmountInWithFee(amountOut, reserveIn, reserveOut, p.feeBps)
+}
+
+// sides returns the reserves of the tokens in and out.
+func (p *V2Pair) sides(dir Direction) (*big.Int, *big.Int, bool) {
+	switch {
+	case p.reserves == nil:
+		return nil, nil, false
+	case dir == ZeroForOne:
+		return p.reserves.Reserve0, p.reserves.Reserve1, true
+	case dir == OneForZero:
+		return p.reserves.Reserve1, p.reserves.Reserve0, true
+	default:
+		return nil, nil, false
+	}
+}
+
+// StateAt reads the pair's reserves at the given block, or the latest one if nil.
+func (p *V2Pair) StateAt(ctx context.Context, blockNumber *big.Int) (Pool, error) {
+	return p.ReservesAt(ctx, blockNumber)
+}
+
+// ReservesAt is StateAt returning the pair itself.
+func (p *V2Pair) ReservesAt(ctx context.Context, blockNumber *big.Int) (*V2Pair, error) {
+	var reserves *uniswapdto.PairReserves
+	var err error
+	if blockNumber == nil {
+		reserves, err = p.client.GetPairReserves(ctx, p.address)
+	} else {
+		reserves, err = p.client.GetPairReservesAt(ctx, p.address, blockNumber)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "p.client.GetPairReserves")
+	}
+
+	next := *p
+	next.reserves = reserves
+	next.blockNumber = 0
+	if blockNumber != nil {
+		next.blockNumber = blockNumber.Uint64()
+	}
+	return &next, nil
+}
+
+// WithReserves returns the pair with supplied reserves, for what-if quotes.
+func (p *V2Pair) WithReserves(reserve0, reserve1 *big.Int) *V2Pair {
+	next := *p
+	next.reserves = &uniswapdto.PairReserves{Reserve0: reserve0, Reserve1: reserve1}
+	next.blockNumber = 0
+	return &next
+}
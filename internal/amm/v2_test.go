@@ -0,0 +1,74 @@
+package amm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+)
+
+func TestV2Pair_StateAt(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	address := common.HexToAddress("0x1234")
+	token0, token1 := common.HexToAddress("0xa"), common.HexToAddress("0xb")
+	block := big.NewInt(19_000_000)
+
+	mockClient := mock.NewMockV2Reader(ctrl)
+	mockClient.EXPECT().
+		GetPairReservesAt(gomock.Any(), address, block).
+		Return(&uniswapdto.PairReserves{
+			Reserve0:           big.NewInt(10000),
+			Reserve1:           big.NewInt(20000),
+			BlockTimestampLast: 1_700_000_000,
+		}, nil)
+
+	pair := NewV2Pair(mockClient, address, token0, token1, 30)
+	_, ok := pair.Quote(big.NewInt(1000), ZeroForOne)
+	require.False(t, ok, "a pair without state cannot quote")
+
+	state, err := pair.StateAt(context.Background(), block)
+	require.NoError(t, err)
+	require.Equal(t, uint64(19_000_000), state.BlockNumber())
+	require.Equal(t, []common.Address{token0, token1}, state.Tokens())
+	require.Equal(t, time.Unix(1_700_000_000, 0), state.(*V2Pair).UpdatedAt())
+
+	out, ok := state.Quote(big.NewInt(1000), ZeroForOne)
+	require.True(t, ok)
+	require.Equal(t, big.NewInt(1813), out)
+
+	in, ok := state.QuoteExactOut(big.NewInt(1813), ZeroForOne)
+	require.True(t, ok)
+	require.Equal(t, big.NewInt(1000), in)
+
+	_, ok = state.Quote(big.NewInt(1000), Direction{In: 0, Out: 2})
+	require.False(t, ok)
+}
+
+func TestV2Pair_WithReserves(t *testing.T) {
+	t.Parallel()
+
+	pair := NewV2Pair(nil, common.HexToAddress("0x1234"), common.HexToAddress("0xa"), common.HexToAddress("0xb"), 25)
+	state := pair.WithReserves(big.NewInt(1000), big.NewInt(1000))
+
+	out, ok := state.Quote(big.NewInt(100), OneForZero)
+	require.True(t, ok)
+	require.Equal(t, big.NewInt(90), out)
+
+	_, ok = state.QuoteExactOut(big.NewInt(1000), OneForZero)
+	require.False(t, ok, "a swap cannot drain the pair")
+
+	r0, r1 := pair.Reserves()
+	require.Nil(t, r0, "WithReserves must not modify the receiver")
+	require.Nil(t, r1)
+}
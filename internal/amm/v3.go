@@ -0,0 +1,224 @@
+package amm
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/v3math"
+)
+
+const (
+	// v3TickWords is how many tick bitmap words Expand first reads on each
+	// side of the current one. Each following Expand reads twice as many
+	// words in a swap's direction, up to v3MaxTickWords.
+	v3TickWords    = 2
+	v3MaxTickWords = 32
+)
+
+// TickReader reads the initialized ticks of the tick bitmap words from minWord
+// to maxWord of a V3 pool at a block, sorted by index.
+type TickReader func(
+	ctx context.Context,
+	pool common.Address,
+	tickSpacing int32,
+	minWord, maxWord int16,
+	blockNumber *big.Int,
+) ([]v3math.Tick, error)
+
+// V3Client is what a V3Pool reads: the pool, and the latest block's number
+// its state is read at.
+type V3Client interface {
+	uniswap.V3Reader
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// V3Pool is a Uniswap V3 pool. Its state covers the ticks near its current
+// price: Expand reads the tick bitmap words a swap needs, starting from none.
+type V3Pool struct {
+	client  V3Client
+	address common.Address
+	tokens  []common.Address
+	ticks   TickReader
+
+	// state is nil until the pool's state is read.
+	state       *uniswapdto.V3PoolState
+	blockNumber uint64
+	// words says whether any tick bitmap words are read: those from minWord to maxWord.
+	words            bool
+	minWord, maxWord int16
+	initialized      []v3math.Tick
+}
+
+var _ Expander = (*V3Pool)(nil)
+
+// NewV3Pool returns a pool of token0 and token1 without state, whose ticks are
+// read with ticks, or the client if it is nil. Its state is read with StateAt.
+func NewV3Pool(client V3Client, address, token0, token1 common.Address, ticks TickReader) *V3Pool {
+	return &V3Pool{
+		client:  client,
+		address: address,
+		tokens:  []common.Address{token0, token1},
+		ticks:   ticks,
+	}
+}
+
+// Address is the pool's contract.
+func (p *V3Pool) Address() common.Address { return p.address }
+
+// Tokens are token0 and token1.
+func (p *V3Pool) Tokens() []common.Address { return p.tokens }
+
+// BlockNumber is the block the state was read at.
+func (p *V3Pool) BlockNumber() uint64 { return p.blockNumber }
+
+// Fee is the pool's swap fee in pips, zero if the pool has no state.
+func (p *V3Pool) Fee() uint32 {
+	if p.state == nil {
+		return 0
+	}
+	return p.state.Fee
+}
+
+// Quote returns the output of selling amountIn, as QuoterV2's
+// quoteExactInputSingle does without a price limit.
+//
+// Returns false if the swap moves the price past the words read.
+func (p *V3Pool) Quote(amountIn *big.Int, dir Direction) (*big.Int, bool) {
+	pool, ok := p.math(dir)
+	if !ok {
+		return new(big.Int), false
+	}
+	return pool.QuoteExactIn(amountIn, dir == ZeroForOne)
+}
+
+// QuoteExactOut returns the input needed to buy amountOut, as QuoterV2's
+// quoteExactOutputSingle does without a price limit.
+//
+// Returns false if the swap moves the price past the words read.
+func (p *V3Pool) QuoteExactOut(amountOut *big.Int, dir Direction) (*big.Int, bool) {
+	pool, ok := p.math(dir)
+	if !ok {
+		return new(big.Int), false
+	}
+	return pool.QuoteExactOut(amountOut, dir == ZeroForOne)
+}
+
+// math returns the pool's state for v3math.
+func (p *V3Pool) math(dir Direction) (v3math.Pool, bool) {
+	if p.state == nil || !p.words || (dir != ZeroForOne && dir != OneForZero) {
+		return v3math.Pool{}, false
+	}
+
+	return v3math.Pool{
+		SqrtPriceX96: p.state.SqrtPriceX96,
+		Tick:         p.state.Tick,
+		Liquidity:    p.state.Liquidity,
+		Fee:          p.state.Fee,
+		TickSpacing:  p.state.TickSpacing,
+		Ticks:        p.initialized,
+		MinWord:      p.minWord,
+		MaxWord:      p.maxWord,
+	}, true
+}
+
+// Expand reads the words around the current one, and then twice as many
+// words in the swap's direction each time.
+func (p *V3Pool) Expand(ctx context.Context, dir Direction) (Pool, bool, error) {
+	if p.state == nil {
+		return p, false, nil
+	}
+
+	word := v3math.TickWord(p.state.Tick, p.state.TickSpacing)
+	first := v3math.TickWord(v3math.MinTick, p.state.TickSpacing)
+	last := v3math.TickWord(v3math.MaxTick, p.state.TickSpacing)
+
+	minWord, maxWord := max(word-v3TickWords, first), min(word+v3TickWords, last)
+	if p.words {
+		// Selling token0 moves the price, and the tick, down.
+		minWord, maxWord = p.minWord, p.maxWord
+		switch {
+		case dir == ZeroForOne && minWord > first && word-minWord < v3MaxTickWords:
+			minWord = max(word-2*(word-minWord), first)
+		case dir == OneForZero && maxWord < last && maxWord-word < v3MaxTickWords:
+			maxWord = min(word+2*(maxWord-word), last)
+		default:
+			return p, false, nil
+		}
+	}
+
+	read := p.ticks
+	if read == nil {
+		read = p.readTicks
+	}
+	ticks, err := read(ctx, p.address, p.state.TickSpacing, minWord, maxWord, p.block())
+	if err != nil {
+		return nil, false, errors.Wrap(err, "read")
+	}
+
+	next := *p
+	next.words = true
+	next.minWord, next.maxWord = minWord, maxWord
+	next.initialized = ticks
+	return &next, true, nil
+}
+
+// StateAt reads the pool's price, tick, liquidity, fee and tick spacing at the
+// given block, or the latest one if nil, without any tick bitmap words.
+func (p *V3Pool) StateAt(ctx context.Context, blockNumber *big.Int) (Pool, error) {
+	return p.SlotAt(ctx, blockNumber)
+}
+
+// SlotAt is StateAt returning the pool itself. A nil block is first resolved
+// to the latest block's number, so that Expand reads ticks of the same block.
+func (p *V3Pool) SlotAt(ctx context.Context, blockNumber *big.Int) (*V3Pool, error) {
+	if blockNumber == nil {
+		latest, err := p.client.BlockNumber(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "p.client.BlockNumber")
+		}
+		blockNumber = new(big.Int).SetUint64(latest)
+	}
+
+	state, err := p.client.GetV3PoolState(ctx, p.address, blockNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "p.client.GetV3PoolState")
+	}
+
+	next := *p
+	next.state = state
+	next.blockNumber = blockNumber.Uint64()
+	next.words = false
+	next.minWord, next.maxWord = 0, 0
+	next.initialized = nil
+	return &next, nil
+}
+
+// block is the block the state was read at.
+func (p *V3Pool) block() *big.Int {
+	return new(big.Int).SetUint64(p.blockNumber)
+}
+
+// readTicks is the TickReader of pools created without one.
+func (p *V3Pool) readTicks(
+	ctx context.Context,
+	pool common.Address,
+	tickSpacing int32,
+	minWord, maxWord int16,
+	blockNumber *big.Int,
+) ([]v3math.Tick, error) {
+	ticks, err := p.client.GetV3Ticks(ctx, pool, tickSpacing, minWord, maxWord, blockNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "p.client.GetV3Ticks")
+	}
+
+	out := make([]v3math.Tick, len(ticks))
+	for i, t := range ticks {
+		out[i] = v3math.Tick{Index: t.Index, LiquidityNet: t.LiquidityNet}
+	}
+	return out, nil
+}
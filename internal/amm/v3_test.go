@@ -0,0 +1,74 @@
+package amm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+	"github.com/fleshka4/1inch-test-task/internal/v3math"
+)
+
+func TestV3Pool_Expand(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	address := common.HexToAddress("0x1234")
+	block := big.NewInt(19_000_000)
+
+	mockClient := mock.NewMockClient(ctrl)
+	mockClient.EXPECT().
+		GetV3PoolState(gomock.Any(), address, block).
+		Return(&uniswapdto.V3PoolState{
+			SqrtPriceX96: new(big.Int).Set(v3math.Q96),
+			Liquidity:    big.NewInt(1e18),
+			Fee:          3000,
+			TickSpacing:  60,
+		}, nil)
+
+	// One position over [-600, 600] with the price at tick 0.
+	var reads [][2]int16
+	ticks := func(_ context.Context, _ common.Address, _ int32, minWord, maxWord int16, blockNumber *big.Int) ([]v3math.Tick, error) {
+		require.Equal(t, block, blockNumber)
+		reads = append(reads, [2]int16{minWord, maxWord})
+		return []v3math.Tick{
+			{Index: -600, LiquidityNet: big.NewInt(1e18)},
+			{Index: 600, LiquidityNet: big.NewInt(-1e18)},
+		}, nil
+	}
+
+	var pool Pool = NewV3Pool(mockClient, address, common.HexToAddress("0xa"), common.HexToAddress("0xb"), ticks)
+	pool, err := pool.StateAt(context.Background(), block)
+	require.NoError(t, err)
+
+	_, ok := pool.Quote(big.NewInt(1e15), ZeroForOne)
+	require.False(t, ok, "a pool without words cannot quote")
+
+	// A swap that runs out of liquidity reads twice as many words each time,
+	// up to 32 in its direction.
+	for {
+		next, more, err := pool.(Expander).Expand(context.Background(), ZeroForOne)
+		require.NoError(t, err)
+		if !more {
+			break
+		}
+		pool = next
+	}
+	require.Equal(t, [][2]int16{{-2, 2}, {-4, 2}, {-8, 2}, {-16, 2}, {-32, 2}}, reads)
+
+	out, ok := pool.Quote(big.NewInt(1e15), ZeroForOne)
+	require.True(t, ok)
+	in, ok := pool.QuoteExactOut(out, ZeroForOne)
+	require.True(t, ok)
+	require.LessOrEqual(t, in.Cmp(big.NewInt(1e15)), 0)
+
+	_, ok = pool.Quote(big.NewInt(1e18), ZeroForOne)
+	require.False(t, ok, "the swap exceeds the liquidity of the words read")
+}
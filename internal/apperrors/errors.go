@@ -9,4 +9,20 @@ var (
 	// ErrInsufficientLiquidity is returned when the pool does not have enough
 	// reserves to satisfy the requested swap.
 	ErrInsufficientLiquidity = errors.New("insufficient liquidity")
+
+	// ErrNotAllowed is returned when the pool or one of the tokens is denied by
+	// the chain's allowlist/denylist policy.
+	ErrNotAllowed = errors.New("not allowed")
+
+	// ErrUnverifiedPool is returned in strict pair verification mode when the pool
+	// cannot be traced to any of the chain's configured factories.
+	ErrUnverifiedPool = errors.New("unverified pool")
+
+	// ErrPriceDeviation is returned when the pool's spot price deviates from its
+	// time-weighted average price by more than the chain allows.
+	ErrPriceDeviation = errors.New("price deviates from twap")
+
+	// ErrStalePool is returned when the pool's reserves were last updated longer
+	// ago than the request or the chain allows.
+	ErrStalePool = errors.New("stale pool")
 )
@@ -1,31 +1,201 @@
 package config
 
 import (
+	"io"
 	"log"
 	"os"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
+	"go.uber.org/multierr"
 	"gopkg.in/yaml.v3"
 )
 
 // Config holds application configuration loaded from file.
 type Config struct {
-	RPCURL            string        `yaml:"rpc_url"`
+	// RPCURL is the legacy single-chain endpoint. When Chains is empty it is
+	// turned into a single Ethereum mainnet chain; otherwise it replaces the
+	// rpc_urls of the default chain, the first one.
+	RPCURL            string        `yaml:"rpc_url,omitempty"`
 	ListenAddr        string        `yaml:"listen_addr"`
 	GraceTimeout      time.Duration `yaml:"shutdown_timeout"`
 	RequestTimeout    time.Duration `yaml:"request_timeout"`
 	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
 	CallTimeout       time.Duration `yaml:"call_timeout"`
+	// HistoryTimeout bounds a whole /estimate/history stream.
+	HistoryTimeout time.Duration `yaml:"history_timeout"`
+	// MaxHistoryRequests is the number of /estimate/history streams served
+	// at once; more are rejected.
+	MaxHistoryRequests int `yaml:"max_history_requests"`
+	// AdminToken protects the admin endpoints, which are disabled when it is empty.
+	AdminToken string `yaml:"admin_token,omitempty"`
+
+	// Chains lists every chain served by this instance. The first one is used
+	// when a request does not specify a chain.
+	Chains []ChainConfig `yaml:"chains"`
+
+	// Arbitrage configures the scanner of the chains' tracked_pools.
+	Arbitrage ArbitrageConfig `yaml:"arbitrage"`
+}
+
+// ArbitrageConfig configures the background arbitrage scanner, which runs
+// while any chain has tracked pools.
+type ArbitrageConfig struct {
+	// Interval is the time between scans.
+	Interval time.Duration `yaml:"interval"`
+	// MinProfitBps is the least profit, relative to the input, that is reported.
+	MinProfitBps uint32 `yaml:"min_profit_bps"`
+	// MaxCycleLength is the largest number of swaps in a cycle, from 2 to 4.
+	MaxCycleLength int `yaml:"max_cycle_length"`
+}
+
+// ChainConfig holds per-chain settings.
+type ChainConfig struct {
+	Name      string          `yaml:"name"`
+	ChainID   uint64          `yaml:"chain_id"`
+	RPCURLs   []string        `yaml:"rpc_urls"`
+	Multicall common.Address  `yaml:"multicall"`
+	Factories []FactoryConfig `yaml:"factories"`
+	// FeeBps is the swap fee in basis points applied to pools of this chain
+	// unless their factory overrides it.
+	FeeBps uint32       `yaml:"fee_bps"`
+	Safety SafetyConfig `yaml:"safety"`
+	// PairVerification controls pools that cannot be traced to one of Factories:
+	// "off" skips the check, "permissive" quotes and flags them, "strict" rejects them.
+	PairVerification string `yaml:"pair_verification"`
+	// Tokens lists fee-on-transfer and rebasing tokens of the chain.
+	Tokens []TokenConfig `yaml:"tokens,omitempty"`
+	// DetectTokenTax measures the transfer tax of tokens missing from Tokens with
+	// an eth_call using state overrides, which the RPC endpoints must support.
+	DetectTokenTax bool `yaml:"detect_token_tax"`
+	// CrossCheckRatio is the share of quotes, from 0 to 1, compared with the
+	// router of the pool's factory at the block the reserves were read at.
+	CrossCheckRatio float64 `yaml:"cross_check_ratio"`
+	// TrackedPools are scanned for arbitrage cycles. They are restart-only, as
+	// the scanner only starts if some chain tracks pools at startup.
+	TrackedPools []common.Address `yaml:"tracked_pools,omitempty"`
+	// MaxTWAPDeviationBps rejects quotes from pools whose spot price deviates
+	// from their TWAP over TWAPWindow by more; zero disables the check.
+	MaxTWAPDeviationBps uint32        `yaml:"max_twap_deviation_bps"`
+	TWAPWindow          time.Duration `yaml:"twap_window"`
+	// MaxPoolAge rejects quotes from pools whose reserves were last updated
+	// longer ago; zero disables the check.
+	MaxPoolAge time.Duration `yaml:"max_pool_age"`
+	// Pools fixes the type of pools that are not detected reliably, such as
+	// pools behind upgradeable proxies.
+	Pools []PoolConfig `yaml:"pools,omitempty"`
+	// Indexer keeps the reserve history of uniswap-v2 pairs in a local file.
+	Indexer IndexerConfig `yaml:"indexer,omitempty"`
+}
+
+// IndexerConfig configures the backfill of pairs' Sync and Swap logs into a
+// local store, which serves their reserves at past blocks. It is enabled by
+// listing pools.
+type IndexerConfig struct {
+	// File is the store of the chain's indexed logs, created if missing.
+	File  string           `yaml:"file"`
+	Pools []common.Address `yaml:"pools"`
+	// StartBlock is the first block indexed for every pool.
+	StartBlock uint64 `yaml:"start_block"`
+	// ChunkSize is the largest block range of one eth_getLogs call; ranges the
+	// RPC node refuses are split.
+	ChunkSize uint64 `yaml:"chunk_size"`
+	// Confirmations is how many blocks behind the latest one the file stays; the
+	// more recent blocks are kept in memory and rolled back on reorganisations,
+	// which must not reach deeper.
+	Confirmations uint64 `yaml:"confirmations"`
+	// Interval is the time between catching up with new blocks.
+	Interval time.Duration `yaml:"interval"`
+}
+
+// PoolConfig gives the type of a pool, one of the factory types.
+type PoolConfig struct {
+	Address common.Address `yaml:"address"`
+	Type    string         `yaml:"type"`
+}
+
+// TokenConfig describes how a token deviates from a plain ERC-20 transfer.
+type TokenConfig struct {
+	Address common.Address `yaml:"address"`
+	// InputTaxBps is the tax on transfers to a pool (selling the token).
+	InputTaxBps uint32 `yaml:"input_tax_bps"`
+	// OutputTaxBps is the tax on transfers from a pool (buying the token).
+	OutputTaxBps uint32 `yaml:"output_tax_bps"`
+	Rebasing     bool   `yaml:"rebasing"`
 }
 
+// Pair verification modes.
+const (
+	PairVerificationOff        = "off"
+	PairVerificationPermissive = "permissive"
+	PairVerificationStrict     = "strict"
+)
+
+// Factory types.
+const (
+	FactoryTypeUniswapV2 = "uniswap-v2"
+	FactoryTypeUniswapV3 = "uniswap-v3"
+	FactoryTypeSolidly   = "solidly"
+	// FactoryTypeCurve is a Curve registry answering is_registered(pool),
+	// such as the MetaRegistry, rather than a factory.
+	FactoryTypeCurve = "curve"
+	// FactoryTypeBalancerWeighted is a Balancer weighted pool factory answering
+	// isPoolFromFactory(pool).
+	FactoryTypeBalancerWeighted = "balancer-weighted"
+)
+
+// SafetyConfig restricts the pools and tokens that may be quoted on a chain.
+// Denylists always win; a non-empty allowlist rejects everything not on it.
+type SafetyConfig struct {
+	PoolAllowlist  []common.Address `yaml:"pool_allowlist,omitempty"`
+	PoolDenylist   []common.Address `yaml:"pool_denylist,omitempty"`
+	TokenAllowlist []common.Address `yaml:"token_allowlist,omitempty"`
+	TokenDenylist  []common.Address `yaml:"token_denylist,omitempty"`
+	// TokenList is a Uniswap format token list JSON file whose tokens for the
+	// chain are added to the token allowlist. It is re-read on every reload.
+	TokenList string `yaml:"token_list,omitempty"`
+}
+
+// FactoryConfig describes a factory deployed on a chain.
+type FactoryConfig struct {
+	Name string `yaml:"name"`
+	// Type is the kind of pools the factory deploys, uniswap-v2 by default.
+	Type         string         `yaml:"type,omitempty"`
+	Address      common.Address `yaml:"address"`
+	InitCodeHash common.Hash    `yaml:"init_code_hash"`
+	// FeeBps is the swap fee of uniswap-v2 pairs; pools of the other types
+	// report their own, and solidly factories the fee of each pair.
+	FeeBps uint32 `yaml:"fee_bps"`
+	// Router is the factory's UniswapV2Router02, used to cross-check quotes.
+	Router common.Address `yaml:"router,omitempty"`
+}
+
+const (
+	legacyChainName = "ethereum"
+	legacyChainID   = 1
+)
+
 // Load reads the config from a YAML file path.
 // Fails if config is invalid or file is missing.
 func Load(path string) (*Config, error) {
+	var cfg Config
+	if err := decodeFile(path, &cfg); err != nil {
+		return nil, errors.Wrap(err, "decodeFile")
+	}
+
+	if err := cfg.finalize(); err != nil {
+		return nil, errors.Wrap(err, "cfg.finalize")
+	}
+
+	return &cfg, nil
+}
+
+func decodeFile(path string, cfg *Config) error {
 	//nolint:gosec
 	file, err := os.Open(path)
 	if err != nil {
-		return nil, errors.Wrap(err, "os.Open")
+		return errors.Wrap(err, "os.Open")
 	}
 	defer func() {
 		if err := file.Close(); err != nil {
@@ -33,25 +203,37 @@ func Load(path string) (*Config, error) {
 		}
 	}()
 
-	var cfg Config
 	decoder := yaml.NewDecoder(file)
-	if err := decoder.Decode(&cfg); err != nil {
-		return nil, errors.Wrap(err, "decoder.Decode")
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return errors.Wrap(err, "decoder.Decode")
 	}
 
-	if cfg.RPCURL == "" {
-		return nil, errors.New("rpc_url is required")
-	}
+	return nil
+}
 
-	cfg.applyDefaults()
+// finalize fills defaults into the merged config and validates it,
+// reporting all problems at once.
+func (c *Config) finalize() error {
+	errs := c.validateRaw()
 
-	return &cfg, nil
+	c.applyDefaults()
+
+	return multierr.Append(errs, c.Validate())
 }
 
 func (c *Config) applyDefaults() {
 	const (
-		defaultTimeout = 5 * time.Second
-		listenAddr     = ":1337"
+		defaultTimeout        = 5 * time.Second
+		defaultHistoryTimeout = time.Minute
+		defaultHistoryStreams = 4
+		listenAddr            = ":1337"
+		defaultFeeBps         = 30
+		defaultScanInterval   = 15 * time.Second
+		defaultMaxCycleLength = 3
+		defaultChunkSize      = 2000
+		defaultConfirmations  = 12
+		defaultIndexInterval  = 15 * time.Second
 	)
 
 	if c.ListenAddr == "" {
@@ -69,4 +251,55 @@ func (c *Config) applyDefaults() {
 	if c.CallTimeout <= 0 {
 		c.CallTimeout = defaultTimeout
 	}
+	if c.HistoryTimeout <= 0 {
+		c.HistoryTimeout = defaultHistoryTimeout
+	}
+	if c.MaxHistoryRequests == 0 {
+		c.MaxHistoryRequests = defaultHistoryStreams
+	}
+	if c.Arbitrage.Interval <= 0 {
+		c.Arbitrage.Interval = defaultScanInterval
+	}
+	if c.Arbitrage.MaxCycleLength == 0 {
+		c.Arbitrage.MaxCycleLength = defaultMaxCycleLength
+	}
+
+	if c.RPCURL != "" {
+		if len(c.Chains) == 0 {
+			c.Chains = []ChainConfig{{
+				Name:    legacyChainName,
+				ChainID: legacyChainID,
+			}}
+		}
+		c.Chains[0].RPCURLs = []string{c.RPCURL}
+	}
+
+	for i := range c.Chains {
+		chain := &c.Chains[i]
+		if chain.FeeBps == 0 {
+			chain.FeeBps = defaultFeeBps
+		}
+		if chain.PairVerification == "" {
+			chain.PairVerification = PairVerificationPermissive
+		}
+		if indexer := &chain.Indexer; len(indexer.Pools) > 0 {
+			if indexer.ChunkSize == 0 {
+				indexer.ChunkSize = defaultChunkSize
+			}
+			if indexer.Confirmations == 0 {
+				indexer.Confirmations = defaultConfirmations
+			}
+			if indexer.Interval <= 0 {
+				indexer.Interval = defaultIndexInterval
+			}
+		}
+		for j := range chain.Factories {
+			if chain.Factories[j].Type == "" {
+				chain.Factories[j].Type = FactoryTypeUniswapV2
+			}
+			if chain.Factories[j].FeeBps == 0 {
+				chain.Factories[j].FeeBps = chain.FeeBps
+			}
+		}
+	}
 }
@@ -0,0 +1,256 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	envPrefix   = "ESTIMATOR_"
+	fileSuffix  = "_FILE"
+	defaultPath = "cfg/config.yaml"
+)
+
+// LookupEnvFunc looks up an environment variable, as os.LookupEnv does.
+type LookupEnvFunc func(key string) (string, bool)
+
+// override is a config field that can be set from the environment and flags.
+type override struct {
+	key   string
+	usage string
+	apply func(c *Config, value string) error
+}
+
+var overrides = []override{
+	{key: "rpc_url", usage: "legacy single-chain RPC URL", apply: setString(func(c *Config) *string { return &c.RPCURL })},
+	{key: "listen_addr", usage: "HTTP listen address", apply: setString(func(c *Config) *string { return &c.ListenAddr })},
+	{key: "shutdown_timeout", usage: "graceful shutdown timeout", apply: setDuration(func(c *Config) *time.Duration { return &c.GraceTimeout })},
+	{key: "request_timeout", usage: "per-request timeout", apply: setDuration(func(c *Config) *time.Duration { return &c.RequestTimeout })},
+	{key: "read_header_timeout", usage: "HTTP read header timeout", apply: setDuration(func(c *Config) *time.Duration { return &c.ReadHeaderTimeout })},
+	{key: "call_timeout", usage: "per RPC call timeout", apply: setDuration(func(c *Config) *time.Duration { return &c.CallTimeout })},
+	{key: "history_timeout", usage: "timeout of a whole estimate history stream", apply: setDuration(func(c *Config) *time.Duration { return &c.HistoryTimeout })},
+	{key: "admin_token", usage: "bearer token for admin endpoints", apply: setString(func(c *Config) *string { return &c.AdminToken })},
+}
+
+// Loader builds the configuration from layered sources with increasing precedence:
+// defaults, YAML file, environment variables, command-line flags.
+//
+// Every field listed in overrides can be set with the ESTIMATOR_<KEY> variable
+// (e.g. ESTIMATOR_RPC_URL) or the -<key> flag (e.g. -rpc-url). Chain endpoints are
+// set with ESTIMATOR_CHAIN_<NAME>_RPC_URLS or -chain-rpc-urls name=url1,url2.
+// Each variable also has a <VAR>_FILE variant that reads the value from a file,
+// which is the preferred way to pass secrets.
+type Loader struct {
+	fs        *flag.FlagSet
+	lookupEnv LookupEnvFunc
+
+	path      *string
+	flags     map[string]*string
+	chainURLs chainURLsFlag
+}
+
+// NewLoader registers the config flags on fs. Flags must be parsed before Load is called.
+func NewLoader(fs *flag.FlagSet, lookupEnv LookupEnvFunc) *Loader {
+	l := &Loader{
+		fs:        fs,
+		lookupEnv: lookupEnv,
+		flags:     make(map[string]*string, len(overrides)),
+		chainURLs: make(chainURLsFlag),
+	}
+
+	l.path = fs.String("config", "", "path to YAML config (default $CONFIG_PATH or "+defaultPath+")")
+	for _, o := range overrides {
+		l.flags[o.key] = fs.String(flagName(o.key), "", o.usage)
+	}
+	fs.Var(l.chainURLs, "chain-rpc-urls", "chain RPC URLs as name=url1,url2 (repeatable)")
+
+	return l
+}
+
+// Path returns the YAML config path and whether it was set explicitly.
+func (l *Loader) Path() (string, bool) {
+	if *l.path != "" {
+		return *l.path, true
+	}
+	if path, ok := l.lookupEnv("CONFIG_PATH"); ok && path != "" {
+		return path, true
+	}
+	return defaultPath, false
+}
+
+// Load merges all config sources, applies defaults and validates the result.
+// The YAML file may be absent only when its path was not set explicitly.
+func (l *Loader) Load() (*Config, error) {
+	var cfg Config
+
+	path, explicit := l.Path()
+	if err := decodeFile(path, &cfg); err != nil {
+		if explicit || !errors.Is(err, os.ErrNotExist) {
+			return nil, errors.Wrap(err, "decodeFile")
+		}
+	}
+
+	if err := l.applyEnv(&cfg); err != nil {
+		return nil, errors.Wrap(err, "l.applyEnv")
+	}
+
+	if err := l.applyFlags(&cfg); err != nil {
+		return nil, errors.Wrap(err, "l.applyFlags")
+	}
+
+	if err := cfg.finalize(); err != nil {
+		return nil, errors.Wrap(err, "cfg.finalize")
+	}
+
+	return &cfg, nil
+}
+
+func (l *Loader) applyEnv(cfg *Config) error {
+	for _, o := range overrides {
+		value, ok, err := l.env(envPrefix + strings.ToUpper(o.key))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if err := o.apply(cfg, value); err != nil {
+			return errors.Wrapf(err, "env %s", envPrefix+strings.ToUpper(o.key))
+		}
+	}
+
+	for i := range cfg.Chains {
+		name := envPrefix + "CHAIN_" + envName(cfg.Chains[i].Name) + "_RPC_URLS"
+		value, ok, err := l.env(name)
+		if err != nil {
+			return err
+		}
+		if ok {
+			cfg.Chains[i].RPCURLs = splitList(value)
+		}
+	}
+
+	return nil
+}
+
+// env returns the value of the variable or the trimmed content of the file named by <key>_FILE.
+func (l *Loader) env(key string) (string, bool, error) {
+	value, ok := l.lookupEnv(key)
+	path, fileOK := l.lookupEnv(key + fileSuffix)
+
+	switch {
+	case ok && fileOK:
+		return "", false, errors.Errorf("both %s and %s are set", key, key+fileSuffix)
+	case fileOK:
+		//nolint:gosec
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, errors.Wrapf(err, "os.ReadFile(%s)", key+fileSuffix)
+		}
+		return strings.TrimSpace(string(content)), true, nil
+	default:
+		return value, ok, nil
+	}
+}
+
+func (l *Loader) applyFlags(cfg *Config) error {
+	set := make(map[string]bool)
+	l.fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	for _, o := range overrides {
+		if !set[flagName(o.key)] {
+			continue
+		}
+		if err := o.apply(cfg, *l.flags[o.key]); err != nil {
+			return errors.Wrapf(err, "flag -%s", flagName(o.key))
+		}
+	}
+
+	for name, urls := range l.chainURLs {
+		chain := cfg.chainByName(name)
+		if chain == nil {
+			return errors.Errorf("flag -chain-rpc-urls: unknown chain %s", name)
+		}
+		chain.RPCURLs = urls
+	}
+
+	return nil
+}
+
+func (c *Config) chainByName(name string) *ChainConfig {
+	for i := range c.Chains {
+		if strings.EqualFold(c.Chains[i].Name, name) {
+			return &c.Chains[i]
+		}
+	}
+	return nil
+}
+
+// chainURLsFlag collects repeated -chain-rpc-urls name=url1,url2 flags.
+type chainURLsFlag map[string][]string
+
+func (f chainURLsFlag) String() string {
+	parts := make([]string, 0, len(f))
+	for name, urls := range f {
+		parts = append(parts, name+"="+strings.Join(urls, ","))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (f chainURLsFlag) Set(value string) error {
+	name, urls, ok := strings.Cut(value, "=")
+	if !ok || name == "" || urls == "" {
+		return errors.New("expected name=url1,url2")
+	}
+	f[name] = splitList(urls)
+	return nil
+}
+
+func setString(field func(c *Config) *string) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+func setDuration(field func(c *Config) *time.Duration) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return errors.Wrap(err, "time.ParseDuration")
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+func flagName(key string) string {
+	return strings.ReplaceAll(key, "_", "-")
+}
+
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+func splitList(value string) []string {
+	var out []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testYAML = `
+rpc_url: "https://yaml.example"
+request_timeout: 3s
+call_timeout: 2s
+`
+
+const testChainsYAML = `
+chains:
+  - name: ethereum
+    chain_id: 1
+    rpc_urls: ["https://yaml.example"]
+    multicall: "0xcA11bde05977b3631167028862bE2a173976CA11"
+  - name: base
+    chain_id: 8453
+    rpc_urls: ["https://base.example"]
+`
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func envMap(env map[string]string) LookupEnvFunc {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+}
+
+func load(t *testing.T, args []string, env map[string]string) (*Config, error) {
+	t.Helper()
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	loader := NewLoader(fs, envMap(env))
+	require.NoError(t, fs.Parse(args))
+
+	return loader.Load()
+}
+
+func TestLoader_Precedence(t *testing.T) {
+	t.Parallel()
+
+	path := writeFile(t, "config.yaml", testYAML)
+
+	tests := []struct {
+		name            string
+		args            []string
+		env             map[string]string
+		wantRPC         string
+		wantRequest     time.Duration
+		wantCall        time.Duration
+		wantReadHeaders time.Duration
+	}{
+		{
+			name:            "yaml over defaults",
+			args:            []string{"-config", path},
+			wantRPC:         "https://yaml.example",
+			wantRequest:     3 * time.Second,
+			wantCall:        2 * time.Second,
+			wantReadHeaders: 5 * time.Second,
+		},
+		{
+			name: "env over yaml",
+			args: []string{"-config", path},
+			env: map[string]string{
+				"ESTIMATOR_RPC_URL":         "https://env.example",
+				"ESTIMATOR_REQUEST_TIMEOUT": "7s",
+			},
+			wantRPC:         "https://env.example",
+			wantRequest:     7 * time.Second,
+			wantCall:        2 * time.Second,
+			wantReadHeaders: 5 * time.Second,
+		},
+		{
+			name: "flags over env",
+			args: []string{"-config", path, "-rpc-url", "https://flag.example", "-request-timeout", "9s"},
+			env: map[string]string{
+				"ESTIMATOR_RPC_URL":         "https://env.example",
+				"ESTIMATOR_REQUEST_TIMEOUT": "7s",
+			},
+			wantRPC:         "https://flag.example",
+			wantRequest:     9 * time.Second,
+			wantCall:        2 * time.Second,
+			wantReadHeaders: 5 * time.Second,
+		},
+		{
+			name: "config path from env",
+			env: map[string]string{
+				"CONFIG_PATH":            path,
+				"ESTIMATOR_CALL_TIMEOUT": "1s",
+			},
+			wantRPC:         "https://yaml.example",
+			wantRequest:     3 * time.Second,
+			wantCall:        time.Second,
+			wantReadHeaders: 5 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg, err := load(t, tt.args, tt.env)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.wantRPC, cfg.RPCURL)
+			require.Equal(t, []string{tt.wantRPC}, cfg.Chains[0].RPCURLs)
+			require.Equal(t, tt.wantRequest, cfg.RequestTimeout)
+			require.Equal(t, tt.wantCall, cfg.CallTimeout)
+			require.Equal(t, tt.wantReadHeaders, cfg.ReadHeaderTimeout)
+		})
+	}
+}
+
+func TestLoader_Secrets(t *testing.T) {
+	t.Parallel()
+
+	path := writeFile(t, "config.yaml", testChainsYAML)
+	secret := writeFile(t, "secret", "https://secret.example/key\n")
+
+	t.Run("file variant", func(t *testing.T) {
+		t.Parallel()
+
+		cfg, err := load(t, []string{"-config", path}, map[string]string{
+			"ESTIMATOR_CHAIN_BASE_RPC_URLS_FILE": secret,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"https://yaml.example"}, cfg.Chains[0].RPCURLs)
+		require.Equal(t, common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11"), cfg.Chains[0].Multicall)
+		require.Equal(t, []string{"https://secret.example/key"}, cfg.Chains[1].RPCURLs)
+	})
+
+	t.Run("chain flag over env", func(t *testing.T) {
+		t.Parallel()
+
+		cfg, err := load(t, []string{"-config", path, "-chain-rpc-urls", "base=https://a, https://b"},
+			map[string]string{"ESTIMATOR_CHAIN_BASE_RPC_URLS": "https://env.example"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"https://a", "https://b"}, cfg.Chains[1].RPCURLs)
+	})
+
+	t.Run("rpc_url over the default chain", func(t *testing.T) {
+		t.Parallel()
+
+		cfg, err := load(t, []string{"-config", path}, map[string]string{"ESTIMATOR_RPC_URL": "https://env.example/key"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"https://env.example/key"}, cfg.Chains[0].RPCURLs)
+		require.Equal(t, []string{"https://base.example"}, cfg.Chains[1].RPCURLs)
+	})
+
+	t.Run("value and file both set", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := load(t, []string{"-config", path}, map[string]string{
+			"ESTIMATOR_CHAIN_BASE_RPC_URLS":      "https://env.example",
+			"ESTIMATOR_CHAIN_BASE_RPC_URLS_FILE": secret,
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("missing secret file", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := load(t, []string{"-config", path}, map[string]string{
+			"ESTIMATOR_CHAIN_BASE_RPC_URLS_FILE": filepath.Join(t.TempDir(), "missing"),
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestLoader_Errors(t *testing.T) {
+	t.Parallel()
+
+	path := writeFile(t, "config.yaml", testChainsYAML)
+
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "explicit missing file",
+			args:    []string{"-config", filepath.Join(t.TempDir(), "missing.yaml")},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "default missing file with env only",
+			env:     map[string]string{"ESTIMATOR_RPC_URL": "https://env.example"},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "bad duration",
+			args:    []string{"-config", path},
+			env:     map[string]string{"ESTIMATOR_CALL_TIMEOUT": "soon"},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "unknown chain flag",
+			args:    []string{"-config", path, "-chain-rpc-urls", "polygon=https://a"},
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := load(t, tt.args, tt.env)
+			tt.wantErr(t, err)
+		})
+	}
+}
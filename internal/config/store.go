@@ -0,0 +1,269 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Store keeps the active config and replaces it atomically on reload.
+//
+// Only runtime-safe fields may change on reload: request, call and shutdown
+// timeouts, the admin token, chain RPC endpoints and chain safety lists.
+// A reload that changes any other field, or that fails validation or one of
+// the registered checks, is rejected and the previous config stays active.
+type Store struct {
+	loader  *Loader
+	current atomic.Pointer[Config]
+
+	mu     sync.Mutex
+	checks []func(*Config) error
+	hooks  []func(*Config)
+}
+
+// NewStore creates a Store serving cfg until the next successful reload.
+func NewStore(loader *Loader, cfg *Config) *Store {
+	s := &Store{loader: loader}
+	s.current.Store(cfg)
+	return s
+}
+
+// Config returns the active config. The result must not be modified.
+func (s *Store) Config() *Config {
+	return s.current.Load()
+}
+
+// AddCheck registers fn to validate a reloaded config before it is activated,
+// e.g. to verify files referenced by the config.
+func (s *Store) AddCheck(fn func(*Config) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checks = append(s.checks, fn)
+}
+
+// OnReload registers fn to be called with the new config after each successful reload.
+// Hooks run even when the config itself is unchanged, so that they can refresh
+// the files it references.
+func (s *Store) OnReload(fn func(*Config)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hooks = append(s.hooks, fn)
+}
+
+// Reload reads the config sources again and activates the result.
+// It logs and returns the list of changed fields with secrets redacted.
+func (s *Store) Reload() ([]string, error) {
+	changes, err := s.reload()
+	if err != nil {
+		log.Printf("config reload rejected, keeping previous config: %v", err)
+		return nil, err
+	}
+
+	if len(changes) == 0 {
+		log.Println("config reloaded: no config changes")
+		return nil, nil
+	}
+
+	log.Printf("config reloaded, %d change(s):", len(changes))
+	for _, change := range changes {
+		log.Printf("  %s", change)
+	}
+
+	return changes, nil
+}
+
+func (s *Store) reload() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next, err := s.loader.Load()
+	if err != nil {
+		return nil, errors.Wrap(err, "s.loader.Load")
+	}
+
+	prev := s.current.Load()
+	if fixed := Diff(prev.withoutRuntimeFields(), next.withoutRuntimeFields()); len(fixed) > 0 {
+		return nil, errors.Errorf("fields require restart: %s", strings.Join(fixed, "; "))
+	}
+
+	for _, check := range s.checks {
+		if err := check(next); err != nil {
+			return nil, errors.Wrap(err, "check")
+		}
+	}
+
+	changes := Diff(prev, next)
+
+	s.current.Store(next)
+	for _, hook := range s.hooks {
+		hook(next)
+	}
+
+	return changes, nil
+}
+
+// withoutRuntimeFields returns a copy with every runtime-safe field cleared,
+// so that comparing two results reveals restart-only changes.
+func (c *Config) withoutRuntimeFields() *Config {
+	out := c.clone()
+	out.RPCURL = ""
+	out.GraceTimeout = 0
+	out.RequestTimeout = 0
+	out.CallTimeout = 0
+	out.HistoryTimeout = 0
+	out.AdminToken = ""
+
+	for i := range out.Chains {
+		out.Chains[i].RPCURLs = nil
+		out.Chains[i].Safety = SafetyConfig{}
+	}
+
+	return out
+}
+
+func (c *Config) clone() *Config {
+	out := *c
+
+	out.Chains = make([]ChainConfig, len(c.Chains))
+	for i, chain := range c.Chains {
+		chain.RPCURLs = append([]string(nil), chain.RPCURLs...)
+		chain.Factories = append([]FactoryConfig(nil), chain.Factories...)
+		chain.Tokens = append([]TokenConfig(nil), chain.Tokens...)
+		chain.Pools = append([]PoolConfig(nil), chain.Pools...)
+		chain.TrackedPools = append([]common.Address(nil), chain.TrackedPools...)
+		chain.Indexer.Pools = append([]common.Address(nil), chain.Indexer.Pools...)
+		chain.Safety = chain.Safety.clone()
+		out.Chains[i] = chain
+	}
+
+	return &out
+}
+
+// Diff lists the fields that differ between two configs as
+// "path: old -> new" lines, with secrets redacted.
+func Diff(prev, next *Config) []string {
+	before, after, err := flattenPair(prev, next)
+	if err != nil {
+		return []string{"config: " + err.Error()}
+	}
+	shownBefore, shownAfter, err := flattenPair(prev.Redacted(), next.Redacted())
+	if err != nil {
+		return []string{"config: " + err.Error()}
+	}
+
+	keys := make(map[string]struct{}, len(before)+len(after))
+	for k := range before {
+		keys[k] = struct{}{}
+	}
+	for k := range after {
+		keys[k] = struct{}{}
+	}
+
+	var changes []string
+	for k := range keys {
+		if before[k] == after[k] {
+			continue
+		}
+
+		line := fmt.Sprintf("%s: %s -> %s", k, orNone(shownBefore[k]), orNone(shownAfter[k]))
+		if shownBefore[k] == shownAfter[k] {
+			line += " (secret changed)"
+		}
+		changes = append(changes, line)
+	}
+	sort.Strings(changes)
+
+	return changes
+}
+
+func flattenPair(a, b *Config) (map[string]string, map[string]string, error) {
+	fa, err := flatten(a)
+	if err != nil {
+		return nil, nil, err
+	}
+	fb, err := flatten(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fa, fb, nil
+}
+
+// flatten renders a config as a map of field paths to printable values.
+// Lists of scalars are kept whole so that endpoint changes read naturally.
+func flatten(c *Config) (map[string]string, error) {
+	raw, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, errors.Wrap(err, "yaml.Marshal")
+	}
+
+	var tree any
+	if err := yaml.Unmarshal(raw, &tree); err != nil {
+		return nil, errors.Wrap(err, "yaml.Unmarshal")
+	}
+
+	out := make(map[string]string)
+	flattenInto(out, "", tree)
+
+	return out, nil
+}
+
+func flattenInto(out map[string]string, path string, v any) {
+	switch node := v.(type) {
+	case map[string]any:
+		for k, child := range node {
+			key := k
+			if path != "" {
+				key = path + "." + k
+			}
+			flattenInto(out, key, child)
+		}
+	case []any:
+		if isScalarList(node) {
+			items := make([]string, len(node))
+			for i, item := range node {
+				items[i] = fmt.Sprint(item)
+			}
+			out[path] = "[" + strings.Join(items, ", ") + "]"
+			return
+		}
+		for i, child := range node {
+			flattenInto(out, fmt.Sprintf("%s[%d]", path, i), child)
+		}
+	default:
+		out[path] = fmt.Sprint(node)
+	}
+}
+
+func isScalarList(items []any) bool {
+	for _, item := range items {
+		switch item.(type) {
+		case map[string]any, []any:
+			return false
+		}
+	}
+	return true
+}
+
+func orNone(v string) string {
+	if v == "" {
+		return "<none>"
+	}
+	return v
+}
+
+func (c SafetyConfig) clone() SafetyConfig {
+	c.PoolAllowlist = append([]common.Address(nil), c.PoolAllowlist...)
+	c.PoolDenylist = append([]common.Address(nil), c.PoolDenylist...)
+	c.TokenAllowlist = append([]common.Address(nil), c.TokenAllowlist...)
+	c.TokenDenylist = append([]common.Address(nil), c.TokenDenylist...)
+	return c
+}
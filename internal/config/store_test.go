@@ -0,0 +1,140 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+const storeYAML = `
+request_timeout: 3s
+admin_token: "s3cret"
+chains:
+  - name: ethereum
+    chain_id: 1
+    rpc_urls: ["https://rpc.example/v3/key1"]
+`
+
+func newTestStore(t *testing.T, content string) (*Store, string) {
+	t.Helper()
+
+	path := writeFile(t, "config.yaml", content)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	loader := NewLoader(fs, envMap(nil))
+	require.NoError(t, fs.Parse([]string{"-config", path}))
+
+	cfg, err := loader.Load()
+	require.NoError(t, err)
+
+	return NewStore(loader, cfg), path
+}
+
+func TestStore_Reload(t *testing.T) {
+	t.Parallel()
+
+	store, path := newTestStore(t, storeYAML)
+
+	var applied *Config
+	store.OnReload(func(cfg *Config) { applied = cfg })
+
+	// Hooks run without config changes so that referenced files are re-read.
+	changes, err := store.Reload()
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	require.Same(t, store.Config(), applied)
+
+	updated := `
+request_timeout: 4s
+admin_token: "rotated"
+chains:
+  - name: ethereum
+    chain_id: 1
+    rpc_urls: ["https://rpc.example/v3/key2", "https://backup.example"]
+`
+	require.NoError(t, os.WriteFile(path, []byte(updated), 0o600))
+
+	changes, err = store.Reload()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"admin_token: *** -> *** (secret changed)",
+		"chains[0].rpc_urls: [https://rpc.example/***] -> [https://rpc.example/***, https://backup.example]",
+		"request_timeout: 3s -> 4s",
+	}, changes)
+
+	require.Same(t, store.Config(), applied)
+	require.Equal(t, 4*time.Second, store.Config().RequestTimeout)
+}
+
+func TestStore_ReloadRejected(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "invalid config",
+			content: "request_timeout: -4s\n" + storeYAML[len("\nrequest_timeout: 3s\n"):],
+		},
+		{
+			name:    "unparseable config",
+			content: "chains: [",
+		},
+		{
+			name:    "failed check",
+			content: storeYAML,
+		},
+		{
+			name:    "restart-only field",
+			content: "listen_addr: \":9000\"\n" + storeYAML,
+		},
+		{
+			// The history slots are allocated when the server starts.
+			name:    "history limit changed",
+			content: "max_history_requests: 8\n" + storeYAML,
+			wantErr: "fields require restart: max_history_requests",
+		},
+		{
+			name: "chain added",
+			content: storeYAML + `
+  - name: base
+    chain_id: 8453
+    rpc_urls: ["https://base.example"]
+`,
+		},
+		{
+			// The arbitrage scanner only starts if pools are tracked at startup.
+			name: "tracked pools added",
+			content: storeYAML + `    tracked_pools: ["0x0d4a11d5EEaaC28EC3F61d100daF4d40471f1852"]
+`,
+			wantErr: "fields require restart: chains[0].tracked_pools",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store, path := newTestStore(t, storeYAML)
+			prev := store.Config()
+
+			store.AddCheck(func(*Config) error { return errors.New("token list is broken") })
+			store.OnReload(func(*Config) { t.Fatal("hook must not run on rejected reload") })
+
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			_, err := store.Reload()
+			require.Error(t, err)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+			}
+			require.Same(t, prev, store.Config())
+		})
+	}
+}
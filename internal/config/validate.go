@@ -0,0 +1,373 @@
+package config
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+const (
+	maxTimeout      = 10 * time.Minute
+	maxFeeBps       = 10000
+	redacted        = "***"
+	minScanInterval = time.Second
+	maxCycleLength  = 4
+	minTWAPWindow   = time.Minute
+	maxTWAPWindow   = 24 * time.Hour
+	maxChunkSize    = 100000
+)
+
+// Validate checks every field of a config with defaults applied and
+// reports all problems at once.
+func (c *Config) Validate() error {
+	var errs error
+
+	if err := validateListenAddr(c.ListenAddr); err != nil {
+		errs = multierr.Append(errs, errors.Wrap(err, "listen_addr"))
+	}
+
+	for _, t := range c.timeouts() {
+		if t.value <= 0 || t.value > maxTimeout {
+			errs = multierr.Append(errs, errors.Errorf("%s must be in (0, %s], got %s", t.key, maxTimeout, t.value))
+		}
+	}
+
+	if len(c.Chains) == 0 {
+		errs = multierr.Append(errs, errors.New("at least one chain is required"))
+	}
+
+	errs = multierr.Append(errs, c.Arbitrage.validate())
+
+	ids := make(map[uint64]struct{}, len(c.Chains))
+	names := make(map[string]struct{}, len(c.Chains))
+	files := make(map[string]struct{}, len(c.Chains))
+
+	for i, chain := range c.Chains {
+		errs = multierr.Append(errs, chain.validate(i))
+
+		name := strings.ToLower(chain.Name)
+		if _, ok := names[name]; ok && name != "" {
+			errs = multierr.Append(errs, errors.Errorf("duplicate chain name %s", chain.Name))
+		}
+		if _, ok := ids[chain.ChainID]; ok && chain.ChainID != 0 {
+			errs = multierr.Append(errs, errors.Errorf("duplicate chain_id %d", chain.ChainID))
+		}
+		names[name] = struct{}{}
+		ids[chain.ChainID] = struct{}{}
+
+		if file := chain.Indexer.File; file != "" {
+			if _, ok := files[file]; ok {
+				errs = multierr.Append(errs, errors.Errorf("duplicate indexer file %s", file))
+			}
+			files[file] = struct{}{}
+		}
+	}
+
+	return errs
+}
+
+// validateRaw reports problems that applyDefaults would otherwise hide.
+func (c *Config) validateRaw() error {
+	var errs error
+
+	if c.RPCURL == "" && len(c.Chains) == 0 {
+		errs = multierr.Append(errs, errors.New("rpc_url or chains is required"))
+	}
+
+	if c.MaxHistoryRequests < 0 {
+		errs = multierr.Append(errs, errors.Errorf("max_history_requests must not be negative, got %d", c.MaxHistoryRequests))
+	}
+
+	if c.Arbitrage.Interval < 0 {
+		errs = multierr.Append(errs, errors.Errorf("arbitrage.interval must not be negative, got %s", c.Arbitrage.Interval))
+	}
+
+	for _, t := range c.timeouts() {
+		if t.value < 0 {
+			errs = multierr.Append(errs, errors.Errorf("%s must not be negative, got %s", t.key, t.value))
+		}
+	}
+
+	return errs
+}
+
+type namedTimeout struct {
+	key   string
+	value time.Duration
+}
+
+func (c *Config) timeouts() []namedTimeout {
+	return []namedTimeout{
+		{key: "shutdown_timeout", value: c.GraceTimeout},
+		{key: "request_timeout", value: c.RequestTimeout},
+		{key: "read_header_timeout", value: c.ReadHeaderTimeout},
+		{key: "call_timeout", value: c.CallTimeout},
+		{key: "history_timeout", value: c.HistoryTimeout},
+	}
+}
+
+func (c *ChainConfig) validate(idx int) error {
+	var errs error
+
+	prefix := "chains[" + strconv.Itoa(idx) + "]"
+	if c.Name != "" {
+		prefix += " (" + c.Name + ")"
+	}
+
+	if c.Name == "" {
+		errs = multierr.Append(errs, errors.Errorf("%s: name is required", prefix))
+	}
+	if c.ChainID == 0 {
+		errs = multierr.Append(errs, errors.Errorf("%s: chain_id is required", prefix))
+	}
+	if len(c.RPCURLs) == 0 {
+		errs = multierr.Append(errs, errors.Errorf("%s: rpc_urls is required", prefix))
+	}
+	for j, rawURL := range c.RPCURLs {
+		if err := validateRPCURL(rawURL); err != nil {
+			errs = multierr.Append(errs, errors.Wrapf(err, "%s: rpc_urls[%d]", prefix, j))
+		}
+	}
+	if c.FeeBps >= maxFeeBps {
+		errs = multierr.Append(errs, errors.Errorf("%s: fee_bps must be below %d", prefix, maxFeeBps))
+	}
+
+	if c.CrossCheckRatio < 0 || c.CrossCheckRatio > 1 {
+		errs = multierr.Append(errs, errors.Errorf("%s: cross_check_ratio must be in [0, 1], got %v", prefix, c.CrossCheckRatio))
+	}
+
+	if c.MaxTWAPDeviationBps > 0 && (c.TWAPWindow < minTWAPWindow || c.TWAPWindow > maxTWAPWindow) {
+		errs = multierr.Append(errs, errors.Errorf(
+			"%s: twap_window must be in [%s, %s] with max_twap_deviation_bps, got %s",
+			prefix, minTWAPWindow, maxTWAPWindow, c.TWAPWindow,
+		))
+	}
+
+	if c.MaxPoolAge < 0 {
+		errs = multierr.Append(errs, errors.Errorf("%s: max_pool_age cannot be negative, got %s", prefix, c.MaxPoolAge))
+	}
+
+	switch c.PairVerification {
+	case PairVerificationOff, PairVerificationPermissive:
+	case PairVerificationStrict:
+		if len(c.Factories) == 0 {
+			errs = multierr.Append(errs, errors.Errorf("%s: pair_verification strict requires factories", prefix))
+		}
+	default:
+		errs = multierr.Append(errs, errors.Errorf(
+			"%s: pair_verification must be one of off, permissive, strict, got %q", prefix, c.PairVerification,
+		))
+	}
+
+	for j, factory := range c.Factories {
+		if factory.Address == (common.Address{}) {
+			errs = multierr.Append(errs, errors.Errorf("%s: factories[%d]: address is required", prefix, j))
+		}
+		if factory.FeeBps >= maxFeeBps {
+			errs = multierr.Append(errs, errors.Errorf("%s: factories[%d]: fee_bps must be below %d", prefix, j, maxFeeBps))
+		}
+		if !validFactoryType(factory.Type) {
+			errs = multierr.Append(errs, errors.Errorf(
+				"%s: factories[%d]: type must be one of %s, got %q", prefix, j, factoryTypeList, factory.Type,
+			))
+		}
+		if (factory.Type == FactoryTypeCurve || factory.Type == FactoryTypeBalancerWeighted) &&
+			factory.InitCodeHash != (common.Hash{}) {
+			errs = multierr.Append(errs, errors.Errorf("%s: factories[%d]: init_code_hash is not supported for %s", prefix, j, factory.Type))
+		}
+		if factory.Type != FactoryTypeUniswapV2 && factory.Router != (common.Address{}) {
+			errs = multierr.Append(errs, errors.Errorf("%s: factories[%d]: router is only supported for %s", prefix, j, FactoryTypeUniswapV2))
+		}
+	}
+
+	pools := make(map[common.Address]struct{}, len(c.Pools))
+	for j, pool := range c.Pools {
+		if pool.Address == (common.Address{}) {
+			errs = multierr.Append(errs, errors.Errorf("%s: pools[%d]: address is required", prefix, j))
+		}
+		if _, ok := pools[pool.Address]; ok {
+			errs = multierr.Append(errs, errors.Errorf("%s: pools[%d]: duplicate pool %s", prefix, j, pool.Address.Hex()))
+		}
+		pools[pool.Address] = struct{}{}
+		if !validFactoryType(pool.Type) {
+			errs = multierr.Append(errs, errors.Errorf(
+				"%s: pools[%d]: type must be one of %s, got %q", prefix, j, factoryTypeList, pool.Type,
+			))
+		}
+	}
+
+	errs = multierr.Append(errs, c.Indexer.validate(prefix))
+
+	tokens := make(map[common.Address]struct{}, len(c.Tokens))
+	for j, token := range c.Tokens {
+		if token.Address == (common.Address{}) {
+			errs = multierr.Append(errs, errors.Errorf("%s: tokens[%d]: address is required", prefix, j))
+		}
+		if _, ok := tokens[token.Address]; ok {
+			errs = multierr.Append(errs, errors.Errorf("%s: tokens[%d]: duplicate token %s", prefix, j, token.Address.Hex()))
+		}
+		tokens[token.Address] = struct{}{}
+		if token.InputTaxBps >= maxFeeBps || token.OutputTaxBps >= maxFeeBps {
+			errs = multierr.Append(errs, errors.Errorf("%s: tokens[%d]: tax bps must be below %d", prefix, j, maxFeeBps))
+		}
+	}
+
+	return errs
+}
+
+func (c *IndexerConfig) validate(prefix string) error {
+	if len(c.Pools) == 0 {
+		if c.File != "" {
+			return errors.Errorf("%s: indexer.pools is required with indexer.file", prefix)
+		}
+		return nil
+	}
+
+	var errs error
+
+	if c.File == "" {
+		errs = multierr.Append(errs, errors.Errorf("%s: indexer.file is required", prefix))
+	}
+
+	pools := make(map[common.Address]struct{}, len(c.Pools))
+	for j, pool := range c.Pools {
+		if pool == (common.Address{}) {
+			errs = multierr.Append(errs, errors.Errorf("%s: indexer.pools[%d]: address is required", prefix, j))
+		}
+		if _, ok := pools[pool]; ok {
+			errs = multierr.Append(errs, errors.Errorf("%s: indexer.pools[%d]: duplicate pool %s", prefix, j, pool.Hex()))
+		}
+		pools[pool] = struct{}{}
+	}
+
+	if c.ChunkSize > maxChunkSize {
+		errs = multierr.Append(errs, errors.Errorf("%s: indexer.chunk_size must be at most %d, got %d", prefix, maxChunkSize, c.ChunkSize))
+	}
+	if c.Interval < minScanInterval || c.Interval > maxTimeout {
+		errs = multierr.Append(errs, errors.Errorf(
+			"%s: indexer.interval must be in [%s, %s], got %s", prefix, minScanInterval, maxTimeout, c.Interval,
+		))
+	}
+
+	return errs
+}
+
+// factoryTypeList lists the factory types for error messages.
+var factoryTypeList = strings.Join([]string{
+	FactoryTypeUniswapV2, FactoryTypeUniswapV3, FactoryTypeSolidly, FactoryTypeCurve, FactoryTypeBalancerWeighted,
+}, ", ")
+
+func validFactoryType(t string) bool {
+	switch t {
+	case FactoryTypeUniswapV2, FactoryTypeUniswapV3, FactoryTypeSolidly, FactoryTypeCurve, FactoryTypeBalancerWeighted:
+		return true
+	}
+	return false
+}
+
+func (c *ArbitrageConfig) validate() error {
+	var errs error
+
+	if c.Interval < minScanInterval || c.Interval > maxTimeout {
+		errs = multierr.Append(errs, errors.Errorf(
+			"arbitrage.interval must be in [%s, %s], got %s", minScanInterval, maxTimeout, c.Interval,
+		))
+	}
+	if c.MaxCycleLength < 2 || c.MaxCycleLength > maxCycleLength {
+		errs = multierr.Append(errs, errors.Errorf(
+			"arbitrage.max_cycle_length must be in [2, %d], got %d", maxCycleLength, c.MaxCycleLength,
+		))
+	}
+	if c.MinProfitBps >= maxFeeBps {
+		errs = multierr.Append(errs, errors.Errorf("arbitrage.min_profit_bps must be below %d", maxFeeBps))
+	}
+
+	return errs
+}
+
+func validateListenAddr(addr string) error {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return errors.Wrap(err, "net.SplitHostPort")
+	}
+
+	if port == "" {
+		return nil
+	}
+
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return errors.Errorf("bad port %q", port)
+	}
+
+	return nil
+}
+
+func validateRPCURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return errors.New("unparseable url")
+	}
+
+	switch u.Scheme {
+	case "http", "https", "ws", "wss":
+	default:
+		return errors.Errorf("unsupported scheme %q, want http(s) or ws(s)", u.Scheme)
+	}
+
+	if u.Host == "" {
+		return errors.New("host is required")
+	}
+
+	return nil
+}
+
+// Redacted returns a copy of the config that is safe to print:
+// RPC URLs keep only their scheme and host, as API keys usually live in
+// the path, query or user info, and the admin token is masked.
+func (c *Config) Redacted() *Config {
+	out := *c
+	out.RPCURL = redactURL(c.RPCURL)
+	if c.AdminToken != "" {
+		out.AdminToken = redacted
+	}
+
+	out.Chains = make([]ChainConfig, len(c.Chains))
+	for i, chain := range c.Chains {
+		chain.RPCURLs = make([]string, len(c.Chains[i].RPCURLs))
+		for j, rawURL := range c.Chains[i].RPCURLs {
+			chain.RPCURLs[j] = redactURL(rawURL)
+		}
+		chain.Factories = append([]FactoryConfig(nil), c.Chains[i].Factories...)
+		chain.Tokens = append([]TokenConfig(nil), c.Chains[i].Tokens...)
+		chain.Pools = append([]PoolConfig(nil), c.Chains[i].Pools...)
+		chain.TrackedPools = append([]common.Address(nil), c.Chains[i].TrackedPools...)
+		chain.Indexer.Pools = append([]common.Address(nil), c.Chains[i].Indexer.Pools...)
+		chain.Safety = c.Chains[i].Safety.clone()
+		out.Chains[i] = chain
+	}
+
+	return &out
+}
+
+func redactURL(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return redacted
+	}
+
+	if u.User == nil && (u.Path == "" || u.Path == "/") && u.RawQuery == "" && u.Fragment == "" {
+		return rawURL
+	}
+
+	return u.Scheme + "://" + u.Host + "/" + redacted
+}
@@ -0,0 +1,91 @@
+package dexmath
+
+import "math/big"
+
+// sqrtPrec is the precision in bits of the square root in OptimalCycleInput.
+const sqrtPrec = 512
+
+// Hop is a swap through a pool in a direction.
+type Hop struct {
+	Pool PoolState
+	Dir  Direction
+}
+
+// ApplyPath swaps in through the hops in order and returns the output of the last one.
+//
+// Returns false if any of the swaps is not possible.
+func ApplyPath(in *big.Int, hops []Hop) (*big.Int, bool) {
+	amount := in
+	for _, hop := range hops {
+		out, _, ok := hop.Pool.ApplySwap(amount, hop.Dir)
+		if !ok {
+			return out, false
+		}
+		amount = out
+	}
+	return amount, true
+}
+
+// OptimalCycleInput returns the input that maximizes the profit of swapping
+// through hops whose last output token is the first input token.
+//
+// The hops are folded into a single virtual pool with reserves (Ea, Eb) and
+// the first hop's fee multiplier g, whose output is g*x*Eb / (Ea + g*x), so the
+// optimal input is (sqrt(g*Ea*Eb) - Ea) / g in closed form. The result is
+// rounded down; the exact profit should be taken from ApplyPath.
+//
+// Returns false if no input is profitable.
+func OptimalCycleInput(hops []Hop) (*big.Int, bool) {
+	if len(hops) == 0 {
+		return nil, false
+	}
+
+	var ea, eb *big.Rat
+	var g *big.Rat
+	for i, hop := range hops {
+		if hop.Pool.FeeBps >= 10000 {
+			return nil, false
+		}
+
+		ra, rb := hop.Pool.Reserve0, hop.Pool.Reserve1
+		if hop.Dir == OneForZero {
+			ra, rb = rb, ra
+		}
+		if ra.Sign() <= 0 || rb.Sign() <= 0 {
+			return nil, false
+		}
+
+		gamma := big.NewRat(int64(10000-hop.Pool.FeeBps), 10000)
+		if i == 0 {
+			ea, eb, g = new(big.Rat).SetInt(ra), new(big.Rat).SetInt(rb), gamma
+			continue
+		}
+
+		// Ea' = Ea*Ra / (Ra + g2*Eb), Eb' = g2*Eb*Rb / (Ra + g2*Eb).
+		ra2, rb2 := new(big.Rat).SetInt(ra), new(big.Rat).SetInt(rb)
+		gEb := new(big.Rat).Mul(gamma, eb)
+		den := new(big.Rat).Add(ra2, gEb)
+
+		ea = new(big.Rat).Quo(new(big.Rat).Mul(ea, ra2), den)
+		eb = new(big.Rat).Quo(new(big.Rat).Mul(gEb, rb2), den)
+	}
+
+	// Profitable only if the marginal rate at zero input, g*Eb/Ea, exceeds 1.
+	if new(big.Rat).Mul(g, eb).Cmp(ea) <= 0 {
+		return nil, false
+	}
+
+	product := new(big.Rat).Mul(g, new(big.Rat).Mul(ea, eb))
+	root := new(big.Float).SetPrec(sqrtPrec).SetRat(product)
+	root.Sqrt(root)
+
+	x := new(big.Float).SetPrec(sqrtPrec).SetRat(ea)
+	x.Sub(root, x)
+	x.Quo(x, new(big.Float).SetPrec(sqrtPrec).SetRat(g))
+
+	in, _ := x.Int(nil)
+	if in.Sign() <= 0 {
+		return nil, false
+	}
+	return in, true
+}
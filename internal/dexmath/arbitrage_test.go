@@ -0,0 +1,109 @@
+package dexmath
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestOptimalCycleInput(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		hops []Hop
+	}{
+		{
+			// token0 buys 3 token1 in the first pool and sells 2 token1 in the second.
+			name: "two pools",
+			hops: []Hop{
+				{Pool: PoolState{Reserve0: bi("10000"), Reserve1: bi("30000"), FeeBps: 30}, Dir: ZeroForOne},
+				{Pool: PoolState{Reserve0: bi("10000"), Reserve1: bi("20000"), FeeBps: 30}, Dir: OneForZero},
+			},
+		},
+		{
+			name: "triangle with mixed fees",
+			hops: []Hop{
+				{Pool: PoolState{Reserve0: bi("10000"), Reserve1: bi("20000"), FeeBps: 30}, Dir: ZeroForOne},
+				{Pool: PoolState{Reserve0: bi("30000"), Reserve1: bi("20000"), FeeBps: 25}, Dir: OneForZero},
+				{Pool: PoolState{Reserve0: bi("12000"), Reserve1: bi("30000"), FeeBps: 30}, Dir: OneForZero},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			in, ok := OptimalCycleInput(tt.hops)
+			if !ok {
+				t.Fatal("ok=false")
+			}
+			out, ok := ApplyPath(in, tt.hops)
+			if !ok {
+				t.Fatal("ApplyPath ok=false")
+			}
+			profit := new(big.Int).Sub(out, in)
+
+			// Rounding of the integer swaps may cost a few units against the
+			// best input found by trying every one.
+			best := new(big.Int)
+			for x := int64(1); x <= 20000; x++ {
+				out, ok := ApplyPath(big.NewInt(x), tt.hops)
+				if !ok {
+					continue
+				}
+				if p := out.Sub(out, big.NewInt(x)); p.Cmp(best) > 0 {
+					best = p
+				}
+			}
+			if best.Sign() <= 0 {
+				t.Fatal("test cycle is not profitable")
+			}
+			if new(big.Int).Sub(best, profit).Cmp(big.NewInt(2)) > 0 {
+				t.Fatalf("profit %s at %s, best %s", profit, in, best)
+			}
+		})
+	}
+}
+
+func TestOptimalCycleInput_Unprofitable(t *testing.T) {
+	t.Parallel()
+
+	pool := PoolState{Reserve0: bi("10000"), Reserve1: bi("20000"), FeeBps: 30}
+	same := []Hop{{Pool: pool, Dir: ZeroForOne}, {Pool: pool, Dir: OneForZero}}
+	if _, ok := OptimalCycleInput(same); ok {
+		t.Fatal("round trip through equal prices should be unprofitable")
+	}
+
+	// A 0.5% price gap does not cover two 0.3% fees, but covers one.
+	gap := PoolState{Reserve0: bi("1000000"), Reserve1: bi("2010000"), FeeBps: 30}
+	if _, ok := OptimalCycleInput([]Hop{{Pool: gap, Dir: ZeroForOne}, {Pool: pool, Dir: OneForZero}}); ok {
+		t.Fatal("gap below fees should be unprofitable")
+	}
+	gap.FeeBps = 0
+	if _, ok := OptimalCycleInput([]Hop{{Pool: gap, Dir: ZeroForOne}, {Pool: pool, Dir: OneForZero}}); !ok {
+		t.Fatal("gap above fees should be profitable")
+	}
+
+	if _, ok := OptimalCycleInput(nil); ok {
+		t.Fatal("empty cycle should be false")
+	}
+}
+
+func TestApplyPath(t *testing.T) {
+	t.Parallel()
+
+	hops := []Hop{
+		{Pool: PoolState{Reserve0: bi("10000"), Reserve1: bi("20000"), FeeBps: 30}, Dir: ZeroForOne},
+		{Pool: PoolState{Reserve0: bi("10000"), Reserve1: bi("20000"), FeeBps: 30}, Dir: ZeroForOne},
+	}
+
+	out, ok := ApplyPath(bi("1000"), hops)
+	if !ok || out.Cmp(bi("3061")) != 0 { // 1000 -> 1813 -> 3061.
+		t.Fatalf("ok=%v out=%s", ok, out.String())
+	}
+
+	if _, ok := ApplyPath(bi("0"), hops); ok {
+		t.Fatal("zero input should be false")
+	}
+}
@@ -0,0 +1,246 @@
+package dexmath
+
+import "math/big"
+
+// maxCurveIterations is the iteration limit of the pool's get_D and get_y.
+const maxCurveIterations = 255
+
+var (
+	// curveFeeDen is the pool's FEE_DENOMINATOR.
+	curveFeeDen = big.NewInt(1e10)
+	// curvePrecision is the pool's PRECISION, the scale of rates.
+	curvePrecision = big.NewInt(1e18)
+)
+
+// CurvePool is the state of a Curve StableSwap pool that a swap depends on.
+type CurvePool struct {
+	Balances []*big.Int
+	// Rates scale each balance to 18 decimals with a precision of 1e18:
+	// 10^(36 - decimals) for plain coins, the base pool's virtual price for
+	// the LP token of a metapool's base pool.
+	Rates []*big.Int
+	// Amp is the amplification coefficient A multiplied by APrecision, as
+	// A_precise() returns it; pools without A_precise() have an APrecision of 1.
+	Amp        *big.Int
+	APrecision *big.Int
+	// Fee is the swap fee with a denominator of 1e10.
+	Fee *big.Int
+	// OffpegFeeMultiplier raises the fee of stableswap-ng pools as the pool
+	// goes off peg. Nil, or at most 1e10, leaves the fee flat.
+	OffpegFeeMultiplier *big.Int
+	// NextGen selects the get_D of stableswap-ng pools, which divides the
+	// D_P product by n^n once per iteration rather than by n per coin.
+	NextGen bool
+}
+
+// GetDy computes the output of swapping dx of coin i for coin j, as the pool's
+// exchange does: the fee is taken from the output in 18 decimals before it is
+// scaled back to coin j.
+//
+// Returns (0, false) if the coins are out of range, a balance is zero or the
+// invariant does not converge.
+func (p CurvePool) GetDy(i, j int, dx *big.Int) (*big.Int, bool) {
+	n := len(p.Balances)
+	if i == j || i < 0 || j < 0 || i >= n || j >= n || len(p.Rates) != n || dx.Sign() <= 0 {
+		return new(big.Int), false
+	}
+
+	xp := make([]*big.Int, n)
+	for k := range xp {
+		if p.Balances[k].Sign() <= 0 || p.Rates[k].Sign() <= 0 {
+			return new(big.Int), false
+		}
+		xp[k] = new(big.Int).Mul(p.Balances[k], p.Rates[k])
+		xp[k].Quo(xp[k], curvePrecision)
+	}
+
+	// x = xp[i] + dx * rates[i] / PRECISION.
+	x := new(big.Int).Mul(dx, p.Rates[i])
+	x.Quo(x, curvePrecision)
+	x.Add(x, xp[i])
+
+	y, ok := p.getY(i, j, x, xp)
+	if !ok {
+		return new(big.Int), false
+	}
+
+	// dy = xp[j] - y - 1, the 1 covering rounding.
+	dy := new(big.Int).Sub(xp[j], y)
+	dy.Sub(dy, big.NewInt(1))
+	if dy.Sign() <= 0 {
+		return new(big.Int), false
+	}
+
+	fee := p.dynamicFee(xp[i], x, xp[j], y)
+	fee.Mul(fee, dy)
+	fee.Quo(fee, curveFeeDen)
+
+	// (dy - fee) * PRECISION / rates[j].
+	dy.Sub(dy, fee)
+	dy.Mul(dy, curvePrecision)
+	return dy.Quo(dy, p.Rates[j]), true
+}
+
+// dynamicFee is the fee of a swap moving coin i from xi to x and coin j from
+// xj to y: the flat fee, or the stableswap-ng _dynamic_fee at the midpoints
+//
+//	multiplier * fee / ((multiplier - 1e10) * 4 * xpi * xpj / (xpi + xpj)² + 1e10).
+func (p CurvePool) dynamicFee(xi, x, xj, y *big.Int) *big.Int {
+	if p.OffpegFeeMultiplier == nil || p.OffpegFeeMultiplier.Cmp(curveFeeDen) <= 0 {
+		return new(big.Int).Set(p.Fee)
+	}
+
+	two := big.NewInt(2)
+	xpi := new(big.Int).Add(xi, x)
+	xpi.Quo(xpi, two)
+	xpj := new(big.Int).Add(xj, y)
+	xpj.Quo(xpj, two)
+
+	xps2 := new(big.Int).Add(xpi, xpj)
+	xps2.Mul(xps2, xps2)
+
+	den := new(big.Int).Sub(p.OffpegFeeMultiplier, curveFeeDen)
+	den.Mul(den, big.NewInt(4))
+	den.Mul(den, xpi)
+	den.Mul(den, xpj)
+	den.Quo(den, xps2)
+	den.Add(den, curveFeeDen)
+
+	fee := new(big.Int).Mul(p.OffpegFeeMultiplier, p.Fee)
+	return fee.Quo(fee, den)
+}
+
+// D computes the pool's invariant over balances scaled to 18 decimals, as the
+// pool's get_D does.
+//
+// Returns false if it does not converge.
+func (p CurvePool) D(xp []*big.Int) (*big.Int, bool) {
+	n := big.NewInt(int64(len(xp)))
+	s := new(big.Int)
+	for _, x := range xp {
+		s.Add(s, x)
+	}
+	if s.Sign() == 0 {
+		return s, true
+	}
+
+	nn := new(big.Int).Exp(n, n, nil)
+	ann := new(big.Int).Mul(p.Amp, n)
+	// Ann * S / A_PRECISION and (Ann - A_PRECISION) do not change between iterations.
+	annS := new(big.Int).Mul(ann, s)
+	annS.Quo(annS, p.APrecision)
+	annLess := new(big.Int).Sub(ann, p.APrecision)
+	nPlus1 := new(big.Int).Add(n, big.NewInt(1))
+
+	d := new(big.Int).Set(s)
+	dp := new(big.Int)
+	num := new(big.Int)
+	den := new(big.Int)
+	tmp := new(big.Int)
+	for range maxCurveIterations {
+		dp.Set(d)
+		for _, x := range xp {
+			if x.Sign() == 0 {
+				return nil, false
+			}
+			if p.NextGen {
+				// D_P = D_P * D / x, divided by n^n after the loop.
+				dp.Mul(dp, d)
+				dp.Quo(dp, x)
+				continue
+			}
+			// D_P = D_P * D / (x * N_COINS).
+			dp.Mul(dp, d)
+			dp.Quo(dp, tmp.Mul(x, n))
+		}
+		if p.NextGen {
+			dp.Quo(dp, nn)
+		}
+
+		// D = (Ann * S / A_PRECISION + D_P * N) * D /
+		//     ((Ann - A_PRECISION) * D / A_PRECISION + (N + 1) * D_P).
+		num.Mul(dp, n)
+		num.Add(num, annS)
+		num.Mul(num, d)
+
+		den.Mul(annLess, d)
+		den.Quo(den, p.APrecision)
+		den.Add(den, tmp.Mul(nPlus1, dp))
+		if den.Sign() == 0 {
+			return nil, false
+		}
+
+		prev := new(big.Int).Set(d)
+		d.Quo(num, den)
+		if tmp.Sub(d, prev).CmpAbs(big.NewInt(1)) <= 0 {
+			return d, true
+		}
+	}
+
+	return nil, false
+}
+
+// getY solves the invariant for the balance of coin j after coin i's balance
+// is set to x, as the pool's get_y does.
+func (p CurvePool) getY(i, j int, x *big.Int, xp []*big.Int) (*big.Int, bool) {
+	d, ok := p.D(xp)
+	if !ok {
+		return nil, false
+	}
+
+	n := big.NewInt(int64(len(xp)))
+	ann := new(big.Int).Mul(p.Amp, n)
+
+	c := new(big.Int).Set(d)
+	s := new(big.Int)
+	tmp := new(big.Int)
+	for k := range xp {
+		var xk *big.Int
+		switch k {
+		case i:
+			xk = x
+		case j:
+			continue
+		default:
+			xk = xp[k]
+		}
+		s.Add(s, xk)
+		// c = c * D / (x * N_COINS).
+		c.Mul(c, d)
+		c.Quo(c, tmp.Mul(xk, n))
+	}
+
+	// c = c * D * A_PRECISION / (Ann * N_COINS).
+	c.Mul(c, d)
+	c.Mul(c, p.APrecision)
+	c.Quo(c, tmp.Mul(ann, n))
+
+	// b = S + D * A_PRECISION / Ann.
+	b := new(big.Int).Mul(d, p.APrecision)
+	b.Quo(b, ann)
+	b.Add(b, s)
+
+	y := new(big.Int).Set(d)
+	num := new(big.Int)
+	den := new(big.Int)
+	for range maxCurveIterations {
+		prev := new(big.Int).Set(y)
+
+		// y = (y * y + c) / (2 * y + b - D).
+		num.Mul(y, y)
+		num.Add(num, c)
+		den.Lsh(y, 1)
+		den.Add(den, b)
+		den.Sub(den, d)
+		if den.Sign() <= 0 {
+			return nil, false
+		}
+		y.Quo(num, den)
+
+		if tmp.Sub(y, prev).CmpAbs(big.NewInt(1)) <= 0 {
+			return y, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,157 @@
+package dexmath
+
+import (
+	"math/big"
+	"testing"
+)
+
+// The expected values are computed with the Vyper get_D, get_y and exchange
+// transcribed to Python integers.
+func TestCurvePool_GetDy(t *testing.T) {
+	t.Parallel()
+
+	// DAI, USDC and USDT in a 3pool style pool: A = 2000 without A_PRECISION, 0.01% fee.
+	threePool := CurvePool{
+		Balances:   []*big.Int{bi("150000000000000000000000000"), bi("160000000000000"), bi("90000000000000")},
+		Rates:      []*big.Int{bi("1000000000000000000"), bi("1000000000000000000000000000000"), bi("1000000000000000000000000000000")},
+		Amp:        big.NewInt(2000),
+		APrecision: big.NewInt(1),
+		Fee:        big.NewInt(1000000),
+	}
+	// A factory metapool of an 18 decimals coin against a base pool LP token
+	// with a virtual price of 1.03: A = 100, 0.04% fee.
+	metaPool := CurvePool{
+		Balances:   []*big.Int{bi("5000000000000000000000000"), bi("4700000000000000000000000")},
+		Rates:      []*big.Int{bi("1000000000000000000"), bi("1030000000000000000")},
+		Amp:        big.NewInt(10000),
+		APrecision: big.NewInt(100),
+		Fee:        big.NewInt(4000000),
+	}
+	// An imbalanced stableswap-ng pool of a 6 and an 18 decimals coin with a
+	// 2x off peg fee multiplier: A = 1500, 0.01% fee.
+	ngPool := CurvePool{
+		Balances:            []*big.Int{bi("2000000000000"), bi("8000000000000000000000000")},
+		Rates:               []*big.Int{bi("1000000000000000000000000000000"), bi("1000000000000000000")},
+		Amp:                 big.NewInt(150000),
+		APrecision:          big.NewInt(100),
+		Fee:                 big.NewInt(1000000),
+		OffpegFeeMultiplier: big.NewInt(20000000000),
+		NextGen:             true,
+	}
+
+	tests := []struct {
+		name string
+		pool CurvePool
+		i, j int
+		dx   *big.Int
+		want *big.Int
+	}{
+		{name: "3pool usdc to usdt", pool: threePool, i: 1, j: 2, dx: bi("1000000000000"), want: bi("999537854196")},
+		{name: "3pool dai to usdc", pool: threePool, i: 0, j: 1, dx: bi("1000000000000000000000"), want: bi("999930461")},
+		{name: "metapool coin to lp", pool: metaPool, i: 0, j: 1, dx: bi("10000000000000000000000"), want: bi("9701552807032187973664")},
+		{name: "metapool lp to coin", pool: metaPool, i: 1, j: 0, dx: bi("10000000000000000000000"), want: bi("10298962479879494035278")},
+		{name: "ng off peg", pool: ngPool, i: 0, j: 1, dx: bi("100000000000"), want: bi("100172875802688521762011")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := tt.pool.GetDy(tt.i, tt.j, tt.dx)
+			if !ok {
+				t.Fatal("ok=false")
+			}
+			if got.Cmp(tt.want) != 0 {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurvePool_D(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		pool CurvePool
+		xp   []*big.Int
+		want *big.Int
+	}{
+		{
+			name: "balanced",
+			pool: CurvePool{Amp: big.NewInt(100), APrecision: big.NewInt(1)},
+			xp:   []*big.Int{bi("1000000000000000000000"), bi("1000000000000000000000")},
+			want: bi("2000000000000000000000"),
+		},
+		{
+			name: "3pool",
+			pool: CurvePool{Amp: big.NewInt(2000), APrecision: big.NewInt(1)},
+			xp: []*big.Int{
+				bi("150000000000000000000000000"), bi("160000000000000000000000000"), bi("90000000000000000000000000"),
+			},
+			want: bi("399993510753183256915053307"),
+		},
+		{
+			name: "stableswap-ng",
+			pool: CurvePool{Amp: big.NewInt(150000), APrecision: big.NewInt(100), NextGen: true},
+			xp:   []*big.Int{bi("2000000000000000000000000"), bi("8000000000000000000000000")},
+			want: bi("9998127301310870784593033"),
+		},
+		{
+			name: "empty",
+			pool: CurvePool{Amp: big.NewInt(100), APrecision: big.NewInt(1)},
+			xp:   []*big.Int{new(big.Int), new(big.Int)},
+			want: new(big.Int),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := tt.pool.D(tt.xp)
+			if !ok {
+				t.Fatal("ok=false")
+			}
+			if got.Cmp(tt.want) != 0 {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurvePool_GetDy_Invalid(t *testing.T) {
+	t.Parallel()
+
+	pool := CurvePool{
+		Balances:   []*big.Int{bi("1000000000000000000000"), bi("1000000000000000000000")},
+		Rates:      []*big.Int{bi("1000000000000000000"), bi("1000000000000000000")},
+		Amp:        big.NewInt(100),
+		APrecision: big.NewInt(1),
+		Fee:        big.NewInt(4000000),
+	}
+	empty := pool
+	empty.Balances = []*big.Int{bi("1000000000000000000000"), new(big.Int)}
+
+	tests := []struct {
+		name string
+		pool CurvePool
+		i, j int
+		dx   *big.Int
+	}{
+		{name: "same coin", pool: pool, i: 0, j: 0, dx: big.NewInt(1000)},
+		{name: "coin out of range", pool: pool, i: 0, j: 2, dx: big.NewInt(1000)},
+		{name: "zero amount", pool: pool, i: 0, j: 1, dx: new(big.Int)},
+		{name: "empty balance", pool: empty, i: 0, j: 1, dx: big.NewInt(1000)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, ok := tt.pool.GetDy(tt.i, tt.j, tt.dx); ok {
+				t.Fatal("ok=true")
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package dexmath
+
+import "math/big"
+
+// MinimumLiquidity is the amount of LP tokens that UniswapV2Pair locks forever
+// on the first deposit.
+var MinimumLiquidity = big.NewInt(1000)
+
+// PairState is the state of a Uniswap V2 pair that its liquidity math depends on.
+type PairState struct {
+	Reserve0    *big.Int
+	Reserve1    *big.Int
+	TotalSupply *big.Int
+	// KLast is the reserve product at the last liquidity event; nil counts as zero.
+	KLast *big.Int
+	// FeeOn is set when the factory's feeTo is, so the pair mints protocol fee
+	// LP tokens on every mint and burn.
+	FeeOn bool
+}
+
+// MintFee returns the LP tokens that UniswapV2Pair._mintFee issues to feeTo
+// before a mint or burn: one sixth of the growth of sqrt(k) since KLast,
+// ts * (rootK - rootKLast) / (5 * rootK + rootKLast), rounded down.
+func (p PairState) MintFee() *big.Int {
+	fee := new(big.Int)
+	if !p.FeeOn || p.KLast == nil || p.KLast.Sign() == 0 {
+		return fee
+	}
+
+	rootK := new(big.Int).Mul(p.Reserve0, p.Reserve1)
+	rootK.Sqrt(rootK)
+	rootKLast := new(big.Int).Sqrt(p.KLast)
+	if rootK.Cmp(rootKLast) <= 0 {
+		return fee
+	}
+
+	fee.Sub(rootK, rootKLast).Mul(fee, p.TotalSupply)
+	den := rootK.Mul(rootK, big.NewInt(5)).Add(rootK, rootKLast)
+	return fee.Quo(fee, den)
+}
+
+// withMintFee returns the state with the protocol fee minted, as the pair
+// sees it in mint and burn.
+func (p PairState) withMintFee() PairState {
+	if fee := p.MintFee(); fee.Sign() > 0 {
+		p.TotalSupply = fee.Add(fee, p.TotalSupply)
+	}
+	return p
+}
+
+// Mint returns the LP tokens that UniswapV2Pair.mint issues for a deposit of
+// amount0 and amount1: sqrt(amount0*amount1) - MinimumLiquidity for the first
+// deposit, otherwise the smaller of the shares the two amounts are of the reserves.
+// The excess of the larger side is donated to the pool. The protocol fee is
+// minted first, diluting the deposit.
+//
+// Returns false if the pair would revert with INSUFFICIENT_LIQUIDITY_MINTED.
+func (p PairState) Mint(amount0, amount1 *big.Int) (*big.Int, bool) {
+	if amount0.Sign() < 0 || amount1.Sign() < 0 {
+		return new(big.Int), false
+	}
+	p = p.withMintFee()
+
+	liquidity := new(big.Int)
+	if p.TotalSupply.Sign() == 0 {
+		liquidity.Mul(amount0, amount1).Sqrt(liquidity).Sub(liquidity, MinimumLiquidity)
+	} else {
+		if p.Reserve0.Sign() <= 0 || p.Reserve1.Sign() <= 0 {
+			return liquidity, false
+		}
+		liquidity.Mul(amount0, p.TotalSupply).Quo(liquidity, p.Reserve0)
+		other := new(big.Int).Mul(amount1, p.TotalSupply)
+		other.Quo(other, p.Reserve1)
+		if other.Cmp(liquidity) < 0 {
+			liquidity = other
+		}
+	}
+
+	if liquidity.Sign() <= 0 {
+		return new(big.Int), false
+	}
+	return liquidity, true
+}
+
+// Value returns the reserves that liquidity LP tokens are a claim on, rounded
+// down as UniswapV2Pair.burn does, after the protocol fee is minted.
+func (p PairState) Value(liquidity *big.Int) (*big.Int, *big.Int) {
+	p = p.withMintFee()
+	if p.TotalSupply.Sign() <= 0 {
+		return new(big.Int), new(big.Int)
+	}
+
+	amount0 := new(big.Int).Mul(liquidity, p.Reserve0)
+	amount0.Quo(amount0, p.TotalSupply)
+	amount1 := new(big.Int).Mul(liquidity, p.Reserve1)
+	amount1.Quo(amount1, p.TotalSupply)
+	return amount0, amount1
+}
+
+// Burn returns the amounts that UniswapV2Pair.burn pays out for liquidity LP
+// tokens, assuming the pair holds exactly its reserves.
+//
+// Returns false if liquidity exceeds the total supply or the pair would revert
+// with INSUFFICIENT_LIQUIDITY_BURNED.
+func (p PairState) Burn(liquidity *big.Int) (*big.Int, *big.Int, bool) {
+	if liquidity.Sign() <= 0 || liquidity.Cmp(p.TotalSupply) > 0 {
+		return new(big.Int), new(big.Int), false
+	}
+
+	amount0, amount1 := p.Value(liquidity)
+	if amount0.Sign() == 0 || amount1.Sign() == 0 {
+		return amount0, amount1, false
+	}
+	return amount0, amount1, true
+}
+
+// Zap is a single-sided deposit: part of the input is swapped for the other
+// token and both are deposited.
+type Zap struct {
+	// Swap is the part of the input sold to the pair.
+	Swap *big.Int
+	// SwapOut is the output of the swap.
+	SwapOut *big.Int
+	// Amount0 and Amount1 are the deposited amounts.
+	Amount0 *big.Int
+	Amount1 *big.Int
+	// Liquidity is the LP tokens minted for the deposit.
+	Liquidity *big.Int
+}
+
+// OptimalZapSwap returns the part of amountIn to sell to a pool with reserveIn
+// of the input token, so that the rest and the swap output match the reserves
+// after the swap. With the fee multiplier g = 1 - feeBps/10000 it is
+// (sqrt(r*(r*(1+g)^2 + 4*g*a)) - r*(1+g)) / (2*g), rounded down.
+//
+// Returns false if the fee is not below 100% or the inputs are not positive.
+func OptimalZapSwap(amountIn, reserveIn *big.Int, feeBps uint32) (*big.Int, bool) {
+	if feeBps >= 10000 || amountIn.Sign() <= 0 || reserveIn.Sign() <= 0 {
+		return new(big.Int), false
+	}
+
+	// Scaled by 10000: d = 10000, g = 10000 - feeBps.
+	g := new(big.Int).SetUint64(uint64(10000 - feeBps))
+	dg := new(big.Int).Add(bpsDen, g)
+
+	// r*(d+g)^2 + 4*g*d*a.
+	inner := new(big.Int).Mul(dg, dg)
+	inner.Mul(inner, reserveIn)
+	t := new(big.Int).Mul(g, bpsDen)
+	t.Lsh(t, 2).Mul(t, amountIn)
+	inner.Add(inner, t)
+
+	swap := inner.Mul(inner, reserveIn).Sqrt(inner)
+	swap.Sub(swap, t.Mul(reserveIn, dg))
+	swap.Quo(swap, g.Lsh(g, 1))
+	return swap, true
+}
+
+// Zap computes a single-sided deposit of amountIn of the token sold in dir:
+// the optimal part is swapped with the pair's feeBps fee and the rest and the
+// swap output are minted at the reserves after the swap.
+//
+// Returns false if the swap or the mint is not possible.
+func (p PairState) Zap(amountIn *big.Int, dir Direction, feeBps uint32) (*Zap, bool) {
+	reserveIn := p.Reserve0
+	if dir == OneForZero {
+		reserveIn = p.Reserve1
+	}
+
+	swap, ok := OptimalZapSwap(amountIn, reserveIn, feeBps)
+	if !ok {
+		return nil, false
+	}
+
+	pool := PoolState{Reserve0: p.Reserve0, Reserve1: p.Reserve1, FeeBps: feeBps}
+	out, next, ok := pool.ApplySwap(swap, dir)
+	if !ok {
+		return nil, false
+	}
+
+	rest := new(big.Int).Sub(amountIn, swap)
+	z := &Zap{Swap: swap, SwapOut: out, Amount0: rest, Amount1: out}
+	if dir == OneForZero {
+		z.Amount0, z.Amount1 = out, rest
+	}
+
+	after := p
+	after.Reserve0, after.Reserve1 = next.Reserve0, next.Reserve1
+	z.Liquidity, ok = after.Mint(z.Amount0, z.Amount1)
+	if !ok {
+		return nil, false
+	}
+	return z, true
+}
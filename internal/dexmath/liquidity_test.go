@@ -0,0 +1,177 @@
+package dexmath
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestPairState_Mint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		pair             PairState
+		amount0, amount1 string
+		want             string
+		ok               bool
+	}{
+		{
+			name:    "first deposit locks minimum liquidity",
+			pair:    PairState{Reserve0: bi("0"), Reserve1: bi("0"), TotalSupply: bi("0")},
+			amount0: "10000", amount1: "40000",
+			want: "19000", ok: true,
+		},
+		{
+			name:    "first deposit rounds the root down",
+			pair:    PairState{Reserve0: bi("0"), Reserve1: bi("0"), TotalSupply: bi("0")},
+			amount0: "2000", amount1: "2001",
+			want: "1000", ok: true,
+		},
+		{
+			name:    "first deposit below minimum liquidity",
+			pair:    PairState{Reserve0: bi("0"), Reserve1: bi("0"), TotalSupply: bi("0")},
+			amount0: "1000", amount1: "1000",
+			want: "0",
+		},
+		{
+			name:    "smaller share wins",
+			pair:    PairState{Reserve0: bi("10000"), Reserve1: bi("20000"), TotalSupply: bi("14142")},
+			amount0: "1000", amount1: "3000",
+			want: "1414", ok: true,
+		},
+		{
+			name:    "one sided deposit mints nothing",
+			pair:    PairState{Reserve0: bi("10000"), Reserve1: bi("20000"), TotalSupply: bi("14142")},
+			amount0: "1000", amount1: "0",
+			want: "0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := tt.pair.Mint(bi(tt.amount0), bi(tt.amount1))
+			if ok != tt.ok || got.Cmp(bi(tt.want)) != 0 {
+				t.Fatalf("Mint = %s, %v; want %s, %v", got.String(), ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestPairState_Burn(t *testing.T) {
+	t.Parallel()
+
+	p := PairState{Reserve0: bi("10000"), Reserve1: bi("20000"), TotalSupply: bi("14142")}
+
+	a0, a1, ok := p.Burn(bi("1414"))
+	if !ok || a0.Cmp(bi("999")) != 0 || a1.Cmp(bi("1999")) != 0 {
+		t.Fatalf("Burn = %s, %s, %v", a0.String(), a1.String(), ok)
+	}
+
+	a0, a1, ok = p.Burn(bi("14142"))
+	if !ok || a0.Cmp(bi("10000")) != 0 || a1.Cmp(bi("20000")) != 0 {
+		t.Fatalf("Burn all = %s, %s, %v", a0.String(), a1.String(), ok)
+	}
+
+	if _, _, ok := p.Burn(bi("14143")); ok {
+		t.Fatal("burning more than the supply should be false")
+	}
+	if _, _, ok := p.Burn(bi("1")); ok {
+		t.Fatal("burning to zero amount0 should be false")
+	}
+
+	v0, v1 := p.Value(bi("1"))
+	if v0.Sign() != 0 || v1.Cmp(bi("1")) != 0 {
+		t.Fatalf("Value = %s, %s", v0.String(), v1.String())
+	}
+}
+
+func TestPairState_Zap(t *testing.T) {
+	t.Parallel()
+
+	p := PairState{Reserve0: bi("1000000"), Reserve1: bi("3000000"), TotalSupply: bi("1732050")}
+
+	for _, dir := range []Direction{ZeroForOne, OneForZero} {
+		amountIn := bi("100000")
+		zap, ok := p.Zap(amountIn, dir, 30)
+		if !ok {
+			t.Fatalf("dir %d: Zap not ok", dir)
+		}
+
+		// No other split of the input mints noticeably more.
+		best := new(big.Int)
+		for d := int64(-200); d <= 200; d++ {
+			swap := new(big.Int).Add(zap.Swap, big.NewInt(d))
+			out, next, ok := (PoolState{Reserve0: p.Reserve0, Reserve1: p.Reserve1, FeeBps: 30}).ApplySwap(swap, dir)
+			if !ok {
+				continue
+			}
+			rest := new(big.Int).Sub(amountIn, swap)
+			a0, a1 := rest, out
+			if dir == OneForZero {
+				a0, a1 = out, rest
+			}
+			liquidity, ok := (PairState{Reserve0: next.Reserve0, Reserve1: next.Reserve1, TotalSupply: p.TotalSupply}).Mint(a0, a1)
+			if ok && liquidity.Cmp(best) > 0 {
+				best = liquidity
+			}
+		}
+		if new(big.Int).Sub(best, zap.Liquidity).Cmp(bi("1")) > 0 {
+			t.Fatalf("dir %d: zap minted %s, a split mints %s", dir, zap.Liquidity.String(), best.String())
+		}
+		rest := zap.Amount0
+		if dir == OneForZero {
+			rest = zap.Amount1
+		}
+		if new(big.Int).Add(zap.Swap, rest).Cmp(amountIn) != 0 {
+			t.Fatalf("dir %d: zap does not use the whole input", dir)
+		}
+	}
+
+	// (sqrt(r*(3988009*r + 3988000*a)) - 1997*r) / 1994 for a 0.3% fee.
+	swap, ok := OptimalZapSwap(bi("1000"), bi("10000"), 30)
+	if !ok || swap.Cmp(bi("488")) != 0 {
+		t.Fatalf("OptimalZapSwap = %s, %v", swap.String(), ok)
+	}
+
+	if _, ok := OptimalZapSwap(bi("1000"), bi("10000"), 10000); ok {
+		t.Fatal("100% fee should be false")
+	}
+	if _, ok := (PairState{Reserve0: bi("0"), Reserve1: bi("0"), TotalSupply: bi("0")}).Zap(bi("1000"), ZeroForOne, 30); ok {
+		t.Fatal("zap into an empty pair should be false")
+	}
+}
+
+func TestPairState_MintFee(t *testing.T) {
+	t.Parallel()
+
+	// sqrt(k) doubled from 10000 to 20000 since the last liquidity event.
+	p := PairState{Reserve0: bi("10000"), Reserve1: bi("40000"), TotalSupply: bi("10000"), KLast: bi("100000000"), FeeOn: true}
+
+	if fee := p.MintFee(); fee.Cmp(bi("909")) != 0 {
+		t.Fatalf("MintFee = %s", fee.String())
+	}
+
+	// Deposits and burns see the supply diluted by the fee.
+	if liquidity, ok := p.Mint(bi("1000"), bi("4000")); !ok || liquidity.Cmp(bi("1090")) != 0 {
+		t.Fatalf("Mint = %s, %v", liquidity.String(), ok)
+	}
+	if a0, a1, ok := p.Burn(bi("1000")); !ok || a0.Cmp(bi("916")) != 0 || a1.Cmp(bi("3666")) != 0 {
+		t.Fatalf("Burn = %s, %s, %v", a0.String(), a1.String(), ok)
+	}
+	if p.TotalSupply.Cmp(bi("10000")) != 0 {
+		t.Fatal("receiver was modified")
+	}
+
+	for name, q := range map[string]PairState{
+		"fee off":    {Reserve0: p.Reserve0, Reserve1: p.Reserve1, TotalSupply: p.TotalSupply, KLast: p.KLast},
+		"no k last":  {Reserve0: p.Reserve0, Reserve1: p.Reserve1, TotalSupply: p.TotalSupply, FeeOn: true},
+		"k shrunk":   {Reserve0: bi("100"), Reserve1: bi("100"), TotalSupply: p.TotalSupply, KLast: p.KLast, FeeOn: true},
+		"k constant": {Reserve0: bi("10000"), Reserve1: bi("10000"), TotalSupply: p.TotalSupply, KLast: p.KLast, FeeOn: true},
+	} {
+		if fee := q.MintFee(); fee.Sign() != 0 {
+			t.Fatalf("%s: MintFee = %s", name, fee.String())
+		}
+	}
+}
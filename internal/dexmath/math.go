@@ -9,6 +9,7 @@ var (
 	// Fee constants.
 	feeMul = big.NewInt(997)
 	feeDen = big.NewInt(1000)
+	bpsDen = big.NewInt(10000)
 
 	defaultMath = newMathService()
 )
@@ -37,7 +38,7 @@ func newMathService() *mathService {
 	}
 }
 
-func (m *mathService) getAmountOutInto(out, amountIn, reserveIn, reserveOut *big.Int) bool {
+func (m *mathService) getAmountOutInto(out, amountIn, reserveIn, reserveOut, feeMul, feeDen *big.Int) bool {
 	if out == nil {
 		return false
 	}
@@ -81,7 +82,45 @@ func (m *mathService) getAmountOutInto(out, amountIn, reserveIn, reserveOut *big
 // out must be non-nil; this function does not allocate for temporaries
 // if the pool is warm. Caller should reuse `out` when possible.
 func GetAmountOutInto(out, amountIn, reserveIn, reserveOut *big.Int) bool {
-	return defaultMath.getAmountOutInto(out, amountIn, reserveIn, reserveOut)
+	return defaultMath.getAmountOutInto(out, amountIn, reserveIn, reserveOut, feeMul, feeDen)
+}
+
+// GetAmountOutWithFeeInto is GetAmountOutInto for forks with a different swap fee,
+// expressed in basis points (e.g. 30 for Uniswap V2, 25 for PancakeSwap V2).
+//
+// Returns false if any value is zero or the fee is not below 100%.
+func GetAmountOutWithFeeInto(out, amountIn, reserveIn, reserveOut *big.Int, feeBps uint32) bool {
+	if feeBps >= 10000 {
+		if out != nil {
+			out.SetInt64(0)
+		}
+		return false
+	}
+
+	mul := new(big.Int).SetUint64(uint64(10000 - feeBps))
+	return defaultMath.getAmountOutInto(out, amountIn, reserveIn, reserveOut, mul, bpsDen)
+}
+
+// GetAmountInWithFee computes the input needed to receive amountOut, as
+// UniswapV2Library.getAmountIn does with a swap fee in basis points:
+//
+//	reserveIn * amountOut * 10000 / ((reserveOut - amountOut) * (10000 - feeBps)) + 1.
+//
+// Returns false if any value is zero, amountOut is not below reserveOut or the
+// fee is not below 100%.
+func GetAmountInWithFee(amountOut, reserveIn, reserveOut *big.Int, feeBps uint32) (*big.Int, bool) {
+	if feeBps >= 10000 || amountOut.Sign() <= 0 || reserveIn.Sign() <= 0 || amountOut.Cmp(reserveOut) >= 0 {
+		return new(big.Int), false
+	}
+
+	num := new(big.Int).Mul(reserveIn, amountOut)
+	num.Mul(num, bpsDen)
+
+	den := new(big.Int).Sub(reserveOut, amountOut)
+	den.Mul(den, new(big.Int).SetUint64(uint64(10000-feeBps)))
+
+	in := num.Quo(num, den)
+	return in.Add(in, big.NewInt(1)), true
 }
 
 // GetAmountOut computes the amount of output tokens received for a given input amount,
@@ -91,6 +130,25 @@ func GetAmountOutInto(out, amountIn, reserveIn, reserveOut *big.Int) bool {
 // It represents backwards-compatible allocator: returns a newly allocated *big.Int (uses pool for temps).
 func GetAmountOut(amountIn, reserveIn, reserveOut *big.Int) (*big.Int, bool) {
 	out := new(big.Int)
-	ok := defaultMath.getAmountOutInto(out, amountIn, reserveIn, reserveOut)
+	ok := defaultMath.getAmountOutInto(out, amountIn, reserveIn, reserveOut, feeMul, feeDen)
 	return out, ok
 }
+
+// ApplyTransferTaxInto writes into out the amount that arrives after a transfer
+// of amount of a token that keeps taxBps basis points of every transfer.
+// The result is rounded down, so that the estimate never exceeds what arrives.
+//
+// Returns false if the tax is not below 100%.
+func ApplyTransferTaxInto(out, amount *big.Int, taxBps uint32) bool {
+	if out == nil {
+		return false
+	}
+	if taxBps >= 10000 {
+		out.SetInt64(0)
+		return false
+	}
+
+	out.Mul(amount, new(big.Int).SetUint64(uint64(10000-taxBps)))
+	out.Quo(out, bpsDen)
+	return true
+}
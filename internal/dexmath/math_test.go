@@ -88,3 +88,66 @@ func BenchmarkGetAmountOut_NoAllocs(b *testing.B) {
 		}
 	}
 }
+
+func TestGetAmountOutWithFeeInto(t *testing.T) {
+	t.Parallel()
+
+	out := new(big.Int)
+	if ok := GetAmountOutWithFeeInto(out, bi("1000000"), bi("50000000"), bi("70000000"), 30); !ok {
+		t.Fatalf("ok=false")
+	}
+	want, _ := GetAmountOut(bi("1000000"), bi("50000000"), bi("70000000"))
+	if out.Cmp(want) != 0 { // 30 bps must match 997/1000.
+		t.Fatalf("want %s got %s", want.String(), out.String())
+	}
+
+	if ok := GetAmountOutWithFeeInto(out, bi("100"), bi("1000"), bi("1000"), 25); !ok {
+		t.Fatalf("ok=false")
+	}
+	if out.Cmp(bi("90")) != 0 { // 99750000/1099750 = 90.7... -> 90.
+		t.Fatalf("want 90 got %s", out.String())
+	}
+
+	if ok := GetAmountOutWithFeeInto(out, bi("100"), bi("1000"), bi("1000"), 10000); ok {
+		t.Fatal("100% fee should be false")
+	}
+}
+
+func TestGetAmountInWithFee(t *testing.T) {
+	t.Parallel()
+
+	in, ok := GetAmountInWithFee(bi("90"), bi("1000"), bi("1000"), 30)
+	if !ok {
+		t.Fatalf("ok=false")
+	}
+	if in.Cmp(bi("100")) != 0 { // 900000000/9072700 = 99.1... -> 99, plus 1.
+		t.Fatalf("want 100 got %s", in.String())
+	}
+	if out, _ := GetAmountOut(in, bi("1000"), bi("1000")); out.Cmp(bi("90")) < 0 {
+		t.Fatalf("input %s buys only %s", in.String(), out.String())
+	}
+
+	if _, ok := GetAmountInWithFee(bi("1000"), bi("1000"), bi("1000"), 30); ok {
+		t.Fatal("draining the pool should be false")
+	}
+	if _, ok := GetAmountInWithFee(bi("90"), bi("1000"), bi("1000"), 10000); ok {
+		t.Fatal("100% fee should be false")
+	}
+}
+
+func TestApplyTransferTaxInto(t *testing.T) {
+	t.Parallel()
+
+	out := new(big.Int)
+	if ok := ApplyTransferTaxInto(out, bi("1000"), 0); !ok || out.Cmp(bi("1000")) != 0 {
+		t.Fatalf("no tax: ok=%v out=%s", ok, out.String())
+	}
+
+	if ok := ApplyTransferTaxInto(out, bi("999"), 500); !ok || out.Cmp(bi("949")) != 0 { // 949.05 -> 949.
+		t.Fatalf("5%% tax: ok=%v out=%s", ok, out.String())
+	}
+
+	if ok := ApplyTransferTaxInto(out, bi("1000"), 10000); ok || out.Sign() != 0 {
+		t.Fatal("100% tax should be false")
+	}
+}
@@ -0,0 +1,129 @@
+package dexmath
+
+import "math/big"
+
+// maxSearchDoublings bounds the search for the largest front-run a victim tolerates.
+const maxSearchDoublings = 256
+
+// Sandwich is the most profitable sandwich attack on a swap: the attacker
+// buys ahead of the victim with FrontRun, the victim swaps, and the attacker
+// sells what it bought back to the pool.
+type Sandwich struct {
+	// FrontRun is the attacker's input, in units of the victim's input token.
+	FrontRun *big.Int
+	// Profit is what the back-run returns beyond FrontRun, in the same units.
+	Profit *big.Int
+	// VictimOut is the victim's output when sandwiched.
+	VictimOut *big.Int
+	// VictimLoss is how much less the victim receives than without the attack.
+	VictimLoss *big.Int
+}
+
+// OptimalSandwich finds the front-run that maximizes the attacker's profit
+// on a swap of amountIn in the given direction, subject to the victim
+// receiving at least its quote less slippageBps basis points, as a router's
+// amountOutMin would enforce.
+//
+// A swap without a profitable sandwich has zero FrontRun, Profit and VictimLoss.
+// Returns false if the swap itself is not possible.
+func OptimalSandwich(pool PoolState, amountIn *big.Int, dir Direction, slippageBps uint32) (Sandwich, bool) {
+	quote, _, ok := pool.ApplySwap(amountIn, dir)
+	if !ok || slippageBps >= 10000 {
+		return Sandwich{}, false
+	}
+
+	minOut := new(big.Int).Mul(quote, new(big.Int).SetUint64(uint64(10000-slippageBps)))
+	minOut.Quo(minOut, bpsDen)
+
+	none := Sandwich{FrontRun: new(big.Int), Profit: new(big.Int), VictimOut: quote, VictimLoss: new(big.Int)}
+
+	attack := func(frontRun *big.Int) (profit, victimOut *big.Int, ok bool) {
+		bought, afterFront, ok := pool.ApplySwap(frontRun, dir)
+		if !ok {
+			// Front-runs too small to buy anything only lose their input.
+			return new(big.Int).Neg(frontRun), quote, true
+		}
+		victimOut, afterVictim, ok := afterFront.ApplySwap(amountIn, dir)
+		if !ok || victimOut.Cmp(minOut) < 0 {
+			return nil, nil, false
+		}
+		back, _, ok := afterVictim.ApplySwap(bought, dir.opposite())
+		if !ok {
+			return new(big.Int).Neg(frontRun), victimOut, true
+		}
+		return back.Sub(back, frontRun), victimOut, true
+	}
+
+	// The victim's output falls as the front-run grows, so the tolerated
+	// front-runs are [1, maxFront], found by doubling and bisection.
+	lo, hi := new(big.Int), big.NewInt(1)
+	for i := 0; ; i++ {
+		if _, _, ok := attack(hi); !ok {
+			break
+		}
+		if i == maxSearchDoublings {
+			return none, true
+		}
+		lo.Set(hi)
+		hi.Lsh(hi, 1)
+	}
+	for new(big.Int).Sub(hi, lo).Cmp(big.NewInt(1)) > 0 {
+		mid := new(big.Int).Add(lo, hi)
+		mid.Rsh(mid, 1)
+		if _, _, ok := attack(mid); ok {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	maxFront := lo
+	if maxFront.Sign() == 0 {
+		return none, true
+	}
+
+	// The profit is unimodal in the front-run, up to rounding, so a ternary
+	// search finds its maximum on [1, maxFront]. The maximum is usually at
+	// maxFront, where the victim's limit binds, which is checked exactly.
+	profitAt := func(frontRun *big.Int) *big.Int {
+		profit, _, _ := attack(frontRun)
+		return profit
+	}
+	lo, hi = big.NewInt(1), new(big.Int).Set(maxFront)
+	for new(big.Int).Sub(hi, lo).Cmp(big.NewInt(2)) > 0 {
+		third := new(big.Int).Sub(hi, lo)
+		third.Quo(third, big.NewInt(3))
+		m1 := new(big.Int).Add(lo, third)
+		m2 := new(big.Int).Sub(hi, third)
+		if profitAt(m1).Cmp(profitAt(m2)) < 0 {
+			lo = m1.Add(m1, big.NewInt(1))
+		} else {
+			hi = m2.Sub(m2, big.NewInt(1))
+		}
+	}
+
+	best := none
+	candidates := []*big.Int{maxFront}
+	for a := lo; a.Cmp(hi) <= 0; a = new(big.Int).Add(a, big.NewInt(1)) {
+		candidates = append(candidates, a)
+	}
+	for _, a := range candidates {
+		profit, victimOut, _ := attack(a)
+		if profit.Cmp(best.Profit) > 0 {
+			best = Sandwich{
+				FrontRun:   a,
+				Profit:     profit,
+				VictimOut:  victimOut,
+				VictimLoss: new(big.Int).Sub(quote, victimOut),
+			}
+		}
+	}
+
+	return best, true
+}
+
+func (d Direction) opposite() Direction {
+	if d == ZeroForOne {
+		return OneForZero
+	}
+	return ZeroForOne
+}
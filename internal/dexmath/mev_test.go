@@ -0,0 +1,103 @@
+package dexmath
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestOptimalSandwich(t *testing.T) {
+	t.Parallel()
+
+	pool := PoolState{Reserve0: bi("10000"), Reserve1: bi("20000"), FeeBps: 30}
+
+	tests := []struct {
+		name        string
+		amountIn    string
+		dir         Direction
+		slippageBps uint32
+	}{
+		{name: "tight slippage", amountIn: "1000", dir: ZeroForOne, slippageBps: 50},
+		{name: "loose slippage", amountIn: "1000", dir: ZeroForOne, slippageBps: 500},
+		{name: "other direction", amountIn: "3000", dir: OneForZero, slippageBps: 300},
+		{name: "very loose slippage", amountIn: "1000", dir: ZeroForOne, slippageBps: 9000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := OptimalSandwich(pool, bi(tt.amountIn), tt.dir, tt.slippageBps)
+			if !ok {
+				t.Fatal("ok=false")
+			}
+
+			// Rounding makes the profit only approximately unimodal, so an
+			// interior maximum may be missed by a few units.
+			want := bruteForceSandwich(pool, bi(tt.amountIn), tt.dir, tt.slippageBps)
+			slack := new(big.Int).Quo(want.Profit, big.NewInt(100))
+			if new(big.Int).Sub(want.Profit, got.Profit).Cmp(slack) > 0 || got.Profit.Cmp(want.Profit) > 0 {
+				t.Fatalf("profit: want %s (front-run %s) got %s (front-run %s)",
+					want.Profit, want.FrontRun, got.Profit, got.FrontRun)
+			}
+			quote, _, _ := pool.ApplySwap(bi(tt.amountIn), tt.dir)
+			minOut := new(big.Int).Mul(quote, big.NewInt(int64(10000-tt.slippageBps)))
+			if got.VictimOut.Cmp(minOut.Quo(minOut, bpsDen)) < 0 {
+				t.Fatalf("victim limit broken: %+v", got)
+			}
+			if got.Profit.Sign() > 0 && got.VictimLoss.Sign() <= 0 {
+				t.Fatalf("profitable sandwich without victim loss: %+v", got)
+			}
+		})
+	}
+}
+
+func TestOptimalSandwich_NoExposure(t *testing.T) {
+	t.Parallel()
+
+	pool := PoolState{Reserve0: bi("1000000"), Reserve1: bi("1000000"), FeeBps: 30}
+
+	got, ok := OptimalSandwich(pool, bi("1000"), ZeroForOne, 0)
+	if !ok {
+		t.Fatal("ok=false")
+	}
+	if got.FrontRun.Sign() != 0 || got.Profit.Sign() != 0 || got.VictimLoss.Sign() != 0 {
+		t.Fatalf("zero slippage should leave nothing to extract: %+v", got)
+	}
+
+	if _, ok := OptimalSandwich(pool, bi("0"), ZeroForOne, 50); ok {
+		t.Fatal("zero input should be false")
+	}
+	if _, ok := OptimalSandwich(pool, bi("1000"), ZeroForOne, 10000); ok {
+		t.Fatal("100% slippage should be false")
+	}
+}
+
+// bruteForceSandwich tries every front-run up to five times the input reserve.
+func bruteForceSandwich(pool PoolState, amountIn *big.Int, dir Direction, slippageBps uint32) Sandwich {
+	quote, _, _ := pool.ApplySwap(amountIn, dir)
+	minOut := new(big.Int).Mul(quote, big.NewInt(int64(10000-slippageBps)))
+	minOut.Quo(minOut, bpsDen)
+
+	reserveIn := pool.Reserve0
+	if dir == OneForZero {
+		reserveIn = pool.Reserve1
+	}
+
+	best := Sandwich{FrontRun: new(big.Int), Profit: new(big.Int)}
+	for a := int64(1); a <= 5*reserveIn.Int64(); a++ {
+		frontRun := big.NewInt(a)
+		bought, s1, ok := pool.ApplySwap(frontRun, dir)
+		if !ok {
+			continue
+		}
+		victimOut, s2, ok := s1.ApplySwap(amountIn, dir)
+		if !ok || victimOut.Cmp(minOut) < 0 {
+			continue
+		}
+		back, _, _ := s2.ApplySwap(bought, dir.opposite())
+		if profit := new(big.Int).Sub(back, frontRun); profit.Cmp(best.Profit) > 0 {
+			best = Sandwich{FrontRun: frontRun, Profit: profit}
+		}
+	}
+	return best
+}
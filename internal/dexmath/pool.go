@@ -0,0 +1,47 @@
+package dexmath
+
+import "math/big"
+
+// Direction is the side of a pool a swap sells into.
+type Direction int
+
+const (
+	// ZeroForOne sells token0 for token1.
+	ZeroForOne Direction = iota
+	// OneForZero sells token1 for token0.
+	OneForZero
+)
+
+// PoolState is the state of a Uniswap V2 pool that a swap depends on.
+type PoolState struct {
+	Reserve0 *big.Int
+	Reserve1 *big.Int
+	// FeeBps is the swap fee in basis points, e.g. 30 for Uniswap V2.
+	FeeBps uint32
+}
+
+// ApplySwap computes the output of selling in to the pool in the given
+// direction and the pool state after the swap. The fee stays in the pool,
+// so the input reserve grows by the whole of in. The receiver is not modified.
+//
+// Returns false, with the receiver as the state, if the swap is not possible.
+func (p PoolState) ApplySwap(in *big.Int, dir Direction) (*big.Int, PoolState, bool) {
+	reserveIn, reserveOut := p.Reserve0, p.Reserve1
+	if dir == OneForZero {
+		reserveIn, reserveOut = p.Reserve1, p.Reserve0
+	}
+
+	out := new(big.Int)
+	if !GetAmountOutWithFeeInto(out, in, reserveIn, reserveOut, p.FeeBps) || out.Sign() == 0 {
+		return out, p, false
+	}
+
+	newIn := new(big.Int).Add(reserveIn, in)
+	newOut := new(big.Int).Sub(reserveOut, out)
+
+	next := PoolState{Reserve0: newIn, Reserve1: newOut, FeeBps: p.FeeBps}
+	if dir == OneForZero {
+		next.Reserve0, next.Reserve1 = newOut, newIn
+	}
+	return out, next, true
+}
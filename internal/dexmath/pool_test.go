@@ -0,0 +1,48 @@
+package dexmath
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestPoolState_ApplySwap(t *testing.T) {
+	t.Parallel()
+
+	p := PoolState{Reserve0: bi("10000"), Reserve1: bi("20000"), FeeBps: 30}
+
+	out, next, ok := p.ApplySwap(bi("1000"), ZeroForOne)
+	if !ok || out.Cmp(bi("1813")) != 0 {
+		t.Fatalf("first swap: ok=%v out=%s", ok, out.String())
+	}
+	if next.Reserve0.Cmp(bi("11000")) != 0 || next.Reserve1.Cmp(bi("18187")) != 0 {
+		t.Fatalf("first swap reserves: %s/%s", next.Reserve0.String(), next.Reserve1.String())
+	}
+	if p.Reserve0.Cmp(bi("10000")) != 0 || p.Reserve1.Cmp(bi("20000")) != 0 {
+		t.Fatal("receiver was modified")
+	}
+
+	// The same swap again gets less, as the price moved.
+	out2, _, ok := next.ApplySwap(bi("1000"), ZeroForOne)
+	if !ok || out2.Cmp(out) >= 0 {
+		t.Fatalf("second swap: ok=%v out=%s", ok, out2.String())
+	}
+
+	// Selling the output back returns less than the input because of fees.
+	back, final, ok := next.ApplySwap(out, OneForZero)
+	if !ok || back.Cmp(bi("1000")) >= 0 {
+		t.Fatalf("swap back: ok=%v out=%s", ok, back.String())
+	}
+	if final.Reserve1.Cmp(bi("20000")) != 0 || final.Reserve0.Cmp(new(big.Int).Sub(bi("11000"), back)) != 0 {
+		t.Fatalf("swap back reserves: %s/%s", final.Reserve0.String(), final.Reserve1.String())
+	}
+	if final.FeeBps != 30 {
+		t.Fatalf("fee lost: %d", final.FeeBps)
+	}
+
+	if _, same, ok := p.ApplySwap(bi("0"), ZeroForOne); ok || same.Reserve0 != p.Reserve0 {
+		t.Fatal("zero input should be false")
+	}
+	if _, _, ok := (PoolState{Reserve0: bi("1"), Reserve1: bi("1")}).ApplySwap(bi("1"), ZeroForOne); ok {
+		t.Fatal("zero output should be false")
+	}
+}
@@ -0,0 +1,167 @@
+package dexmath
+
+import "math/big"
+
+// maxGetYIterations is the iteration limit of the pool's _get_y.
+const maxGetYIterations = 255
+
+var wad = big.NewInt(1e18)
+
+// SolidlyPool is the state of a Solidly style pair (Velodrome, Aerodrome,
+// Thena) that a swap depends on. Stable pairs trade on the x³y + xy³ curve
+// and volatile pairs on the constant product.
+type SolidlyPool struct {
+	Reserve0 *big.Int
+	Reserve1 *big.Int
+	// Decimals0 and Decimals1 are the token units, 10^decimals, as metadata()
+	// returns them.
+	Decimals0 *big.Int
+	Decimals1 *big.Int
+	Stable    bool
+	// FeeBps is the swap fee in basis points, taken from the input.
+	FeeBps uint32
+}
+
+// GetAmountOut computes the output of selling amountIn to the pool in the given
+// direction, as the pair's getAmountOut does, including its rounding.
+//
+// Returns (0, false) if any reserve is zero or the stable curve does not converge.
+func (p SolidlyPool) GetAmountOut(amountIn *big.Int, dir Direction) (*big.Int, bool) {
+	if amountIn.Sign() <= 0 || p.Reserve0.Sign() <= 0 || p.Reserve1.Sign() <= 0 {
+		return new(big.Int), false
+	}
+
+	// amountIn -= amountIn * fee / 10000.
+	fee := new(big.Int).Mul(amountIn, big.NewInt(int64(p.FeeBps)))
+	fee.Quo(fee, bpsDen)
+	in := new(big.Int).Sub(amountIn, fee)
+
+	reserveA, reserveB := p.Reserve0, p.Reserve1
+	if dir == OneForZero {
+		reserveA, reserveB = p.Reserve1, p.Reserve0
+	}
+
+	if !p.Stable {
+		// amountIn * reserveB / (reserveA + amountIn).
+		out := new(big.Int).Mul(in, reserveB)
+		return out.Quo(out, new(big.Int).Add(reserveA, in)), true
+	}
+
+	if p.Decimals0.Sign() <= 0 || p.Decimals1.Sign() <= 0 {
+		return new(big.Int), false
+	}
+
+	xy := p.k(p.Reserve0, p.Reserve1)
+
+	decA, decB := p.Decimals0, p.Decimals1
+	if dir == OneForZero {
+		decA, decB = p.Decimals1, p.Decimals0
+	}
+	reserveA = normalize(reserveA, decA)
+	reserveB = normalize(reserveB, decB)
+	in = normalize(in, decA)
+
+	y, ok := p.getY(new(big.Int).Add(in, reserveA), xy, reserveB)
+	if !ok || y.Cmp(reserveB) > 0 {
+		return new(big.Int), false
+	}
+
+	// (reserveB - y) * decimalsB / 1e18.
+	out := new(big.Int).Sub(reserveB, y)
+	out.Mul(out, decB)
+	return out.Quo(out, wad), true
+}
+
+// normalize scales an amount of a token with the given unit to 18 decimals.
+func normalize(amount, unit *big.Int) *big.Int {
+	out := new(big.Int).Mul(amount, wad)
+	return out.Quo(out, unit)
+}
+
+// k is the pair's _k for stable pairs: x³y + xy³ of the reserves scaled to
+// 18 decimals, each product rounded down to 18 decimals.
+func (p SolidlyPool) k(x, y *big.Int) *big.Int {
+	return f(normalize(x, p.Decimals0), normalize(y, p.Decimals1))
+}
+
+// f is the pair's _f: x0³y + x0y³ in 18 decimals fixed point.
+func f(x0, y *big.Int) *big.Int {
+	a := new(big.Int).Mul(x0, y)
+	a.Quo(a, wad)
+
+	b := new(big.Int).Mul(x0, x0)
+	b.Quo(b, wad)
+	yy := new(big.Int).Mul(y, y)
+	b.Add(b, yy.Quo(yy, wad))
+
+	a.Mul(a, b)
+	return a.Quo(a, wad)
+}
+
+// d is the pair's _d, the derivative of f in y: 3x0y² + x0³.
+func d(x0, y *big.Int) *big.Int {
+	yy := new(big.Int).Mul(y, y)
+	yy.Quo(yy, wad)
+	a := new(big.Int).Mul(big.NewInt(3), x0)
+	a.Mul(a, yy)
+	a.Quo(a, wad)
+
+	b := new(big.Int).Mul(x0, x0)
+	b.Quo(b, wad)
+	b.Mul(b, x0)
+	b.Quo(b, wad)
+
+	return a.Add(a, b)
+}
+
+// getY solves f(x0, y) = xy for y by Newton's method starting from y, step
+// for step as the pair's _get_y does, including its use of _k (which scales
+// by the token decimals again) for the last step up.
+//
+// Returns false where the pair reverts: the iteration limit is reached or the
+// derivative is zero.
+func (p SolidlyPool) getY(x0, xy, y *big.Int) (*big.Int, bool) {
+	y = new(big.Int).Set(y)
+	one := big.NewInt(1)
+
+	for range maxGetYIterations {
+		k := f(x0, y)
+		den := d(x0, y)
+		if den.Sign() == 0 {
+			return nil, false
+		}
+
+		if k.Cmp(xy) < 0 {
+			// dy = (xy - k) * 1e18 / _d(x0, y).
+			dy := new(big.Int).Sub(xy, k)
+			dy.Mul(dy, wad).Quo(dy, den)
+			if dy.Sign() == 0 {
+				// The rounding of dy stops short of xy; y + 1 is the closest
+				// answer if it passes it.
+				yUp := new(big.Int).Add(y, one)
+				if p.k(x0, yUp).Cmp(xy) > 0 {
+					return yUp, true
+				}
+				dy.SetInt64(1)
+			}
+			y.Add(y, dy)
+			continue
+		}
+
+		// dy = (k - xy) * 1e18 / _d(x0, y).
+		dy := new(big.Int).Sub(k, xy)
+		dy.Mul(dy, wad).Quo(dy, den)
+		if dy.Sign() == 0 {
+			if k.Cmp(xy) == 0 || f(x0, new(big.Int).Sub(y, one)).Cmp(xy) < 0 {
+				return y, true
+			}
+			dy.SetInt64(1)
+		}
+		if dy.Cmp(y) > 0 {
+			return nil, false
+		}
+		y.Sub(y, dy)
+	}
+
+	return nil, false
+}
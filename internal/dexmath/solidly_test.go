@@ -0,0 +1,136 @@
+package dexmath
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestSolidlyPool_GetAmountOut_Stable(t *testing.T) {
+	t.Parallel()
+
+	// 1M of a 6 decimals token against 1M of an 18 decimals token.
+	pool := SolidlyPool{
+		Reserve0:  bi("1000000000000"),
+		Reserve1:  bi("1000000000000000000000000"),
+		Decimals0: bi("1000000"),
+		Decimals1: bi("1000000000000000000"),
+		Stable:    true,
+		FeeBps:    5,
+	}
+
+	tests := []struct {
+		name    string
+		in      *big.Int
+		dir     Direction
+		wantMin *big.Int
+		wantMax *big.Int
+	}{
+		{
+			// 1000 in, less 0.05%, at a flat price: 999.5 out, a hair less.
+			name:    "small swap token0 to token1",
+			in:      bi("1000000000"),
+			dir:     ZeroForOne,
+			wantMin: bi("999400000000000000000"),
+			wantMax: bi("999500000000000000000"),
+		},
+		{
+			name:    "small swap token1 to token0",
+			in:      bi("1000000000000000000000"),
+			dir:     OneForZero,
+			wantMin: bi("999400000"),
+			wantMax: bi("999500000"),
+		},
+		{
+			// Half the reserve moves the price, but far less than x*y would:
+			// the constant product gives 333k for the same swap.
+			name:    "large swap",
+			in:      bi("500000000000"),
+			dir:     ZeroForOne,
+			wantMin: bi("450000000000000000000000"),
+			wantMax: bi("500000000000000000000000"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, ok := pool.GetAmountOut(tt.in, tt.dir)
+			if !ok {
+				t.Fatal("ok=false")
+			}
+			if out.Cmp(tt.wantMin) < 0 || out.Cmp(tt.wantMax) > 0 {
+				t.Fatalf("out %s not in [%s, %s]", out, tt.wantMin, tt.wantMax)
+			}
+
+			// The pair only accepts swaps that keep k from falling.
+			reserveIn, reserveOut := pool.Reserve0, pool.Reserve1
+			if tt.dir == OneForZero {
+				reserveIn, reserveOut = pool.Reserve1, pool.Reserve0
+			}
+			fee := new(big.Int).Mul(tt.in, big.NewInt(int64(pool.FeeBps)))
+			fee.Quo(fee, bpsDen)
+			newIn := new(big.Int).Add(reserveIn, tt.in)
+			newIn.Sub(newIn, fee)
+			newOut := new(big.Int).Sub(reserveOut, out)
+			if tt.dir == OneForZero {
+				newIn, newOut = newOut, newIn
+			}
+			if pool.k(newIn, newOut).Cmp(pool.k(pool.Reserve0, pool.Reserve1)) < 0 {
+				t.Fatal("swap decreases k")
+			}
+		})
+	}
+}
+
+func TestSolidlyPool_GetAmountOut_Volatile(t *testing.T) {
+	t.Parallel()
+
+	pool := SolidlyPool{Reserve0: bi("10000"), Reserve1: bi("20000"), FeeBps: 30}
+
+	// 1000 less 3 fee: 997 * 20000 / 10997.
+	out, ok := pool.GetAmountOut(bi("1000"), ZeroForOne)
+	if !ok || out.Cmp(bi("1813")) != 0 {
+		t.Fatalf("got %s, ok=%v, want 1813", out, ok)
+	}
+}
+
+func TestSolidlyPool_GetAmountOut_Invalid(t *testing.T) {
+	t.Parallel()
+
+	pool := SolidlyPool{
+		Reserve0:  bi("0"),
+		Reserve1:  bi("1000"),
+		Decimals0: bi("1"),
+		Decimals1: bi("1"),
+		Stable:    true,
+	}
+	if _, ok := pool.GetAmountOut(bi("1"), ZeroForOne); ok {
+		t.Fatal("zero reserve should be false")
+	}
+
+	pool.Reserve0 = bi("1000")
+	if _, ok := pool.GetAmountOut(bi("0"), ZeroForOne); ok {
+		t.Fatal("zero amountIn should be false")
+	}
+}
+
+func TestSolidlyGetY(t *testing.T) {
+	t.Parallel()
+
+	pool := SolidlyPool{Decimals0: wad, Decimals1: wad}
+	x0 := bi("1500000000000000000000")
+	xy := f(bi("1000000000000000000000"), bi("1000000000000000000000"))
+
+	y, ok := pool.getY(x0, xy, bi("1000000000000000000000"))
+	if !ok {
+		t.Fatal("ok=false")
+	}
+	// y is the least value at which the curve reaches xy, up to the rounding of f.
+	if f(x0, y).Cmp(xy) < 0 {
+		t.Fatalf("f(x0, %s) below xy", y)
+	}
+	if f(x0, new(big.Int).Sub(y, big.NewInt(2))).Cmp(xy) >= 0 {
+		t.Fatalf("y = %s is not the least solution", y)
+	}
+}
@@ -0,0 +1,100 @@
+package dexmath
+
+import "math/big"
+
+var (
+	// q112 is 2^112, the scale of the UQ112x112 fixed point numbers that
+	// Uniswap V2 accumulates prices in.
+	q112 = new(big.Int).Lsh(big.NewInt(1), 112)
+
+	// mod256 wraps cumulative prices as the pair contract does; differences
+	// taken modulo 2^256, like uint32 timestamp differences, stay correct.
+	mod256 = new(big.Int).Lsh(big.NewInt(1), 256)
+)
+
+// Observation is a reading of a pair's cumulative prices at a timestamp.
+type Observation struct {
+	Price0Cumulative *big.Int
+	Price1Cumulative *big.Int
+	Timestamp        uint32
+}
+
+// CurrentObservation returns the cumulative prices of a pair at blockTimestamp,
+// as UniswapV2OracleLibrary.currentCumulativePrices does: the stored cumulative
+// prices, last updated at blockTimestampLast, are extended with the current
+// reserve price for the time elapsed since.
+func CurrentObservation(
+	price0CumulativeLast, price1CumulativeLast, reserve0, reserve1 *big.Int,
+	blockTimestampLast uint32,
+	blockTimestamp uint64,
+) Observation {
+	obs := Observation{
+		Price0Cumulative: new(big.Int).Set(price0CumulativeLast),
+		Price1Cumulative: new(big.Int).Set(price1CumulativeLast),
+		Timestamp:        uint32(blockTimestamp),
+	}
+
+	elapsed := obs.Timestamp - blockTimestampLast
+	if elapsed == 0 || reserve0.Sign() <= 0 || reserve1.Sign() <= 0 {
+		return obs
+	}
+
+	dt := new(big.Int).SetUint64(uint64(elapsed))
+	obs.Price0Cumulative.Add(obs.Price0Cumulative, new(big.Int).Mul(EncodePrice(reserve1, reserve0), dt))
+	obs.Price0Cumulative.Mod(obs.Price0Cumulative, mod256)
+	obs.Price1Cumulative.Add(obs.Price1Cumulative, new(big.Int).Mul(EncodePrice(reserve0, reserve1), dt))
+	obs.Price1Cumulative.Mod(obs.Price1Cumulative, mod256)
+	return obs
+}
+
+// EncodePrice returns numerator / denominator as a UQ112x112 number, rounded
+// down, as UQ112x112.encode(numerator).uqdiv(denominator) does.
+func EncodePrice(numerator, denominator *big.Int) *big.Int {
+	out := new(big.Int).Lsh(numerator, 112)
+	return out.Quo(out, denominator)
+}
+
+// TWAP returns the time-weighted average prices of token0 in token1 and of
+// token1 in token0 between two observations, as UQ112x112 numbers.
+//
+// Returns false if no time elapsed between the observations.
+func TWAP(start, end Observation) (*big.Int, *big.Int, bool) {
+	elapsed := end.Timestamp - start.Timestamp
+	if elapsed == 0 {
+		return new(big.Int), new(big.Int), false
+	}
+	dt := new(big.Int).SetUint64(uint64(elapsed))
+
+	price0 := new(big.Int).Sub(end.Price0Cumulative, start.Price0Cumulative)
+	price0.Mod(price0, mod256).Quo(price0, dt)
+	price1 := new(big.Int).Sub(end.Price1Cumulative, start.Price1Cumulative)
+	price1.Mod(price1, mod256).Quo(price1, dt)
+	return price0, price1, true
+}
+
+// ConsultPrice returns amount multiplied by a UQ112x112 price, rounded down,
+// as FixedPoint.mul(price, amount).decode144() does.
+func ConsultPrice(price, amount *big.Int) *big.Int {
+	out := new(big.Int).Mul(price, amount)
+	return out.Rsh(out, 112)
+}
+
+// PriceRat converts a UQ112x112 price to an exact rational number.
+func PriceRat(price *big.Int) *big.Rat {
+	return new(big.Rat).SetFrac(price, q112)
+}
+
+// PriceDeviationBps returns how far price deviates from reference, in basis
+// points of reference, rounded down. A zero reference deviates by the maximum.
+func PriceDeviationBps(price, reference *big.Int) uint64 {
+	if reference.Sign() == 0 {
+		return ^uint64(0)
+	}
+
+	diff := new(big.Int).Sub(price, reference)
+	diff.Abs(diff).Mul(diff, bpsDen).Quo(diff, reference)
+	if !diff.IsUint64() {
+		return ^uint64(0)
+	}
+	return diff.Uint64()
+}
@@ -0,0 +1,68 @@
+package dexmath
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestTWAP(t *testing.T) {
+	t.Parallel()
+
+	// The price of token0 is 2 for a minute and 3 for the next one.
+	start := CurrentObservation(bi("0"), bi("0"), bi("10000"), bi("20000"), 1000, 1000)
+	mid := CurrentObservation(start.Price0Cumulative, start.Price1Cumulative, bi("10000"), bi("20000"), 1000, 1060)
+	end := CurrentObservation(mid.Price0Cumulative, mid.Price1Cumulative, bi("10000"), bi("30000"), 1060, 1120)
+
+	price0, price1, ok := TWAP(start, end)
+	if !ok {
+		t.Fatal("TWAP not ok")
+	}
+	if got := PriceRat(price0); got.Cmp(big.NewRat(5, 2)) != 0 {
+		t.Fatalf("price0 = %s", got.FloatString(6))
+	}
+	// (1/2 + 1/3) / 2, rounded down in UQ112x112.
+	if got, _ := PriceRat(price1).Float64(); math.Abs(got-5.0/12) > 1e-12 {
+		t.Fatalf("price1 = %v", got)
+	}
+	if got := ConsultPrice(price0, bi("1000")); got.Cmp(bi("2500")) != 0 {
+		t.Fatalf("ConsultPrice = %s", got.String())
+	}
+
+	if _, _, ok := TWAP(end, end); ok {
+		t.Fatal("empty window should be false")
+	}
+}
+
+func TestTWAP_Overflow(t *testing.T) {
+	t.Parallel()
+
+	// Both the cumulative price and the timestamp wrap around during the window.
+	nearMax := new(big.Int).Sub(mod256, EncodePrice(bi("1"), bi("1")))
+	start := Observation{Price0Cumulative: nearMax, Price1Cumulative: nearMax, Timestamp: math.MaxUint32 - 4}
+	end := CurrentObservation(nearMax, nearMax, bi("10000"), bi("20000"), math.MaxUint32-4, uint64(math.MaxUint32)+6)
+
+	if end.Price0Cumulative.Cmp(nearMax) >= 0 {
+		t.Fatal("cumulative price did not wrap")
+	}
+
+	price0, price1, ok := TWAP(start, end)
+	if !ok || PriceRat(price0).Cmp(big.NewRat(2, 1)) != 0 || PriceRat(price1).Cmp(big.NewRat(1, 2)) != 0 {
+		t.Fatalf("TWAP = %s, %s, %v", PriceRat(price0).FloatString(6), PriceRat(price1).FloatString(6), ok)
+	}
+}
+
+func TestPriceDeviationBps(t *testing.T) {
+	t.Parallel()
+
+	two := EncodePrice(bi("2"), bi("1"))
+	if got := PriceDeviationBps(EncodePrice(bi("21"), bi("10")), two); got != 499 && got != 500 {
+		t.Fatalf("up = %d", got)
+	}
+	if got := PriceDeviationBps(EncodePrice(bi("19"), bi("10")), two); got != 499 && got != 500 {
+		t.Fatalf("down = %d", got)
+	}
+	if got := PriceDeviationBps(two, bi("0")); got != math.MaxUint64 {
+		t.Fatalf("zero reference = %d", got)
+	}
+}
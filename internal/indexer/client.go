@@ -0,0 +1,54 @@
+package indexer
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/metrics"
+)
+
+// Client serves the past reserves of indexed pairs from an Indexer and passes
+// every other call, and reads the indexer cannot answer, to the wrapped client.
+type Client struct {
+	uniswap.Client
+
+	chain   string
+	indexer *Indexer
+}
+
+// NewClient wraps client of chain with the reserves indexed by ix.
+func NewClient(chain string, client uniswap.Client, ix *Indexer) *Client {
+	return &Client{Client: client, chain: chain, indexer: ix}
+}
+
+// GetPairReservesAt returns the reserves of a pair at the given block from the
+// indexer when it has them, and from the wrapped client otherwise.
+func (c *Client) GetPairReservesAt(
+	ctx context.Context,
+	pair common.Address,
+	blockNumber *big.Int,
+) (*uniswapdto.PairReserves, error) {
+	if c.indexer.Indexes(pair) && blockNumber != nil && blockNumber.IsUint64() {
+		if reserves, ok := c.indexer.ReservesAt(pair, blockNumber.Uint64()); ok {
+			metrics.RecordReserveRead(c.chain, metrics.ReserveReadIndex)
+			return reserves, nil
+		}
+		metrics.RecordReserveRead(c.chain, metrics.ReserveReadRPC)
+	}
+
+	return c.Client.GetPairReservesAt(ctx, pair, blockNumber)
+}
+
+// Reconfigure passes new connection settings to the wrapped client.
+func (c *Client) Reconfigure(rpcURLs []string, callTimeout time.Duration) error {
+	r, ok := c.Client.(uniswap.Reconfigurer)
+	if !ok {
+		return nil
+	}
+	return r.Reconfigure(rpcURLs, callTimeout)
+}
@@ -0,0 +1,271 @@
+package indexer
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/metrics"
+)
+
+// Head follows the blocks past the store's last indexed block, which a chain
+// reorganisation can still replace. It keeps a window of their headers and of
+// the reserves their Sync logs set, checks every new block against the hash
+// of its parent, and on a mismatch rolls the window back to the last block
+// still on the chain before applying the logs of the canonical blocks.
+type Head struct {
+	chain  string
+	client uniswap.Client
+	store  *Store
+	pools  []common.Address
+
+	mu sync.RWMutex
+	// blocks are consecutive, from the base block, the last one in the store,
+	// to the head. Follow replaces the slice rather than changing it.
+	blocks []headBlock
+}
+
+// headBlock is a block of the window.
+type headBlock struct {
+	header uniswapdto.BlockHeader
+	// reserves are the reserves of the pairs synced in the block.
+	reserves map[common.Address]*uniswapdto.PairReserves
+}
+
+// NewHead creates a follower of the pools' blocks past those in store.
+func NewHead(chain string, client uniswap.Client, store *Store, pools []common.Address) *Head {
+	return &Head{chain: chain, client: client, store: store, pools: pools}
+}
+
+// Follow moves the window to start at block base, which the store has indexed
+// for every pool, or at the window's start if the store is further, and
+// extends it to block latest.
+func (h *Head) Follow(ctx context.Context, base, latest uint64) error {
+	blocks := h.window(base)
+	defer func() { h.set(blocks) }()
+
+	if len(blocks) == 0 {
+		header, err := h.client.GetBlockHeader(ctx, new(big.Int).SetUint64(base))
+		if err != nil {
+			return errors.Wrap(err, "h.client.GetBlockHeader")
+		}
+		blocks = []headBlock{{header: *header}}
+	} else {
+		// A fork may have replaced the head without outgrowing it.
+		var err error
+		if blocks, err = h.rollback(ctx, blocks, latest); err != nil {
+			return errors.Wrap(err, "h.rollback")
+		}
+	}
+
+	for {
+		tip := blocks[len(blocks)-1].header
+		if tip.Number >= latest {
+			return nil
+		}
+
+		headers, forked, err := h.headers(ctx, tip, latest)
+		if err != nil {
+			return errors.Wrap(err, "h.headers")
+		}
+
+		if len(headers) > 0 {
+			next, err := h.apply(ctx, headers)
+			if err != nil {
+				return errors.Wrap(err, "h.apply")
+			}
+			if len(next) == 0 {
+				return errors.Errorf("logs of block %d are not from block %s", headers[0].Number, headers[0].Hash.Hex())
+			}
+			blocks = append(blocks, next...)
+			if len(next) < len(headers) {
+				// The chain changed between reading the headers and the logs.
+				continue
+			}
+		}
+
+		if forked {
+			n := len(blocks)
+			if blocks, err = h.rollback(ctx, blocks, latest); err != nil {
+				return errors.Wrap(err, "h.rollback")
+			}
+			if len(blocks) == n {
+				return errors.Errorf("block %d does not follow block %d", blocks[n-1].header.Number+1, blocks[n-1].header.Number)
+			}
+		}
+	}
+}
+
+// headers reads the headers of the blocks after tip up to block latest. It
+// stops at the first block whose parent is not the previous block, reporting
+// that the chain forked.
+func (h *Head) headers(ctx context.Context, tip uniswapdto.BlockHeader, latest uint64) ([]uniswapdto.BlockHeader, bool, error) {
+	var headers []uniswapdto.BlockHeader
+	for parent := tip; parent.Number < latest; {
+		header, err := h.client.GetBlockHeader(ctx, new(big.Int).SetUint64(parent.Number+1))
+		if err != nil {
+			return nil, false, errors.Wrap(err, "h.client.GetBlockHeader")
+		}
+		if header.ParentHash != parent.Hash {
+			return headers, true, nil
+		}
+		headers = append(headers, *header)
+		parent = *header
+	}
+	return headers, false, nil
+}
+
+// apply applies the pools' logs to consecutive blocks. It returns the blocks
+// up to the first one with a removed log or a log of another block of the
+// same number, which the chain replaced after its header was read.
+func (h *Head) apply(ctx context.Context, headers []uniswapdto.BlockHeader) ([]headBlock, error) {
+	from, to := headers[0].Number, headers[len(headers)-1].Number
+	logs, err := h.client.GetPairLogs(ctx, h.pools, from, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "h.client.GetPairLogs")
+	}
+
+	blocks := make([]headBlock, len(headers))
+	for i := range headers {
+		blocks[i].header = headers[i]
+	}
+
+	canonical := len(blocks)
+	for _, l := range logs {
+		if l.BlockNumber < from || l.BlockNumber > to {
+			continue
+		}
+		i := int(l.BlockNumber - from) //nolint:gosec
+		if l.Removed || l.BlockHash != blocks[i].header.Hash {
+			canonical = min(canonical, i)
+			continue
+		}
+		if l.Sync == nil {
+			continue
+		}
+
+		if blocks[i].reserves == nil {
+			blocks[i].reserves = make(map[common.Address]*uniswapdto.PairReserves)
+		}
+		// Logs are in chain order, so the last Sync of a pair in a block wins.
+		blocks[i].reserves[l.Pair] = &uniswapdto.PairReserves{
+			Reserve0:           l.Sync.Reserve0,
+			Reserve1:           l.Sync.Reserve1,
+			BlockTimestampLast: uint32(headers[i].Timestamp), //nolint:gosec
+		}
+	}
+
+	return blocks[:canonical], nil
+}
+
+// rollback drops the blocks at the end of the window that are past block
+// latest or no longer on the chain, and records the reorganisation. A
+// reorganisation reaching the base block, which the store holds, empties the
+// window and fails.
+func (h *Head) rollback(ctx context.Context, blocks []headBlock, latest uint64) ([]headBlock, error) {
+	// Blocks past the latest one were either replaced by a shorter fork or not
+	// seen yet by a lagging node; the blocks below them tell which.
+	past := 0
+	for len(blocks) > 1 && blocks[len(blocks)-1].header.Number > latest {
+		blocks = blocks[:len(blocks)-1]
+		past++
+	}
+
+	depth := 0
+	for len(blocks) > 0 {
+		last := blocks[len(blocks)-1].header
+		header, err := h.client.GetBlockHeader(ctx, new(big.Int).SetUint64(last.Number))
+		if err != nil {
+			// The window stays valid: the blocks still in it are unchecked, not stale.
+			h.recordReorg(blocks, depth, past)
+			return blocks, errors.Wrap(err, "h.client.GetBlockHeader")
+		}
+		if header.Hash == last.Hash {
+			break
+		}
+		blocks = blocks[:len(blocks)-1]
+		depth++
+	}
+
+	h.recordReorg(blocks, depth, past)
+	if len(blocks) == 0 {
+		return nil, errors.Errorf("reorganisation of %d blocks reached the indexed blocks", depth+past)
+	}
+	return blocks, nil
+}
+
+// recordReorg logs and counts a reorganisation that replaced depth blocks of
+// the window and dropped past blocks beyond the new fork's head.
+func (h *Head) recordReorg(blocks []headBlock, depth, past int) {
+	if depth == 0 {
+		return
+	}
+	depth += past
+
+	var ancestor uint64
+	if len(blocks) > 0 {
+		ancestor = blocks[len(blocks)-1].header.Number
+	}
+	log.Printf("indexer of chain %s: reorganisation of %d blocks after block %d", h.chain, depth, ancestor)
+	metrics.RecordReorg(h.chain, depth)
+}
+
+// window returns a copy of the window starting at block base, or nil if the
+// window ends before base. A base before the window, which the store already
+// holds, leaves its start as is.
+func (h *Head) window(base uint64) []headBlock {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if len(h.blocks) == 0 {
+		return nil
+	}
+	first, tip := h.blocks[0].header.Number, h.blocks[len(h.blocks)-1].header.Number
+	if base > tip {
+		return nil
+	}
+	return slices.Clone(h.blocks[max(base, first)-first:])
+}
+
+// set replaces the window.
+func (h *Head) set(blocks []headBlock) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.blocks = blocks
+}
+
+// ReservesAt returns the reserves of a pair at the end of a block past the
+// base block, up to the head. Pairs not synced since the base block have the
+// reserves the store holds for it.
+func (h *Head) ReservesAt(pair common.Address, block uint64) (*uniswapdto.PairReserves, bool) {
+	h.mu.RLock()
+	blocks := h.blocks
+	h.mu.RUnlock()
+
+	if len(blocks) == 0 {
+		return nil, false
+	}
+	base := blocks[0].header.Number
+	if block <= base || block > blocks[len(blocks)-1].header.Number {
+		return nil, false
+	}
+
+	for i := block - base; i > 0; i-- {
+		if r, ok := blocks[i].reserves[pair]; ok {
+			return &uniswapdto.PairReserves{
+				Reserve0:           new(big.Int).Set(r.Reserve0),
+				Reserve1:           new(big.Int).Set(r.Reserve1),
+				BlockTimestampLast: r.BlockTimestampLast,
+			}, true
+		}
+	}
+	return h.store.ReservesAt(pair, base)
+}
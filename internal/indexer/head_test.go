@@ -0,0 +1,325 @@
+package indexer
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+)
+
+// fakeSync is a Sync log of a scripted block.
+type fakeSync struct {
+	pair     common.Address
+	r0, r1   int64
+	removed  bool
+	stale    bool
+	logIndex uint
+}
+
+type fakeBlock struct {
+	header uniswapdto.BlockHeader
+	syncs  []fakeSync
+}
+
+// fakeChain is a scripted chain serving the headers and logs of its blocks.
+// Reorganisations replace its last blocks with blocks of a new fork.
+type fakeChain struct {
+	uniswap.Client
+
+	mu     sync.Mutex
+	blocks []fakeBlock
+	fork   int
+	// beforeLogs runs once, at the next GetPairLogs call, to change the chain
+	// between reading headers and logs.
+	beforeLogs func()
+}
+
+// newFakeChain creates a chain of empty blocks 0 to n.
+func newFakeChain(n int) *fakeChain {
+	c := &fakeChain{}
+	for range n + 1 {
+		c.mine()
+	}
+	return c
+}
+
+// mine appends a block with the given Sync logs.
+func (c *fakeChain) mine(syncs ...fakeSync) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	number := uint64(len(c.blocks))
+	header := uniswapdto.BlockHeader{
+		Number:    number,
+		Hash:      crypto.Keccak256Hash([]byte(fmt.Sprintf("%d/%d", number, c.fork))),
+		Timestamp: 1_700_000_000 + number*12,
+	}
+	if number > 0 {
+		header.ParentHash = c.blocks[number-1].header.Hash
+	}
+	c.blocks = append(c.blocks, fakeBlock{header: header, syncs: syncs})
+}
+
+// reorg drops the last depth blocks; blocks mined next are of a new fork.
+func (c *fakeChain) reorg(depth int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.blocks = c.blocks[:len(c.blocks)-depth]
+	c.fork++
+}
+
+func (c *fakeChain) BlockNumber(context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return uint64(len(c.blocks) - 1), nil
+}
+
+func (c *fakeChain) GetBlockHeader(_ context.Context, blockNumber *big.Int) (*uniswapdto.BlockHeader, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := blockNumber.Uint64()
+	if n >= uint64(len(c.blocks)) {
+		return nil, errors.Errorf("block %d not found", n)
+	}
+	header := c.blocks[n].header
+	return &header, nil
+}
+
+func (c *fakeChain) GetPairLogs(_ context.Context, pairs []common.Address, from, to uint64) ([]uniswapdto.PairLog, error) {
+	if hook := c.beforeLogs; hook != nil {
+		c.beforeLogs = nil
+		hook()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var logs []uniswapdto.PairLog
+	for n := from; n <= to && n < uint64(len(c.blocks)); n++ {
+		block := c.blocks[n]
+		for _, s := range block.syncs {
+			if !slices.Contains(pairs, s.pair) {
+				continue
+			}
+			l := uniswapdto.PairLog{
+				Pair:           s.pair,
+				BlockNumber:    n,
+				BlockHash:      block.header.Hash,
+				BlockTimestamp: block.header.Timestamp,
+				LogIndex:       s.logIndex,
+				Removed:        s.removed,
+				Sync:           &uniswapdto.PairSync{Reserve0: big.NewInt(s.r0), Reserve1: big.NewInt(s.r1)},
+			}
+			if s.stale {
+				l.BlockHash = common.HexToHash("0xdead")
+			}
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+// requireReserves checks the reserve0 the indexer serves for a pair at each block;
+// zero means none.
+func requireReserves(t *testing.T, ix *Indexer, pair common.Address, want map[uint64]int64) {
+	t.Helper()
+
+	for block, r0 := range want {
+		got, ok := ix.ReservesAt(pair, block)
+		if r0 == 0 {
+			require.False(t, ok, "block %d", block)
+			continue
+		}
+		require.True(t, ok, "block %d", block)
+		require.Equal(t, big.NewInt(r0), got.Reserve0, "block %d", block)
+	}
+}
+
+func reorgCount(chain string, depth int) int64 {
+	v, ok := expvar.Get("reorgs").(*expvar.Map).Get(fmt.Sprintf("%s.%d", chain, depth)).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+	return v.Value()
+}
+
+func newTestIndexer(t *testing.T, chain string, client uniswap.Client) *Indexer {
+	t.Helper()
+
+	store := openStore(t, filepath.Join(t.TempDir(), "store.ndjson"))
+	return New(chain, client, store, Config{
+		Pools:         []common.Address{pairA, pairB},
+		StartBlock:    1,
+		ChunkSize:     100,
+		Confirmations: 3,
+	})
+}
+
+func TestHead_Follow(t *testing.T) {
+	t.Parallel()
+
+	chain := newFakeChain(9)
+	chain.mine(fakeSync{pair: pairA, r0: 100, r1: 1})
+	chain.mine(fakeSync{pair: pairB, r0: 200, r1: 1})
+	chain.mine()
+	chain.mine(fakeSync{pair: pairA, r0: 101, r1: 1}, fakeSync{pair: pairA, r0: 102, r1: 1, logIndex: 1})
+	chain.mine(fakeSync{pair: pairA, r0: 103, r1: 1})
+
+	ix := newTestIndexer(t, "follow", chain)
+	require.NoError(t, ix.CatchUp(context.Background()))
+
+	// Blocks up to 11 are stored, 12 to 14 followed.
+	n, _ := ix.store.Indexed(pairA)
+	require.Equal(t, uint64(11), n)
+	requireReserves(t, ix, pairA, map[uint64]int64{9: 0, 10: 100, 11: 100, 12: 100, 13: 102, 14: 103, 15: 0})
+	requireReserves(t, ix, pairB, map[uint64]int64{10: 0, 11: 200, 14: 200})
+
+	got, ok := ix.ReservesAt(pairA, 14)
+	require.True(t, ok)
+	require.Equal(t, uint32(1_700_000_000+14*12), got.BlockTimestampLast)
+
+	// The store catches up with the head, whose window moves along.
+	chain.mine(fakeSync{pair: pairB, r0: 201, r1: 1})
+	chain.mine()
+	require.NoError(t, ix.CatchUp(context.Background()))
+	n, _ = ix.store.Indexed(pairA)
+	require.Equal(t, uint64(13), n)
+	requireReserves(t, ix, pairA, map[uint64]int64{13: 102, 16: 103})
+	requireReserves(t, ix, pairB, map[uint64]int64{14: 200, 15: 201, 16: 201})
+}
+
+func TestHead_Reorg(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// script changes the chain after the first catch-up, which stores
+		// blocks up to 12 and follows 13 to 15, with a Sync of pairA in each
+		// block from 10.
+		script    func(c *fakeChain)
+		want      map[uint64]int64
+		wantDepth int
+		wantErr   string
+	}{
+		{
+			name: "longer fork",
+			script: func(c *fakeChain) {
+				c.reorg(2)
+				c.mine(fakeSync{pair: pairA, r0: 214, r1: 1})
+				c.mine()
+				c.mine(fakeSync{pair: pairA, r0: 216, r1: 1})
+			},
+			want:      map[uint64]int64{13: 113, 14: 214, 15: 214, 16: 216},
+			wantDepth: 2,
+		},
+		{
+			name: "shorter fork",
+			script: func(c *fakeChain) {
+				c.reorg(3)
+				c.mine()
+				c.mine(fakeSync{pair: pairB, r0: 1, r1: 1})
+			},
+			want:      map[uint64]int64{12: 112, 13: 112, 14: 112, 15: 0},
+			wantDepth: 3,
+		},
+		{
+			name: "fork replaces the head only",
+			script: func(c *fakeChain) {
+				c.reorg(1)
+				c.mine(fakeSync{pair: pairA, r0: 215, r1: 1})
+			},
+			want:      map[uint64]int64{14: 114, 15: 215},
+			wantDepth: 1,
+		},
+		{
+			name: "fork while reading logs",
+			script: func(c *fakeChain) {
+				c.mine(fakeSync{pair: pairA, r0: 116, r1: 1})
+				c.mine(fakeSync{pair: pairA, r0: 117, r1: 1})
+				c.beforeLogs = func() {
+					c.reorg(1)
+					c.mine(fakeSync{pair: pairA, r0: 217, r1: 1})
+				}
+			},
+			want: map[uint64]int64{16: 116, 17: 217},
+		},
+		{
+			name: "removed logs",
+			script: func(c *fakeChain) {
+				c.mine(fakeSync{pair: pairA, r0: 116, r1: 1, removed: true})
+			},
+			wantErr: "logs of block 16 are not from block",
+		},
+		{
+			name: "stale logs",
+			script: func(c *fakeChain) {
+				c.mine()
+				c.mine(fakeSync{pair: pairA, r0: 117, r1: 1, stale: true})
+			},
+			want:    map[uint64]int64{16: 115, 17: 0},
+			wantErr: "logs of block 17 are not from block",
+		},
+		{
+			name: "fork past the window",
+			script: func(c *fakeChain) {
+				c.reorg(6)
+				for range 7 {
+					c.mine(fakeSync{pair: pairA, r0: 300, r1: 1})
+				}
+			},
+			// The store kept blocks 10 to 12 of the old fork before indexing
+			// block 13 of the new one, and the window is gone.
+			want:      map[uint64]int64{12: 112, 13: 300, 14: 0},
+			wantDepth: 3,
+			wantErr:   "reorganisation of 3 blocks reached the indexed blocks",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			chain := newFakeChain(9)
+			for r0 := int64(110); r0 <= 115; r0++ {
+				chain.mine(fakeSync{pair: pairA, r0: r0, r1: 1})
+			}
+
+			name := fmt.Sprintf("reorg%d", i)
+			ix := newTestIndexer(t, name, chain)
+			require.NoError(t, ix.CatchUp(context.Background()))
+			requireReserves(t, ix, pairA, map[uint64]int64{12: 112, 15: 115})
+
+			tt.script(chain)
+			err := ix.CatchUp(context.Background())
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			requireReserves(t, ix, pairA, tt.want)
+			for depth := 1; depth <= 6; depth++ {
+				var want int64
+				if depth == tt.wantDepth {
+					want = 1
+				}
+				require.Equal(t, want, reorgCount(name, depth), "depth %d", depth)
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+// Package indexer backfills the Sync and Swap logs of Uniswap V2 pairs into a
+// local Store, which serves the pairs' past reserves without archive eth_calls.
+package indexer
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/metrics"
+)
+
+// Config configures an Indexer.
+type Config struct {
+	Pools []common.Address
+	// StartBlock is the first block indexed for every pool.
+	StartBlock uint64
+	// ChunkSize is the largest block range of one eth_getLogs call.
+	ChunkSize uint64
+	// Confirmations is how many blocks behind the latest one the store stays.
+	Confirmations uint64
+	Interval      time.Duration
+}
+
+// Indexer backfills the logs of pools from StartBlock, or from where the
+// store left off, and keeps up with the chain. Pools added later are indexed
+// on their own until they catch up with the others. The blocks too recent to
+// be stored are followed by a Head.
+type Indexer struct {
+	chain  string
+	client uniswap.Client
+	store  *Store
+	head   *Head
+	cfg    Config
+
+	// chunk is the block range of eth_getLogs calls, halved while the node
+	// refuses ranges and reset on every catch-up.
+	chunk uint64
+}
+
+// New creates an indexer of the pools of chain into store.
+func New(chain string, client uniswap.Client, store *Store, cfg Config) *Indexer {
+	return &Indexer{
+		chain:  chain,
+		client: client,
+		store:  store,
+		head:   NewHead(chain, client, store, cfg.Pools),
+		cfg:    cfg,
+	}
+}
+
+// Indexes reports whether the indexer indexes a pool.
+func (ix *Indexer) Indexes(pool common.Address) bool {
+	return slices.Contains(ix.cfg.Pools, pool)
+}
+
+// ReservesAt returns the reserves of a pair at the end of a block, from the
+// followed blocks past the store's last indexed block or from the store.
+func (ix *Indexer) ReservesAt(pair common.Address, block uint64) (*uniswapdto.PairReserves, bool) {
+	if reserves, ok := ix.head.ReservesAt(pair, block); ok {
+		return reserves, true
+	}
+	return ix.store.ReservesAt(pair, block)
+}
+
+// Run catches up with the chain every cfg.Interval until ctx is done.
+func (ix *Indexer) Run(ctx context.Context) {
+	ticker := time.NewTicker(ix.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := ix.CatchUp(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("indexer of chain %s: %v", ix.chain, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CatchUp indexes the pools up to cfg.Confirmations blocks behind the latest
+// block, and follows the blocks after it up to the latest one.
+func (ix *Indexer) CatchUp(ctx context.Context) error {
+	latest, err := ix.client.BlockNumber(ctx)
+	if err != nil {
+		return errors.Wrap(err, "ix.client.BlockNumber")
+	}
+	if latest < ix.cfg.Confirmations {
+		return nil
+	}
+	target := latest - ix.cfg.Confirmations
+
+	ix.chunk = max(ix.cfg.ChunkSize, 1)
+	for {
+		pairs, from, to, ok := ix.nextRange(target)
+		if !ok {
+			break
+		}
+
+		if err := ix.index(ctx, pairs, from, to); err != nil {
+			return errors.Wrapf(err, "blocks %d to %d", from, to)
+		}
+	}
+	metrics.RecordIndexedBlock(ix.chain, target)
+
+	if err := ix.head.Follow(ctx, target, latest); err != nil {
+		return errors.Wrap(err, "ix.head.Follow")
+	}
+	return nil
+}
+
+// nextRange returns the pools furthest behind and the blocks to index next for
+// them: a chunk, ending at the target or where the next pools left off, so
+// that pools that catch up are indexed together from then on.
+func (ix *Indexer) nextRange(target uint64) ([]common.Address, uint64, uint64, bool) {
+	next := make(map[uint64][]common.Address)
+	for _, pool := range ix.cfg.Pools {
+		n := ix.cfg.StartBlock
+		if last, ok := ix.store.Indexed(pool); ok {
+			n = max(last+1, n)
+		}
+		if n <= target {
+			next[n] = append(next[n], pool)
+		}
+	}
+	if len(next) == 0 {
+		return nil, 0, 0, false
+	}
+
+	starts := make([]uint64, 0, len(next))
+	for n := range next {
+		starts = append(starts, n)
+	}
+	slices.Sort(starts)
+
+	from := starts[0]
+	to := min(from+ix.chunk-1, target)
+	if len(starts) > 1 {
+		to = min(to, starts[1]-1)
+	}
+	return next[from], from, to, true
+}
+
+// index fetches the logs of pairs over a block range and commits them.
+// Ranges the node refuses for their size or number of logs are halved until
+// they are a single block; other errors fail at once.
+func (ix *Indexer) index(ctx context.Context, pairs []common.Address, from, to uint64) error {
+	logs, err := ix.client.GetPairLogs(ctx, pairs, from, to)
+	for err != nil {
+		if ctx.Err() != nil || from == to || !isRangeError(err) {
+			return errors.Wrap(err, "ix.client.GetPairLogs")
+		}
+		ix.chunk = max((to-from+1)/2, 1)
+		to = from + ix.chunk - 1
+		logs, err = ix.client.GetPairLogs(ctx, pairs, from, to)
+	}
+
+	batch, err := ix.batch(ctx, pairs, to, logs)
+	if err != nil {
+		return errors.Wrap(err, "ix.batch")
+	}
+
+	if err := ix.store.Append(batch); err != nil {
+		return errors.Wrap(err, "ix.store.Append")
+	}
+	return nil
+}
+
+// limitExceededCode is the JSON-RPC error code geth and Infura refuse
+// eth_getLogs ranges with for returning too many logs.
+const limitExceededCode = -32005
+
+// rangeErrors are parts of the messages nodes and providers refuse eth_getLogs
+// ranges with under other codes, such as Alchemy's "Log response size exceeded".
+var rangeErrors = []string{
+	"query returned more than",
+	"response size exceeded",
+	"block range",
+	"range too large",
+	"range is too large",
+}
+
+// rateLimitErrors are parts of the messages of rate limited requests, which
+// some providers send with limitExceededCode too.
+var rateLimitErrors = []string{
+	"rate limit",
+	"too many requests",
+}
+
+// isRangeError reports whether the node refused a range of eth_getLogs for its
+// size or number of logs, so that a smaller range may succeed. Only JSON-RPC
+// errors are, and never rate limited requests.
+func isRangeError(err error) bool {
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+
+	msg := strings.ToLower(rpcErr.Error())
+	contains := func(part string) bool { return strings.Contains(msg, part) }
+	if slices.ContainsFunc(rateLimitErrors, contains) {
+		return false
+	}
+	return rpcErr.ErrorCode() == limitExceededCode || slices.ContainsFunc(rangeErrors, contains)
+}
+
+// batch turns the logs of pairs up to block to into a store batch, reading the
+// timestamps of blocks whose logs lack them.
+func (ix *Indexer) batch(ctx context.Context, pairs []common.Address, to uint64, logs []uniswapdto.PairLog) (Batch, error) {
+	batch := Batch{
+		Pairs:    pairs,
+		To:       to,
+		Reserves: make(map[common.Address][]Reserves),
+		Swaps:    make(map[common.Address][]Swap),
+	}
+	timestamps := make(map[uint64]uint64)
+
+	for _, l := range logs {
+		if l.Removed {
+			continue
+		}
+
+		switch {
+		case l.Sync != nil:
+			ts := l.BlockTimestamp
+			if ts == 0 {
+				var ok bool
+				if ts, ok = timestamps[l.BlockNumber]; !ok {
+					var err error
+					ts, err = ix.client.BlockTimestamp(ctx, new(big.Int).SetUint64(l.BlockNumber))
+					if err != nil {
+						return Batch{}, errors.Wrap(err, "ix.client.BlockTimestamp")
+					}
+					timestamps[l.BlockNumber] = ts
+				}
+			}
+			batch.Reserves[l.Pair] = append(batch.Reserves[l.Pair], Reserves{
+				BlockNumber: l.BlockNumber,
+				LogIndex:    l.LogIndex,
+				Timestamp:   ts,
+				Reserve0:    l.Sync.Reserve0,
+				Reserve1:    l.Sync.Reserve1,
+			})
+		case l.Swap != nil:
+			batch.Swaps[l.Pair] = append(batch.Swaps[l.Pair], Swap{
+				BlockNumber: l.BlockNumber,
+				LogIndex:    l.LogIndex,
+				Sender:      l.Swap.Sender,
+				To:          l.Swap.To,
+				Amount0In:   l.Swap.Amount0In,
+				Amount1In:   l.Swap.Amount1In,
+				Amount0Out:  l.Swap.Amount0Out,
+				Amount1Out:  l.Swap.Amount1Out,
+			})
+		}
+	}
+
+	return batch, nil
+}
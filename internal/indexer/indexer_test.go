@@ -0,0 +1,240 @@
+package indexer
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+)
+
+func syncLog(pair common.Address, block uint64, r0, r1 int64) uniswapdto.PairLog {
+	return uniswapdto.PairLog{
+		Pair:           pair,
+		BlockNumber:    block,
+		BlockTimestamp: 1_700_000_000 + block,
+		Sync:           &uniswapdto.PairSync{Reserve0: big.NewInt(r0), Reserve1: big.NewInt(r1)},
+	}
+}
+
+// rpcError is a JSON-RPC error of a node.
+type rpcError struct {
+	code int
+	msg  string
+}
+
+func (e rpcError) Error() string  { return e.msg }
+func (e rpcError) ErrorCode() int { return e.code }
+
+func TestIndexer_CatchUp(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	path := filepath.Join(t.TempDir(), "store.ndjson")
+	store := openStore(t, path)
+	// pairA was indexed by an earlier run; pairB was added since.
+	require.NoError(t, store.Append(Batch{Pairs: []common.Address{pairA}, To: 149}))
+
+	removed := syncLog(pairA, 160, 1, 1)
+	removed.Removed = true
+	// Swap logs need no timestamp.
+	swap := uniswapdto.PairLog{
+		Pair:        pairA,
+		BlockNumber: 165,
+		LogIndex:    1,
+		Swap: &uniswapdto.PairSwap{
+			Sender:     common.HexToAddress("0x5"),
+			To:         common.HexToAddress("0x6"),
+			Amount0In:  big.NewInt(100),
+			Amount1In:  big.NewInt(0),
+			Amount0Out: big.NewInt(0),
+			Amount1Out: big.NewInt(190),
+		},
+	}
+
+	mockClient := mock.NewMockClient(ctrl)
+	mockClient.EXPECT().BlockNumber(gomock.Any()).Return(uint64(210), nil)
+	gomock.InOrder(
+		// pairB catches up with pairA in chunks.
+		mockClient.EXPECT().GetPairLogs(gomock.Any(), []common.Address{pairB}, uint64(100), uint64(139)).
+			Return([]uniswapdto.PairLog{syncLog(pairB, 120, 500, 600)}, nil),
+		mockClient.EXPECT().GetPairLogs(gomock.Any(), []common.Address{pairB}, uint64(140), uint64(149)).
+			Return(nil, nil),
+		// The node refuses a range, which is halved.
+		mockClient.EXPECT().GetPairLogs(gomock.Any(), []common.Address{pairA, pairB}, uint64(150), uint64(189)).
+			Return(nil, rpcError{code: -32005, msg: "query returned more than 10000 results"}),
+		mockClient.EXPECT().GetPairLogs(gomock.Any(), []common.Address{pairA, pairB}, uint64(150), uint64(169)).
+			Return([]uniswapdto.PairLog{removed, syncLog(pairA, 165, 1000, 2000), swap}, nil),
+		mockClient.EXPECT().GetPairLogs(gomock.Any(), []common.Address{pairA, pairB}, uint64(170), uint64(189)).
+			Return(nil, nil),
+		mockClient.EXPECT().GetPairLogs(gomock.Any(), []common.Address{pairA, pairB}, uint64(190), uint64(209)).
+			Return([]uniswapdto.PairLog{{
+				Pair:        pairB,
+				BlockNumber: 200,
+				Sync:        &uniswapdto.PairSync{Reserve0: big.NewInt(700), Reserve1: big.NewInt(800)},
+			}}, nil),
+		mockClient.EXPECT().BlockTimestamp(gomock.Any(), big.NewInt(200)).Return(uint64(1_700_000_200), nil),
+		mockClient.EXPECT().GetPairLogs(gomock.Any(), []common.Address{pairA, pairB}, uint64(210), uint64(210)).
+			Return(nil, nil),
+		// The head starts at the last indexed block.
+		mockClient.EXPECT().GetBlockHeader(gomock.Any(), big.NewInt(210)).
+			Return(&uniswapdto.BlockHeader{Number: 210}, nil),
+	)
+
+	ix := New("ethereum", mockClient, store, Config{
+		Pools:      []common.Address{pairA, pairB},
+		StartBlock: 100,
+		ChunkSize:  40,
+	})
+	require.NoError(t, ix.CatchUp(context.Background()))
+
+	for _, pair := range []common.Address{pairA, pairB} {
+		n, ok := store.Indexed(pair)
+		require.True(t, ok)
+		require.Equal(t, uint64(210), n)
+	}
+
+	got, ok := store.ReservesAt(pairA, 170)
+	require.True(t, ok)
+	require.Equal(t, big.NewInt(1000), got.Reserve0)
+	_, ok = store.ReservesAt(pairA, 160)
+	require.False(t, ok, "removed logs are not indexed")
+	require.Equal(t, []Swap{{
+		BlockNumber: 165,
+		LogIndex:    1,
+		Sender:      common.HexToAddress("0x5"),
+		To:          common.HexToAddress("0x6"),
+		Amount0In:   big.NewInt(100),
+		Amount1In:   big.NewInt(0),
+		Amount0Out:  big.NewInt(0),
+		Amount1Out:  big.NewInt(190),
+	}}, store.Swaps(pairA, 100, 210))
+
+	got, ok = store.ReservesAt(pairB, 205)
+	require.True(t, ok)
+	require.Equal(t, &uniswapdto.PairReserves{
+		Reserve0:           big.NewInt(700),
+		Reserve1:           big.NewInt(800),
+		BlockTimestampLast: 1_700_000_200,
+	}, got)
+}
+
+func TestIndexer_CatchUpError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		mockSetup func(mc *mock.MockClient)
+		wantErr   string
+	}{
+		{
+			// A single block the node refuses fails the catch-up.
+			name: "refused single block",
+			mockSetup: func(mc *mock.MockClient) {
+				gomock.InOrder(
+					mc.EXPECT().GetPairLogs(gomock.Any(), []common.Address{pairA}, uint64(100), uint64(101)).
+						Return(nil, rpcError{code: -32602, msg: "Log response size exceeded."}),
+					mc.EXPECT().GetPairLogs(gomock.Any(), []common.Address{pairA}, uint64(100), uint64(100)).
+						Return(nil, rpcError{code: -32602, msg: "Log response size exceeded."}),
+				)
+			},
+			wantErr: "blocks 100 to 101: ix.client.GetPairLogs: Log response size exceeded.",
+		},
+		{
+			// Rate limited requests are not ranges refused, whatever their code.
+			name: "rate limited",
+			mockSetup: func(mc *mock.MockClient) {
+				mc.EXPECT().GetPairLogs(gomock.Any(), []common.Address{pairA}, uint64(100), uint64(101)).
+					Return(nil, rpcError{code: -32005, msg: "daily request count exceeded, request rate limited"})
+			},
+			wantErr: "blocks 100 to 101: ix.client.GetPairLogs: daily request count exceeded, request rate limited",
+		},
+		{
+			// Only the node's own JSON-RPC errors refuse ranges.
+			name: "http error",
+			mockSetup: func(mc *mock.MockClient) {
+				mc.EXPECT().GetPairLogs(gomock.Any(), []common.Address{pairA}, uint64(100), uint64(101)).
+					Return(nil, errors.New("429 Too Many Requests: rate limit exceeded"))
+			},
+			wantErr: "blocks 100 to 101: ix.client.GetPairLogs: 429 Too Many Requests: rate limit exceeded",
+		},
+		{
+			// Errors other than refused ranges are not retried with smaller ones.
+			name: "node error",
+			mockSetup: func(mc *mock.MockClient) {
+				mc.EXPECT().GetPairLogs(gomock.Any(), []common.Address{pairA}, uint64(100), uint64(101)).
+					Return(nil, errors.New("connection refused"))
+			},
+			wantErr: "blocks 100 to 101: ix.client.GetPairLogs: connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := openStore(t, filepath.Join(t.TempDir(), "store.ndjson"))
+
+			mockClient := mock.NewMockClient(ctrl)
+			mockClient.EXPECT().BlockNumber(gomock.Any()).Return(uint64(101), nil)
+			tt.mockSetup(mockClient)
+
+			ix := New("ethereum", mockClient, store, Config{Pools: []common.Address{pairA}, StartBlock: 100, ChunkSize: 2})
+			err := ix.CatchUp(context.Background())
+			require.ErrorContains(t, err, tt.wantErr)
+			_, ok := store.Indexed(pairA)
+			require.False(t, ok)
+		})
+	}
+}
+
+func TestClient_GetPairReservesAt(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := openStore(t, filepath.Join(t.TempDir(), "store.ndjson"))
+	require.NoError(t, store.Append(Batch{
+		Pairs:    []common.Address{pairA},
+		To:       200,
+		Reserves: map[common.Address][]Reserves{pairA: {reserves(150, 1100, 1900)}},
+	}))
+
+	fromRPC := &uniswapdto.PairReserves{Reserve0: big.NewInt(1), Reserve1: big.NewInt(2)}
+	mockClient := mock.NewMockClient(ctrl)
+	mockClient.EXPECT().GetPairReservesAt(gomock.Any(), pairA, big.NewInt(201)).Return(fromRPC, nil)
+	mockClient.EXPECT().GetPairReservesAt(gomock.Any(), pairA, nil).Return(fromRPC, nil)
+	mockClient.EXPECT().GetPairReservesAt(gomock.Any(), pairB, big.NewInt(180)).Return(fromRPC, nil)
+
+	client := NewClient("ethereum", mockClient, New("ethereum", mockClient, store, Config{Pools: []common.Address{pairA}}))
+
+	got, err := client.GetPairReservesAt(context.Background(), pairA, big.NewInt(180))
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(1100), got.Reserve0)
+
+	for _, call := range []struct {
+		pair  common.Address
+		block *big.Int
+	}{
+		{pair: pairA, block: big.NewInt(201)},
+		{pair: pairA},
+		{pair: pairB, block: big.NewInt(180)},
+	} {
+		got, err := client.GetPairReservesAt(context.Background(), call.pair, call.block)
+		require.NoError(t, err)
+		require.Equal(t, fromRPC, got)
+	}
+}
@@ -0,0 +1,340 @@
+package indexer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+)
+
+// Record kinds of the store file.
+const (
+	kindReserves   = "reserves"
+	kindSwap       = "swap"
+	kindCheckpoint = "checkpoint"
+)
+
+// Reserves are the reserves of a pair after a Sync log.
+type Reserves struct {
+	BlockNumber uint64
+	LogIndex    uint
+	// Timestamp is the timestamp of the block.
+	Timestamp uint64
+	Reserve0  *big.Int
+	Reserve1  *big.Int
+}
+
+// Swap is a Swap log of a pair.
+type Swap struct {
+	BlockNumber uint64
+	LogIndex    uint
+	Sender      common.Address
+	To          common.Address
+	Amount0In   *big.Int
+	Amount1In   *big.Int
+	Amount0Out  *big.Int
+	Amount1Out  *big.Int
+}
+
+// Batch is the logs of some pairs up to block To, committed at once.
+type Batch struct {
+	Pairs    []common.Address
+	To       uint64
+	Reserves map[common.Address][]Reserves
+	Swaps    map[common.Address][]Swap
+}
+
+// record is a line of the store file. A checkpoint line commits the lines
+// before it, back to the previous checkpoint.
+type record struct {
+	Kind      string           `json:"kind"`
+	Pair      *common.Address  `json:"pair,omitempty"`
+	Pairs     []common.Address `json:"pairs,omitempty"`
+	Block     uint64           `json:"block"`
+	LogIndex  uint             `json:"log_index,omitempty"`
+	Timestamp uint64           `json:"timestamp,omitempty"`
+	Reserve0  *big.Int         `json:"reserve0,omitempty"`
+	Reserve1  *big.Int         `json:"reserve1,omitempty"`
+
+	Sender     *common.Address `json:"sender,omitempty"`
+	To         *common.Address `json:"to,omitempty"`
+	Amount0In  *big.Int        `json:"amount0_in,omitempty"`
+	Amount1In  *big.Int        `json:"amount1_in,omitempty"`
+	Amount0Out *big.Int        `json:"amount0_out,omitempty"`
+	Amount1Out *big.Int        `json:"amount1_out,omitempty"`
+}
+
+// Store keeps the indexed logs of pairs in an append-only file of JSON lines,
+// and in memory for lookups. Writes are committed by a checkpoint line, and
+// uncommitted lines left by a crash are dropped when the store is opened.
+type Store struct {
+	mu   sync.RWMutex
+	file *os.File
+	// size is the committed length of the file.
+	size int64
+
+	// indexed is the last block indexed for each pair.
+	indexed  map[common.Address]uint64
+	reserves map[common.Address][]Reserves
+	swaps    map[common.Address][]Swap
+}
+
+// OpenStore opens the store at path, creating it if missing.
+func OpenStore(path string) (*Store, error) {
+	//nolint:gosec
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, errors.Wrap(err, "os.OpenFile")
+	}
+
+	s := &Store{
+		file:     file,
+		indexed:  make(map[common.Address]uint64),
+		reserves: make(map[common.Address][]Reserves),
+		swaps:    make(map[common.Address][]Swap),
+	}
+
+	if err := s.load(); err != nil {
+		_ = file.Close()
+		return nil, errors.Wrap(err, "s.load")
+	}
+
+	return s, nil
+}
+
+// load replays the committed records of the file and truncates the rest.
+func (s *Store) load() error {
+	var committed int64
+	var pending []record
+
+	r := bufio.NewReader(s.file)
+	var offset int64
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// A line without a newline was cut short by a crash.
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "r.ReadBytes")
+		}
+		offset += int64(len(line))
+
+		var rec record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return errors.Wrapf(err, "line at offset %d", offset-int64(len(line)))
+		}
+
+		if rec.Kind != kindCheckpoint {
+			pending = append(pending, rec)
+			continue
+		}
+		if err := s.apply(pending, rec); err != nil {
+			return errors.Wrapf(err, "checkpoint at offset %d", offset-int64(len(line)))
+		}
+		pending = pending[:0]
+		committed = offset
+	}
+
+	size, err := s.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return errors.Wrap(err, "s.file.Seek")
+	}
+	if size > committed {
+		log.Printf("indexer store %s: dropping %d uncommitted bytes", s.file.Name(), size-committed)
+		if err := s.file.Truncate(committed); err != nil {
+			return errors.Wrap(err, "s.file.Truncate")
+		}
+		if _, err := s.file.Seek(committed, io.SeekStart); err != nil {
+			return errors.Wrap(err, "s.file.Seek")
+		}
+	}
+	s.size = committed
+
+	return nil
+}
+
+// apply adds committed records to memory.
+func (s *Store) apply(recs []record, checkpoint record) error {
+	for _, rec := range recs {
+		if rec.Pair == nil {
+			return errors.Errorf("%s record without a pair", rec.Kind)
+		}
+		pair := *rec.Pair
+
+		switch rec.Kind {
+		case kindReserves:
+			s.reserves[pair] = append(s.reserves[pair], Reserves{
+				BlockNumber: rec.Block,
+				LogIndex:    rec.LogIndex,
+				Timestamp:   rec.Timestamp,
+				Reserve0:    rec.Reserve0,
+				Reserve1:    rec.Reserve1,
+			})
+		case kindSwap:
+			swap := Swap{
+				BlockNumber: rec.Block,
+				LogIndex:    rec.LogIndex,
+				Amount0In:   rec.Amount0In,
+				Amount1In:   rec.Amount1In,
+				Amount0Out:  rec.Amount0Out,
+				Amount1Out:  rec.Amount1Out,
+			}
+			if rec.Sender != nil {
+				swap.Sender = *rec.Sender
+			}
+			if rec.To != nil {
+				swap.To = *rec.To
+			}
+			s.swaps[pair] = append(s.swaps[pair], swap)
+		default:
+			return errors.Errorf("unknown record kind %q", rec.Kind)
+		}
+	}
+
+	for _, pair := range checkpoint.Pairs {
+		s.indexed[pair] = checkpoint.Block
+	}
+	return nil
+}
+
+// Close closes the store file.
+func (s *Store) Close() error {
+	return s.file.Close()
+}
+
+// Indexed returns the last block indexed for a pair.
+func (s *Store) Indexed(pair common.Address) (uint64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n, ok := s.indexed[pair]
+	return n, ok
+}
+
+// Append commits a batch. Its logs must follow the pairs' last indexed blocks
+// and be in chain order.
+func (s *Store) Append(batch Batch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, pair := range batch.Pairs {
+		if n, ok := s.indexed[pair]; ok && n >= batch.To {
+			return errors.Errorf("pair %s is indexed up to block %d, not before %d", pair.Hex(), n, batch.To)
+		}
+	}
+
+	var recs []record
+	for _, pair := range batch.Pairs {
+		for _, r := range batch.Reserves[pair] {
+			recs = append(recs, record{
+				Kind:      kindReserves,
+				Pair:      &pair,
+				Block:     r.BlockNumber,
+				LogIndex:  r.LogIndex,
+				Timestamp: r.Timestamp,
+				Reserve0:  r.Reserve0,
+				Reserve1:  r.Reserve1,
+			})
+		}
+		for _, swap := range batch.Swaps[pair] {
+			recs = append(recs, record{
+				Kind:       kindSwap,
+				Pair:       &pair,
+				Block:      swap.BlockNumber,
+				LogIndex:   swap.LogIndex,
+				Sender:     &swap.Sender,
+				To:         &swap.To,
+				Amount0In:  swap.Amount0In,
+				Amount1In:  swap.Amount1In,
+				Amount0Out: swap.Amount0Out,
+				Amount1Out: swap.Amount1Out,
+			})
+		}
+	}
+	checkpoint := record{Kind: kindCheckpoint, Pairs: batch.Pairs, Block: batch.To}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, rec := range append(recs, checkpoint) {
+		if err := enc.Encode(rec); err != nil {
+			return errors.Wrap(err, "enc.Encode")
+		}
+	}
+
+	if err := s.write(buf.Bytes()); err != nil {
+		return errors.Wrap(err, "s.write")
+	}
+
+	return s.apply(recs, checkpoint)
+}
+
+// write appends data to the file and syncs it, cutting the file back to its
+// committed length on failure so that no partial line is left behind.
+func (s *Store) write(data []byte) error {
+	_, err := s.file.Write(data)
+	if err == nil {
+		err = s.file.Sync()
+	}
+	if err != nil {
+		if terr := s.file.Truncate(s.size); terr != nil {
+			log.Printf("indexer store %s: s.file.Truncate: %v", s.file.Name(), terr)
+		} else if _, serr := s.file.Seek(s.size, io.SeekStart); serr != nil {
+			log.Printf("indexer store %s: s.file.Seek: %v", s.file.Name(), serr)
+		}
+		return err
+	}
+
+	s.size += int64(len(data))
+	return nil
+}
+
+// ReservesAt returns the reserves of a pair at the end of a block, as
+// getReserves would at that block. It fails for blocks past the pair's last
+// indexed block or before its first indexed Sync.
+func (s *Store) ReservesAt(pair common.Address, block uint64) (*uniswapdto.PairReserves, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if n, ok := s.indexed[pair]; !ok || block > n {
+		return nil, false
+	}
+
+	history := s.reserves[pair]
+	i := sort.Search(len(history), func(i int) bool { return history[i].BlockNumber > block })
+	if i == 0 {
+		return nil, false
+	}
+
+	// getReserves keeps the timestamp modulo 2^32.
+	r := history[i-1]
+	return &uniswapdto.PairReserves{
+		Reserve0:           new(big.Int).Set(r.Reserve0),
+		Reserve1:           new(big.Int).Set(r.Reserve1),
+		BlockTimestampLast: uint32(r.Timestamp), //nolint:gosec
+	}, true
+}
+
+// Swaps returns the Swap logs of a pair from block from to block to, inclusive.
+func (s *Store) Swaps(pair common.Address, from, to uint64) []Swap {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	swaps := s.swaps[pair]
+	i := sort.Search(len(swaps), func(i int) bool { return swaps[i].BlockNumber >= from })
+	j := sort.Search(len(swaps), func(i int) bool { return swaps[i].BlockNumber > to })
+	if i >= j {
+		return nil
+	}
+	return append([]Swap(nil), swaps[i:j]...)
+}
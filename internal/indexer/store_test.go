@@ -0,0 +1,181 @@
+package indexer
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+)
+
+var (
+	pairA = common.HexToAddress("0xa")
+	pairB = common.HexToAddress("0xb")
+)
+
+func reserves(block uint64, r0, r1 int64) Reserves {
+	return Reserves{
+		BlockNumber: block,
+		Timestamp:   1_700_000_000 + block,
+		Reserve0:    big.NewInt(r0),
+		Reserve1:    big.NewInt(r1),
+	}
+}
+
+func openStore(t *testing.T, path string) *Store {
+	t.Helper()
+
+	store, err := OpenStore(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestStore_ReservesAt(t *testing.T) {
+	t.Parallel()
+
+	store := openStore(t, filepath.Join(t.TempDir(), "store.ndjson"))
+	require.NoError(t, store.Append(Batch{
+		Pairs: []common.Address{pairA, pairB},
+		To:    200,
+		Reserves: map[common.Address][]Reserves{
+			pairA: {reserves(110, 1000, 2000), reserves(150, 1100, 1900)},
+		},
+	}))
+
+	tests := []struct {
+		name  string
+		pair  common.Address
+		block uint64
+		want  *uniswapdto.PairReserves
+	}{
+		{name: "before the first sync", pair: pairA, block: 109},
+		{
+			name:  "at a sync",
+			pair:  pairA,
+			block: 110,
+			want:  &uniswapdto.PairReserves{Reserve0: big.NewInt(1000), Reserve1: big.NewInt(2000), BlockTimestampLast: 1_700_000_110},
+		},
+		{
+			name:  "between syncs",
+			pair:  pairA,
+			block: 149,
+			want:  &uniswapdto.PairReserves{Reserve0: big.NewInt(1000), Reserve1: big.NewInt(2000), BlockTimestampLast: 1_700_000_110},
+		},
+		{
+			name:  "at the last indexed block",
+			pair:  pairA,
+			block: 200,
+			want:  &uniswapdto.PairReserves{Reserve0: big.NewInt(1100), Reserve1: big.NewInt(1900), BlockTimestampLast: 1_700_000_150},
+		},
+		{name: "past the last indexed block", pair: pairA, block: 201},
+		{name: "pair without syncs", pair: pairB, block: 150},
+		{name: "unknown pair", pair: common.HexToAddress("0xc"), block: 150},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := store.ReservesAt(tt.pair, tt.block)
+			require.Equal(t, tt.want != nil, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStore_Reopen(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "store.ndjson")
+	swap := Swap{
+		BlockNumber: 150,
+		LogIndex:    3,
+		Sender:      common.HexToAddress("0x5"),
+		To:          common.HexToAddress("0x6"),
+		Amount0In:   big.NewInt(100),
+		Amount1In:   big.NewInt(0),
+		Amount0Out:  big.NewInt(0),
+		Amount1Out:  big.NewInt(90),
+	}
+
+	store, err := OpenStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.Append(Batch{
+		Pairs:    []common.Address{pairA},
+		To:       200,
+		Reserves: map[common.Address][]Reserves{pairA: {reserves(150, 1100, 1900)}},
+		Swaps:    map[common.Address][]Swap{pairA: {swap}},
+	}))
+	require.NoError(t, store.Append(Batch{Pairs: []common.Address{pairA, pairB}, To: 300}))
+	require.ErrorContains(t, store.Append(Batch{Pairs: []common.Address{pairB}, To: 300}), "is indexed up to block 300")
+	require.NoError(t, store.Close())
+
+	store = openStore(t, path)
+
+	n, ok := store.Indexed(pairA)
+	require.True(t, ok)
+	require.Equal(t, uint64(300), n)
+	n, ok = store.Indexed(pairB)
+	require.True(t, ok)
+	require.Equal(t, uint64(300), n)
+
+	got, ok := store.ReservesAt(pairA, 250)
+	require.True(t, ok)
+	require.Equal(t, big.NewInt(1100), got.Reserve0)
+	require.Equal(t, []Swap{swap}, store.Swaps(pairA, 100, 200))
+	require.Empty(t, store.Swaps(pairA, 151, 300))
+}
+
+func TestStore_DropsUncommitted(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "store.ndjson")
+
+	store, err := OpenStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.Append(Batch{
+		Pairs:    []common.Address{pairA},
+		To:       200,
+		Reserves: map[common.Address][]Reserves{pairA: {reserves(150, 1100, 1900)}},
+	}))
+	require.NoError(t, store.Close())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	// A crash left a whole record without its checkpoint and a torn line.
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"kind":"reserves","pair":"0x000000000000000000000000000000000000000a","block":250,"reserve0":1,"reserve1":1}` +
+		"\n" + `{"kind":"checkp`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	store = openStore(t, path)
+
+	got, ok := store.ReservesAt(pairA, 200)
+	require.True(t, ok)
+	require.Equal(t, big.NewInt(1100), got.Reserve0)
+
+	truncated, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, info.Size(), truncated.Size())
+
+	// Appends resume from the committed end.
+	require.NoError(t, store.Append(Batch{
+		Pairs:    []common.Address{pairA},
+		To:       300,
+		Reserves: map[common.Address][]Reserves{pairA: {reserves(250, 1200, 1800)}},
+	}))
+	require.NoError(t, store.Close())
+
+	store = openStore(t, path)
+	got, ok = store.ReservesAt(pairA, 300)
+	require.True(t, ok)
+	require.Equal(t, big.NewInt(1200), got.Reserve0)
+}
@@ -0,0 +1,235 @@
+package uniswap
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+)
+
+const balancerPoolABIJSON = `[
+	{"inputs":[],"name":"getPoolId","outputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"getVault","outputs":[{"internalType":"contract IVault","name":"","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"getNormalizedWeights","outputs":[{"internalType":"uint256[]","name":"","type":"uint256[]"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"getSwapFeePercentage","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"version","outputs":[{"internalType":"string","name":"","type":"string"}],"stateMutability":"view","type":"function"}
+]`
+
+const balancerVaultABIJSON = `[
+	{"inputs":[{"internalType":"bytes32","name":"poolId","type":"bytes32"}],"name":"getPoolTokens","outputs":[{"internalType":"contract IERC20[]","name":"tokens","type":"address[]"},{"internalType":"uint256[]","name":"balances","type":"uint256[]"},{"internalType":"uint256","name":"lastChangeBlock","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"internalType":"enum IVault.SwapKind","name":"kind","type":"uint8"},{"components":[{"internalType":"bytes32","name":"poolId","type":"bytes32"},{"internalType":"uint256","name":"assetInIndex","type":"uint256"},{"internalType":"uint256","name":"assetOutIndex","type":"uint256"},{"internalType":"uint256","name":"amount","type":"uint256"},{"internalType":"bytes","name":"userData","type":"bytes"}],"internalType":"struct IVault.BatchSwapStep[]","name":"swaps","type":"tuple[]"},{"internalType":"contract IAsset[]","name":"assets","type":"address[]"},{"components":[{"internalType":"address","name":"sender","type":"address"},{"internalType":"bool","name":"fromInternalBalance","type":"bool"},{"internalType":"address payable","name":"recipient","type":"address"},{"internalType":"bool","name":"toInternalBalance","type":"bool"}],"internalType":"struct IVault.FundManagement","name":"funds","type":"tuple"}],"name":"queryBatchSwap","outputs":[{"internalType":"int256[]","name":"","type":"int256[]"}],"stateMutability":"nonpayable","type":"function"}
+]`
+
+const balancerFactoryABIJSON = `[
+	{"inputs":[{"internalType":"address","name":"pool","type":"address"}],"name":"isPoolFromFactory","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"view","type":"function"}
+]`
+
+var (
+	balancerPoolABI    = mustParseABI(balancerPoolABIJSON)
+	balancerVaultABI   = mustParseABI(balancerVaultABIJSON)
+	balancerFactoryABI = mustParseABI(balancerFactoryABIJSON)
+)
+
+// balancerBatchSwapStep and balancerFundManagement are the Vault's
+// BatchSwapStep and FundManagement structs.
+type balancerBatchSwapStep struct {
+	PoolId        [32]byte //nolint:revive,stylecheck
+	AssetInIndex  *big.Int
+	AssetOutIndex *big.Int
+	Amount        *big.Int
+	UserData      []byte
+}
+
+type balancerFundManagement struct {
+	Sender              common.Address
+	FromInternalBalance bool
+	Recipient           common.Address
+	ToInternalBalance   bool
+}
+
+// IsBalancerWeightedPool reports whether a contract answers getNormalizedWeights(),
+// as Balancer weighted pools do. Contracts that revert or return no weights are
+// not weighted pools; other errors are returned, as the call may have failed transiently.
+func (c *ethClientImpl) IsBalancerWeightedPool(ctx context.Context, pool common.Address) (bool, error) {
+	out, ok, err := c.probe(ctx, balancerPoolABI, pool, nil, "getNormalizedWeights")
+	if err != nil || !ok || len(out) == 0 {
+		return false, err
+	}
+
+	weights, ok := out[0].([]*big.Int)
+	return ok && len(weights) > 1, nil
+}
+
+// GetBalancerWeightedPoolState reads the tokens and balances of a Balancer
+// weighted pool from its Vault, and its weights and swap fee, at the given
+// block, which must be set so that every read sees the same block.
+func (c *ethClientImpl) GetBalancerWeightedPoolState(
+	ctx context.Context,
+	pool common.Address,
+	blockNumber *big.Int,
+) (*dto.BalancerWeightedPoolState, error) {
+	if blockNumber == nil {
+		return nil, errors.New("block number is required")
+	}
+
+	ctxCall, cancel := context.WithTimeout(ctx, time.Duration(c.callTimeout.Load()))
+	defer cancel()
+
+	state := &dto.BalancerWeightedPoolState{BlockNumber: blockNumber.Uint64()}
+
+	read := func(method string, decode func(out []interface{}) error) func() error {
+		return func() error {
+			out, err := c.callABI(ctxCall, balancerPoolABI, pool, blockNumber, method)
+			if err != nil {
+				return errors.Wrapf(err, "failed to call %s", method)
+			}
+			if len(out) == 0 {
+				return errors.Errorf("empty %s output", method)
+			}
+			return decode(out)
+		}
+	}
+
+	reads := []func() error{
+		read("getPoolId", func(out []interface{}) error {
+			id, ok := out[0].([32]byte)
+			if !ok {
+				return errors.New("failed to cast getPoolId output")
+			}
+			state.PoolID = id
+			return nil
+		}),
+		read("getVault", func(out []interface{}) error {
+			vault, ok := out[0].(common.Address)
+			if !ok {
+				return errors.New("failed to cast getVault output")
+			}
+			state.Vault = vault
+			return nil
+		}),
+		read("getNormalizedWeights", func(out []interface{}) error {
+			weights, ok := out[0].([]*big.Int)
+			if !ok {
+				return errors.New("failed to cast getNormalizedWeights output")
+			}
+			state.Weights = weights
+			return nil
+		}),
+		read("getSwapFeePercentage", func(out []interface{}) (err error) {
+			state.SwapFee, err = firstBigInt(out, "getSwapFeePercentage")
+			return err
+		}),
+		func() error {
+			_, ok, err := c.probe(ctxCall, balancerPoolABI, pool, blockNumber, "version")
+			if err != nil {
+				return errors.Wrap(err, "failed to call version")
+			}
+			state.ExactPow = ok
+			return nil
+		},
+	}
+
+	// Every read sets its own fields of state and its own error.
+	if err := c.forEach(len(reads), func(i int) error { return reads[i]() }); err != nil {
+		return nil, errors.Wrap(err, "failed to get balancer pool")
+	}
+
+	out, err := c.callABI(ctxCall, balancerVaultABI, state.Vault, blockNumber, "getPoolTokens", state.PoolID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to call getPoolTokens")
+	}
+	if len(out) < 2 {
+		return nil, errors.Errorf("insufficient outputs from getPoolTokens call: expected 2, got %d", len(out))
+	}
+	var ok0, ok1 bool
+	state.Tokens, ok0 = out[0].([]common.Address)
+	state.Balances, ok1 = out[1].([]*big.Int)
+	if !ok0 || !ok1 {
+		return nil, errors.New("failed to cast getPoolTokens outputs")
+	}
+	if len(state.Tokens) != len(state.Weights) || len(state.Balances) != len(state.Weights) {
+		return nil, errors.Errorf("pool has %d weights for %d tokens", len(state.Weights), len(state.Tokens))
+	}
+
+	state.ScalingFactors = make([]*big.Int, len(state.Tokens))
+	if err := c.forEach(len(state.Tokens), func(i int) error {
+		token := state.Tokens[i]
+		out, err := c.callABI(ctxCall, erc20ABI, token, blockNumber, "decimals")
+		if err != nil {
+			return errors.Wrapf(err, "failed to call decimals of %s", token.Hex())
+		}
+		if len(out) == 0 {
+			return errors.New("empty decimals output")
+		}
+		decimals, ok := out[0].(uint8)
+		if !ok || decimals > 18 {
+			return errors.Errorf("bad decimals of %s", token.Hex())
+		}
+		state.ScalingFactors[i] = new(big.Int).Exp(big.NewInt(10), big.NewInt(18-int64(decimals)), nil)
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to get token decimals")
+	}
+
+	return state, nil
+}
+
+// GetBalancerAmountOut quotes selling amountIn of tokenIn for tokenOut in a
+// Balancer pool with the Vault's queryBatchSwap at the given block, or the
+// latest one if nil.
+func (c *ethClientImpl) GetBalancerAmountOut(
+	ctx context.Context,
+	vault common.Address,
+	poolID [32]byte,
+	tokenIn, tokenOut common.Address,
+	amountIn *big.Int,
+	blockNumber *big.Int,
+) (*big.Int, error) {
+	swaps := []balancerBatchSwapStep{{
+		PoolId:        poolID,
+		AssetInIndex:  big.NewInt(0),
+		AssetOutIndex: big.NewInt(1),
+		Amount:        amountIn,
+		UserData:      []byte{},
+	}}
+	funds := balancerFundManagement{}
+
+	// SwapKind.GIVEN_IN is 0.
+	out, err := c.callABI(ctx, balancerVaultABI, vault, blockNumber, "queryBatchSwap",
+		uint8(0), swaps, []common.Address{tokenIn, tokenOut}, funds)
+	if err != nil {
+		return nil, errors.Wrap(err, "c.callABI")
+	}
+
+	if len(out) == 0 {
+		return nil, errors.New("empty queryBatchSwap output")
+	}
+	deltas, ok := out[0].([]*big.Int)
+	if !ok || len(deltas) != 2 {
+		return nil, errors.New("failed to cast queryBatchSwap output")
+	}
+
+	// The Vault's delta of the token out is what it pays, negative.
+	return new(big.Int).Neg(deltas[1]), nil
+}
+
+// IsBalancerPoolFromFactory asks a Balancer pool factory whether it deployed a pool.
+func (c *ethClientImpl) IsBalancerPoolFromFactory(ctx context.Context, factory, pool common.Address) (bool, error) {
+	out, err := c.callABI(ctx, balancerFactoryABI, factory, nil, "isPoolFromFactory", pool)
+	if err != nil {
+		return false, errors.Wrap(err, "c.callABI")
+	}
+
+	if len(out) == 0 {
+		return false, errors.New("empty isPoolFromFactory output")
+	}
+	deployed, ok := out[0].(bool)
+	if !ok {
+		return false, errors.New("failed to cast isPoolFromFactory output to bool")
+	}
+	return deployed, nil
+}
@@ -0,0 +1,183 @@
+package uniswap
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+)
+
+// balancerCall is a call to a fake Balancer pool, Vault or token.
+type balancerCall struct {
+	to     common.Address
+	method string
+	args   []interface{}
+}
+
+// balancerContracts answers calls from answer, which returns nil outputs for
+// methods that revert.
+func balancerContracts(t *testing.T, answer func(call balancerCall) []interface{}) func(
+	context.Context, ethereum.CallMsg, *big.Int,
+) ([]byte, error) {
+	return func(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+		for _, contractABI := range []abi.ABI{balancerPoolABI, balancerVaultABI, erc20ABI} {
+			method, err := contractABI.MethodById(msg.Data[:4])
+			if err != nil {
+				continue
+			}
+			args, err := method.Inputs.Unpack(msg.Data[4:])
+			require.NoError(t, err)
+
+			out := answer(balancerCall{to: *msg.To, method: method.Name, args: args})
+			if out == nil {
+				return nil, revertError{}
+			}
+			return method.Outputs.Pack(out...)
+		}
+		t.Fatalf("unexpected call data %x", msg.Data)
+		return nil, nil
+	}
+}
+
+func TestGetBalancerWeightedPoolState(t *testing.T) {
+	t.Parallel()
+
+	pool := common.HexToAddress("0x1234")
+	vault := common.HexToAddress("0xba12")
+	bal := common.HexToAddress("0x5678")
+	usdc := common.HexToAddress("0x9abc")
+	poolID := [32]byte{0x12, 0x34}
+	block := big.NewInt(19_000_000)
+
+	answer := func(version bool) func(call balancerCall) []interface{} {
+		return func(call balancerCall) []interface{} {
+			switch call.method {
+			case "getPoolId":
+				return []interface{}{poolID}
+			case "getVault":
+				return []interface{}{vault}
+			case "getNormalizedWeights":
+				return []interface{}{[]*big.Int{big.NewInt(8e17), big.NewInt(2e17)}}
+			case "getSwapFeePercentage":
+				return []interface{}{big.NewInt(1e16)}
+			case "version":
+				if version {
+					return []interface{}{`{"name":"WeightedPool","version":4}`}
+				}
+			case "getPoolTokens":
+				require.Equal(t, vault, call.to)
+				require.Equal(t, poolID, call.args[0])
+				return []interface{}{
+					[]common.Address{bal, usdc},
+					[]*big.Int{big.NewInt(1000), big.NewInt(2000)},
+					big.NewInt(18_999_999),
+				}
+			case "decimals":
+				if call.to == usdc {
+					return []interface{}{uint8(6)}
+				}
+				return []interface{}{uint8(18)}
+			}
+			return nil
+		}
+	}
+
+	for _, version := range []bool{false, true} {
+		ctrl := gomock.NewController(t)
+
+		mockCaller := mock.NewMockEthCaller(ctrl)
+		client, err := newClientWithCaller(mockCaller, timeout)
+		require.NoError(t, err)
+
+		mockCaller.EXPECT().
+			CallContract(gomock.Any(), gomock.Any(), block).
+			DoAndReturn(balancerContracts(t, answer(version))).
+			AnyTimes()
+
+		got, err := client.GetBalancerWeightedPoolState(context.Background(), pool, block)
+		require.NoError(t, err)
+		require.Equal(t, &dto.BalancerWeightedPoolState{
+			BlockNumber:    block.Uint64(),
+			PoolID:         poolID,
+			Vault:          vault,
+			Tokens:         []common.Address{bal, usdc},
+			Balances:       []*big.Int{big.NewInt(1000), big.NewInt(2000)},
+			ScalingFactors: []*big.Int{big.NewInt(1), big.NewInt(1e12)},
+			Weights:        []*big.Int{big.NewInt(8e17), big.NewInt(2e17)},
+			SwapFee:        big.NewInt(1e16),
+			ExactPow:       version,
+		}, got)
+
+		ctrl.Finish()
+	}
+}
+
+func TestGetBalancerAmountOut(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	vault := common.HexToAddress("0xba12")
+	bal := common.HexToAddress("0x5678")
+	usdc := common.HexToAddress("0x9abc")
+	poolID := [32]byte{0x12, 0x34}
+
+	mockCaller := mock.NewMockEthCaller(ctrl)
+	client, err := newClientWithCaller(mockCaller, timeout)
+	require.NoError(t, err)
+
+	mockCaller.EXPECT().
+		CallContract(gomock.Any(), gomock.Any(), nil).
+		DoAndReturn(balancerContracts(t, func(call balancerCall) []interface{} {
+			require.Equal(t, "queryBatchSwap", call.method)
+			require.Equal(t, vault, call.to)
+			require.Equal(t, uint8(0), call.args[0])
+			require.Equal(t, []common.Address{bal, usdc}, call.args[2])
+			return []interface{}{[]*big.Int{big.NewInt(1000), big.NewInt(-1979)}}
+		}))
+
+	got, err := client.GetBalancerAmountOut(context.Background(), vault, poolID, bal, usdc, big.NewInt(1000), nil)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(1979), got)
+}
+
+func TestIsBalancerWeightedPool(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pool := common.HexToAddress("0x1234")
+	pair := common.HexToAddress("0x5678")
+
+	mockCaller := mock.NewMockEthCaller(ctrl)
+	client, err := newClientWithCaller(mockCaller, timeout)
+	require.NoError(t, err)
+
+	mockCaller.EXPECT().
+		CallContract(gomock.Any(), gomock.Any(), nil).
+		DoAndReturn(balancerContracts(t, func(call balancerCall) []interface{} {
+			if call.to == pool && call.method == "getNormalizedWeights" {
+				return []interface{}{[]*big.Int{big.NewInt(5e17), big.NewInt(5e17)}}
+			}
+			return nil
+		})).
+		Times(2)
+
+	got, err := client.IsBalancerWeightedPool(context.Background(), pool)
+	require.NoError(t, err)
+	require.True(t, got)
+
+	got, err = client.IsBalancerWeightedPool(context.Background(), pair)
+	require.NoError(t, err)
+	require.False(t, got)
+}
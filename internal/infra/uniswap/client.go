@@ -5,47 +5,230 @@ import (
 	"math/big"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/pkg/errors"
 	"go.uber.org/multierr"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
 )
 
 const pairABIJSON = `[
 	{"inputs":[],"name":"token0","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},
 	{"inputs":[],"name":"token1","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},
-	{"inputs":[],"name":"getReserves","outputs":[{"internalType":"uint112","name":"_reserve0","type":"uint112"},{"internalType":"uint112","name":"_reserve1","type":"uint112"},{"internalType":"uint32","name":"_blockTimestampLast","type":"uint32"}],"stateMutability":"view","type":"function"}
+	{"inputs":[],"name":"getReserves","outputs":[{"internalType":"uint112","name":"_reserve0","type":"uint112"},{"internalType":"uint112","name":"_reserve1","type":"uint112"},{"internalType":"uint32","name":"_blockTimestampLast","type":"uint32"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"factory","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"totalSupply","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"price0CumulativeLast","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"price1CumulativeLast","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"kLast","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}
+]`
+
+const routerABIJSON = `[
+	{"inputs":[{"internalType":"uint256","name":"amountIn","type":"uint256"},{"internalType":"uint256","name":"amountOutMin","type":"uint256"},{"internalType":"address[]","name":"path","type":"address[]"},{"internalType":"address","name":"to","type":"address"},{"internalType":"uint256","name":"deadline","type":"uint256"}],"name":"swapExactTokensForTokensSupportingFeeOnTransferTokens","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"internalType":"uint256","name":"amountIn","type":"uint256"},{"internalType":"address[]","name":"path","type":"address[]"}],"name":"getAmountsOut","outputs":[{"internalType":"uint256[]","name":"amounts","type":"uint256[]"}],"stateMutability":"view","type":"function"}
+]`
+
+const factoryABIJSON = `[
+	{"inputs":[{"internalType":"address","name":"","type":"address"},{"internalType":"address","name":"","type":"address"}],"name":"getPair","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"feeTo","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"}
 ]`
 
-// Client defines an abstraction for reading Uniswap V2 pair data from the Ethereum blockchain.
+// Client defines an abstraction for reading Uniswap V2 pair, V3 pool, Solidly pair, Curve pool
+// and Balancer weighted pool data from the Ethereum blockchain. Its reads are grouped by
+// backend, so that code reading one kind of pool depends only on that kind's reads.
 type Client interface {
+	ChainReader
+	V2Reader
+	V3Reader
+	SolidlyReader
+	CurveReader
+	BalancerReader
+	Simulator
+}
+
+// ChainReader reads blocks and contract code.
+type ChainReader interface {
+	// BlockNumber returns the number of the latest block.
+	BlockNumber(ctx context.Context) (uint64, error)
+	// BlockTimestamp returns the timestamp of the given block, or the latest one if nil.
+	BlockTimestamp(ctx context.Context, blockNumber *big.Int) (uint64, error)
+	// GetBlockHeader returns the hash, parent hash and timestamp of the given block,
+	// or the latest one if nil.
+	GetBlockHeader(ctx context.Context, blockNumber *big.Int) (*dto.BlockHeader, error)
+	// GetCode returns the runtime bytecode of a contract at the latest block,
+	// that of the implementation for EIP-1167 minimal proxies.
+	GetCode(ctx context.Context, contract common.Address) ([]byte, error)
+}
+
+// V2Reader reads Uniswap V2 pairs, factories and routers.
+type V2Reader interface {
 	// GetPairTokens returns the addresses of token0 and token1 for a given pair contract.
 	GetPairTokens(ctx context.Context, pair common.Address) (common.Address, common.Address, error)
-	// GetPairReserves returns the current reserves of token0 and token1 for a given pair contract.
-	GetPairReserves(ctx context.Context, pair common.Address) (*big.Int, *big.Int, error)
+	// GetPairReserves returns the current reserves of token0 and token1 for a given pair contract
+	// and the timestamp of their last update.
+	GetPairReserves(ctx context.Context, pair common.Address) (*dto.PairReserves, error)
+	// GetPairReservesAt returns the reserves of a pair and the timestamp of their last update
+	// at the given block, or the latest one if nil.
+	GetPairReservesAt(ctx context.Context, pair common.Address, blockNumber *big.Int) (*dto.PairReserves, error)
+	// GetPairFactory returns the factory that a pair contract reports as its deployer.
+	GetPairFactory(ctx context.Context, pair common.Address) (common.Address, error)
+	// GetFactoryPair returns the pair registered by a factory for two tokens, or the zero address.
+	GetFactoryPair(ctx context.Context, factory, tokenA, tokenB common.Address) (common.Address, error)
+	// GetFactoryFeeTo returns the protocol fee recipient of a factory at the given block,
+	// or the latest one if nil. The zero address means the protocol fee is off.
+	GetFactoryFeeTo(ctx context.Context, factory common.Address, blockNumber *big.Int) (common.Address, error)
+	// GetPairTotalSupply returns the LP token supply of a pair at the given block, or the latest one if nil.
+	GetPairTotalSupply(ctx context.Context, pair common.Address, blockNumber *big.Int) (*big.Int, error)
+	// GetPairKLast returns the reserve product a pair recorded at its last liquidity event
+	// at the given block, or the latest one if nil. It is zero while the protocol fee is off.
+	GetPairKLast(ctx context.Context, pair common.Address, blockNumber *big.Int) (*big.Int, error)
+	// GetPairObservation reads the price oracle state of a pair and the timestamp of the given block.
+	GetPairObservation(ctx context.Context, pair common.Address, blockNumber *big.Int) (*dto.PairObservation, error)
+	// GetAmountsOut calls UniswapV2Router02.getAmountsOut at the given block, or the latest one if nil.
+	GetAmountsOut(
+		ctx context.Context,
+		router common.Address,
+		amountIn *big.Int,
+		path []common.Address,
+		blockNumber *big.Int,
+	) ([]*big.Int, error)
+	// GetPairLogs returns the Sync and Swap logs of Uniswap V2 pairs from block from
+	// to block to, inclusive, in chain order.
+	GetPairLogs(ctx context.Context, pairs []common.Address, from, to uint64) ([]dto.PairLog, error)
+}
+
+// V3Reader reads Uniswap V3 pools and factories.
+type V3Reader interface {
+	// IsV3Pool reports whether a contract is a Uniswap V3 pool.
+	IsV3Pool(ctx context.Context, pool common.Address) (bool, error)
+	// GetV3PoolState reads the price, tick, liquidity, fee and tick spacing of a
+	// Uniswap V3 pool at the given block.
+	GetV3PoolState(ctx context.Context, pool common.Address, blockNumber *big.Int) (*dto.V3PoolState, error)
+	// GetV3Ticks reads the initialized ticks of a Uniswap V3 pool in the tick bitmap
+	// words from minWord to maxWord at the given block.
+	GetV3Ticks(
+		ctx context.Context,
+		pool common.Address,
+		tickSpacing int32,
+		minWord, maxWord int16,
+		blockNumber *big.Int,
+	) ([]dto.V3Tick, error)
+	// GetV3FactoryPool returns the pool registered by a Uniswap V3 factory for two
+	// tokens and a fee, or the zero address.
+	GetV3FactoryPool(ctx context.Context, factory, tokenA, tokenB common.Address, fee uint32) (common.Address, error)
+}
+
+// SolidlyReader reads Solidly style pairs and factories.
+type SolidlyReader interface {
+	// IsSolidlyPair reports whether a contract is a Solidly style pair.
+	IsSolidlyPair(ctx context.Context, pair common.Address) (bool, error)
+	// GetSolidlyMetadata reads the decimals, reserves, curve and tokens of a Solidly style pair
+	// at the given block, or the latest one if nil.
+	GetSolidlyMetadata(ctx context.Context, pair common.Address, blockNumber *big.Int) (*dto.SolidlyMetadata, error)
+	// GetSolidlyAmountOut calls a Solidly style pair's getAmountOut at the given block,
+	// or the latest one if nil.
+	GetSolidlyAmountOut(
+		ctx context.Context,
+		pair common.Address,
+		amountIn *big.Int,
+		tokenIn common.Address,
+		blockNumber *big.Int,
+	) (*big.Int, error)
+	// GetSolidlyFee returns the swap fee in basis points that a Solidly style factory charges
+	// on a pair at the given block, or the latest one if nil.
+	GetSolidlyFee(ctx context.Context, factory, pair common.Address, stable bool, blockNumber *big.Int) (*big.Int, error)
+	// GetSolidlyFactoryPair returns the pair registered by a Solidly style factory for two tokens
+	// and a curve, or the zero address.
+	GetSolidlyFactoryPair(ctx context.Context, factory, tokenA, tokenB common.Address, stable bool) (common.Address, error)
+}
+
+// CurveReader reads Curve StableSwap pools and registries.
+type CurveReader interface {
+	// IsCurvePool reports whether a contract is a Curve StableSwap pool.
+	IsCurvePool(ctx context.Context, pool common.Address) (bool, error)
+	// GetCurvePoolState reads the coins, balances, rates, amplification and fee of a
+	// Curve StableSwap pool at the given block.
+	GetCurvePoolState(ctx context.Context, pool common.Address, blockNumber *big.Int) (*dto.CurvePoolState, error)
+	// GetCurveAmountOut calls a Curve pool's get_dy at the given block, or the latest one if nil.
+	GetCurveAmountOut(ctx context.Context, pool common.Address, i, j int, dx *big.Int, blockNumber *big.Int) (*big.Int, error)
+	// IsCurvePoolRegistered asks a Curve registry whether it lists a pool.
+	IsCurvePoolRegistered(ctx context.Context, registry, pool common.Address) (bool, error)
+}
+
+// BalancerReader reads Balancer weighted pools, pool factories and the Vault.
+type BalancerReader interface {
+	// IsBalancerWeightedPool reports whether a contract is a Balancer weighted pool.
+	IsBalancerWeightedPool(ctx context.Context, pool common.Address) (bool, error)
+	// GetBalancerWeightedPoolState reads the tokens, balances, weights and swap fee of a
+	// Balancer weighted pool at the given block.
+	GetBalancerWeightedPoolState(
+		ctx context.Context,
+		pool common.Address,
+		blockNumber *big.Int,
+	) (*dto.BalancerWeightedPoolState, error)
+	// GetBalancerAmountOut quotes a swap in a Balancer pool with the Vault's queryBatchSwap
+	// at the given block, or the latest one if nil.
+	GetBalancerAmountOut(
+		ctx context.Context,
+		vault common.Address,
+		poolID [32]byte,
+		tokenIn, tokenOut common.Address,
+		amountIn *big.Int,
+		blockNumber *big.Int,
+	) (*big.Int, error)
+	// IsBalancerPoolFromFactory asks a Balancer pool factory whether it deployed a pool.
+	IsBalancerPoolFromFactory(ctx context.Context, factory, pool common.Address) (bool, error)
+}
+
+// Simulator runs transfers and swaps through eth_call with state overrides.
+type Simulator interface {
+	// ProbeTransfer simulates moving amount of token out of a pair and back to measure transfer taxes.
+	ProbeTransfer(ctx context.Context, pair, token common.Address, amount *big.Int) (*dto.TransferProbe, error)
+	// FindBalanceMapping locates the storage mapping holding token balances.
+	FindBalanceMapping(ctx context.Context, token, holder common.Address) (*dto.StorageMapping, error)
+	// FindAllowanceMapping locates the storage mapping holding token allowances.
+	FindAllowanceMapping(ctx context.Context, token, owner, spender common.Address) (*dto.StorageMapping, error)
+	// SimulateSwap runs a router swap from a sender through eth_call with state overrides.
+	SimulateSwap(ctx context.Context, swap *dto.SwapSimulation) (*dto.SwapSimulationResult, error)
 }
 
 // EthCaller represents interface for calling contracts.
 type EthCaller interface {
 	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
 }
 
 type ethClientImpl struct {
-	caller  EthCaller
-	pairABI abi.ABI
-
-	callTimeout time.Duration
+	caller       EthCaller
+	pairABI      abi.ABI
+	factoryABI   abi.ABI
+	routerABI    abi.ABI
+	v3PoolABI    abi.ABI
+	v3FactoryABI abi.ABI
+
+	solidlyPairABI    abi.ABI
+	solidlyFactoryABI abi.ABI
+
+	// callTimeout holds a time.Duration, replaced on Reconfigure.
+	callTimeout atomic.Int64
 }
 
-// NewClient creates a new Uniswap Client backed by an Ethereum RPC connection.
-func NewClient(rpcURL string, callTimeout time.Duration) (Client, error) {
-	caller, err := ethclient.Dial(rpcURL)
+// NewClient creates a new Uniswap Client backed by Ethereum RPC connections.
+// Endpoints are tried in the given order, falling back to the next one on failure.
+func NewClient(rpcURLs []string, callTimeout time.Duration) (Client, error) {
+	caller, err := dialEndpoints(rpcURLs)
 	if err != nil {
-		return nil, errors.Wrap(err, "ethclient.Dial")
+		return nil, errors.Wrap(err, "dialEndpoints")
 	}
 
 	return newClientWithCaller(caller, callTimeout)
@@ -57,18 +240,67 @@ func newClientWithCaller(caller EthCaller, callTimeout time.Duration) (Client, e
 		return nil, errors.Wrap(err, "abi.JSON")
 	}
 
-	return &ethClientImpl{
-		caller:  caller,
-		pairABI: pairABI,
+	factoryABI, err := abi.JSON(strings.NewReader(factoryABIJSON))
+	if err != nil {
+		return nil, errors.Wrap(err, "abi.JSON")
+	}
 
-		callTimeout: callTimeout,
-	}, nil
+	routerABI, err := abi.JSON(strings.NewReader(routerABIJSON))
+	if err != nil {
+		return nil, errors.Wrap(err, "abi.JSON")
+	}
+
+	v3PoolABI, err := abi.JSON(strings.NewReader(v3PoolABIJSON))
+	if err != nil {
+		return nil, errors.Wrap(err, "abi.JSON")
+	}
+
+	v3FactoryABI, err := abi.JSON(strings.NewReader(v3FactoryABIJSON))
+	if err != nil {
+		return nil, errors.Wrap(err, "abi.JSON")
+	}
+
+	solidlyPairABI, err := abi.JSON(strings.NewReader(solidlyPairABIJSON))
+	if err != nil {
+		return nil, errors.Wrap(err, "abi.JSON")
+	}
+
+	solidlyFactoryABI, err := abi.JSON(strings.NewReader(solidlyFactoryABIJSON))
+	if err != nil {
+		return nil, errors.Wrap(err, "abi.JSON")
+	}
+
+	c := &ethClientImpl{
+		caller:       caller,
+		pairABI:      pairABI,
+		factoryABI:   factoryABI,
+		routerABI:    routerABI,
+		v3PoolABI:    v3PoolABI,
+		v3FactoryABI: v3FactoryABI,
+
+		solidlyPairABI:    solidlyPairABI,
+		solidlyFactoryABI: solidlyFactoryABI,
+	}
+	c.callTimeout.Store(int64(callTimeout))
+
+	return c, nil
 }
 
 func (c *ethClientImpl) call(ctx context.Context, to common.Address, method string) ([]interface{}, error) {
-	data, err := c.pairABI.Pack(method)
+	return c.callABI(ctx, c.pairABI, to, nil, method)
+}
+
+func (c *ethClientImpl) callABI(
+	ctx context.Context,
+	contractABI abi.ABI,
+	to common.Address,
+	blockNumber *big.Int,
+	method string,
+	args ...interface{},
+) ([]interface{}, error) {
+	data, err := contractABI.Pack(method, args...)
 	if err != nil {
-		return nil, errors.Wrap(err, "c.pairABI.Pack")
+		return nil, errors.Wrap(err, "contractABI.Pack")
 	}
 
 	res, err := c.caller.CallContract(
@@ -77,20 +309,76 @@ func (c *ethClientImpl) call(ctx context.Context, to common.Address, method stri
 			To:   &to,
 			Data: data,
 		},
-		nil,
+		blockNumber,
 	)
 	if err != nil {
 		return nil, errors.Wrap(err, "c.caller.CallContract")
 	}
 
-	out, err := c.pairABI.Unpack(method, res)
+	out, err := contractABI.Unpack(method, res)
 	if err != nil {
-		return nil, errors.Wrap(err, "c.pairABI.Unpack")
+		return nil, errors.Wrap(err, "contractABI.Unpack")
 	}
 
 	return out, nil
 }
 
+// probe calls a method that only some contracts have at the given block, or
+// the latest one if nil. It reports false when the call reverts or returns
+// nothing, as calls of missing methods do, and returns other errors, as the
+// call may have failed transiently.
+func (c *ethClientImpl) probe(
+	ctx context.Context,
+	contractABI abi.ABI,
+	to common.Address,
+	blockNumber *big.Int,
+	method string,
+	args ...interface{},
+) ([]interface{}, bool, error) {
+	ctxCall, cancel := context.WithTimeout(ctx, time.Duration(c.callTimeout.Load()))
+	defer cancel()
+
+	data, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "contractABI.Pack")
+	}
+
+	res, err := c.caller.CallContract(ctxCall, ethereum.CallMsg{To: &to, Data: data}, blockNumber)
+	if err != nil {
+		if isRevert(err) {
+			return nil, false, nil
+		}
+		return nil, false, errors.Wrap(err, "c.caller.CallContract")
+	}
+	if len(res) == 0 {
+		return nil, false, nil
+	}
+
+	out, err := contractABI.Unpack(method, res)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "contractABI.Unpack")
+	}
+	return out, true, nil
+}
+
+// revertErrorCode is the JSON-RPC error code of eth_calls that reverted.
+const revertErrorCode = 3
+
+// isRevert reports whether an eth_call failed because the call reverted rather
+// than because of the node. Nodes report reverts with error code 3 and the
+// revert data, or, without the data, in the message of another code.
+func isRevert(err error) bool {
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	if rpcErr.ErrorCode() == revertErrorCode {
+		return true
+	}
+	msg := strings.ToLower(rpcErr.Error())
+	return strings.Contains(msg, "execution reverted") || strings.Contains(msg, "invalid opcode")
+}
+
 // GetPairTokens returns the addresses of token0 and token1 for a given pair contract.
 func (c *ethClientImpl) GetPairTokens(ctx context.Context, pair common.Address) (common.Address, common.Address, error) {
 	const (
@@ -111,7 +399,7 @@ func (c *ethClientImpl) GetPairTokens(ctx context.Context, pair common.Address)
 	getToken := func(method string) {
 		defer wg.Done()
 
-		ctxCall, cancel := context.WithTimeout(ctx, c.callTimeout)
+		ctxCall, cancel := context.WithTimeout(ctx, time.Duration(c.callTimeout.Load()))
 		defer cancel()
 
 		select {
@@ -171,28 +459,184 @@ func (c *ethClientImpl) GetPairTokens(ctx context.Context, pair common.Address)
 	return token0, token1, nil
 }
 
-// GetPairReserves returns the current reserves of token0 and token1 for a given pair contract.
-func (c *ethClientImpl) GetPairReserves(ctx context.Context, pair common.Address) (*big.Int, *big.Int, error) {
-	out, err := c.call(ctx, pair, "getReserves")
+// GetPairReserves returns the current reserves of token0 and token1 for a given pair contract
+// and the timestamp of their last update.
+func (c *ethClientImpl) GetPairReserves(ctx context.Context, pair common.Address) (*dto.PairReserves, error) {
+	return c.GetPairReservesAt(ctx, pair, nil)
+}
+
+// GetPairReservesAt returns the reserves of a pair and the timestamp of their last update
+// at the given block, or the latest one if nil.
+func (c *ethClientImpl) GetPairReservesAt(
+	ctx context.Context,
+	pair common.Address,
+	blockNumber *big.Int,
+) (*dto.PairReserves, error) {
+	out, err := c.callABI(ctx, c.pairABI, pair, blockNumber, "getReserves")
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "c.call")
+		return nil, errors.Wrap(err, "c.callABI")
 	}
 
-	const requiredSize = 2
+	return decodeReserves(out)
+}
+
+// decodeReserves decodes the outputs of getReserves.
+func decodeReserves(out []interface{}) (*dto.PairReserves, error) {
+	const requiredSize = 3
 	if len(out) < requiredSize {
-		return nil, nil, errors.Errorf("insufficient outputs from getReserves call: expected %d, got %d", requiredSize, len(out))
+		return nil, errors.Errorf("insufficient outputs from getReserves call: expected %d, got %d", requiredSize, len(out))
 	}
 
-	reserves := make([]*big.Int, requiredSize)
-	reserveNames := []string{"reserve0", "reserve1"}
+	reserves := &dto.PairReserves{}
+	var ok bool
+	if reserves.Reserve0, ok = out[0].(*big.Int); !ok {
+		return nil, errors.New("failed to cast reserve0 to *big.Int")
+	}
+	if reserves.Reserve1, ok = out[1].(*big.Int); !ok {
+		return nil, errors.New("failed to cast reserve1 to *big.Int")
+	}
+	if reserves.BlockTimestampLast, ok = out[2].(uint32); !ok {
+		return nil, errors.New("failed to cast blockTimestampLast to uint32")
+	}
 
-	for i := 0; i < requiredSize; i++ {
-		reserve, ok := out[i].(*big.Int)
-		if !ok {
-			return nil, nil, errors.Errorf("failed to cast %s to *big.Int", reserveNames[i])
-		}
-		reserves[i] = reserve
+	return reserves, nil
+}
+
+// GetPairTotalSupply returns the LP token supply of a pair at the given block, or the latest one if nil.
+func (c *ethClientImpl) GetPairTotalSupply(ctx context.Context, pair common.Address, blockNumber *big.Int) (*big.Int, error) {
+	out, err := c.callABI(ctx, c.pairABI, pair, blockNumber, "totalSupply")
+	if err != nil {
+		return nil, errors.Wrap(err, "c.callABI")
+	}
+
+	return firstBigInt(out, "totalSupply")
+}
+
+// GetPairKLast returns the reserve product a pair recorded at its last liquidity event
+// at the given block, or the latest one if nil.
+func (c *ethClientImpl) GetPairKLast(ctx context.Context, pair common.Address, blockNumber *big.Int) (*big.Int, error) {
+	out, err := c.callABI(ctx, c.pairABI, pair, blockNumber, "kLast")
+	if err != nil {
+		return nil, errors.Wrap(err, "c.callABI")
+	}
+
+	return firstBigInt(out, "kLast")
+}
+
+// GetPairFactory returns the factory that a pair contract reports as its deployer.
+func (c *ethClientImpl) GetPairFactory(ctx context.Context, pair common.Address) (common.Address, error) {
+	out, err := c.call(ctx, pair, "factory")
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "c.call")
+	}
+
+	return firstAddress(out, "factory")
+}
+
+// GetFactoryPair returns the pair registered by a factory for two tokens, or the zero address.
+func (c *ethClientImpl) GetFactoryPair(ctx context.Context, factory, tokenA, tokenB common.Address) (common.Address, error) {
+	out, err := c.callABI(ctx, c.factoryABI, factory, nil, "getPair", tokenA, tokenB)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "c.callABI")
+	}
+
+	return firstAddress(out, "getPair")
+}
+
+// GetFactoryFeeTo returns the protocol fee recipient of a factory at the given block,
+// or the latest one if nil.
+func (c *ethClientImpl) GetFactoryFeeTo(ctx context.Context, factory common.Address, blockNumber *big.Int) (common.Address, error) {
+	out, err := c.callABI(ctx, c.factoryABI, factory, blockNumber, "feeTo")
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "c.callABI")
+	}
+
+	return firstAddress(out, "feeTo")
+}
+
+// BlockNumber returns the number of the latest block.
+func (c *ethClientImpl) BlockNumber(ctx context.Context) (uint64, error) {
+	n, err := c.caller.BlockNumber(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "c.caller.BlockNumber")
+	}
+	return n, nil
+}
+
+// BlockTimestamp returns the timestamp of the given block, or the latest one if nil.
+func (c *ethClientImpl) BlockTimestamp(ctx context.Context, blockNumber *big.Int) (uint64, error) {
+	header, err := c.caller.HeaderByNumber(ctx, blockNumber)
+	if err != nil {
+		return 0, errors.Wrap(err, "c.caller.HeaderByNumber")
+	}
+	return header.Time, nil
+}
+
+// GetBlockHeader returns the hash, parent hash and timestamp of the given block,
+// or the latest one if nil.
+func (c *ethClientImpl) GetBlockHeader(ctx context.Context, blockNumber *big.Int) (*dto.BlockHeader, error) {
+	header, err := c.caller.HeaderByNumber(ctx, blockNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "c.caller.HeaderByNumber")
+	}
+	return &dto.BlockHeader{
+		Number:     header.Number.Uint64(),
+		Hash:       header.Hash(),
+		ParentHash: header.ParentHash,
+		Timestamp:  header.Time,
+	}, nil
+}
+
+// GetAmountsOut calls UniswapV2Router02.getAmountsOut at the given block, or the latest one if nil.
+func (c *ethClientImpl) GetAmountsOut(
+	ctx context.Context,
+	router common.Address,
+	amountIn *big.Int,
+	path []common.Address,
+	blockNumber *big.Int,
+) ([]*big.Int, error) {
+	out, err := c.callABI(ctx, c.routerABI, router, blockNumber, "getAmountsOut", amountIn, path)
+	if err != nil {
+		return nil, errors.Wrap(err, "c.callABI")
+	}
+
+	if len(out) == 0 {
+		return nil, errors.New("no outputs from getAmountsOut call")
+	}
+
+	amounts, ok := out[0].([]*big.Int)
+	if !ok {
+		return nil, errors.New("failed to cast getAmountsOut result to []*big.Int")
+	}
+	if len(amounts) != len(path) {
+		return nil, errors.Errorf("getAmountsOut returned %d amounts for a path of %d", len(amounts), len(path))
+	}
+
+	return amounts, nil
+}
+
+func firstAddress(out []interface{}, method string) (common.Address, error) {
+	if len(out) == 0 {
+		return common.Address{}, errors.Errorf("no outputs from %s call", method)
+	}
+
+	addr, ok := out[0].(common.Address)
+	if !ok {
+		return common.Address{}, errors.Errorf("failed to cast %s result to address", method)
+	}
+
+	return addr, nil
+}
+
+func firstBigInt(out []interface{}, method string) (*big.Int, error) {
+	if len(out) == 0 {
+		return nil, errors.Errorf("no outputs from %s call", method)
+	}
+
+	v, ok := out[0].(*big.Int)
+	if !ok {
+		return nil, errors.Errorf("failed to cast %s result to *big.Int", method)
 	}
 
-	return reserves[0], reserves[1], nil
+	return v, nil
 }
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
@@ -14,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
 	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
 )
 
@@ -23,12 +25,55 @@ func TestNewClient(t *testing.T) {
 	t.Parallel()
 
 	t.Run("dial error", func(t *testing.T) {
-		client, err := NewClient("invalid://url", timeout)
+		client, err := NewClient([]string{"invalid://url"}, timeout)
+		require.Error(t, err)
+		require.Nil(t, client)
+	})
+
+	t.Run("no urls", func(t *testing.T) {
+		client, err := NewClient(nil, timeout)
 		require.Error(t, err)
 		require.Nil(t, client)
 	})
 }
 
+func TestReconfigure(t *testing.T) {
+	t.Parallel()
+
+	t.Run("endpoints and timeout", func(t *testing.T) {
+		t.Parallel()
+
+		client, err := NewClient([]string{"http://localhost:8545"}, timeout)
+		require.NoError(t, err)
+
+		r, ok := client.(Reconfigurer)
+		require.True(t, ok)
+		require.NoError(t, r.Reconfigure([]string{"http://localhost:8546", "http://localhost:8547"}, time.Second))
+
+		impl := client.(*ethClientImpl)
+		require.Len(t, *impl.caller.(*failoverCaller).callers.Load(), 2)
+		require.Equal(t, int64(time.Second), impl.callTimeout.Load())
+
+		require.Error(t, r.Reconfigure([]string{"invalid://url"}, 0))
+		require.Len(t, *impl.caller.(*failoverCaller).callers.Load(), 2)
+	})
+
+	t.Run("static caller", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		client, err := newClientWithCaller(mock.NewMockEthCaller(ctrl), timeout)
+		require.NoError(t, err)
+
+		r := client.(Reconfigurer)
+		require.Error(t, r.Reconfigure([]string{"http://localhost:8545"}, 0))
+		require.NoError(t, r.Reconfigure(nil, time.Second))
+		require.Equal(t, int64(time.Second), client.(*ethClientImpl).callTimeout.Load())
+	})
+}
+
 func TestCallMethod(t *testing.T) {
 	t.Parallel()
 
@@ -239,10 +284,9 @@ func TestGetPairReserves(t *testing.T) {
 			CallContract(gomock.Any(), gomock.Any(), gomock.Nil()).
 			Return(mustPackReserves(t, pairABIJSON, "getReserves", r0, r1, timestamp), nil)
 
-		got0, got1, err := client.GetPairReserves(context.Background(), common.Address{})
+		got, err := client.GetPairReserves(context.Background(), common.Address{})
 		require.NoError(t, err)
-		require.Equal(t, r0, got0)
-		require.Equal(t, r1, got1)
+		require.Equal(t, &dto.PairReserves{Reserve0: r0, Reserve1: r1, BlockTimestampLast: timestamp}, got)
 	})
 
 	t.Run("call error", func(t *testing.T) {
@@ -250,7 +294,7 @@ func TestGetPairReserves(t *testing.T) {
 			CallContract(gomock.Any(), gomock.Any(), gomock.Nil()).
 			Return(nil, errors.New("call error"))
 
-		_, _, err := client.GetPairReserves(context.Background(), common.Address{})
+		_, err := client.GetPairReserves(context.Background(), common.Address{})
 		require.Error(t, err)
 	})
 }
@@ -297,3 +341,229 @@ func mustPackReserves(t *testing.T, abiJSON, method string, r0, r1 *big.Int, ts
 
 	return b
 }
+
+func TestGetPairFactory(t *testing.T) {
+	t.Parallel()
+
+	factory := common.HexToAddress("0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f")
+
+	tests := []struct {
+		name    string
+		result  []byte
+		callErr error
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{name: "success", result: mustPackAddr(t, "factory", factory), wantErr: assert.NoError},
+		{name: "call error", callErr: errors.New("execution reverted"), wantErr: assert.Error},
+		{name: "empty result", result: []byte{}, wantErr: assert.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCaller := mock.NewMockEthCaller(ctrl)
+			mockCaller.EXPECT().
+				CallContract(gomock.Any(), gomock.Any(), gomock.Nil()).
+				Return(tt.result, tt.callErr)
+
+			client, err := newClientWithCaller(mockCaller, timeout)
+			require.NoError(t, err)
+
+			got, err := client.GetPairFactory(context.Background(), common.Address{})
+			tt.wantErr(t, err)
+			if err == nil {
+				require.Equal(t, factory, got)
+			}
+		})
+	}
+}
+
+func TestGetFactoryPair(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	factory := common.HexToAddress("0xfac7")
+	tokenA := common.HexToAddress("0x01")
+	tokenB := common.HexToAddress("0x02")
+	pair := common.HexToAddress("0x1234")
+
+	mockCaller := mock.NewMockEthCaller(ctrl)
+	client, err := newClientWithCaller(mockCaller, timeout)
+	require.NoError(t, err)
+
+	wantData, err := client.(*ethClientImpl).factoryABI.Pack("getPair", tokenA, tokenB)
+	require.NoError(t, err)
+
+	mockCaller.EXPECT().
+		CallContract(gomock.Any(), gomock.Any(), gomock.Nil()).
+		DoAndReturn(func(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+			require.Equal(t, factory, *msg.To)
+			require.Equal(t, wantData, msg.Data)
+			return mustPackAddr(t, "getPair", pair), nil
+		})
+
+	got, err := client.GetFactoryPair(context.Background(), factory, tokenA, tokenB)
+	require.NoError(t, err)
+	require.Equal(t, pair, got)
+}
+
+func TestGetFactoryFeeTo(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	factory := common.HexToAddress("0xfac7")
+	feeTo := common.HexToAddress("0xfee")
+	block := big.NewInt(19_000_000)
+
+	mockCaller := mock.NewMockEthCaller(ctrl)
+	client, err := newClientWithCaller(mockCaller, timeout)
+	require.NoError(t, err)
+
+	wantData, err := client.(*ethClientImpl).factoryABI.Pack("feeTo")
+	require.NoError(t, err)
+
+	mockCaller.EXPECT().
+		CallContract(gomock.Any(), gomock.Any(), block).
+		DoAndReturn(func(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+			require.Equal(t, factory, *msg.To)
+			require.Equal(t, wantData, msg.Data)
+			return mustPackAddr(t, "feeTo", feeTo), nil
+		})
+
+	got, err := client.GetFactoryFeeTo(context.Background(), factory, block)
+	require.NoError(t, err)
+	require.Equal(t, feeTo, got)
+}
+
+func TestGetAmountsOut(t *testing.T) {
+	t.Parallel()
+
+	router := common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D")
+	path := []common.Address{common.HexToAddress("0x01"), common.HexToAddress("0x02")}
+	block := big.NewInt(19_000_000)
+
+	tests := []struct {
+		name    string
+		amounts []*big.Int
+		callErr error
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{name: "success", amounts: []*big.Int{big.NewInt(1000), big.NewInt(1813)}, wantErr: assert.NoError},
+		{name: "call error", callErr: errors.New("execution reverted"), wantErr: assert.Error},
+		{name: "wrong length", amounts: []*big.Int{big.NewInt(1000)}, wantErr: assert.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCaller := mock.NewMockEthCaller(ctrl)
+			client, err := newClientWithCaller(mockCaller, timeout)
+			require.NoError(t, err)
+			routerABI := client.(*ethClientImpl).routerABI
+
+			mockCaller.EXPECT().
+				CallContract(gomock.Any(), gomock.Any(), block).
+				DoAndReturn(func(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+					require.Equal(t, router, *msg.To)
+					if tt.callErr != nil {
+						return nil, tt.callErr
+					}
+					return routerABI.Methods["getAmountsOut"].Outputs.Pack(tt.amounts)
+				})
+
+			got, err := client.GetAmountsOut(context.Background(), router, big.NewInt(1000), path, block)
+			tt.wantErr(t, err)
+			if err == nil {
+				require.Equal(t, tt.amounts, got)
+			}
+		})
+	}
+}
+
+func TestGetPairReservesAt(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	block := big.NewInt(19_000_000)
+
+	mockCaller := mock.NewMockEthCaller(ctrl)
+	mockCaller.EXPECT().
+		CallContract(gomock.Any(), gomock.Any(), block).
+		Return(mustPackReserves(t, pairABIJSON, "getReserves", big.NewInt(10), big.NewInt(20), 0), nil)
+
+	client, err := newClientWithCaller(mockCaller, timeout)
+	require.NoError(t, err)
+
+	got, err := client.GetPairReservesAt(context.Background(), common.Address{}, block)
+	require.NoError(t, err)
+	require.Equal(t, &dto.PairReserves{Reserve0: big.NewInt(10), Reserve1: big.NewInt(20)}, got)
+}
+
+func TestGetPairLiquidityState(t *testing.T) {
+	t.Parallel()
+
+	pair := common.HexToAddress("0x1234")
+	block := big.NewInt(19_000_000)
+
+	tests := []struct {
+		name    string
+		method  string
+		result  []byte
+		callErr error
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{name: "total supply", method: "totalSupply", result: common.LeftPadBytes(big.NewInt(14142).Bytes(), 32), wantErr: assert.NoError},
+		{name: "k last", method: "kLast", result: common.LeftPadBytes(big.NewInt(14142).Bytes(), 32), wantErr: assert.NoError},
+		{name: "call error", method: "totalSupply", callErr: errors.New("execution reverted"), wantErr: assert.Error},
+		{name: "empty result", method: "kLast", result: []byte{}, wantErr: assert.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCaller := mock.NewMockEthCaller(ctrl)
+			client, err := newClientWithCaller(mockCaller, timeout)
+			require.NoError(t, err)
+
+			wantData, err := client.(*ethClientImpl).pairABI.Pack(tt.method)
+			require.NoError(t, err)
+
+			mockCaller.EXPECT().
+				CallContract(gomock.Any(), gomock.Any(), block).
+				DoAndReturn(func(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+					require.Equal(t, pair, *msg.To)
+					require.Equal(t, wantData, msg.Data)
+					return tt.result, tt.callErr
+				})
+
+			var got *big.Int
+			if tt.method == "kLast" {
+				got, err = client.GetPairKLast(context.Background(), pair, block)
+			} else {
+				got, err = client.GetPairTotalSupply(context.Background(), pair, block)
+			}
+			tt.wantErr(t, err)
+			if err == nil {
+				require.Equal(t, big.NewInt(14142), got)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package uniswap
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// EIP-1167 minimal proxies are this prefix, the implementation address and this suffix.
+var (
+	minimalProxyPrefix = common.FromHex("0x363d3d373d3d3d363d73")
+	minimalProxySuffix = common.FromHex("0x5af43d82803e903d91602b57fd5bf3")
+)
+
+// GetCode returns the runtime bytecode of a contract at the latest block. For
+// EIP-1167 minimal proxies, which many pool factories deploy, it returns the
+// code of the implementation the proxy delegates to.
+func (c *ethClientImpl) GetCode(ctx context.Context, contract common.Address) ([]byte, error) {
+	ctxCall, cancel := context.WithTimeout(ctx, time.Duration(c.callTimeout.Load()))
+	defer cancel()
+
+	code, err := c.caller.CodeAt(ctxCall, contract, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "c.caller.CodeAt")
+	}
+
+	implementation, ok := minimalProxyTarget(code)
+	if !ok {
+		return code, nil
+	}
+
+	code, err = c.caller.CodeAt(ctxCall, implementation, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "c.caller.CodeAt")
+	}
+	return code, nil
+}
+
+// minimalProxyTarget returns the implementation of an EIP-1167 minimal proxy.
+func minimalProxyTarget(code []byte) (common.Address, bool) {
+	if len(code) != len(minimalProxyPrefix)+common.AddressLength+len(minimalProxySuffix) ||
+		!bytes.HasPrefix(code, minimalProxyPrefix) || !bytes.HasSuffix(code, minimalProxySuffix) {
+		return common.Address{}, false
+	}
+	return common.BytesToAddress(code[len(minimalProxyPrefix) : len(minimalProxyPrefix)+common.AddressLength]), true
+}
@@ -0,0 +1,60 @@
+package uniswap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+)
+
+func TestGetCode(t *testing.T) {
+	t.Parallel()
+
+	pool := common.HexToAddress("0x1234")
+	implementation := common.HexToAddress("0xa11ce")
+	runtime := common.FromHex("0x6080604052348015600f57600080fd5b50")
+
+	proxy := append(append(append([]byte{}, minimalProxyPrefix...), implementation.Bytes()...), minimalProxySuffix...)
+
+	tests := []struct {
+		name      string
+		mockSetup func(mc *mock.MockEthCaller)
+	}{
+		{
+			name: "plain contract",
+			mockSetup: func(mc *mock.MockEthCaller) {
+				mc.EXPECT().CodeAt(gomock.Any(), pool, nil).Return(runtime, nil)
+			},
+		},
+		{
+			name: "minimal proxy",
+			mockSetup: func(mc *mock.MockEthCaller) {
+				mc.EXPECT().CodeAt(gomock.Any(), pool, nil).Return(proxy, nil)
+				mc.EXPECT().CodeAt(gomock.Any(), implementation, nil).Return(runtime, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCaller := mock.NewMockEthCaller(ctrl)
+			client, err := newClientWithCaller(mockCaller, timeout)
+			require.NoError(t, err)
+
+			tt.mockSetup(mockCaller)
+
+			got, err := client.GetCode(context.Background(), pool)
+			require.NoError(t, err)
+			require.Equal(t, runtime, got)
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package uniswap
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+)
+
+const curvePoolABIJSON = `[
+	{"inputs":[],"name":"A","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"A_precise","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"fee","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"offpeg_fee_multiplier","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"internalType":"uint256","name":"","type":"uint256"}],"name":"coins","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"internalType":"uint256","name":"","type":"uint256"}],"name":"balances","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"stored_rates","outputs":[{"internalType":"uint256[]","name":"","type":"uint256[]"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"base_pool","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"get_virtual_price","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"internalType":"int128","name":"i","type":"int128"},{"internalType":"int128","name":"j","type":"int128"},{"internalType":"uint256","name":"dx","type":"uint256"}],"name":"get_dy","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}
+]`
+
+// Pools deployed before Vyper 0.2 index coins and balances with int128.
+const curveLegacyPoolABIJSON = `[
+	{"inputs":[{"internalType":"int128","name":"","type":"int128"}],"name":"coins","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"internalType":"int128","name":"","type":"int128"}],"name":"balances","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}
+]`
+
+const curveRegistryABIJSON = `[
+	{"inputs":[{"internalType":"address","name":"_pool","type":"address"}],"name":"is_registered","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"view","type":"function"}
+]`
+
+// maxCurveCoins is the most coins a Curve pool holds.
+const maxCurveCoins = 8
+
+var (
+	curvePoolABI       = mustParseABI(curvePoolABIJSON)
+	curveLegacyPoolABI = mustParseABI(curveLegacyPoolABIJSON)
+	curveRegistryABI   = mustParseABI(curveRegistryABIJSON)
+
+	// curveNativeCoin is how Curve pools list ETH among their coins.
+	curveNativeCoin = common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+	// curveAPrecision is the A_PRECISION of pools that have A_precise().
+	curveAPrecision = big.NewInt(100)
+)
+
+// IsCurvePool reports whether a contract answers A(), as Curve StableSwap
+// pools do. Contracts that revert or return no word are not Curve pools;
+// other errors are returned, as the call may have failed transiently.
+func (c *ethClientImpl) IsCurvePool(ctx context.Context, pool common.Address) (bool, error) {
+	out, ok, err := c.probe(ctx, curvePoolABI, pool, nil, "A")
+	if err != nil || !ok {
+		return false, err
+	}
+
+	a, err := firstBigInt(out, "A")
+	if err != nil {
+		return false, nil //nolint:nilerr
+	}
+	return a.Sign() > 0, nil
+}
+
+// GetCurvePoolState reads the coins, balances, rates, amplification and fee of
+// a Curve StableSwap pool at the given block, which must be set so that every
+// read sees the same block.
+//
+// Rates are the pool's stored_rates() where it has them (stableswap-ng pools),
+// otherwise 10^(36 - decimals) of each coin, with the base pool's virtual price
+// for the last coin of a metapool.
+func (c *ethClientImpl) GetCurvePoolState(
+	ctx context.Context,
+	pool common.Address,
+	blockNumber *big.Int,
+) (*dto.CurvePoolState, error) {
+	if blockNumber == nil {
+		return nil, errors.New("block number is required")
+	}
+
+	ctxCall, cancel := context.WithTimeout(ctx, time.Duration(c.callTimeout.Load()))
+	defer cancel()
+
+	poolABI, coins, err := c.curveCoins(ctxCall, pool, blockNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "c.curveCoins")
+	}
+
+	n := len(coins)
+	state := &dto.CurvePoolState{
+		BlockNumber: blockNumber.Uint64(),
+		Coins:       coins,
+		Balances:    make([]*big.Int, n),
+	}
+
+	var a, aPrecise *big.Int
+	var storedRates []*big.Int
+	optional := func(method string, decode func(out []interface{})) func() error {
+		return func() error {
+			out, ok, err := c.probe(ctxCall, curvePoolABI, pool, blockNumber, method)
+			if err != nil {
+				return errors.Wrapf(err, "failed to call %s", method)
+			}
+			if ok && len(out) > 0 {
+				decode(out)
+			}
+			return nil
+		}
+	}
+
+	reads := []func() error{
+		func() error {
+			out, err := c.callABI(ctxCall, curvePoolABI, pool, blockNumber, "A")
+			if err != nil {
+				return errors.Wrap(err, "failed to call A")
+			}
+			a, err = firstBigInt(out, "A")
+			return err
+		},
+		func() error {
+			out, err := c.callABI(ctxCall, curvePoolABI, pool, blockNumber, "fee")
+			if err != nil {
+				return errors.Wrap(err, "failed to call fee")
+			}
+			state.Fee, err = firstBigInt(out, "fee")
+			return err
+		},
+		optional("A_precise", func(out []interface{}) { aPrecise, _ = out[0].(*big.Int) }),
+		optional("offpeg_fee_multiplier", func(out []interface{}) { state.OffpegFeeMultiplier, _ = out[0].(*big.Int) }),
+		optional("stored_rates", func(out []interface{}) { storedRates, _ = out[0].([]*big.Int) }),
+		optional("base_pool", func(out []interface{}) { state.BasePool, _ = out[0].(common.Address) }),
+	}
+	for i := range coins {
+		reads = append(reads, func() error {
+			out, err := c.callABI(ctxCall, poolABI, pool, blockNumber, "balances", big.NewInt(int64(i)))
+			if err != nil {
+				return errors.Wrapf(err, "failed to call balances(%d)", i)
+			}
+			state.Balances[i], err = firstBigInt(out, "balances")
+			return err
+		})
+	}
+
+	// Every read sets its own fields of state and its own error.
+	if err := c.forEach(len(reads), func(i int) error { return reads[i]() }); err != nil {
+		return nil, errors.Wrap(err, "failed to get curve pool state")
+	}
+
+	state.Amp, state.APrecision = a, big.NewInt(1)
+	if aPrecise != nil {
+		state.Amp, state.APrecision = aPrecise, curveAPrecision
+	}
+
+	if len(storedRates) == n {
+		state.Rates, state.NextGen = storedRates, true
+		return state, nil
+	}
+
+	state.Rates, err = c.curveRates(ctxCall, coins, state.BasePool, blockNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "c.curveRates")
+	}
+
+	return state, nil
+}
+
+// curveCoins lists the coins of a Curve pool, trying coins(uint256) and then
+// coins(int128), and returns the ABI that answered.
+func (c *ethClientImpl) curveCoins(
+	ctx context.Context,
+	pool common.Address,
+	blockNumber *big.Int,
+) (abi.ABI, []common.Address, error) {
+	for _, poolABI := range []abi.ABI{curvePoolABI, curveLegacyPoolABI} {
+		var coins []common.Address
+		for i := range maxCurveCoins {
+			// Indexes past the last coin revert.
+			out, ok, err := c.probe(ctx, poolABI, pool, blockNumber, "coins", big.NewInt(int64(i)))
+			if err != nil {
+				return abi.ABI{}, nil, errors.Wrapf(err, "failed to call coins(%d)", i)
+			}
+			if !ok {
+				break
+			}
+
+			coin, err := firstAddress(out, "coins")
+			if err != nil {
+				return abi.ABI{}, nil, err
+			}
+			coins = append(coins, coin)
+		}
+
+		switch {
+		case len(coins) == 1:
+			return abi.ABI{}, nil, errors.New("pool has a single coin")
+		case len(coins) > 1:
+			return poolABI, coins, nil
+		}
+	}
+
+	return abi.ABI{}, nil, errors.New("pool does not list its coins")
+}
+
+// curveRates computes the rates of pools without stored_rates(): 10^(36 - decimals)
+// of each coin, and for a metapool the base pool's virtual price for its last coin,
+// the base pool's LP token.
+func (c *ethClientImpl) curveRates(
+	ctx context.Context,
+	coins []common.Address,
+	basePool common.Address,
+	blockNumber *big.Int,
+) ([]*big.Int, error) {
+	rates := make([]*big.Int, len(coins))
+	if err := c.forEach(len(coins), func(i int) error {
+		if basePool != (common.Address{}) && i == len(coins)-1 {
+			out, err := c.callABI(ctx, curvePoolABI, basePool, blockNumber, "get_virtual_price")
+			if err != nil {
+				return errors.Wrap(err, "failed to call get_virtual_price")
+			}
+			rates[i], err = firstBigInt(out, "get_virtual_price")
+			return err
+		}
+
+		decimals := uint8(18)
+		if coins[i] != curveNativeCoin {
+			out, err := c.callABI(ctx, erc20ABI, coins[i], blockNumber, "decimals")
+			if err != nil {
+				return errors.Wrapf(err, "failed to call decimals of %s", coins[i].Hex())
+			}
+			if len(out) == 0 {
+				return errors.New("empty decimals output")
+			}
+			var ok bool
+			if decimals, ok = out[0].(uint8); !ok || decimals > 36 {
+				return errors.Errorf("bad decimals of %s", coins[i].Hex())
+			}
+		}
+		rates[i] = new(big.Int).Exp(big.NewInt(10), big.NewInt(36-int64(decimals)), nil)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+// GetCurveAmountOut calls a Curve pool's get_dy for swapping dx of coin i for
+// coin j at the given block, or the latest one if nil.
+func (c *ethClientImpl) GetCurveAmountOut(
+	ctx context.Context,
+	pool common.Address,
+	i, j int,
+	dx *big.Int,
+	blockNumber *big.Int,
+) (*big.Int, error) {
+	out, err := c.callABI(ctx, curvePoolABI, pool, blockNumber, "get_dy", big.NewInt(int64(i)), big.NewInt(int64(j)), dx)
+	if err != nil {
+		return nil, errors.Wrap(err, "c.callABI")
+	}
+
+	return firstBigInt(out, "get_dy")
+}
+
+// IsCurvePoolRegistered asks a Curve registry whether it lists a pool.
+func (c *ethClientImpl) IsCurvePoolRegistered(ctx context.Context, registry, pool common.Address) (bool, error) {
+	out, err := c.callABI(ctx, curveRegistryABI, registry, nil, "is_registered", pool)
+	if err != nil {
+		return false, errors.Wrap(err, "c.callABI")
+	}
+
+	if len(out) == 0 {
+		return false, errors.New("empty is_registered output")
+	}
+	registered, ok := out[0].(bool)
+	if !ok {
+		return false, errors.New("failed to cast is_registered output to bool")
+	}
+	return registered, nil
+}
@@ -0,0 +1,232 @@
+package uniswap
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+)
+
+// curveCall is a call to a fake Curve pool or token. legacy marks the int128
+// coins and balances getters.
+type curveCall struct {
+	to     common.Address
+	method string
+	legacy bool
+	args   []interface{}
+}
+
+// curveContracts answers the calls of GetCurvePoolState from answer, which
+// returns nil outputs for methods that revert.
+func curveContracts(t *testing.T, answer func(call curveCall) []interface{}) func(
+	context.Context, ethereum.CallMsg, *big.Int,
+) ([]byte, error) {
+	return func(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+		for i, contractABI := range []abi.ABI{curvePoolABI, curveLegacyPoolABI, erc20ABI} {
+			method, err := contractABI.MethodById(msg.Data[:4])
+			if err != nil {
+				continue
+			}
+			args, err := method.Inputs.Unpack(msg.Data[4:])
+			require.NoError(t, err)
+
+			out := answer(curveCall{
+				to:     *msg.To,
+				method: method.Name,
+				legacy: i == 1,
+				args:   args,
+			})
+			if out == nil {
+				return nil, revertError{}
+			}
+			return method.Outputs.Pack(out...)
+		}
+		t.Fatalf("unexpected call data %x", msg.Data)
+		return nil, nil
+	}
+}
+
+func TestGetCurvePoolState(t *testing.T) {
+	t.Parallel()
+
+	pool := common.HexToAddress("0x1234")
+	basePool := common.HexToAddress("0xba5e")
+	usdc := common.HexToAddress("0x5678")
+	lp := common.HexToAddress("0x12345678")
+	block := big.NewInt(19_000_000)
+	pow10 := func(n int64) *big.Int { return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil) }
+
+	// coins and balances of a pool with the given coins and getter flavour.
+	holdings := func(call curveCall, coins []common.Address, legacy bool) []interface{} {
+		if call.legacy != legacy {
+			return nil
+		}
+		i := int(call.args[0].(*big.Int).Int64())
+		if i >= len(coins) {
+			return nil
+		}
+		if call.method == "coins" {
+			return []interface{}{coins[i]}
+		}
+		return []interface{}{big.NewInt(int64(1000 * (i + 1)))}
+	}
+
+	tests := []struct {
+		name   string
+		answer func(call curveCall) []interface{}
+		want   *dto.CurvePoolState
+	}{
+		{
+			name: "legacy plain pool with ETH",
+			answer: func(call curveCall) []interface{} {
+				coins := []common.Address{curveNativeCoin, usdc}
+				switch call.method {
+				case "coins", "balances":
+					return holdings(call, coins, true)
+				case "A":
+					return []interface{}{big.NewInt(200)}
+				case "fee":
+					return []interface{}{big.NewInt(4000000)}
+				case "decimals":
+					return []interface{}{uint8(6)}
+				}
+				return nil
+			},
+			want: &dto.CurvePoolState{
+				BlockNumber: block.Uint64(),
+				Coins:       []common.Address{curveNativeCoin, usdc},
+				Balances:    []*big.Int{big.NewInt(1000), big.NewInt(2000)},
+				Rates:       []*big.Int{pow10(18), pow10(30)},
+				Amp:         big.NewInt(200),
+				APrecision:  big.NewInt(1),
+				Fee:         big.NewInt(4000000),
+			},
+		},
+		{
+			name: "metapool",
+			answer: func(call curveCall) []interface{} {
+				if call.to == basePool {
+					return []interface{}{big.NewInt(1_030_000_000_000_000_000)}
+				}
+				switch call.method {
+				case "coins", "balances":
+					return holdings(call, []common.Address{usdc, lp}, false)
+				case "A":
+					return []interface{}{big.NewInt(100)}
+				case "A_precise":
+					return []interface{}{big.NewInt(10000)}
+				case "fee":
+					return []interface{}{big.NewInt(4000000)}
+				case "base_pool":
+					return []interface{}{basePool}
+				case "decimals":
+					return []interface{}{uint8(6)}
+				}
+				return nil
+			},
+			want: &dto.CurvePoolState{
+				BlockNumber: block.Uint64(),
+				Coins:       []common.Address{usdc, lp},
+				Balances:    []*big.Int{big.NewInt(1000), big.NewInt(2000)},
+				Rates:       []*big.Int{pow10(30), big.NewInt(1_030_000_000_000_000_000)},
+				Amp:         big.NewInt(10000),
+				APrecision:  big.NewInt(100),
+				Fee:         big.NewInt(4000000),
+				BasePool:    basePool,
+			},
+		},
+		{
+			name: "stableswap-ng pool",
+			answer: func(call curveCall) []interface{} {
+				switch call.method {
+				case "coins", "balances":
+					return holdings(call, []common.Address{usdc, lp}, false)
+				case "A":
+					return []interface{}{big.NewInt(1500)}
+				case "A_precise":
+					return []interface{}{big.NewInt(150000)}
+				case "fee":
+					return []interface{}{big.NewInt(1000000)}
+				case "offpeg_fee_multiplier":
+					return []interface{}{big.NewInt(20000000000)}
+				case "stored_rates":
+					return []interface{}{[]*big.Int{pow10(30), big.NewInt(1e18)}}
+				}
+				return nil
+			},
+			want: &dto.CurvePoolState{
+				BlockNumber:         block.Uint64(),
+				Coins:               []common.Address{usdc, lp},
+				Balances:            []*big.Int{big.NewInt(1000), big.NewInt(2000)},
+				Rates:               []*big.Int{pow10(30), big.NewInt(1e18)},
+				Amp:                 big.NewInt(150000),
+				APrecision:          big.NewInt(100),
+				Fee:                 big.NewInt(1000000),
+				OffpegFeeMultiplier: big.NewInt(20000000000),
+				NextGen:             true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCaller := mock.NewMockEthCaller(ctrl)
+			client, err := newClientWithCaller(mockCaller, timeout)
+			require.NoError(t, err)
+
+			mockCaller.EXPECT().
+				CallContract(gomock.Any(), gomock.Any(), block).
+				DoAndReturn(curveContracts(t, tt.answer)).
+				AnyTimes()
+
+			got, err := client.GetCurvePoolState(context.Background(), pool, block)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestIsCurvePool(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pool := common.HexToAddress("0x1234")
+	pair := common.HexToAddress("0x5678")
+
+	mockCaller := mock.NewMockEthCaller(ctrl)
+	client, err := newClientWithCaller(mockCaller, timeout)
+	require.NoError(t, err)
+
+	mockCaller.EXPECT().
+		CallContract(gomock.Any(), gomock.Any(), nil).
+		DoAndReturn(curveContracts(t, func(call curveCall) []interface{} {
+			if call.to == pool && call.method == "A" {
+				return []interface{}{big.NewInt(100)}
+			}
+			return nil
+		})).
+		Times(2)
+
+	got, err := client.IsCurvePool(context.Background(), pool)
+	require.NoError(t, err)
+	require.True(t, got)
+
+	got, err = client.IsCurvePool(context.Background(), pair)
+	require.NoError(t, err)
+	require.False(t, got)
+}
@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BalancerWeightedPoolState is the state of a Balancer weighted pool at a block.
+type BalancerWeightedPoolState struct {
+	BlockNumber uint64
+	PoolID      [32]byte
+	Vault       common.Address
+	Tokens      []common.Address
+	Balances    []*big.Int
+	// ScalingFactors are 10^(18 - decimals) of each token.
+	ScalingFactors []*big.Int
+	// Weights are normalized to 1e18.
+	Weights []*big.Int
+	// SwapFee is the swap fee in 18 decimals.
+	SwapFee *big.Int
+	// ExactPow is set for pools that report version(), which compute powers
+	// of 1, 2 and 4 exactly.
+	ExactPow bool
+}
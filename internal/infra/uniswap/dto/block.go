@@ -0,0 +1,11 @@
+package dto
+
+import "github.com/ethereum/go-ethereum/common"
+
+// BlockHeader is the part of a block header that links it into the chain.
+type BlockHeader struct {
+	Number     uint64
+	Hash       common.Hash
+	ParentHash common.Hash
+	Timestamp  uint64
+}
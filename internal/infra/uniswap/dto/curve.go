@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CurvePoolState is the state of a Curve StableSwap pool at a block.
+type CurvePoolState struct {
+	BlockNumber uint64
+	Coins       []common.Address
+	Balances    []*big.Int
+	// Rates scale each balance to 18 decimals with a precision of 1e18.
+	Rates []*big.Int
+	// Amp is A multiplied by APrecision, which is 1 for pools without A_precise().
+	Amp        *big.Int
+	APrecision *big.Int
+	// Fee is the swap fee with a denominator of 1e10.
+	Fee *big.Int
+	// OffpegFeeMultiplier is nil for pools without a dynamic fee.
+	OffpegFeeMultiplier *big.Int
+	// BasePool is the base pool of a metapool, or the zero address.
+	BasePool common.Address
+	// NextGen is set for stableswap-ng pools, which report stored_rates().
+	NextGen bool
+}
@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PairLog is a Sync or Swap log of a Uniswap V2 pair; exactly one of Sync
+// and Swap is set.
+type PairLog struct {
+	Pair        common.Address
+	BlockNumber uint64
+	BlockHash   common.Hash
+	// BlockTimestamp is zero when the node does not report it.
+	BlockTimestamp uint64
+	TxIndex        uint
+	LogIndex       uint
+	// Removed is set on logs undone by a chain reorganisation.
+	Removed bool
+
+	Sync *PairSync
+	Swap *PairSwap
+}
+
+// PairSync is a pair's Sync event, the reserves after a trade, mint, burn or sync.
+type PairSync struct {
+	Reserve0 *big.Int
+	Reserve1 *big.Int
+}
+
+// PairSwap is a pair's Swap event.
+type PairSwap struct {
+	Sender     common.Address
+	To         common.Address
+	Amount0In  *big.Int
+	Amount1In  *big.Int
+	Amount0Out *big.Int
+	Amount1Out *big.Int
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "math/big"
+
+// PairObservation is the price oracle state of a Uniswap V2 pair at a block.
+type PairObservation struct {
+	BlockNumber uint64
+	// BlockTimestamp is the timestamp of the block.
+	BlockTimestamp       uint64
+	Price0CumulativeLast *big.Int
+	Price1CumulativeLast *big.Int
+	// PairReserves are the reserves at the block; the cumulative prices are
+	// accumulated up to their BlockTimestampLast.
+	PairReserves
+}
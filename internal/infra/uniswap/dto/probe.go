@@ -0,0 +1,15 @@
+package dto
+
+import "math/big"
+
+// TransferProbe is the outcome of a simulated round trip of a token through a pair.
+type TransferProbe struct {
+	// Sent is the amount transferred from the pair.
+	Sent *big.Int
+	// Received is the amount that arrived at the recipient, after any tax on
+	// transfers from the pair.
+	Received *big.Int
+	// Returned is the amount that arrived back at the pair when Received was
+	// sent to it, after any tax on transfers to the pair.
+	Returned *big.Int
+}
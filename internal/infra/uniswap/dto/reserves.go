@@ -0,0 +1,13 @@
+package dto
+
+import "math/big"
+
+// PairReserves is the output of a Uniswap V2 pair's getReserves.
+type PairReserves struct {
+	Reserve0 *big.Int
+	Reserve1 *big.Int
+	// BlockTimestampLast is the timestamp, modulo 2^32, of the block the
+	// reserves were last updated in, that is of the pair's last trade,
+	// mint, burn or sync.
+	BlockTimestampLast uint32
+}
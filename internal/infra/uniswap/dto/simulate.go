@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SwapSimulation describes a router swap executed from Sender through eth_call.
+type SwapSimulation struct {
+	Router    common.Address
+	Sender    common.Address
+	Recipient common.Address
+	Path      []common.Address
+	AmountIn  *big.Int
+	// AmountOutMin is passed to the router, which reverts when less arrives.
+	AmountOutMin *big.Int
+	// Storage patches the storage of the input token, e.g. to give Sender
+	// balance and allowance.
+	Storage map[common.Hash]common.Hash
+}
+
+// SwapSimulationResult is the outcome of a SwapSimulation.
+type SwapSimulationResult struct {
+	// Received is the increase of the recipient's balance of the last path token.
+	Received *big.Int
+	// Reverted is set when the swap failed, with the decoded RevertReason.
+	Reverted     bool
+	RevertReason string
+}
@@ -0,0 +1,20 @@
+package dto
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SolidlyMetadata is what a Solidly style pair's metadata() returns at a block.
+type SolidlyMetadata struct {
+	BlockNumber uint64
+	// Decimals0 and Decimals1 are the token units, 10^decimals.
+	Decimals0 *big.Int
+	Decimals1 *big.Int
+	Reserve0  *big.Int
+	Reserve1  *big.Int
+	Stable    bool
+	Token0    common.Address
+	Token1    common.Address
+}
@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// StorageMapping locates a token's balance or allowance mapping in contract storage.
+type StorageMapping struct {
+	// Index is the storage slot of the mapping itself.
+	Index uint64
+	// Vyper is set for Vyper layouts, which hash the slot before the key.
+	Vyper bool
+}
+
+// BalanceSlot returns the slot holding the balance of holder.
+func (m StorageMapping) BalanceSlot(holder common.Address) common.Hash {
+	return m.slot(common.BigToHash(new(big.Int).SetUint64(m.Index)), holder)
+}
+
+// AllowanceSlot returns the slot holding the amount owner allows spender to transfer.
+func (m StorageMapping) AllowanceSlot(owner, spender common.Address) common.Hash {
+	return m.slot(m.slot(common.BigToHash(new(big.Int).SetUint64(m.Index)), owner), spender)
+}
+
+func (m StorageMapping) slot(base common.Hash, key common.Address) common.Hash {
+	k := common.BytesToHash(key.Bytes())
+	if m.Vyper {
+		return crypto.Keccak256Hash(base.Bytes(), k.Bytes())
+	}
+	return crypto.Keccak256Hash(k.Bytes(), base.Bytes())
+}
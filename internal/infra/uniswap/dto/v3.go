@@ -0,0 +1,21 @@
+package dto
+
+import "math/big"
+
+// V3PoolState is the swap state of a Uniswap V3 pool at a block.
+type V3PoolState struct {
+	BlockNumber  uint64
+	SqrtPriceX96 *big.Int
+	Tick         int32
+	// Liquidity is the liquidity in range at the current price.
+	Liquidity *big.Int
+	// Fee is the swap fee in pips, hundredths of a basis point.
+	Fee         uint32
+	TickSpacing int32
+}
+
+// V3Tick is an initialized tick of a Uniswap V3 pool.
+type V3Tick struct {
+	Index        int32
+	LiquidityNet *big.Int
+}
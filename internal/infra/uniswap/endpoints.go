@@ -0,0 +1,202 @@
+package uniswap
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+// drainDelay is how long replaced endpoints stay open for in-flight calls.
+const drainDelay = time.Minute
+
+// Reconfigurer is implemented by clients whose connection settings can be
+// changed at runtime without interrupting in-flight calls.
+type Reconfigurer interface {
+	Reconfigure(rpcURLs []string, callTimeout time.Duration) error
+}
+
+// failoverCaller sends calls to the first endpoint that answers, trying
+// the remaining ones in order when an endpoint fails.
+type failoverCaller struct {
+	callers atomic.Pointer[[]*ethclient.Client]
+}
+
+func dialEndpoints(rpcURLs []string) (*failoverCaller, error) {
+	callers, err := dialAll(rpcURLs)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &failoverCaller{}
+	f.callers.Store(&callers)
+
+	return f, nil
+}
+
+func dialAll(rpcURLs []string) ([]*ethclient.Client, error) {
+	if len(rpcURLs) == 0 {
+		return nil, errors.New("no rpc urls")
+	}
+
+	callers := make([]*ethclient.Client, 0, len(rpcURLs))
+	for _, url := range rpcURLs {
+		caller, err := ethclient.Dial(url)
+		if err != nil {
+			for _, c := range callers {
+				c.Close()
+			}
+			return nil, errors.Wrap(err, "ethclient.Dial")
+		}
+		callers = append(callers, caller)
+	}
+
+	return callers, nil
+}
+
+// reset replaces the endpoint list. The previous endpoints are closed
+// once in-flight calls had time to finish.
+func (f *failoverCaller) reset(rpcURLs []string) error {
+	callers, err := dialAll(rpcURLs)
+	if err != nil {
+		return err
+	}
+
+	prev := f.callers.Swap(&callers)
+	time.AfterFunc(drainDelay, func() {
+		for _, c := range *prev {
+			c.Close()
+		}
+	})
+
+	return nil
+}
+
+// CallContract executes the call on the first healthy endpoint.
+func (f *failoverCaller) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	var combinedErr error
+
+	for _, caller := range *f.callers.Load() {
+		res, err := caller.CallContract(ctx, msg, blockNumber)
+		if err == nil {
+			return res, nil
+		}
+		combinedErr = multierr.Append(combinedErr, err)
+
+		// A JSON-RPC error means the endpoint is healthy and the call itself
+		// failed (e.g. reverted), so another endpoint would answer the same.
+		var rpcErr rpc.Error
+		if ctx.Err() != nil || errors.As(err, &rpcErr) {
+			break
+		}
+	}
+
+	return nil, errors.Wrap(combinedErr, "all endpoints failed")
+}
+
+// BlockNumber returns the latest block number from the first healthy endpoint.
+func (f *failoverCaller) BlockNumber(ctx context.Context) (uint64, error) {
+	var combinedErr error
+
+	for _, caller := range *f.callers.Load() {
+		n, err := caller.BlockNumber(ctx)
+		if err == nil {
+			return n, nil
+		}
+		combinedErr = multierr.Append(combinedErr, err)
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return 0, errors.Wrap(combinedErr, "all endpoints failed")
+}
+
+// Reconfigure replaces the RPC endpoints and the per-call timeout.
+// Endpoints can only be replaced on clients created by NewClient.
+func (c *ethClientImpl) Reconfigure(rpcURLs []string, callTimeout time.Duration) error {
+	if len(rpcURLs) > 0 {
+		f, ok := c.caller.(*failoverCaller)
+		if !ok {
+			return errors.New("client endpoints are not reconfigurable")
+		}
+		if err := f.reset(rpcURLs); err != nil {
+			return errors.Wrap(err, "f.reset")
+		}
+	}
+
+	if callTimeout > 0 {
+		c.callTimeout.Store(int64(callTimeout))
+	}
+
+	return nil
+}
+
+// HeaderByNumber returns a block header from the first healthy endpoint.
+func (f *failoverCaller) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	var combinedErr error
+
+	for _, caller := range *f.callers.Load() {
+		header, err := caller.HeaderByNumber(ctx, number)
+		if err == nil {
+			return header, nil
+		}
+		combinedErr = multierr.Append(combinedErr, err)
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, errors.Wrap(combinedErr, "all endpoints failed")
+}
+
+// CodeAt returns the code of an account from the first healthy endpoint.
+func (f *failoverCaller) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	var combinedErr error
+
+	for _, caller := range *f.callers.Load() {
+		code, err := caller.CodeAt(ctx, account, blockNumber)
+		if err == nil {
+			return code, nil
+		}
+		combinedErr = multierr.Append(combinedErr, err)
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, errors.Wrap(combinedErr, "all endpoints failed")
+}
+
+// FilterLogs returns the logs matching a query from the first healthy endpoint.
+func (f *failoverCaller) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	var combinedErr error
+
+	for _, caller := range *f.callers.Load() {
+		logs, err := caller.FilterLogs(ctx, q)
+		if err == nil {
+			return logs, nil
+		}
+		combinedErr = multierr.Append(combinedErr, err)
+
+		// A JSON-RPC error, such as a range with too many logs, would be the
+		// same on another endpoint.
+		var rpcErr rpc.Error
+		if ctx.Err() != nil || errors.As(err, &rpcErr) {
+			break
+		}
+	}
+
+	return nil, errors.Wrap(combinedErr, "all endpoints failed")
+}
@@ -0,0 +1,130 @@
+package uniswap
+
+import (
+	"encoding/binary"
+	"math/big"
+)
+
+// EVM opcodes used by the probe contracts injected through state overrides.
+const (
+	opStop           = 0x00
+	opAdd            = 0x01
+	opMul            = 0x02
+	opSub            = 0x03
+	opDiv            = 0x04
+	opLt             = 0x10
+	opEq             = 0x14
+	opIsZero         = 0x15
+	opShl            = 0x1b
+	opShr            = 0x1c
+	opSha3           = 0x20
+	opAddress        = 0x30
+	opCaller         = 0x33
+	opCallDataLoad   = 0x35
+	opCallDataSize   = 0x36
+	opCallDataCopy   = 0x37
+	opReturnDataSize = 0x3d
+	opReturnDataCopy = 0x3e
+	opMload          = 0x51
+	opMstore         = 0x52
+	opSload          = 0x54
+	opSstore         = 0x55
+	opJump           = 0x56
+	opJumpi          = 0x57
+	opGas            = 0x5a
+	opJumpDest       = 0x5b
+	opPush1          = 0x60
+	opPush2          = 0x61
+	opDup1           = 0x80
+	opCall           = 0xf1
+	opReturn         = 0xf3
+	opStaticCall     = 0xfa
+	opRevert         = 0xfd
+)
+
+// assembler builds small EVM programs with forward jump labels.
+type assembler struct {
+	code   []byte
+	labels map[string]int
+	fixups map[int]string
+}
+
+func newAssembler() *assembler {
+	return &assembler{
+		labels: make(map[string]int),
+		fixups: make(map[int]string),
+	}
+}
+
+func (a *assembler) op(ops ...byte) *assembler {
+	a.code = append(a.code, ops...)
+	return a
+}
+
+// push emits the shortest PUSH of v.
+func (a *assembler) push(v uint64) *assembler {
+	return a.pushBig(new(big.Int).SetUint64(v))
+}
+
+func (a *assembler) pushBig(v *big.Int) *assembler {
+	b := v.Bytes()
+	if len(b) == 0 {
+		b = []byte{0}
+	}
+	a.code = append(a.code, opPush1+byte(len(b)-1))
+	a.code = append(a.code, b...)
+	return a
+}
+
+// pushLabel emits a PUSH2 of the label's offset, resolved by bytes.
+func (a *assembler) pushLabel(name string) *assembler {
+	a.code = append(a.code, opPush2)
+	a.fixups[len(a.code)] = name
+	a.code = append(a.code, 0, 0)
+	return a
+}
+
+// label marks a jump destination.
+func (a *assembler) label(name string) *assembler {
+	a.labels[name] = len(a.code)
+	return a.op(opJumpDest)
+}
+
+// jumpIfZero jumps to the label when the top of the stack is zero.
+func (a *assembler) jumpIfZero(name string) *assembler {
+	return a.op(opIsZero).pushLabel(name).op(opJumpi)
+}
+
+// selector stores a function selector at memory offset 0.
+func (a *assembler) selector(sel uint32) *assembler {
+	return a.push(uint64(sel)).push(224).op(opShl).push(0).op(opMstore)
+}
+
+// balanceOf calls token.balanceOf(owner), with token and owner pushed by the
+// given functions, stores the result at memOut and jumps to "fail" on error.
+func (a *assembler) balanceOf(token, owner func()) *assembler {
+	a.selector(selectorBalanceOf)
+	owner()
+	a.push(0x04).op(opMstore)
+	a.push(0x20).push(memOut).push(0x24).push(0)
+	token()
+	return a.op(opGas, opStaticCall).jumpIfZero("fail")
+}
+
+// revertWithReturnData reverts with the return data of the last call.
+func (a *assembler) revertWithReturnData() *assembler {
+	return a.op(opReturnDataSize).push(0).push(0).op(opReturnDataCopy).
+		op(opReturnDataSize).push(0).op(opRevert)
+}
+
+func (a *assembler) bytes() []byte {
+	out := append([]byte(nil), a.code...)
+	for pos, name := range a.fixups {
+		target, ok := a.labels[name]
+		if !ok {
+			panic("evm assembler: undefined label " + name)
+		}
+		binary.BigEndian.PutUint16(out[pos:], uint16(target))
+	}
+	return out
+}
@@ -0,0 +1,121 @@
+package uniswap
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+)
+
+const pairEventsABIJSON = `[
+	{"anonymous":false,"inputs":[{"indexed":false,"internalType":"uint112","name":"reserve0","type":"uint112"},{"indexed":false,"internalType":"uint112","name":"reserve1","type":"uint112"}],"name":"Sync","type":"event"},
+	{"anonymous":false,"inputs":[{"indexed":true,"internalType":"address","name":"sender","type":"address"},{"indexed":false,"internalType":"uint256","name":"amount0In","type":"uint256"},{"indexed":false,"internalType":"uint256","name":"amount1In","type":"uint256"},{"indexed":false,"internalType":"uint256","name":"amount0Out","type":"uint256"},{"indexed":false,"internalType":"uint256","name":"amount1Out","type":"uint256"},{"indexed":true,"internalType":"address","name":"to","type":"address"}],"name":"Swap","type":"event"}
+]`
+
+var (
+	pairEventsABI = mustParseABI(pairEventsABIJSON)
+
+	syncTopic = pairEventsABI.Events["Sync"].ID
+	swapTopic = pairEventsABI.Events["Swap"].ID
+)
+
+// GetPairLogs returns the Sync and Swap logs of pairs from block from to block
+// to, inclusive, in chain order.
+func (c *ethClientImpl) GetPairLogs(ctx context.Context, pairs []common.Address, from, to uint64) ([]dto.PairLog, error) {
+	ctxCall, cancel := context.WithTimeout(ctx, time.Duration(c.callTimeout.Load()))
+	defer cancel()
+
+	logs, err := c.caller.FilterLogs(ctxCall, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: pairs,
+		Topics:    [][]common.Hash{{syncTopic, swapTopic}},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "c.caller.FilterLogs")
+	}
+
+	out := make([]dto.PairLog, 0, len(logs))
+	for i := range logs {
+		l, err := decodePairLog(&logs[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "log %d of tx %s", logs[i].Index, logs[i].TxHash.Hex())
+		}
+		out = append(out, l)
+	}
+
+	return out, nil
+}
+
+// decodePairLog decodes a Sync or Swap log.
+func decodePairLog(l *types.Log) (dto.PairLog, error) {
+	out := dto.PairLog{
+		Pair:           l.Address,
+		BlockNumber:    l.BlockNumber,
+		BlockHash:      l.BlockHash,
+		BlockTimestamp: l.BlockTimestamp,
+		TxIndex:        l.TxIndex,
+		LogIndex:       l.Index,
+		Removed:        l.Removed,
+	}
+	if len(l.Topics) == 0 {
+		return dto.PairLog{}, errors.New("log has no topics")
+	}
+
+	switch l.Topics[0] {
+	case syncTopic:
+		values, err := pairEventsABI.Unpack("Sync", l.Data)
+		if err != nil {
+			return dto.PairLog{}, errors.Wrap(err, "pairEventsABI.Unpack")
+		}
+		amounts, err := bigInts(values, "Sync")
+		if err != nil {
+			return dto.PairLog{}, err
+		}
+		out.Sync = &dto.PairSync{Reserve0: amounts[0], Reserve1: amounts[1]}
+	case swapTopic:
+		const swapTopics = 3
+		if len(l.Topics) != swapTopics {
+			return dto.PairLog{}, errors.Errorf("Swap log has %d topics, expected %d", len(l.Topics), swapTopics)
+		}
+		values, err := pairEventsABI.Unpack("Swap", l.Data)
+		if err != nil {
+			return dto.PairLog{}, errors.Wrap(err, "pairEventsABI.Unpack")
+		}
+		amounts, err := bigInts(values, "Swap")
+		if err != nil {
+			return dto.PairLog{}, err
+		}
+		out.Swap = &dto.PairSwap{
+			Sender:     common.BytesToAddress(l.Topics[1].Bytes()),
+			To:         common.BytesToAddress(l.Topics[2].Bytes()),
+			Amount0In:  amounts[0],
+			Amount1In:  amounts[1],
+			Amount0Out: amounts[2],
+			Amount1Out: amounts[3],
+		}
+	default:
+		return dto.PairLog{}, errors.Errorf("unexpected topic %s", l.Topics[0].Hex())
+	}
+
+	return out, nil
+}
+
+// bigInts casts the unpacked values of an event to *big.Int.
+func bigInts(values []interface{}, event string) ([]*big.Int, error) {
+	out := make([]*big.Int, len(values))
+	for i, v := range values {
+		n, ok := v.(*big.Int)
+		if !ok {
+			return nil, errors.Errorf("failed to cast %s value %d to *big.Int", event, i)
+		}
+		out[i] = n
+	}
+	return out, nil
+}
@@ -0,0 +1,118 @@
+package uniswap
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+)
+
+func TestGetPairLogs(t *testing.T) {
+	t.Parallel()
+
+	pair := common.HexToAddress("0x1234")
+	sender := common.HexToAddress("0x7a25")
+	to := common.HexToAddress("0xdead")
+	blockHash := common.HexToHash("0xb10c")
+
+	syncData, err := pairEventsABI.Events["Sync"].Inputs.NonIndexed().Pack(big.NewInt(1000), big.NewInt(2000))
+	require.NoError(t, err)
+	swapData, err := pairEventsABI.Events["Swap"].Inputs.NonIndexed().Pack(
+		big.NewInt(10), big.NewInt(1), big.NewInt(2), big.NewInt(19),
+	)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCaller := mock.NewMockEthCaller(ctrl)
+	mockCaller.EXPECT().FilterLogs(gomock.Any(), ethereum.FilterQuery{
+		FromBlock: big.NewInt(100),
+		ToBlock:   big.NewInt(199),
+		Addresses: []common.Address{pair},
+		Topics:    [][]common.Hash{{syncTopic, swapTopic}},
+	}).Return([]types.Log{
+		{
+			Address: pair, Topics: []common.Hash{syncTopic}, Data: syncData,
+			BlockNumber: 150, BlockHash: blockHash, BlockTimestamp: 1_700_000_000, TxIndex: 3, Index: 7,
+		},
+		{
+			Address: pair, Topics: []common.Hash{swapTopic, common.BytesToHash(sender.Bytes()), common.BytesToHash(to.Bytes())},
+			Data: swapData, BlockNumber: 150, BlockHash: blockHash, TxIndex: 3, Index: 8, Removed: true,
+		},
+	}, nil)
+
+	client, err := newClientWithCaller(mockCaller, time.Second)
+	require.NoError(t, err)
+
+	logs, err := client.GetPairLogs(context.Background(), []common.Address{pair}, 100, 199)
+	require.NoError(t, err)
+	require.Equal(t, []dto.PairLog{
+		{
+			Pair: pair, BlockNumber: 150, BlockHash: blockHash, BlockTimestamp: 1_700_000_000, TxIndex: 3, LogIndex: 7,
+			Sync: &dto.PairSync{Reserve0: big.NewInt(1000), Reserve1: big.NewInt(2000)},
+		},
+		{
+			Pair: pair, BlockNumber: 150, BlockHash: blockHash, TxIndex: 3, LogIndex: 8, Removed: true,
+			Swap: &dto.PairSwap{
+				Sender: sender, To: to,
+				Amount0In: big.NewInt(10), Amount1In: big.NewInt(1), Amount0Out: big.NewInt(2), Amount1Out: big.NewInt(19),
+			},
+		},
+	}, logs)
+}
+
+func TestGetPairLogs_UnexpectedTopic(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCaller := mock.NewMockEthCaller(ctrl)
+	mockCaller.EXPECT().FilterLogs(gomock.Any(), gomock.Any()).Return([]types.Log{
+		{Address: common.HexToAddress("0x1234"), Topics: []common.Hash{common.HexToHash("0x01")}},
+	}, nil)
+
+	client, err := newClientWithCaller(mockCaller, time.Second)
+	require.NoError(t, err)
+
+	_, err = client.GetPairLogs(context.Background(), nil, 0, 1)
+	require.ErrorContains(t, err, "unexpected topic")
+}
+
+func TestGetBlockHeader(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	header := &types.Header{
+		Number:     big.NewInt(150),
+		ParentHash: common.HexToHash("0xbeef"),
+		Time:       1_700_000_000,
+	}
+
+	mockCaller := mock.NewMockEthCaller(ctrl)
+	mockCaller.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(150)).Return(header, nil)
+
+	client, err := newClientWithCaller(mockCaller, time.Second)
+	require.NoError(t, err)
+
+	got, err := client.GetBlockHeader(context.Background(), big.NewInt(150))
+	require.NoError(t, err)
+	require.Equal(t, &dto.BlockHeader{
+		Number:     150,
+		Hash:       header.Hash(),
+		ParentHash: common.HexToHash("0xbeef"),
+		Timestamp:  1_700_000_000,
+	}, got)
+}
@@ -16,6 +16,8 @@ import (
 
 	ethereum "github.com/ethereum/go-ethereum"
 	common "github.com/ethereum/go-ethereum/common"
+	types "github.com/ethereum/go-ethereum/core/types"
+	dto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -43,36 +45,1254 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
+// BlockNumber mocks base method.
+func (m *MockClient) BlockNumber(ctx context.Context) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockNumber", ctx)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockNumber indicates an expected call of BlockNumber.
+func (mr *MockClientMockRecorder) BlockNumber(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockNumber", reflect.TypeOf((*MockClient)(nil).BlockNumber), ctx)
+}
+
+// BlockTimestamp mocks base method.
+func (m *MockClient) BlockTimestamp(ctx context.Context, blockNumber *big.Int) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockTimestamp", ctx, blockNumber)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockTimestamp indicates an expected call of BlockTimestamp.
+func (mr *MockClientMockRecorder) BlockTimestamp(ctx, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockTimestamp", reflect.TypeOf((*MockClient)(nil).BlockTimestamp), ctx, blockNumber)
+}
+
+// FindAllowanceMapping mocks base method.
+func (m *MockClient) FindAllowanceMapping(ctx context.Context, token, owner, spender common.Address) (*dto.StorageMapping, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAllowanceMapping", ctx, token, owner, spender)
+	ret0, _ := ret[0].(*dto.StorageMapping)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAllowanceMapping indicates an expected call of FindAllowanceMapping.
+func (mr *MockClientMockRecorder) FindAllowanceMapping(ctx, token, owner, spender any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllowanceMapping", reflect.TypeOf((*MockClient)(nil).FindAllowanceMapping), ctx, token, owner, spender)
+}
+
+// FindBalanceMapping mocks base method.
+func (m *MockClient) FindBalanceMapping(ctx context.Context, token, holder common.Address) (*dto.StorageMapping, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBalanceMapping", ctx, token, holder)
+	ret0, _ := ret[0].(*dto.StorageMapping)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBalanceMapping indicates an expected call of FindBalanceMapping.
+func (mr *MockClientMockRecorder) FindBalanceMapping(ctx, token, holder any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBalanceMapping", reflect.TypeOf((*MockClient)(nil).FindBalanceMapping), ctx, token, holder)
+}
+
+// GetAmountsOut mocks base method.
+func (m *MockClient) GetAmountsOut(ctx context.Context, router common.Address, amountIn *big.Int, path []common.Address, blockNumber *big.Int) ([]*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAmountsOut", ctx, router, amountIn, path, blockNumber)
+	ret0, _ := ret[0].([]*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAmountsOut indicates an expected call of GetAmountsOut.
+func (mr *MockClientMockRecorder) GetAmountsOut(ctx, router, amountIn, path, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAmountsOut", reflect.TypeOf((*MockClient)(nil).GetAmountsOut), ctx, router, amountIn, path, blockNumber)
+}
+
+// GetBalancerAmountOut mocks base method.
+func (m *MockClient) GetBalancerAmountOut(ctx context.Context, vault common.Address, poolID [32]byte, tokenIn, tokenOut common.Address, amountIn, blockNumber *big.Int) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalancerAmountOut", ctx, vault, poolID, tokenIn, tokenOut, amountIn, blockNumber)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalancerAmountOut indicates an expected call of GetBalancerAmountOut.
+func (mr *MockClientMockRecorder) GetBalancerAmountOut(ctx, vault, poolID, tokenIn, tokenOut, amountIn, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalancerAmountOut", reflect.TypeOf((*MockClient)(nil).GetBalancerAmountOut), ctx, vault, poolID, tokenIn, tokenOut, amountIn, blockNumber)
+}
+
+// GetBalancerWeightedPoolState mocks base method.
+func (m *MockClient) GetBalancerWeightedPoolState(ctx context.Context, pool common.Address, blockNumber *big.Int) (*dto.BalancerWeightedPoolState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalancerWeightedPoolState", ctx, pool, blockNumber)
+	ret0, _ := ret[0].(*dto.BalancerWeightedPoolState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalancerWeightedPoolState indicates an expected call of GetBalancerWeightedPoolState.
+func (mr *MockClientMockRecorder) GetBalancerWeightedPoolState(ctx, pool, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalancerWeightedPoolState", reflect.TypeOf((*MockClient)(nil).GetBalancerWeightedPoolState), ctx, pool, blockNumber)
+}
+
+// GetBlockHeader mocks base method.
+func (m *MockClient) GetBlockHeader(ctx context.Context, blockNumber *big.Int) (*dto.BlockHeader, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockHeader", ctx, blockNumber)
+	ret0, _ := ret[0].(*dto.BlockHeader)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockHeader indicates an expected call of GetBlockHeader.
+func (mr *MockClientMockRecorder) GetBlockHeader(ctx, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockHeader", reflect.TypeOf((*MockClient)(nil).GetBlockHeader), ctx, blockNumber)
+}
+
+// GetCode mocks base method.
+func (m *MockClient) GetCode(ctx context.Context, contract common.Address) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCode", ctx, contract)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCode indicates an expected call of GetCode.
+func (mr *MockClientMockRecorder) GetCode(ctx, contract any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCode", reflect.TypeOf((*MockClient)(nil).GetCode), ctx, contract)
+}
+
+// GetCurveAmountOut mocks base method.
+func (m *MockClient) GetCurveAmountOut(ctx context.Context, pool common.Address, i, j int, dx, blockNumber *big.Int) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurveAmountOut", ctx, pool, i, j, dx, blockNumber)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurveAmountOut indicates an expected call of GetCurveAmountOut.
+func (mr *MockClientMockRecorder) GetCurveAmountOut(ctx, pool, i, j, dx, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurveAmountOut", reflect.TypeOf((*MockClient)(nil).GetCurveAmountOut), ctx, pool, i, j, dx, blockNumber)
+}
+
+// GetCurvePoolState mocks base method.
+func (m *MockClient) GetCurvePoolState(ctx context.Context, pool common.Address, blockNumber *big.Int) (*dto.CurvePoolState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurvePoolState", ctx, pool, blockNumber)
+	ret0, _ := ret[0].(*dto.CurvePoolState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurvePoolState indicates an expected call of GetCurvePoolState.
+func (mr *MockClientMockRecorder) GetCurvePoolState(ctx, pool, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurvePoolState", reflect.TypeOf((*MockClient)(nil).GetCurvePoolState), ctx, pool, blockNumber)
+}
+
+// GetFactoryFeeTo mocks base method.
+func (m *MockClient) GetFactoryFeeTo(ctx context.Context, factory common.Address, blockNumber *big.Int) (common.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFactoryFeeTo", ctx, factory, blockNumber)
+	ret0, _ := ret[0].(common.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFactoryFeeTo indicates an expected call of GetFactoryFeeTo.
+func (mr *MockClientMockRecorder) GetFactoryFeeTo(ctx, factory, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFactoryFeeTo", reflect.TypeOf((*MockClient)(nil).GetFactoryFeeTo), ctx, factory, blockNumber)
+}
+
+// GetFactoryPair mocks base method.
+func (m *MockClient) GetFactoryPair(ctx context.Context, factory, tokenA, tokenB common.Address) (common.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFactoryPair", ctx, factory, tokenA, tokenB)
+	ret0, _ := ret[0].(common.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFactoryPair indicates an expected call of GetFactoryPair.
+func (mr *MockClientMockRecorder) GetFactoryPair(ctx, factory, tokenA, tokenB any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFactoryPair", reflect.TypeOf((*MockClient)(nil).GetFactoryPair), ctx, factory, tokenA, tokenB)
+}
+
+// GetPairFactory mocks base method.
+func (m *MockClient) GetPairFactory(ctx context.Context, pair common.Address) (common.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPairFactory", ctx, pair)
+	ret0, _ := ret[0].(common.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPairFactory indicates an expected call of GetPairFactory.
+func (mr *MockClientMockRecorder) GetPairFactory(ctx, pair any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPairFactory", reflect.TypeOf((*MockClient)(nil).GetPairFactory), ctx, pair)
+}
+
+// GetPairKLast mocks base method.
+func (m *MockClient) GetPairKLast(ctx context.Context, pair common.Address, blockNumber *big.Int) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPairKLast", ctx, pair, blockNumber)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPairKLast indicates an expected call of GetPairKLast.
+func (mr *MockClientMockRecorder) GetPairKLast(ctx, pair, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPairKLast", reflect.TypeOf((*MockClient)(nil).GetPairKLast), ctx, pair, blockNumber)
+}
+
+// GetPairLogs mocks base method.
+func (m *MockClient) GetPairLogs(ctx context.Context, pairs []common.Address, from, to uint64) ([]dto.PairLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPairLogs", ctx, pairs, from, to)
+	ret0, _ := ret[0].([]dto.PairLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPairLogs indicates an expected call of GetPairLogs.
+func (mr *MockClientMockRecorder) GetPairLogs(ctx, pairs, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPairLogs", reflect.TypeOf((*MockClient)(nil).GetPairLogs), ctx, pairs, from, to)
+}
+
+// GetPairObservation mocks base method.
+func (m *MockClient) GetPairObservation(ctx context.Context, pair common.Address, blockNumber *big.Int) (*dto.PairObservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPairObservation", ctx, pair, blockNumber)
+	ret0, _ := ret[0].(*dto.PairObservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPairObservation indicates an expected call of GetPairObservation.
+func (mr *MockClientMockRecorder) GetPairObservation(ctx, pair, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPairObservation", reflect.TypeOf((*MockClient)(nil).GetPairObservation), ctx, pair, blockNumber)
+}
+
 // GetPairReserves mocks base method.
-func (m *MockClient) GetPairReserves(ctx context.Context, pair common.Address) (*big.Int, *big.Int, error) {
+func (m *MockClient) GetPairReserves(ctx context.Context, pair common.Address) (*dto.PairReserves, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPairReserves", ctx, pair)
+	ret0, _ := ret[0].(*dto.PairReserves)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPairReserves indicates an expected call of GetPairReserves.
+func (mr *MockClientMockRecorder) GetPairReserves(ctx, pair any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPairReserves", reflect.TypeOf((*MockClient)(nil).GetPairReserves), ctx, pair)
+}
+
+// GetPairReservesAt mocks base method.
+func (m *MockClient) GetPairReservesAt(ctx context.Context, pair common.Address, blockNumber *big.Int) (*dto.PairReserves, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPairReservesAt", ctx, pair, blockNumber)
+	ret0, _ := ret[0].(*dto.PairReserves)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPairReservesAt indicates an expected call of GetPairReservesAt.
+func (mr *MockClientMockRecorder) GetPairReservesAt(ctx, pair, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPairReservesAt", reflect.TypeOf((*MockClient)(nil).GetPairReservesAt), ctx, pair, blockNumber)
+}
+
+// GetPairTokens mocks base method.
+func (m *MockClient) GetPairTokens(ctx context.Context, pair common.Address) (common.Address, common.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPairTokens", ctx, pair)
+	ret0, _ := ret[0].(common.Address)
+	ret1, _ := ret[1].(common.Address)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetPairTokens indicates an expected call of GetPairTokens.
+func (mr *MockClientMockRecorder) GetPairTokens(ctx, pair any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPairTokens", reflect.TypeOf((*MockClient)(nil).GetPairTokens), ctx, pair)
+}
+
+// GetPairTotalSupply mocks base method.
+func (m *MockClient) GetPairTotalSupply(ctx context.Context, pair common.Address, blockNumber *big.Int) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPairTotalSupply", ctx, pair, blockNumber)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPairTotalSupply indicates an expected call of GetPairTotalSupply.
+func (mr *MockClientMockRecorder) GetPairTotalSupply(ctx, pair, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPairTotalSupply", reflect.TypeOf((*MockClient)(nil).GetPairTotalSupply), ctx, pair, blockNumber)
+}
+
+// GetSolidlyAmountOut mocks base method.
+func (m *MockClient) GetSolidlyAmountOut(ctx context.Context, pair common.Address, amountIn *big.Int, tokenIn common.Address, blockNumber *big.Int) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSolidlyAmountOut", ctx, pair, amountIn, tokenIn, blockNumber)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSolidlyAmountOut indicates an expected call of GetSolidlyAmountOut.
+func (mr *MockClientMockRecorder) GetSolidlyAmountOut(ctx, pair, amountIn, tokenIn, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSolidlyAmountOut", reflect.TypeOf((*MockClient)(nil).GetSolidlyAmountOut), ctx, pair, amountIn, tokenIn, blockNumber)
+}
+
+// GetSolidlyFactoryPair mocks base method.
+func (m *MockClient) GetSolidlyFactoryPair(ctx context.Context, factory, tokenA, tokenB common.Address, stable bool) (common.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSolidlyFactoryPair", ctx, factory, tokenA, tokenB, stable)
+	ret0, _ := ret[0].(common.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSolidlyFactoryPair indicates an expected call of GetSolidlyFactoryPair.
+func (mr *MockClientMockRecorder) GetSolidlyFactoryPair(ctx, factory, tokenA, tokenB, stable any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSolidlyFactoryPair", reflect.TypeOf((*MockClient)(nil).GetSolidlyFactoryPair), ctx, factory, tokenA, tokenB, stable)
+}
+
+// GetSolidlyFee mocks base method.
+func (m *MockClient) GetSolidlyFee(ctx context.Context, factory, pair common.Address, stable bool, blockNumber *big.Int) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSolidlyFee", ctx, factory, pair, stable, blockNumber)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSolidlyFee indicates an expected call of GetSolidlyFee.
+func (mr *MockClientMockRecorder) GetSolidlyFee(ctx, factory, pair, stable, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSolidlyFee", reflect.TypeOf((*MockClient)(nil).GetSolidlyFee), ctx, factory, pair, stable, blockNumber)
+}
+
+// GetSolidlyMetadata mocks base method.
+func (m *MockClient) GetSolidlyMetadata(ctx context.Context, pair common.Address, blockNumber *big.Int) (*dto.SolidlyMetadata, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSolidlyMetadata", ctx, pair, blockNumber)
+	ret0, _ := ret[0].(*dto.SolidlyMetadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSolidlyMetadata indicates an expected call of GetSolidlyMetadata.
+func (mr *MockClientMockRecorder) GetSolidlyMetadata(ctx, pair, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSolidlyMetadata", reflect.TypeOf((*MockClient)(nil).GetSolidlyMetadata), ctx, pair, blockNumber)
+}
+
+// GetV3FactoryPool mocks base method.
+func (m *MockClient) GetV3FactoryPool(ctx context.Context, factory, tokenA, tokenB common.Address, fee uint32) (common.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetV3FactoryPool", ctx, factory, tokenA, tokenB, fee)
+	ret0, _ := ret[0].(common.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetV3FactoryPool indicates an expected call of GetV3FactoryPool.
+func (mr *MockClientMockRecorder) GetV3FactoryPool(ctx, factory, tokenA, tokenB, fee any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetV3FactoryPool", reflect.TypeOf((*MockClient)(nil).GetV3FactoryPool), ctx, factory, tokenA, tokenB, fee)
+}
+
+// GetV3PoolState mocks base method.
+func (m *MockClient) GetV3PoolState(ctx context.Context, pool common.Address, blockNumber *big.Int) (*dto.V3PoolState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetV3PoolState", ctx, pool, blockNumber)
+	ret0, _ := ret[0].(*dto.V3PoolState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetV3PoolState indicates an expected call of GetV3PoolState.
+func (mr *MockClientMockRecorder) GetV3PoolState(ctx, pool, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetV3PoolState", reflect.TypeOf((*MockClient)(nil).GetV3PoolState), ctx, pool, blockNumber)
+}
+
+// GetV3Ticks mocks base method.
+func (m *MockClient) GetV3Ticks(ctx context.Context, pool common.Address, tickSpacing int32, minWord, maxWord int16, blockNumber *big.Int) ([]dto.V3Tick, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetV3Ticks", ctx, pool, tickSpacing, minWord, maxWord, blockNumber)
+	ret0, _ := ret[0].([]dto.V3Tick)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetV3Ticks indicates an expected call of GetV3Ticks.
+func (mr *MockClientMockRecorder) GetV3Ticks(ctx, pool, tickSpacing, minWord, maxWord, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetV3Ticks", reflect.TypeOf((*MockClient)(nil).GetV3Ticks), ctx, pool, tickSpacing, minWord, maxWord, blockNumber)
+}
+
+// IsBalancerPoolFromFactory mocks base method.
+func (m *MockClient) IsBalancerPoolFromFactory(ctx context.Context, factory, pool common.Address) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBalancerPoolFromFactory", ctx, factory, pool)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsBalancerPoolFromFactory indicates an expected call of IsBalancerPoolFromFactory.
+func (mr *MockClientMockRecorder) IsBalancerPoolFromFactory(ctx, factory, pool any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBalancerPoolFromFactory", reflect.TypeOf((*MockClient)(nil).IsBalancerPoolFromFactory), ctx, factory, pool)
+}
+
+// IsBalancerWeightedPool mocks base method.
+func (m *MockClient) IsBalancerWeightedPool(ctx context.Context, pool common.Address) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBalancerWeightedPool", ctx, pool)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsBalancerWeightedPool indicates an expected call of IsBalancerWeightedPool.
+func (mr *MockClientMockRecorder) IsBalancerWeightedPool(ctx, pool any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBalancerWeightedPool", reflect.TypeOf((*MockClient)(nil).IsBalancerWeightedPool), ctx, pool)
+}
+
+// IsCurvePool mocks base method.
+func (m *MockClient) IsCurvePool(ctx context.Context, pool common.Address) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsCurvePool", ctx, pool)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsCurvePool indicates an expected call of IsCurvePool.
+func (mr *MockClientMockRecorder) IsCurvePool(ctx, pool any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsCurvePool", reflect.TypeOf((*MockClient)(nil).IsCurvePool), ctx, pool)
+}
+
+// IsCurvePoolRegistered mocks base method.
+func (m *MockClient) IsCurvePoolRegistered(ctx context.Context, registry, pool common.Address) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsCurvePoolRegistered", ctx, registry, pool)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsCurvePoolRegistered indicates an expected call of IsCurvePoolRegistered.
+func (mr *MockClientMockRecorder) IsCurvePoolRegistered(ctx, registry, pool any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsCurvePoolRegistered", reflect.TypeOf((*MockClient)(nil).IsCurvePoolRegistered), ctx, registry, pool)
+}
+
+// IsSolidlyPair mocks base method.
+func (m *MockClient) IsSolidlyPair(ctx context.Context, pair common.Address) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsSolidlyPair", ctx, pair)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsSolidlyPair indicates an expected call of IsSolidlyPair.
+func (mr *MockClientMockRecorder) IsSolidlyPair(ctx, pair any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSolidlyPair", reflect.TypeOf((*MockClient)(nil).IsSolidlyPair), ctx, pair)
+}
+
+// IsV3Pool mocks base method.
+func (m *MockClient) IsV3Pool(ctx context.Context, pool common.Address) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsV3Pool", ctx, pool)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsV3Pool indicates an expected call of IsV3Pool.
+func (mr *MockClientMockRecorder) IsV3Pool(ctx, pool any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsV3Pool", reflect.TypeOf((*MockClient)(nil).IsV3Pool), ctx, pool)
+}
+
+// ProbeTransfer mocks base method.
+func (m *MockClient) ProbeTransfer(ctx context.Context, pair, token common.Address, amount *big.Int) (*dto.TransferProbe, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProbeTransfer", ctx, pair, token, amount)
+	ret0, _ := ret[0].(*dto.TransferProbe)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProbeTransfer indicates an expected call of ProbeTransfer.
+func (mr *MockClientMockRecorder) ProbeTransfer(ctx, pair, token, amount any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProbeTransfer", reflect.TypeOf((*MockClient)(nil).ProbeTransfer), ctx, pair, token, amount)
+}
+
+// SimulateSwap mocks base method.
+func (m *MockClient) SimulateSwap(ctx context.Context, swap *dto.SwapSimulation) (*dto.SwapSimulationResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SimulateSwap", ctx, swap)
+	ret0, _ := ret[0].(*dto.SwapSimulationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SimulateSwap indicates an expected call of SimulateSwap.
+func (mr *MockClientMockRecorder) SimulateSwap(ctx, swap any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SimulateSwap", reflect.TypeOf((*MockClient)(nil).SimulateSwap), ctx, swap)
+}
+
+// MockChainReader is a mock of ChainReader interface.
+type MockChainReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockChainReaderMockRecorder
+	isgomock struct{}
+}
+
+// MockChainReaderMockRecorder is the mock recorder for MockChainReader.
+type MockChainReaderMockRecorder struct {
+	mock *MockChainReader
+}
+
+// NewMockChainReader creates a new mock instance.
+func NewMockChainReader(ctrl *gomock.Controller) *MockChainReader {
+	mock := &MockChainReader{ctrl: ctrl}
+	mock.recorder = &MockChainReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChainReader) EXPECT() *MockChainReaderMockRecorder {
+	return m.recorder
+}
+
+// BlockNumber mocks base method.
+func (m *MockChainReader) BlockNumber(ctx context.Context) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockNumber", ctx)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockNumber indicates an expected call of BlockNumber.
+func (mr *MockChainReaderMockRecorder) BlockNumber(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockNumber", reflect.TypeOf((*MockChainReader)(nil).BlockNumber), ctx)
+}
+
+// BlockTimestamp mocks base method.
+func (m *MockChainReader) BlockTimestamp(ctx context.Context, blockNumber *big.Int) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockTimestamp", ctx, blockNumber)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockTimestamp indicates an expected call of BlockTimestamp.
+func (mr *MockChainReaderMockRecorder) BlockTimestamp(ctx, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockTimestamp", reflect.TypeOf((*MockChainReader)(nil).BlockTimestamp), ctx, blockNumber)
+}
+
+// GetBlockHeader mocks base method.
+func (m *MockChainReader) GetBlockHeader(ctx context.Context, blockNumber *big.Int) (*dto.BlockHeader, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockHeader", ctx, blockNumber)
+	ret0, _ := ret[0].(*dto.BlockHeader)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockHeader indicates an expected call of GetBlockHeader.
+func (mr *MockChainReaderMockRecorder) GetBlockHeader(ctx, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockHeader", reflect.TypeOf((*MockChainReader)(nil).GetBlockHeader), ctx, blockNumber)
+}
+
+// GetCode mocks base method.
+func (m *MockChainReader) GetCode(ctx context.Context, contract common.Address) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCode", ctx, contract)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCode indicates an expected call of GetCode.
+func (mr *MockChainReaderMockRecorder) GetCode(ctx, contract any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCode", reflect.TypeOf((*MockChainReader)(nil).GetCode), ctx, contract)
+}
+
+// MockV2Reader is a mock of V2Reader interface.
+type MockV2Reader struct {
+	ctrl     *gomock.Controller
+	recorder *MockV2ReaderMockRecorder
+	isgomock struct{}
+}
+
+// MockV2ReaderMockRecorder is the mock recorder for MockV2Reader.
+type MockV2ReaderMockRecorder struct {
+	mock *MockV2Reader
+}
+
+// NewMockV2Reader creates a new mock instance.
+func NewMockV2Reader(ctrl *gomock.Controller) *MockV2Reader {
+	mock := &MockV2Reader{ctrl: ctrl}
+	mock.recorder = &MockV2ReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockV2Reader) EXPECT() *MockV2ReaderMockRecorder {
+	return m.recorder
+}
+
+// GetAmountsOut mocks base method.
+func (m *MockV2Reader) GetAmountsOut(ctx context.Context, router common.Address, amountIn *big.Int, path []common.Address, blockNumber *big.Int) ([]*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAmountsOut", ctx, router, amountIn, path, blockNumber)
+	ret0, _ := ret[0].([]*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAmountsOut indicates an expected call of GetAmountsOut.
+func (mr *MockV2ReaderMockRecorder) GetAmountsOut(ctx, router, amountIn, path, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAmountsOut", reflect.TypeOf((*MockV2Reader)(nil).GetAmountsOut), ctx, router, amountIn, path, blockNumber)
+}
+
+// GetFactoryFeeTo mocks base method.
+func (m *MockV2Reader) GetFactoryFeeTo(ctx context.Context, factory common.Address, blockNumber *big.Int) (common.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFactoryFeeTo", ctx, factory, blockNumber)
+	ret0, _ := ret[0].(common.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFactoryFeeTo indicates an expected call of GetFactoryFeeTo.
+func (mr *MockV2ReaderMockRecorder) GetFactoryFeeTo(ctx, factory, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFactoryFeeTo", reflect.TypeOf((*MockV2Reader)(nil).GetFactoryFeeTo), ctx, factory, blockNumber)
+}
+
+// GetFactoryPair mocks base method.
+func (m *MockV2Reader) GetFactoryPair(ctx context.Context, factory, tokenA, tokenB common.Address) (common.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFactoryPair", ctx, factory, tokenA, tokenB)
+	ret0, _ := ret[0].(common.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFactoryPair indicates an expected call of GetFactoryPair.
+func (mr *MockV2ReaderMockRecorder) GetFactoryPair(ctx, factory, tokenA, tokenB any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFactoryPair", reflect.TypeOf((*MockV2Reader)(nil).GetFactoryPair), ctx, factory, tokenA, tokenB)
+}
+
+// GetPairFactory mocks base method.
+func (m *MockV2Reader) GetPairFactory(ctx context.Context, pair common.Address) (common.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPairFactory", ctx, pair)
+	ret0, _ := ret[0].(common.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPairFactory indicates an expected call of GetPairFactory.
+func (mr *MockV2ReaderMockRecorder) GetPairFactory(ctx, pair any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPairFactory", reflect.TypeOf((*MockV2Reader)(nil).GetPairFactory), ctx, pair)
+}
+
+// GetPairKLast mocks base method.
+func (m *MockV2Reader) GetPairKLast(ctx context.Context, pair common.Address, blockNumber *big.Int) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPairKLast", ctx, pair, blockNumber)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPairKLast indicates an expected call of GetPairKLast.
+func (mr *MockV2ReaderMockRecorder) GetPairKLast(ctx, pair, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPairKLast", reflect.TypeOf((*MockV2Reader)(nil).GetPairKLast), ctx, pair, blockNumber)
+}
+
+// GetPairLogs mocks base method.
+func (m *MockV2Reader) GetPairLogs(ctx context.Context, pairs []common.Address, from, to uint64) ([]dto.PairLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPairLogs", ctx, pairs, from, to)
+	ret0, _ := ret[0].([]dto.PairLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPairLogs indicates an expected call of GetPairLogs.
+func (mr *MockV2ReaderMockRecorder) GetPairLogs(ctx, pairs, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPairLogs", reflect.TypeOf((*MockV2Reader)(nil).GetPairLogs), ctx, pairs, from, to)
+}
+
+// GetPairObservation mocks base method.
+func (m *MockV2Reader) GetPairObservation(ctx context.Context, pair common.Address, blockNumber *big.Int) (*dto.PairObservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPairObservation", ctx, pair, blockNumber)
+	ret0, _ := ret[0].(*dto.PairObservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPairObservation indicates an expected call of GetPairObservation.
+func (mr *MockV2ReaderMockRecorder) GetPairObservation(ctx, pair, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPairObservation", reflect.TypeOf((*MockV2Reader)(nil).GetPairObservation), ctx, pair, blockNumber)
+}
+
+// GetPairReserves mocks base method.
+func (m *MockV2Reader) GetPairReserves(ctx context.Context, pair common.Address) (*dto.PairReserves, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPairReserves", ctx, pair)
+	ret0, _ := ret[0].(*dto.PairReserves)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPairReserves indicates an expected call of GetPairReserves.
+func (mr *MockV2ReaderMockRecorder) GetPairReserves(ctx, pair any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPairReserves", reflect.TypeOf((*MockV2Reader)(nil).GetPairReserves), ctx, pair)
+}
+
+// GetPairReservesAt mocks base method.
+func (m *MockV2Reader) GetPairReservesAt(ctx context.Context, pair common.Address, blockNumber *big.Int) (*dto.PairReserves, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPairReservesAt", ctx, pair, blockNumber)
+	ret0, _ := ret[0].(*dto.PairReserves)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPairReservesAt indicates an expected call of GetPairReservesAt.
+func (mr *MockV2ReaderMockRecorder) GetPairReservesAt(ctx, pair, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPairReservesAt", reflect.TypeOf((*MockV2Reader)(nil).GetPairReservesAt), ctx, pair, blockNumber)
+}
+
+// GetPairTokens mocks base method.
+func (m *MockV2Reader) GetPairTokens(ctx context.Context, pair common.Address) (common.Address, common.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPairTokens", ctx, pair)
+	ret0, _ := ret[0].(common.Address)
+	ret1, _ := ret[1].(common.Address)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetPairTokens indicates an expected call of GetPairTokens.
+func (mr *MockV2ReaderMockRecorder) GetPairTokens(ctx, pair any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPairTokens", reflect.TypeOf((*MockV2Reader)(nil).GetPairTokens), ctx, pair)
+}
+
+// GetPairTotalSupply mocks base method.
+func (m *MockV2Reader) GetPairTotalSupply(ctx context.Context, pair common.Address, blockNumber *big.Int) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPairTotalSupply", ctx, pair, blockNumber)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPairTotalSupply indicates an expected call of GetPairTotalSupply.
+func (mr *MockV2ReaderMockRecorder) GetPairTotalSupply(ctx, pair, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPairTotalSupply", reflect.TypeOf((*MockV2Reader)(nil).GetPairTotalSupply), ctx, pair, blockNumber)
+}
+
+// MockV3Reader is a mock of V3Reader interface.
+type MockV3Reader struct {
+	ctrl     *gomock.Controller
+	recorder *MockV3ReaderMockRecorder
+	isgomock struct{}
+}
+
+// MockV3ReaderMockRecorder is the mock recorder for MockV3Reader.
+type MockV3ReaderMockRecorder struct {
+	mock *MockV3Reader
+}
+
+// NewMockV3Reader creates a new mock instance.
+func NewMockV3Reader(ctrl *gomock.Controller) *MockV3Reader {
+	mock := &MockV3Reader{ctrl: ctrl}
+	mock.recorder = &MockV3ReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockV3Reader) EXPECT() *MockV3ReaderMockRecorder {
+	return m.recorder
+}
+
+// GetV3FactoryPool mocks base method.
+func (m *MockV3Reader) GetV3FactoryPool(ctx context.Context, factory, tokenA, tokenB common.Address, fee uint32) (common.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetV3FactoryPool", ctx, factory, tokenA, tokenB, fee)
+	ret0, _ := ret[0].(common.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetV3FactoryPool indicates an expected call of GetV3FactoryPool.
+func (mr *MockV3ReaderMockRecorder) GetV3FactoryPool(ctx, factory, tokenA, tokenB, fee any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetV3FactoryPool", reflect.TypeOf((*MockV3Reader)(nil).GetV3FactoryPool), ctx, factory, tokenA, tokenB, fee)
+}
+
+// GetV3PoolState mocks base method.
+func (m *MockV3Reader) GetV3PoolState(ctx context.Context, pool common.Address, blockNumber *big.Int) (*dto.V3PoolState, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPairReserves", ctx, pair)
+	ret := m.ctrl.Call(m, "GetV3PoolState", ctx, pool, blockNumber)
+	ret0, _ := ret[0].(*dto.V3PoolState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetV3PoolState indicates an expected call of GetV3PoolState.
+func (mr *MockV3ReaderMockRecorder) GetV3PoolState(ctx, pool, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetV3PoolState", reflect.TypeOf((*MockV3Reader)(nil).GetV3PoolState), ctx, pool, blockNumber)
+}
+
+// GetV3Ticks mocks base method.
+func (m *MockV3Reader) GetV3Ticks(ctx context.Context, pool common.Address, tickSpacing int32, minWord, maxWord int16, blockNumber *big.Int) ([]dto.V3Tick, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetV3Ticks", ctx, pool, tickSpacing, minWord, maxWord, blockNumber)
+	ret0, _ := ret[0].([]dto.V3Tick)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetV3Ticks indicates an expected call of GetV3Ticks.
+func (mr *MockV3ReaderMockRecorder) GetV3Ticks(ctx, pool, tickSpacing, minWord, maxWord, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetV3Ticks", reflect.TypeOf((*MockV3Reader)(nil).GetV3Ticks), ctx, pool, tickSpacing, minWord, maxWord, blockNumber)
+}
+
+// IsV3Pool mocks base method.
+func (m *MockV3Reader) IsV3Pool(ctx context.Context, pool common.Address) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsV3Pool", ctx, pool)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsV3Pool indicates an expected call of IsV3Pool.
+func (mr *MockV3ReaderMockRecorder) IsV3Pool(ctx, pool any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsV3Pool", reflect.TypeOf((*MockV3Reader)(nil).IsV3Pool), ctx, pool)
+}
+
+// MockSolidlyReader is a mock of SolidlyReader interface.
+type MockSolidlyReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockSolidlyReaderMockRecorder
+	isgomock struct{}
+}
+
+// MockSolidlyReaderMockRecorder is the mock recorder for MockSolidlyReader.
+type MockSolidlyReaderMockRecorder struct {
+	mock *MockSolidlyReader
+}
+
+// NewMockSolidlyReader creates a new mock instance.
+func NewMockSolidlyReader(ctrl *gomock.Controller) *MockSolidlyReader {
+	mock := &MockSolidlyReader{ctrl: ctrl}
+	mock.recorder = &MockSolidlyReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSolidlyReader) EXPECT() *MockSolidlyReaderMockRecorder {
+	return m.recorder
+}
+
+// GetSolidlyAmountOut mocks base method.
+func (m *MockSolidlyReader) GetSolidlyAmountOut(ctx context.Context, pair common.Address, amountIn *big.Int, tokenIn common.Address, blockNumber *big.Int) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSolidlyAmountOut", ctx, pair, amountIn, tokenIn, blockNumber)
 	ret0, _ := ret[0].(*big.Int)
-	ret1, _ := ret[1].(*big.Int)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// GetPairReserves indicates an expected call of GetPairReserves.
-func (mr *MockClientMockRecorder) GetPairReserves(ctx, pair any) *gomock.Call {
+// GetSolidlyAmountOut indicates an expected call of GetSolidlyAmountOut.
+func (mr *MockSolidlyReaderMockRecorder) GetSolidlyAmountOut(ctx, pair, amountIn, tokenIn, blockNumber any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPairReserves", reflect.TypeOf((*MockClient)(nil).GetPairReserves), ctx, pair)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSolidlyAmountOut", reflect.TypeOf((*MockSolidlyReader)(nil).GetSolidlyAmountOut), ctx, pair, amountIn, tokenIn, blockNumber)
 }
 
-// GetPairTokens mocks base method.
-func (m *MockClient) GetPairTokens(ctx context.Context, pair common.Address) (common.Address, common.Address, error) {
+// GetSolidlyFactoryPair mocks base method.
+func (m *MockSolidlyReader) GetSolidlyFactoryPair(ctx context.Context, factory, tokenA, tokenB common.Address, stable bool) (common.Address, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPairTokens", ctx, pair)
+	ret := m.ctrl.Call(m, "GetSolidlyFactoryPair", ctx, factory, tokenA, tokenB, stable)
 	ret0, _ := ret[0].(common.Address)
-	ret1, _ := ret[1].(common.Address)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// GetPairTokens indicates an expected call of GetPairTokens.
-func (mr *MockClientMockRecorder) GetPairTokens(ctx, pair any) *gomock.Call {
+// GetSolidlyFactoryPair indicates an expected call of GetSolidlyFactoryPair.
+func (mr *MockSolidlyReaderMockRecorder) GetSolidlyFactoryPair(ctx, factory, tokenA, tokenB, stable any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPairTokens", reflect.TypeOf((*MockClient)(nil).GetPairTokens), ctx, pair)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSolidlyFactoryPair", reflect.TypeOf((*MockSolidlyReader)(nil).GetSolidlyFactoryPair), ctx, factory, tokenA, tokenB, stable)
+}
+
+// GetSolidlyFee mocks base method.
+func (m *MockSolidlyReader) GetSolidlyFee(ctx context.Context, factory, pair common.Address, stable bool, blockNumber *big.Int) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSolidlyFee", ctx, factory, pair, stable, blockNumber)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSolidlyFee indicates an expected call of GetSolidlyFee.
+func (mr *MockSolidlyReaderMockRecorder) GetSolidlyFee(ctx, factory, pair, stable, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSolidlyFee", reflect.TypeOf((*MockSolidlyReader)(nil).GetSolidlyFee), ctx, factory, pair, stable, blockNumber)
+}
+
+// GetSolidlyMetadata mocks base method.
+func (m *MockSolidlyReader) GetSolidlyMetadata(ctx context.Context, pair common.Address, blockNumber *big.Int) (*dto.SolidlyMetadata, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSolidlyMetadata", ctx, pair, blockNumber)
+	ret0, _ := ret[0].(*dto.SolidlyMetadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSolidlyMetadata indicates an expected call of GetSolidlyMetadata.
+func (mr *MockSolidlyReaderMockRecorder) GetSolidlyMetadata(ctx, pair, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSolidlyMetadata", reflect.TypeOf((*MockSolidlyReader)(nil).GetSolidlyMetadata), ctx, pair, blockNumber)
+}
+
+// IsSolidlyPair mocks base method.
+func (m *MockSolidlyReader) IsSolidlyPair(ctx context.Context, pair common.Address) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsSolidlyPair", ctx, pair)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsSolidlyPair indicates an expected call of IsSolidlyPair.
+func (mr *MockSolidlyReaderMockRecorder) IsSolidlyPair(ctx, pair any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSolidlyPair", reflect.TypeOf((*MockSolidlyReader)(nil).IsSolidlyPair), ctx, pair)
+}
+
+// MockCurveReader is a mock of CurveReader interface.
+type MockCurveReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockCurveReaderMockRecorder
+	isgomock struct{}
+}
+
+// MockCurveReaderMockRecorder is the mock recorder for MockCurveReader.
+type MockCurveReaderMockRecorder struct {
+	mock *MockCurveReader
+}
+
+// NewMockCurveReader creates a new mock instance.
+func NewMockCurveReader(ctrl *gomock.Controller) *MockCurveReader {
+	mock := &MockCurveReader{ctrl: ctrl}
+	mock.recorder = &MockCurveReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCurveReader) EXPECT() *MockCurveReaderMockRecorder {
+	return m.recorder
+}
+
+// GetCurveAmountOut mocks base method.
+func (m *MockCurveReader) GetCurveAmountOut(ctx context.Context, pool common.Address, i, j int, dx, blockNumber *big.Int) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurveAmountOut", ctx, pool, i, j, dx, blockNumber)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurveAmountOut indicates an expected call of GetCurveAmountOut.
+func (mr *MockCurveReaderMockRecorder) GetCurveAmountOut(ctx, pool, i, j, dx, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurveAmountOut", reflect.TypeOf((*MockCurveReader)(nil).GetCurveAmountOut), ctx, pool, i, j, dx, blockNumber)
+}
+
+// GetCurvePoolState mocks base method.
+func (m *MockCurveReader) GetCurvePoolState(ctx context.Context, pool common.Address, blockNumber *big.Int) (*dto.CurvePoolState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurvePoolState", ctx, pool, blockNumber)
+	ret0, _ := ret[0].(*dto.CurvePoolState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurvePoolState indicates an expected call of GetCurvePoolState.
+func (mr *MockCurveReaderMockRecorder) GetCurvePoolState(ctx, pool, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurvePoolState", reflect.TypeOf((*MockCurveReader)(nil).GetCurvePoolState), ctx, pool, blockNumber)
+}
+
+// IsCurvePool mocks base method.
+func (m *MockCurveReader) IsCurvePool(ctx context.Context, pool common.Address) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsCurvePool", ctx, pool)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsCurvePool indicates an expected call of IsCurvePool.
+func (mr *MockCurveReaderMockRecorder) IsCurvePool(ctx, pool any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsCurvePool", reflect.TypeOf((*MockCurveReader)(nil).IsCurvePool), ctx, pool)
+}
+
+// IsCurvePoolRegistered mocks base method.
+func (m *MockCurveReader) IsCurvePoolRegistered(ctx context.Context, registry, pool common.Address) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsCurvePoolRegistered", ctx, registry, pool)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsCurvePoolRegistered indicates an expected call of IsCurvePoolRegistered.
+func (mr *MockCurveReaderMockRecorder) IsCurvePoolRegistered(ctx, registry, pool any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsCurvePoolRegistered", reflect.TypeOf((*MockCurveReader)(nil).IsCurvePoolRegistered), ctx, registry, pool)
+}
+
+// MockBalancerReader is a mock of BalancerReader interface.
+type MockBalancerReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockBalancerReaderMockRecorder
+	isgomock struct{}
+}
+
+// MockBalancerReaderMockRecorder is the mock recorder for MockBalancerReader.
+type MockBalancerReaderMockRecorder struct {
+	mock *MockBalancerReader
+}
+
+// NewMockBalancerReader creates a new mock instance.
+func NewMockBalancerReader(ctrl *gomock.Controller) *MockBalancerReader {
+	mock := &MockBalancerReader{ctrl: ctrl}
+	mock.recorder = &MockBalancerReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBalancerReader) EXPECT() *MockBalancerReaderMockRecorder {
+	return m.recorder
+}
+
+// GetBalancerAmountOut mocks base method.
+func (m *MockBalancerReader) GetBalancerAmountOut(ctx context.Context, vault common.Address, poolID [32]byte, tokenIn, tokenOut common.Address, amountIn, blockNumber *big.Int) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalancerAmountOut", ctx, vault, poolID, tokenIn, tokenOut, amountIn, blockNumber)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalancerAmountOut indicates an expected call of GetBalancerAmountOut.
+func (mr *MockBalancerReaderMockRecorder) GetBalancerAmountOut(ctx, vault, poolID, tokenIn, tokenOut, amountIn, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalancerAmountOut", reflect.TypeOf((*MockBalancerReader)(nil).GetBalancerAmountOut), ctx, vault, poolID, tokenIn, tokenOut, amountIn, blockNumber)
+}
+
+// GetBalancerWeightedPoolState mocks base method.
+func (m *MockBalancerReader) GetBalancerWeightedPoolState(ctx context.Context, pool common.Address, blockNumber *big.Int) (*dto.BalancerWeightedPoolState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalancerWeightedPoolState", ctx, pool, blockNumber)
+	ret0, _ := ret[0].(*dto.BalancerWeightedPoolState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalancerWeightedPoolState indicates an expected call of GetBalancerWeightedPoolState.
+func (mr *MockBalancerReaderMockRecorder) GetBalancerWeightedPoolState(ctx, pool, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalancerWeightedPoolState", reflect.TypeOf((*MockBalancerReader)(nil).GetBalancerWeightedPoolState), ctx, pool, blockNumber)
+}
+
+// IsBalancerPoolFromFactory mocks base method.
+func (m *MockBalancerReader) IsBalancerPoolFromFactory(ctx context.Context, factory, pool common.Address) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBalancerPoolFromFactory", ctx, factory, pool)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsBalancerPoolFromFactory indicates an expected call of IsBalancerPoolFromFactory.
+func (mr *MockBalancerReaderMockRecorder) IsBalancerPoolFromFactory(ctx, factory, pool any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBalancerPoolFromFactory", reflect.TypeOf((*MockBalancerReader)(nil).IsBalancerPoolFromFactory), ctx, factory, pool)
+}
+
+// IsBalancerWeightedPool mocks base method.
+func (m *MockBalancerReader) IsBalancerWeightedPool(ctx context.Context, pool common.Address) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBalancerWeightedPool", ctx, pool)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsBalancerWeightedPool indicates an expected call of IsBalancerWeightedPool.
+func (mr *MockBalancerReaderMockRecorder) IsBalancerWeightedPool(ctx, pool any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBalancerWeightedPool", reflect.TypeOf((*MockBalancerReader)(nil).IsBalancerWeightedPool), ctx, pool)
+}
+
+// MockSimulator is a mock of Simulator interface.
+type MockSimulator struct {
+	ctrl     *gomock.Controller
+	recorder *MockSimulatorMockRecorder
+	isgomock struct{}
+}
+
+// MockSimulatorMockRecorder is the mock recorder for MockSimulator.
+type MockSimulatorMockRecorder struct {
+	mock *MockSimulator
+}
+
+// NewMockSimulator creates a new mock instance.
+func NewMockSimulator(ctrl *gomock.Controller) *MockSimulator {
+	mock := &MockSimulator{ctrl: ctrl}
+	mock.recorder = &MockSimulatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSimulator) EXPECT() *MockSimulatorMockRecorder {
+	return m.recorder
+}
+
+// FindAllowanceMapping mocks base method.
+func (m *MockSimulator) FindAllowanceMapping(ctx context.Context, token, owner, spender common.Address) (*dto.StorageMapping, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAllowanceMapping", ctx, token, owner, spender)
+	ret0, _ := ret[0].(*dto.StorageMapping)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAllowanceMapping indicates an expected call of FindAllowanceMapping.
+func (mr *MockSimulatorMockRecorder) FindAllowanceMapping(ctx, token, owner, spender any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllowanceMapping", reflect.TypeOf((*MockSimulator)(nil).FindAllowanceMapping), ctx, token, owner, spender)
+}
+
+// FindBalanceMapping mocks base method.
+func (m *MockSimulator) FindBalanceMapping(ctx context.Context, token, holder common.Address) (*dto.StorageMapping, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBalanceMapping", ctx, token, holder)
+	ret0, _ := ret[0].(*dto.StorageMapping)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBalanceMapping indicates an expected call of FindBalanceMapping.
+func (mr *MockSimulatorMockRecorder) FindBalanceMapping(ctx, token, holder any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBalanceMapping", reflect.TypeOf((*MockSimulator)(nil).FindBalanceMapping), ctx, token, holder)
+}
+
+// ProbeTransfer mocks base method.
+func (m *MockSimulator) ProbeTransfer(ctx context.Context, pair, token common.Address, amount *big.Int) (*dto.TransferProbe, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProbeTransfer", ctx, pair, token, amount)
+	ret0, _ := ret[0].(*dto.TransferProbe)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProbeTransfer indicates an expected call of ProbeTransfer.
+func (mr *MockSimulatorMockRecorder) ProbeTransfer(ctx, pair, token, amount any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProbeTransfer", reflect.TypeOf((*MockSimulator)(nil).ProbeTransfer), ctx, pair, token, amount)
+}
+
+// SimulateSwap mocks base method.
+func (m *MockSimulator) SimulateSwap(ctx context.Context, swap *dto.SwapSimulation) (*dto.SwapSimulationResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SimulateSwap", ctx, swap)
+	ret0, _ := ret[0].(*dto.SwapSimulationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SimulateSwap indicates an expected call of SimulateSwap.
+func (mr *MockSimulatorMockRecorder) SimulateSwap(ctx, swap any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SimulateSwap", reflect.TypeOf((*MockSimulator)(nil).SimulateSwap), ctx, swap)
 }
 
 // MockEthCaller is a mock of EthCaller interface.
@@ -99,6 +1319,21 @@ func (m *MockEthCaller) EXPECT() *MockEthCallerMockRecorder {
 	return m.recorder
 }
 
+// BlockNumber mocks base method.
+func (m *MockEthCaller) BlockNumber(ctx context.Context) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockNumber", ctx)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockNumber indicates an expected call of BlockNumber.
+func (mr *MockEthCallerMockRecorder) BlockNumber(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockNumber", reflect.TypeOf((*MockEthCaller)(nil).BlockNumber), ctx)
+}
+
 // CallContract mocks base method.
 func (m *MockEthCaller) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -113,3 +1348,48 @@ func (mr *MockEthCallerMockRecorder) CallContract(ctx, msg, blockNumber any) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CallContract", reflect.TypeOf((*MockEthCaller)(nil).CallContract), ctx, msg, blockNumber)
 }
+
+// CodeAt mocks base method.
+func (m *MockEthCaller) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CodeAt", ctx, account, blockNumber)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CodeAt indicates an expected call of CodeAt.
+func (mr *MockEthCallerMockRecorder) CodeAt(ctx, account, blockNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CodeAt", reflect.TypeOf((*MockEthCaller)(nil).CodeAt), ctx, account, blockNumber)
+}
+
+// FilterLogs mocks base method.
+func (m *MockEthCaller) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterLogs", ctx, q)
+	ret0, _ := ret[0].([]types.Log)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FilterLogs indicates an expected call of FilterLogs.
+func (mr *MockEthCallerMockRecorder) FilterLogs(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterLogs", reflect.TypeOf((*MockEthCaller)(nil).FilterLogs), ctx, q)
+}
+
+// HeaderByNumber mocks base method.
+func (m *MockEthCaller) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HeaderByNumber", ctx, number)
+	ret0, _ := ret[0].(*types.Header)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HeaderByNumber indicates an expected call of HeaderByNumber.
+func (mr *MockEthCallerMockRecorder) HeaderByNumber(ctx, number any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeaderByNumber", reflect.TypeOf((*MockEthCaller)(nil).HeaderByNumber), ctx, number)
+}
@@ -0,0 +1,89 @@
+package uniswap
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+)
+
+// GetPairObservation reads price0CumulativeLast, price1CumulativeLast and
+// getReserves of a pair and the timestamp of the block, all at blockNumber,
+// which must be set so that every read sees the same block.
+func (c *ethClientImpl) GetPairObservation(
+	ctx context.Context,
+	pair common.Address,
+	blockNumber *big.Int,
+) (*dto.PairObservation, error) {
+	if blockNumber == nil {
+		return nil, errors.New("block number is required")
+	}
+
+	ctxCall, cancel := context.WithTimeout(ctx, time.Duration(c.callTimeout.Load()))
+	defer cancel()
+
+	obs := &dto.PairObservation{BlockNumber: blockNumber.Uint64()}
+
+	reads := []func() error{
+		func() error {
+			out, err := c.callABI(ctxCall, c.pairABI, pair, blockNumber, "price0CumulativeLast")
+			if err != nil {
+				return errors.Wrap(err, "failed to call price0CumulativeLast")
+			}
+			obs.Price0CumulativeLast, err = firstBigInt(out, "price0CumulativeLast")
+			return err
+		},
+		func() error {
+			out, err := c.callABI(ctxCall, c.pairABI, pair, blockNumber, "price1CumulativeLast")
+			if err != nil {
+				return errors.Wrap(err, "failed to call price1CumulativeLast")
+			}
+			obs.Price1CumulativeLast, err = firstBigInt(out, "price1CumulativeLast")
+			return err
+		},
+		func() error {
+			out, err := c.callABI(ctxCall, c.pairABI, pair, blockNumber, "getReserves")
+			if err != nil {
+				return errors.Wrap(err, "failed to call getReserves")
+			}
+			reserves, err := decodeReserves(out)
+			if err != nil {
+				return err
+			}
+			obs.PairReserves = *reserves
+			return nil
+		},
+		func() error {
+			ts, err := c.BlockTimestamp(ctxCall, blockNumber)
+			if err != nil {
+				return errors.Wrap(err, "c.BlockTimestamp")
+			}
+			obs.BlockTimestamp = ts
+			return nil
+		},
+	}
+
+	// Every read sets its own fields of obs and its own error.
+	errs := make([]error, len(reads))
+	var wg sync.WaitGroup
+	wg.Add(len(reads))
+	for i, read := range reads {
+		go func() {
+			defer wg.Done()
+			errs[i] = read()
+		}()
+	}
+	wg.Wait()
+
+	if err := multierr.Combine(errs...); err != nil {
+		return nil, errors.Wrap(err, "failed to get pair observation")
+	}
+
+	return obs, nil
+}
@@ -0,0 +1,100 @@
+package uniswap
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+)
+
+func TestGetPairObservation(t *testing.T) {
+	t.Parallel()
+
+	pair := common.HexToAddress("0x1234")
+	block := big.NewInt(19_000_000)
+
+	tests := []struct {
+		name      string
+		headerErr error
+		want      *dto.PairObservation
+		wantErr   string
+	}{
+		{
+			name: "success",
+			want: &dto.PairObservation{
+				BlockNumber:          block.Uint64(),
+				BlockTimestamp:       1_700_000_012,
+				Price0CumulativeLast: big.NewInt(111),
+				Price1CumulativeLast: big.NewInt(222),
+				PairReserves: dto.PairReserves{
+					Reserve0:           big.NewInt(10000),
+					Reserve1:           big.NewInt(20000),
+					BlockTimestampLast: 1_700_000_000,
+				},
+			},
+		},
+		{name: "header error", headerErr: errors.New("not found"), wantErr: "c.BlockTimestamp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCaller := mock.NewMockEthCaller(ctrl)
+			client, err := newClientWithCaller(mockCaller, timeout)
+			require.NoError(t, err)
+			pairABI := client.(*ethClientImpl).pairABI
+
+			mockCaller.EXPECT().
+				CallContract(gomock.Any(), gomock.Any(), block).
+				DoAndReturn(func(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+					require.Equal(t, pair, *msg.To)
+
+					method, err := pairABI.MethodById(msg.Data[:4])
+					require.NoError(t, err)
+					switch method.Name {
+					case "price0CumulativeLast":
+						return method.Outputs.Pack(big.NewInt(111))
+					case "price1CumulativeLast":
+						return method.Outputs.Pack(big.NewInt(222))
+					case "getReserves":
+						return method.Outputs.Pack(big.NewInt(10000), big.NewInt(20000), uint32(1_700_000_000))
+					}
+					return nil, errors.Errorf("unexpected method %s", method.Name)
+				}).Times(3)
+			mockCaller.EXPECT().
+				HeaderByNumber(gomock.Any(), block).
+				Return(&types.Header{Number: block, Time: 1_700_000_012}, tt.headerErr)
+
+			got, err := client.GetPairObservation(context.Background(), pair, block)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("block number is required", func(t *testing.T) {
+		t.Parallel()
+
+		client, err := newClientWithCaller(callerOnly{}, timeout)
+		require.NoError(t, err)
+
+		_, err = client.GetPairObservation(context.Background(), pair, nil)
+		require.ErrorContains(t, err, "block number is required")
+	})
+}
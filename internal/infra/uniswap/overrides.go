@@ -0,0 +1,106 @@
+package uniswap
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+// OverrideAccount replaces parts of an account's state for the duration of an eth_call.
+type OverrideAccount struct {
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	State   map[common.Hash]common.Hash `json:"state,omitempty"`
+	// StateDiff patches individual storage slots, keeping the others.
+	StateDiff map[common.Hash]common.Hash `json:"stateDiff,omitempty"`
+}
+
+// StateOverride is the state override set of an eth_call, keyed by account.
+type StateOverride map[common.Address]OverrideAccount
+
+// OverrideCaller is implemented by callers that support eth_call state overrides.
+type OverrideCaller interface {
+	CallContractWithOverrides(
+		ctx context.Context,
+		msg ethereum.CallMsg,
+		blockNumber *big.Int,
+		overrides StateOverride,
+	) ([]byte, error)
+}
+
+// CallContractWithOverrides executes the call with state overrides on the first healthy endpoint.
+func (f *failoverCaller) CallContractWithOverrides(
+	ctx context.Context,
+	msg ethereum.CallMsg,
+	blockNumber *big.Int,
+	overrides StateOverride,
+) ([]byte, error) {
+	var combinedErr error
+
+	for _, caller := range *f.callers.Load() {
+		var res hexutil.Bytes
+		err := caller.Client().CallContext(ctx, &res, "eth_call", toCallArg(msg), toBlockNumArg(blockNumber), overrides)
+		if err == nil {
+			return res, nil
+		}
+		combinedErr = multierr.Append(combinedErr, err)
+
+		var rpcErr rpc.Error
+		if ctx.Err() != nil || errors.As(err, &rpcErr) {
+			break
+		}
+	}
+
+	return nil, errors.Wrap(combinedErr, "all endpoints failed")
+}
+
+// callWithOverrides runs a raw call with state overrides, failing when the
+// caller does not support them.
+func (c *ethClientImpl) callWithOverrides(
+	ctx context.Context,
+	msg ethereum.CallMsg,
+	blockNumber *big.Int,
+	overrides StateOverride,
+) ([]byte, error) {
+	oc, ok := c.caller.(OverrideCaller)
+	if !ok {
+		return nil, errors.New("state overrides are not supported by the caller")
+	}
+
+	res, err := oc.CallContractWithOverrides(ctx, msg, blockNumber, overrides)
+	if err != nil {
+		return nil, errors.Wrap(err, "oc.CallContractWithOverrides")
+	}
+
+	return res, nil
+}
+
+func toCallArg(msg ethereum.CallMsg) map[string]any {
+	arg := map[string]any{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["input"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	return arg
+}
+
+func toBlockNumArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	return hexutil.EncodeBig(number)
+}
@@ -0,0 +1,53 @@
+package uniswap
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SortTokens returns the tokens in the order used by Uniswap V2 pairs (token0 < token1).
+func SortTokens(tokenA, tokenB common.Address) (common.Address, common.Address) {
+	if bytes.Compare(tokenA.Bytes(), tokenB.Bytes()) < 0 {
+		return tokenA, tokenB
+	}
+	return tokenB, tokenA
+}
+
+// PairAddress computes the CREATE2 address of the pair that a Uniswap V2 compatible
+// factory deploys for two tokens, as UniswapV2Library.pairFor does.
+func PairAddress(factory, tokenA, tokenB common.Address, initCodeHash common.Hash) common.Address {
+	token0, token1 := SortTokens(tokenA, tokenB)
+	salt := crypto.Keccak256Hash(token0.Bytes(), token1.Bytes())
+
+	return crypto.CreateAddress2(factory, salt, initCodeHash.Bytes())
+}
+
+// V3PoolAddress computes the CREATE2 address of the pool that a Uniswap V3
+// compatible factory deploys for two tokens and a fee, as PoolAddress.computeAddress does.
+func V3PoolAddress(factory, tokenA, tokenB common.Address, fee uint32, initCodeHash common.Hash) common.Address {
+	token0, token1 := SortTokens(tokenA, tokenB)
+	salt := crypto.Keccak256Hash(
+		common.LeftPadBytes(token0.Bytes(), common.HashLength),
+		common.LeftPadBytes(token1.Bytes(), common.HashLength),
+		common.LeftPadBytes(new(big.Int).SetUint64(uint64(fee)).Bytes(), common.HashLength),
+	)
+
+	return crypto.CreateAddress2(factory, salt, initCodeHash.Bytes())
+}
+
+// SolidlyPairAddress computes the CREATE2 address of the pair that a Solidly
+// style factory deploys for two tokens and a curve, as Pool.sol's factories
+// salt it: keccak256(abi.encodePacked(token0, token1, stable)).
+func SolidlyPairAddress(factory, tokenA, tokenB common.Address, stable bool, initCodeHash common.Hash) common.Address {
+	token0, token1 := SortTokens(tokenA, tokenB)
+	flag := []byte{0}
+	if stable {
+		flag[0] = 1
+	}
+	salt := crypto.Keccak256Hash(token0.Bytes(), token1.Bytes(), flag)
+
+	return crypto.CreateAddress2(factory, salt, initCodeHash.Bytes())
+}
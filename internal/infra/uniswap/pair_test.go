@@ -0,0 +1,76 @@
+package uniswap
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPairAddress(t *testing.T) {
+	t.Parallel()
+
+	var (
+		factory      = common.HexToAddress("0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f")
+		initCodeHash = common.HexToHash("0x96e8ac4277198ff8b6f785478aa9a39f403cb768dd02cbee326c3e7da348845f")
+		usdc         = common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+		weth         = common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+		usdcWETH     = common.HexToAddress("0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc")
+	)
+
+	require.Equal(t, usdcWETH, PairAddress(factory, usdc, weth, initCodeHash))
+	require.Equal(t, usdcWETH, PairAddress(factory, weth, usdc, initCodeHash))
+	require.NotEqual(t, usdcWETH, PairAddress(factory, usdc, weth, common.Hash{}))
+}
+
+func TestV3PoolAddress(t *testing.T) {
+	t.Parallel()
+
+	var (
+		factory      = common.HexToAddress("0x1F98431c8aD98523631AE4a59f267346ea31F984")
+		initCodeHash = common.HexToHash("0xe34f199b19b2b4f47f68442619d555527d244f78a3297ea89325f843f87b8b54")
+		usdc         = common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+		weth         = common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+		usdcWETH500  = common.HexToAddress("0x88e6A0c2dDD26FEEb64F039a2c41296FcB3f5640")
+		usdcWETH3000 = common.HexToAddress("0x8ad599c3A0ff1De082011EFDDc58f1908eb6e6D8")
+	)
+
+	require.Equal(t, usdcWETH500, V3PoolAddress(factory, usdc, weth, 500, initCodeHash))
+	require.Equal(t, usdcWETH500, V3PoolAddress(factory, weth, usdc, 500, initCodeHash))
+	require.Equal(t, usdcWETH3000, V3PoolAddress(factory, usdc, weth, 3000, initCodeHash))
+}
+
+func TestSolidlyPairAddress(t *testing.T) {
+	t.Parallel()
+
+	var (
+		factory      = common.HexToAddress("0xfac7")
+		initCodeHash = common.HexToHash("0x01")
+		tokenA       = common.HexToAddress("0x5678")
+		tokenB       = common.HexToAddress("0x1234")
+	)
+
+	// The salt packs both tokens and the curve flag without padding.
+	salt := crypto.Keccak256Hash(tokenB.Bytes(), tokenA.Bytes(), []byte{1})
+	stable := crypto.CreateAddress2(factory, salt, initCodeHash.Bytes())
+
+	require.Equal(t, stable, SolidlyPairAddress(factory, tokenA, tokenB, true, initCodeHash))
+	require.Equal(t, stable, SolidlyPairAddress(factory, tokenB, tokenA, true, initCodeHash))
+	require.NotEqual(t, stable, SolidlyPairAddress(factory, tokenA, tokenB, false, initCodeHash))
+}
+
+func TestSortTokens(t *testing.T) {
+	t.Parallel()
+
+	low := common.HexToAddress("0x01")
+	high := common.HexToAddress("0x02")
+
+	token0, token1 := SortTokens(high, low)
+	require.Equal(t, low, token0)
+	require.Equal(t, high, token1)
+
+	token0, token1 = SortTokens(low, high)
+	require.Equal(t, low, token0)
+	require.Equal(t, high, token1)
+}
@@ -0,0 +1,220 @@
+package uniswap
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+)
+
+const erc20ABIJSON = `[
+	{"inputs":[{"internalType":"address","name":"","type":"address"}],"name":"balanceOf","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"internalType":"address","name":"","type":"address"},{"internalType":"address","name":"","type":"address"}],"name":"allowance","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"decimals","outputs":[{"internalType":"uint8","name":"","type":"uint8"}],"stateMutability":"view","type":"function"}
+]`
+
+// maxMappingIndex bounds the storage slots searched for balance and allowance mappings.
+const maxMappingIndex = 32
+
+var (
+	erc20ABI = mustParseABI(erc20ABIJSON)
+
+	// slotMarker is written to every candidate slot, offset by the candidate's
+	// position, so that a single call tells which slot the token reads.
+	slotMarker = new(big.Int).SetBytes(crypto.Keccak256([]byte("estimator.storage-slot-marker"))[:16])
+
+	// walletCode runs in place of the sender's code. Called with
+	// (token, recipient, router, router calldata), it calls the router,
+	// bubbling up its revert, and returns how much token recipient received.
+	walletCode = buildWallet()
+)
+
+// FindBalanceMapping locates the balance mapping of token by patching every
+// candidate slot for holder and reading balanceOf.
+func (c *ethClientImpl) FindBalanceMapping(ctx context.Context, token, holder common.Address) (*dto.StorageMapping, error) {
+	data, err := erc20ABI.Pack("balanceOf", holder)
+	if err != nil {
+		return nil, errors.Wrap(err, "erc20ABI.Pack")
+	}
+
+	return c.findMapping(ctx, token, data, func(m dto.StorageMapping) common.Hash {
+		return m.BalanceSlot(holder)
+	})
+}
+
+// FindAllowanceMapping locates the allowance mapping of token by patching every
+// candidate slot for owner and spender and reading allowance.
+func (c *ethClientImpl) FindAllowanceMapping(
+	ctx context.Context,
+	token, owner, spender common.Address,
+) (*dto.StorageMapping, error) {
+	data, err := erc20ABI.Pack("allowance", owner, spender)
+	if err != nil {
+		return nil, errors.Wrap(err, "erc20ABI.Pack")
+	}
+
+	return c.findMapping(ctx, token, data, func(m dto.StorageMapping) common.Hash {
+		return m.AllowanceSlot(owner, spender)
+	})
+}
+
+func (c *ethClientImpl) findMapping(
+	ctx context.Context,
+	token common.Address,
+	data []byte,
+	slotOf func(dto.StorageMapping) common.Hash,
+) (*dto.StorageMapping, error) {
+	ctxCall, cancel := context.WithTimeout(ctx, time.Duration(c.callTimeout.Load()))
+	defer cancel()
+
+	candidates := make([]dto.StorageMapping, 0, 2*maxMappingIndex)
+	for i := uint64(0); i < maxMappingIndex; i++ {
+		candidates = append(candidates, dto.StorageMapping{Index: i}, dto.StorageMapping{Index: i, Vyper: true})
+	}
+
+	diff := make(map[common.Hash]common.Hash, len(candidates))
+	for k, m := range candidates {
+		diff[slotOf(m)] = common.BigToHash(new(big.Int).Add(slotMarker, big.NewInt(int64(k))))
+	}
+
+	res, err := c.callWithOverrides(ctxCall, ethereum.CallMsg{To: &token, Data: data}, nil, StateOverride{
+		token: {StateDiff: diff},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "c.callWithOverrides")
+	}
+
+	k := new(big.Int).Sub(new(big.Int).SetBytes(res), slotMarker)
+	if k.Sign() < 0 || k.Cmp(big.NewInt(int64(len(candidates)))) >= 0 {
+		return nil, errors.Errorf("storage mapping of token %s not found", token.Hex())
+	}
+
+	m := candidates[k.Int64()]
+	return &m, nil
+}
+
+// SimulateSwap runs swapExactTokensForTokensSupportingFeeOnTransferTokens from
+// the sender through eth_call and measures what the recipient receives.
+//
+// The sender's code is replaced for the call by a wallet contract that forwards
+// the swap, so tokens that treat contract callers differently may behave
+// differently than for an externally owned sender.
+func (c *ethClientImpl) SimulateSwap(ctx context.Context, swap *dto.SwapSimulation) (*dto.SwapSimulationResult, error) {
+	if len(swap.Path) < 2 {
+		return nil, errors.New("path must have at least two tokens")
+	}
+
+	ctxCall, cancel := context.WithTimeout(ctx, time.Duration(c.callTimeout.Load()))
+	defer cancel()
+
+	swapData, err := c.routerABI.Pack(
+		"swapExactTokensForTokensSupportingFeeOnTransferTokens",
+		swap.AmountIn,
+		swap.AmountOutMin,
+		swap.Path,
+		swap.Recipient,
+		new(big.Int).SetUint64(math.MaxUint64),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "c.routerABI.Pack")
+	}
+
+	data := make([]byte, 0, 3*common.HashLength+len(swapData))
+	data = append(data, common.LeftPadBytes(swap.Path[len(swap.Path)-1].Bytes(), common.HashLength)...)
+	data = append(data, common.LeftPadBytes(swap.Recipient.Bytes(), common.HashLength)...)
+	data = append(data, common.LeftPadBytes(swap.Router.Bytes(), common.HashLength)...)
+	data = append(data, swapData...)
+
+	res, err := c.callWithOverrides(
+		ctxCall,
+		ethereum.CallMsg{From: swap.Sender, To: &swap.Sender, Data: data},
+		nil,
+		StateOverride{
+			swap.Sender:  {Code: walletCode},
+			swap.Path[0]: {StateDiff: swap.Storage},
+		},
+	)
+	if err != nil {
+		var dataErr rpc.DataError
+		if errors.As(err, &dataErr) {
+			return &dto.SwapSimulationResult{Reverted: true, RevertReason: revertReason(dataErr.ErrorData())}, nil
+		}
+		return nil, errors.Wrap(err, "c.callWithOverrides")
+	}
+
+	if len(res) != common.HashLength {
+		return nil, errors.Errorf("unexpected wallet result length %d", len(res))
+	}
+
+	return &dto.SwapSimulationResult{Received: new(big.Int).SetBytes(res)}, nil
+}
+
+// revertReason decodes the revert data attached to an eth_call error.
+func revertReason(errData any) string {
+	s, ok := errData.(string)
+	if !ok {
+		return "execution reverted"
+	}
+
+	data, err := hexutil.Decode(s)
+	if err != nil || len(data) == 0 {
+		return "execution reverted"
+	}
+
+	reason, err := abi.UnpackRevert(data)
+	if err != nil {
+		return "execution reverted: " + s
+	}
+	return reason
+}
+
+// Memory offset of the router calldata forwarded by the wallet.
+const memRouterCall = 0x200
+
+func buildWallet() []byte {
+	a := newAssembler()
+
+	token := func() { a.push(0).op(opCallDataLoad) }
+	recipient := func() { a.push(0x20).op(opCallDataLoad) }
+	callSize := func() { a.push(0x60).op(opCallDataSize, opSub) }
+
+	a.balanceOf(token, recipient)
+	a.push(memOut).op(opMload).push(memReceived).op(opMstore)
+
+	callSize()
+	a.push(0x60).push(memRouterCall).op(opCallDataCopy)
+
+	a.push(0).push(0)
+	callSize()
+	a.push(memRouterCall).push(0)
+	a.push(0x40).op(opCallDataLoad)
+	a.op(opGas, opCall).jumpIfZero("fail")
+
+	a.balanceOf(token, recipient)
+	a.push(memReceived).op(opMload).push(memOut).op(opMload).op(opSub).push(memReceived).op(opMstore)
+
+	a.push(0x20).push(memReceived).op(opReturn)
+
+	a.label("fail").revertWithReturnData()
+
+	return a.bytes()
+}
+
+func mustParseABI(raw string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(raw))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
@@ -0,0 +1,203 @@
+package uniswap
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+)
+
+const selectorAllowance = 0xdd62ed3e
+
+// deployMappingToken installs a read-only token keeping balances at mapping
+// index m.Index and allowances at m.Index+1, laid out as solc or vyper would.
+func (e *evmCaller) deployMappingToken(token common.Address, m dto.StorageMapping) {
+	a := newAssembler()
+
+	// hash stores key and base at 0x00 and 0x20 in layout order and hashes them.
+	hash := func(key, base func()) {
+		first, second := key, base
+		if m.Vyper {
+			first, second = base, key
+		}
+		first()
+		a.push(0).op(opMstore)
+		second()
+		a.push(0x20).op(opMstore)
+		a.push(0x40).push(0).op(opSha3)
+	}
+	arg := func(offset uint64) func() { return func() { a.push(offset).op(opCallDataLoad) } }
+
+	a.push(0).op(opCallDataLoad).push(224).op(opShr)
+	a.op(opDup1).push(selectorBalanceOf).op(opEq).pushLabel("balanceOf").op(opJumpi)
+	a.push(selectorAllowance).op(opEq).pushLabel("allowance").op(opJumpi)
+	a.push(0).push(0).op(opRevert)
+
+	a.label("balanceOf")
+	hash(arg(0x04), func() { a.push(m.Index) })
+	a.pushLabel("return").op(opJump)
+
+	a.label("allowance")
+	hash(arg(0x04), func() { a.push(m.Index + 1) })
+	a.push(0x40).op(opMstore)
+	hash(arg(0x24), func() { a.push(0x40).op(opMload) })
+
+	a.label("return")
+	a.op(opSload).push(0).op(opMstore)
+	a.push(0x20).push(0).op(opReturn)
+
+	e.state.SetCode(token, a.bytes())
+}
+
+// deployRouter installs a router that, when the caller holds at least amountIn
+// of src, transfers out of dst to the recipient and otherwise reverts with
+// Error("TRANSFER_FROM_FAILED").
+func (e *evmCaller) deployRouter(router, src, dst common.Address, out uint64) {
+	a := newAssembler()
+
+	a.balanceOf(func() { a.pushBig(src.Big()) }, func() { a.op(opCaller) })
+	a.push(0x04).op(opCallDataLoad).push(memOut).op(opMload, opLt).pushLabel("short").op(opJumpi)
+
+	a.selector(selectorTransfer)
+	a.push(0x64).op(opCallDataLoad).push(0x04).op(opMstore)
+	a.push(out).push(0x24).op(opMstore)
+	a.push(0).push(0).push(0x44).push(0).push(0)
+	a.pushBig(dst.Big())
+	a.op(opGas, opCall).jumpIfZero("fail")
+	a.op(opStop)
+
+	a.label("short")
+	reason, err := errorABI.Pack("Error", "TRANSFER_FROM_FAILED")
+	if err != nil {
+		panic(err)
+	}
+	reason = common.RightPadBytes(reason, (len(reason)+31)/32*32)
+	for i := 0; i < len(reason); i += 32 {
+		a.pushBig(new(big.Int).SetBytes(reason[i : i+32])).push(uint64(i)).op(opMstore)
+	}
+	a.push(100).push(0).op(opRevert)
+
+	a.label("fail").revertWithReturnData()
+
+	e.state.SetCode(router, a.bytes())
+}
+
+var errorABI = mustParseABI(`[{"inputs":[{"name":"","type":"string"}],"name":"Error","type":"function"}]`)
+
+func TestFindMapping(t *testing.T) {
+	t.Parallel()
+
+	token := common.HexToAddress("0x5678")
+	owner := common.HexToAddress("0xa11ce")
+	spender := common.HexToAddress("0xb0b")
+
+	tests := []struct {
+		name    string
+		mapping dto.StorageMapping
+	}{
+		{name: "solidity", mapping: dto.StorageMapping{Index: 3}},
+		{name: "vyper", mapping: dto.StorageMapping{Index: 5, Vyper: true}},
+		{name: "first slot", mapping: dto.StorageMapping{Index: 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			caller := newEVMCaller(t)
+			caller.deployMappingToken(token, tt.mapping)
+
+			client, err := newClientWithCaller(caller, timeout)
+			require.NoError(t, err)
+
+			balance, err := client.FindBalanceMapping(context.Background(), token, owner)
+			require.NoError(t, err)
+			require.Equal(t, tt.mapping, *balance)
+
+			allowance, err := client.FindAllowanceMapping(context.Background(), token, owner, spender)
+			require.NoError(t, err)
+			require.Equal(t, dto.StorageMapping{Index: tt.mapping.Index + 1, Vyper: tt.mapping.Vyper}, *allowance)
+		})
+	}
+}
+
+func TestFindMapping_NotFound(t *testing.T) {
+	t.Parallel()
+
+	token := common.HexToAddress("0x5678")
+
+	caller := newEVMCaller(t)
+	// The tax token keeps balances at slot = holder, which is not a mapping.
+	caller.deployTaxToken(token, common.Address{}, 0, 0)
+
+	client, err := newClientWithCaller(caller, timeout)
+	require.NoError(t, err)
+
+	_, err = client.FindBalanceMapping(context.Background(), token, common.HexToAddress("0xa11ce"))
+	require.ErrorContains(t, err, "storage mapping of token")
+}
+
+func TestSimulateSwap(t *testing.T) {
+	t.Parallel()
+
+	router := common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D")
+	src := common.HexToAddress("0x5678")
+	dst := common.HexToAddress("0x12345678")
+	sender := common.HexToAddress("0xa11ce")
+	recipient := common.HexToAddress("0xb0b")
+	mapping := dto.StorageMapping{Index: 3}
+
+	tests := []struct {
+		name    string
+		outBps  uint64
+		storage map[common.Hash]common.Hash
+		want    *dto.SwapSimulationResult
+	}{
+		{
+			name:    "plain",
+			storage: map[common.Hash]common.Hash{mapping.BalanceSlot(sender): common.BigToHash(big.NewInt(1000))},
+			want:    &dto.SwapSimulationResult{Received: big.NewInt(5000)},
+		},
+		{
+			name:    "output taxed",
+			outBps:  1000,
+			storage: map[common.Hash]common.Hash{mapping.BalanceSlot(sender): common.BigToHash(big.NewInt(1000))},
+			want:    &dto.SwapSimulationResult{Received: big.NewInt(4500)},
+		},
+		{
+			name: "reverted",
+			want: &dto.SwapSimulationResult{Reverted: true, RevertReason: "TRANSFER_FROM_FAILED"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			caller := newEVMCaller(t)
+			caller.deployMappingToken(src, mapping)
+			caller.deployTaxToken(dst, router, 0, tt.outBps)
+			caller.setTokenBalance(dst, router, big.NewInt(1_000_000))
+			caller.deployRouter(router, src, dst, 5000)
+
+			client, err := newClientWithCaller(caller, timeout)
+			require.NoError(t, err)
+
+			res, err := client.SimulateSwap(context.Background(), &dto.SwapSimulation{
+				Router:       router,
+				Sender:       sender,
+				Recipient:    recipient,
+				Path:         []common.Address{src, dst},
+				AmountIn:     big.NewInt(1000),
+				AmountOutMin: big.NewInt(0),
+				Storage:      tt.storage,
+			})
+			require.NoError(t, err)
+			require.Equal(t, tt.want, res)
+		})
+	}
+}
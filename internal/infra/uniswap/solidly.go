@@ -0,0 +1,133 @@
+package uniswap
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+)
+
+const solidlyPairABIJSON = `[
+	{"inputs":[],"name":"stable","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"metadata","outputs":[{"internalType":"uint256","name":"dec0","type":"uint256"},{"internalType":"uint256","name":"dec1","type":"uint256"},{"internalType":"uint256","name":"r0","type":"uint256"},{"internalType":"uint256","name":"r1","type":"uint256"},{"internalType":"bool","name":"st","type":"bool"},{"internalType":"address","name":"t0","type":"address"},{"internalType":"address","name":"t1","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"internalType":"uint256","name":"amountIn","type":"uint256"},{"internalType":"address","name":"tokenIn","type":"address"}],"name":"getAmountOut","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}
+]`
+
+// solidlyFactoryABIJSON has getFee(address pool, bool stable) of Velodrome V2
+// style factories, and getFee(bool stable) of the older ones, as getFee0.
+const solidlyFactoryABIJSON = `[
+	{"inputs":[{"internalType":"address","name":"","type":"address"},{"internalType":"address","name":"","type":"address"},{"internalType":"bool","name":"","type":"bool"}],"name":"getPair","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"internalType":"address","name":"pool","type":"address"},{"internalType":"bool","name":"_stable","type":"bool"}],"name":"getFee","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"internalType":"bool","name":"_stable","type":"bool"}],"name":"getFee","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}
+]`
+
+// IsSolidlyPair reports whether a contract answers stable(), as Solidly style
+// pairs do. Contracts that revert or return no bool are not Solidly pairs;
+// other errors are returned, as the call may have failed transiently.
+func (c *ethClientImpl) IsSolidlyPair(ctx context.Context, pair common.Address) (bool, error) {
+	out, ok, err := c.probe(ctx, c.solidlyPairABI, pair, nil, "stable")
+	if err != nil || !ok || len(out) == 0 {
+		return false, err
+	}
+
+	_, ok = out[0].(bool)
+	return ok, nil
+}
+
+// GetSolidlyMetadata reads the decimals, reserves, curve and tokens of a
+// Solidly style pair with metadata() at the given block, or the latest one if nil.
+func (c *ethClientImpl) GetSolidlyMetadata(
+	ctx context.Context,
+	pair common.Address,
+	blockNumber *big.Int,
+) (*dto.SolidlyMetadata, error) {
+	out, err := c.callABI(ctx, c.solidlyPairABI, pair, blockNumber, "metadata")
+	if err != nil {
+		return nil, errors.Wrap(err, "c.callABI")
+	}
+
+	if len(out) < 7 {
+		return nil, errors.Errorf("insufficient outputs from metadata call: expected 7, got %d", len(out))
+	}
+
+	m := &dto.SolidlyMetadata{}
+	if blockNumber != nil {
+		m.BlockNumber = blockNumber.Uint64()
+	}
+
+	var ok [7]bool
+	m.Decimals0, ok[0] = out[0].(*big.Int)
+	m.Decimals1, ok[1] = out[1].(*big.Int)
+	m.Reserve0, ok[2] = out[2].(*big.Int)
+	m.Reserve1, ok[3] = out[3].(*big.Int)
+	m.Stable, ok[4] = out[4].(bool)
+	m.Token0, ok[5] = out[5].(common.Address)
+	m.Token1, ok[6] = out[6].(common.Address)
+	for _, v := range ok {
+		if !v {
+			return nil, errors.New("failed to cast metadata outputs")
+		}
+	}
+
+	return m, nil
+}
+
+// GetSolidlyAmountOut calls a Solidly style pair's getAmountOut at the given
+// block, or the latest one if nil.
+func (c *ethClientImpl) GetSolidlyAmountOut(
+	ctx context.Context,
+	pair common.Address,
+	amountIn *big.Int,
+	tokenIn common.Address,
+	blockNumber *big.Int,
+) (*big.Int, error) {
+	out, err := c.callABI(ctx, c.solidlyPairABI, pair, blockNumber, "getAmountOut", amountIn, tokenIn)
+	if err != nil {
+		return nil, errors.Wrap(err, "c.callABI")
+	}
+
+	return firstBigInt(out, "getAmountOut")
+}
+
+// GetSolidlyFee returns the swap fee in basis points that a Solidly style
+// factory charges on a pair at the given block, or the latest one if nil. The
+// fee is read with getFee(pair, stable), which has the pair's custom fee, or
+// with getFee(stable) from factories without custom fees.
+func (c *ethClientImpl) GetSolidlyFee(
+	ctx context.Context,
+	factory, pair common.Address,
+	stable bool,
+	blockNumber *big.Int,
+) (*big.Int, error) {
+	out, ok, err := c.probe(ctx, c.solidlyFactoryABI, factory, blockNumber, "getFee", pair, stable)
+	if err != nil {
+		return nil, errors.Wrap(err, "c.probe")
+	}
+	if ok {
+		return firstBigInt(out, "getFee")
+	}
+
+	out, err = c.callABI(ctx, c.solidlyFactoryABI, factory, blockNumber, "getFee0", stable)
+	if err != nil {
+		return nil, errors.Wrap(err, "c.callABI")
+	}
+	return firstBigInt(out, "getFee")
+}
+
+// GetSolidlyFactoryPair returns the pair registered by a Solidly style factory
+// for two tokens and a curve, or the zero address.
+func (c *ethClientImpl) GetSolidlyFactoryPair(
+	ctx context.Context,
+	factory, tokenA, tokenB common.Address,
+	stable bool,
+) (common.Address, error) {
+	out, err := c.callABI(ctx, c.solidlyFactoryABI, factory, nil, "getPair", tokenA, tokenB, stable)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "c.callABI")
+	}
+
+	return firstAddress(out, "getPair")
+}
@@ -0,0 +1,190 @@
+package uniswap
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+)
+
+func TestIsSolidlyPair(t *testing.T) {
+	t.Parallel()
+
+	pair := common.HexToAddress("0x1234")
+
+	tests := []struct {
+		name   string
+		result func(t *testing.T, c *ethClientImpl) ([]byte, error)
+		want   bool
+	}{
+		{
+			name: "stable pair",
+			result: func(t *testing.T, c *ethClientImpl) ([]byte, error) {
+				out, err := c.solidlyPairABI.Methods["stable"].Outputs.Pack(true)
+				require.NoError(t, err)
+				return out, nil
+			},
+			want: true,
+		},
+		{
+			name: "volatile pair",
+			result: func(t *testing.T, c *ethClientImpl) ([]byte, error) {
+				out, err := c.solidlyPairABI.Methods["stable"].Outputs.Pack(false)
+				require.NoError(t, err)
+				return out, nil
+			},
+			want: true,
+		},
+		{
+			name: "reverts",
+			result: func(*testing.T, *ethClientImpl) ([]byte, error) {
+				return nil, revertError{}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCaller := mock.NewMockEthCaller(ctrl)
+			client, err := newClientWithCaller(mockCaller, timeout)
+			require.NoError(t, err)
+
+			mockCaller.EXPECT().
+				CallContract(gomock.Any(), gomock.Any(), nil).
+				Return(tt.result(t, client.(*ethClientImpl)))
+
+			got, err := client.IsSolidlyPair(context.Background(), pair)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetSolidlyMetadata(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pair := common.HexToAddress("0x1234")
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+	block := big.NewInt(19_000_000)
+
+	mockCaller := mock.NewMockEthCaller(ctrl)
+	client, err := newClientWithCaller(mockCaller, timeout)
+	require.NoError(t, err)
+	pairABI := client.(*ethClientImpl).solidlyPairABI
+
+	mockCaller.EXPECT().
+		CallContract(gomock.Any(), gomock.Any(), block).
+		DoAndReturn(func(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+			require.Equal(t, pair, *msg.To)
+			return pairABI.Methods["metadata"].Outputs.Pack(
+				big.NewInt(1e6), big.NewInt(1e18), big.NewInt(10000), big.NewInt(20000), true, token0, token1,
+			)
+		})
+
+	got, err := client.GetSolidlyMetadata(context.Background(), pair, block)
+	require.NoError(t, err)
+	require.Equal(t, &dto.SolidlyMetadata{
+		BlockNumber: block.Uint64(),
+		Decimals0:   big.NewInt(1e6),
+		Decimals1:   big.NewInt(1e18),
+		Reserve0:    big.NewInt(10000),
+		Reserve1:    big.NewInt(20000),
+		Stable:      true,
+		Token0:      token0,
+		Token1:      token1,
+	}, got)
+}
+
+func TestGetSolidlyFee(t *testing.T) {
+	t.Parallel()
+
+	factory := common.HexToAddress("0xfac7")
+	pair := common.HexToAddress("0x1234")
+	block := big.NewInt(19_000_000)
+
+	tests := []struct {
+		name    string
+		result  func(t *testing.T, factoryABI abi.ABI, msg ethereum.CallMsg) ([]byte, error)
+		want    *big.Int
+		wantErr bool
+	}{
+		{
+			name: "custom fee of the pair",
+			result: func(t *testing.T, factoryABI abi.ABI, msg ethereum.CallMsg) ([]byte, error) {
+				want, err := factoryABI.Pack("getFee", pair, true)
+				require.NoError(t, err)
+				require.Equal(t, want, msg.Data)
+				return factoryABI.Methods["getFee"].Outputs.Pack(big.NewInt(1))
+			},
+			want: big.NewInt(1),
+		},
+		{
+			name: "fee of the curve",
+			result: func(t *testing.T, factoryABI abi.ABI, msg ethereum.CallMsg) ([]byte, error) {
+				if want, err := factoryABI.Pack("getFee", pair, true); err == nil && bytes.Equal(want, msg.Data) {
+					return nil, revertError{}
+				}
+				want, err := factoryABI.Pack("getFee0", true)
+				require.NoError(t, err)
+				require.Equal(t, want, msg.Data)
+				return factoryABI.Methods["getFee0"].Outputs.Pack(big.NewInt(2))
+			},
+			want: big.NewInt(2),
+		},
+		{
+			name: "node error",
+			result: func(*testing.T, abi.ABI, ethereum.CallMsg) ([]byte, error) {
+				return nil, nodeError{code: -32000, message: "header not found"}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCaller := mock.NewMockEthCaller(ctrl)
+			client, err := newClientWithCaller(mockCaller, timeout)
+			require.NoError(t, err)
+			factoryABI := client.(*ethClientImpl).solidlyFactoryABI
+
+			mockCaller.EXPECT().
+				CallContract(gomock.Any(), gomock.Any(), block).
+				DoAndReturn(func(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+					require.Equal(t, factory, *msg.To)
+					return tt.result(t, factoryABI, msg)
+				}).
+				AnyTimes()
+
+			got, err := client.GetSolidlyFee(context.Background(), factory, pair, true, block)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
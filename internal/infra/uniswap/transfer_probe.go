@@ -0,0 +1,142 @@
+package uniswap
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+)
+
+const (
+	selectorBalanceOf = 0x70a08231
+	selectorTransfer  = 0xa9059cbb
+)
+
+// probeRecipient receives the probe transfer. Its code is overridden with
+// transferBackCode, so it is never a real account.
+var probeRecipient = common.HexToAddress("0x00000000000000000000000000000000000fee71")
+
+var (
+	// transferProbeCode runs in place of the pair's code. Called with
+	// (token, recipient, amount), it transfers amount of token from the pair to
+	// recipient, asks recipient to send what it received back to the pair, and
+	// returns (received by recipient, received by pair) measured with balanceOf.
+	transferProbeCode = buildTransferProbe()
+
+	// transferBackCode runs at probeRecipient. Called with (token, amount), it
+	// transfers amount of token to the caller.
+	transferBackCode = buildTransferBack()
+)
+
+// ProbeTransfer simulates a transfer of amount of token out of pair and back,
+// using an eth_call with state overrides, to measure transfer taxes.
+func (c *ethClientImpl) ProbeTransfer(
+	ctx context.Context,
+	pair, token common.Address,
+	amount *big.Int,
+) (*dto.TransferProbe, error) {
+	ctxCall, cancel := context.WithTimeout(ctx, time.Duration(c.callTimeout.Load()))
+	defer cancel()
+
+	data := make([]byte, 0, 3*common.HashLength)
+	data = append(data, common.LeftPadBytes(token.Bytes(), common.HashLength)...)
+	data = append(data, common.LeftPadBytes(probeRecipient.Bytes(), common.HashLength)...)
+	data = append(data, common.LeftPadBytes(amount.Bytes(), common.HashLength)...)
+
+	res, err := c.callWithOverrides(
+		ctxCall,
+		ethereum.CallMsg{To: &pair, Data: data},
+		nil,
+		StateOverride{
+			pair:           {Code: transferProbeCode},
+			probeRecipient: {Code: transferBackCode},
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "c.callWithOverrides")
+	}
+
+	if len(res) != 2*common.HashLength {
+		return nil, errors.Errorf("unexpected probe result length %d", len(res))
+	}
+
+	return &dto.TransferProbe{
+		Sent:     new(big.Int).Set(amount),
+		Received: new(big.Int).SetBytes(res[:common.HashLength]),
+		Returned: new(big.Int).SetBytes(res[common.HashLength:]),
+	}, nil
+}
+
+// Memory layout of the probe: call arguments at 0x00, call output at 0x80,
+// results at 0x100 and 0x120.
+const (
+	memOut      = 0x80
+	memReceived = 0x100
+	memReturned = 0x120
+)
+
+func buildTransferProbe() []byte {
+	a := newAssembler()
+
+	token := func() { a.push(0).op(opCallDataLoad) }
+	recipient := func() { a.push(0x20).op(opCallDataLoad) }
+
+	self := func() { a.op(opAddress) }
+	balanceOf := func(owner func()) { a.balanceOf(token, owner) }
+
+	// received = balanceOf(recipient) after - before.
+	balanceOf(recipient)
+	a.push(memOut).op(opMload).push(memReceived).op(opMstore)
+
+	a.selector(selectorTransfer)
+	recipient()
+	a.push(0x04).op(opMstore)
+	a.push(0x40).op(opCallDataLoad).push(0x24).op(opMstore)
+	a.push(0).push(0).push(0x44).push(0).push(0)
+	token()
+	a.op(opGas, opCall).jumpIfZero("fail")
+
+	balanceOf(recipient)
+	a.push(memReceived).op(opMload).push(memOut).op(opMload).op(opSub).push(memReceived).op(opMstore)
+
+	// returned = balanceOf(pair) after - before.
+	balanceOf(self)
+	a.push(memOut).op(opMload).push(memReturned).op(opMstore)
+
+	token()
+	a.push(0).op(opMstore)
+	a.push(memReceived).op(opMload).push(0x20).op(opMstore)
+	a.push(0).push(0).push(0x40).push(0).push(0)
+	recipient()
+	a.op(opGas, opCall).jumpIfZero("fail")
+
+	balanceOf(self)
+	a.push(memReturned).op(opMload).push(memOut).op(opMload).op(opSub).push(memReturned).op(opMstore)
+
+	a.push(0x40).push(memReceived).op(opReturn)
+
+	a.label("fail").revertWithReturnData()
+
+	return a.bytes()
+}
+
+func buildTransferBack() []byte {
+	a := newAssembler()
+
+	a.selector(selectorTransfer)
+	a.op(opCaller).push(0x04).op(opMstore)
+	a.push(0x20).op(opCallDataLoad).push(0x24).op(opMstore)
+	a.push(0).push(0).push(0x44).push(0).push(0)
+	a.push(0).op(opCallDataLoad)
+	a.op(opGas, opCall).jumpIfZero("fail")
+	a.op(opStop)
+
+	a.label("fail").revertWithReturnData()
+
+	return a.bytes()
+}
@@ -0,0 +1,218 @@
+package uniswap
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/holiman/uint256"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// evmCaller executes calls against an in-memory state, applying state overrides
+// to a copy of it, as a node answering eth_call would.
+type evmCaller struct {
+	state *state.StateDB
+}
+
+func newEVMCaller(t *testing.T) *evmCaller {
+	t.Helper()
+
+	statedb, err := state.New(types.EmptyRootHash, state.NewDatabaseForTesting())
+	require.NoError(t, err)
+
+	return &evmCaller{state: statedb}
+}
+
+func (e *evmCaller) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return e.CallContractWithOverrides(ctx, msg, blockNumber, nil)
+}
+
+func (e *evmCaller) CallContractWithOverrides(
+	_ context.Context,
+	msg ethereum.CallMsg,
+	_ *big.Int,
+	overrides StateOverride,
+) ([]byte, error) {
+	statedb := e.state.Copy()
+	for addr, account := range overrides {
+		if account.Code != nil {
+			statedb.SetCode(addr, account.Code)
+		}
+		if account.Balance != nil {
+			statedb.SetBalance(addr, uint256.MustFromBig(account.Balance.ToInt()), tracing.BalanceChangeUnspecified)
+		}
+		for slot, value := range account.StateDiff {
+			statedb.SetState(addr, slot, value)
+		}
+	}
+
+	ret, _, err := runtime.Call(*msg.To, msg.Data, &runtime.Config{
+		Origin:   msg.From,
+		GasLimit: 10_000_000,
+		State:    statedb,
+	})
+	if err != nil {
+		return nil, revertError{data: ret}
+	}
+	return ret, nil
+}
+
+// revertError mirrors the JSON-RPC error a node returns for a reverted eth_call.
+type revertError struct {
+	data []byte
+}
+
+func (e revertError) Error() string  { return "execution reverted" }
+func (e revertError) ErrorCode() int { return 3 }
+func (e revertError) ErrorData() any { return hexutil.Encode(e.data) }
+
+// nodeError is a JSON-RPC error of the node rather than of the call.
+type nodeError struct {
+	code    int
+	message string
+}
+
+func (e nodeError) Error() string  { return e.message }
+func (e nodeError) ErrorCode() int { return e.code }
+
+func (e *evmCaller) BlockNumber(context.Context) (uint64, error) {
+	return 0, nil
+}
+
+func (e *evmCaller) CodeAt(_ context.Context, account common.Address, _ *big.Int) ([]byte, error) {
+	return e.state.GetCode(account), nil
+}
+
+func (e *evmCaller) FilterLogs(context.Context, ethereum.FilterQuery) ([]types.Log, error) {
+	return nil, nil
+}
+
+func (e *evmCaller) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: number}, nil
+}
+
+// deployTaxToken installs a token that keeps balances at slot = holder address
+// and takes inBps of transfers to pair and outBps of transfers from pair.
+func (e *evmCaller) deployTaxToken(token, pair common.Address, inBps, outBps uint64) {
+	a := newAssembler()
+
+	a.push(0).op(opCallDataLoad).push(224).op(opShr)
+	a.op(opDup1).push(selectorBalanceOf).op(opEq).pushLabel("balanceOf").op(opJumpi)
+	a.push(selectorTransfer).op(opEq).pushLabel("transfer").op(opJumpi)
+	a.push(0).push(0).op(opRevert)
+
+	a.label("balanceOf")
+	a.push(0x04).op(opCallDataLoad, opSload).push(0).op(opMstore)
+	a.push(0x20).push(0).op(opReturn)
+
+	a.label("transfer")
+	// fee = amount * (outBps if sender is pair + inBps if recipient is pair) / 10000.
+	a.push(10000)
+	a.pushBig(pair.Big()).op(opCaller, opEq).push(outBps).op(opMul)
+	a.pushBig(pair.Big()).push(0x04).op(opCallDataLoad, opEq).push(inBps).op(opMul)
+	a.op(opAdd)
+	a.push(0x24).op(opCallDataLoad, opMul, opDiv)
+	// require(balance[sender] >= amount).
+	a.push(0x24).op(opCallDataLoad, opCaller, opSload, opLt).pushLabel("fail").op(opJumpi)
+	a.push(0x24).op(opCallDataLoad, opCaller, opSload, opSub, opCaller, opSstore)
+	a.push(0x24).op(opCallDataLoad, opSub)
+	a.push(0x04).op(opCallDataLoad, opSload, opAdd)
+	a.push(0x04).op(opCallDataLoad, opSstore)
+	a.push(1).push(0).op(opMstore)
+	a.push(0x20).push(0).op(opReturn)
+
+	a.label("fail")
+	a.push(0).push(0).op(opRevert)
+
+	e.state.SetCode(token, a.bytes())
+}
+
+func (e *evmCaller) setTokenBalance(token, holder common.Address, amount *big.Int) {
+	e.state.SetState(token, common.BytesToHash(holder.Bytes()), common.BigToHash(amount))
+}
+
+func TestProbeTransfer(t *testing.T) {
+	t.Parallel()
+
+	pair := common.HexToAddress("0x1234")
+	token := common.HexToAddress("0x5678")
+
+	tests := []struct {
+		name         string
+		inBps        uint64
+		outBps       uint64
+		pairBalance  *big.Int
+		wantReceived int64
+		wantReturned int64
+		wantErr      bool
+	}{
+		{name: "plain token", pairBalance: big.NewInt(1_000_000), wantReceived: 10000, wantReturned: 10000},
+		{name: "taxed both ways", inBps: 500, outBps: 200, pairBalance: big.NewInt(1_000_000), wantReceived: 9800, wantReturned: 9310},
+		{name: "taxed on sell only", inBps: 1000, pairBalance: big.NewInt(1_000_000), wantReceived: 10000, wantReturned: 9000},
+		{name: "insufficient pair balance", pairBalance: big.NewInt(1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			caller := newEVMCaller(t)
+			caller.deployTaxToken(token, pair, tt.inBps, tt.outBps)
+			caller.setTokenBalance(token, pair, tt.pairBalance)
+
+			client, err := newClientWithCaller(caller, timeout)
+			require.NoError(t, err)
+
+			probe, err := client.ProbeTransfer(context.Background(), pair, token, big.NewInt(10000))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, big.NewInt(10000), probe.Sent)
+			require.Equal(t, big.NewInt(tt.wantReceived), probe.Received)
+			require.Equal(t, big.NewInt(tt.wantReturned), probe.Returned)
+		})
+	}
+}
+
+func TestProbeTransfer_NoOverrideSupport(t *testing.T) {
+	t.Parallel()
+
+	client, err := newClientWithCaller(callerOnly{}, timeout)
+	require.NoError(t, err)
+
+	_, err = client.ProbeTransfer(context.Background(), common.Address{}, common.Address{}, big.NewInt(1))
+	require.ErrorContains(t, err, "state overrides are not supported")
+}
+
+type callerOnly struct{}
+
+func (callerOnly) CallContract(context.Context, ethereum.CallMsg, *big.Int) ([]byte, error) {
+	return nil, errors.New("unexpected call")
+}
+
+func (callerOnly) BlockNumber(context.Context) (uint64, error) {
+	return 0, errors.New("unexpected call")
+}
+
+func (callerOnly) CodeAt(context.Context, common.Address, *big.Int) ([]byte, error) {
+	return nil, errors.New("unexpected call")
+}
+
+func (callerOnly) FilterLogs(context.Context, ethereum.FilterQuery) ([]types.Log, error) {
+	return nil, errors.New("unexpected call")
+}
+
+func (callerOnly) HeaderByNumber(context.Context, *big.Int) (*types.Header, error) {
+	return nil, errors.New("unexpected call")
+}
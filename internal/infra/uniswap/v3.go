@@ -0,0 +1,234 @@
+package uniswap
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+)
+
+const v3PoolABIJSON = `[
+	{"inputs":[],"name":"slot0","outputs":[{"internalType":"uint160","name":"sqrtPriceX96","type":"uint160"},{"internalType":"int24","name":"tick","type":"int24"},{"internalType":"uint16","name":"observationIndex","type":"uint16"},{"internalType":"uint16","name":"observationCardinality","type":"uint16"},{"internalType":"uint16","name":"observationCardinalityNext","type":"uint16"},{"internalType":"uint8","name":"feeProtocol","type":"uint8"},{"internalType":"bool","name":"unlocked","type":"bool"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"liquidity","outputs":[{"internalType":"uint128","name":"","type":"uint128"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"fee","outputs":[{"internalType":"uint24","name":"","type":"uint24"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"tickSpacing","outputs":[{"internalType":"int24","name":"","type":"int24"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"internalType":"int16","name":"","type":"int16"}],"name":"tickBitmap","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"internalType":"int24","name":"","type":"int24"}],"name":"ticks","outputs":[{"internalType":"uint128","name":"liquidityGross","type":"uint128"},{"internalType":"int128","name":"liquidityNet","type":"int128"},{"internalType":"uint256","name":"feeGrowthOutside0X128","type":"uint256"},{"internalType":"uint256","name":"feeGrowthOutside1X128","type":"uint256"},{"internalType":"int56","name":"tickCumulativeOutside","type":"int56"},{"internalType":"uint160","name":"secondsPerLiquidityOutsideX128","type":"uint160"},{"internalType":"uint32","name":"secondsOutside","type":"uint32"},{"internalType":"bool","name":"initialized","type":"bool"}],"stateMutability":"view","type":"function"}
+]`
+
+const v3FactoryABIJSON = `[
+	{"inputs":[{"internalType":"address","name":"","type":"address"},{"internalType":"address","name":"","type":"address"},{"internalType":"uint24","name":"","type":"uint24"}],"name":"getPool","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"}
+]`
+
+// maxTickCalls bounds the concurrent ticks() calls of GetV3Ticks.
+const maxTickCalls = 16
+
+// IsV3Pool reports whether a contract answers tickSpacing(), as Uniswap V3
+// pools do. Contracts that revert or return no word are not V3 pools; other
+// errors are returned, as the call may have failed transiently.
+func (c *ethClientImpl) IsV3Pool(ctx context.Context, pool common.Address) (bool, error) {
+	out, ok, err := c.probe(ctx, c.v3PoolABI, pool, nil, "tickSpacing")
+	if err != nil || !ok {
+		return false, err
+	}
+
+	spacing, err := firstBigInt(out, "tickSpacing")
+	if err != nil {
+		return false, nil //nolint:nilerr
+	}
+	return spacing.Sign() > 0, nil
+}
+
+// GetV3PoolState reads slot0, liquidity, fee and tickSpacing of a Uniswap V3
+// pool at the given block, which must be set so that every read sees the same block.
+func (c *ethClientImpl) GetV3PoolState(
+	ctx context.Context,
+	pool common.Address,
+	blockNumber *big.Int,
+) (*dto.V3PoolState, error) {
+	if blockNumber == nil {
+		return nil, errors.New("block number is required")
+	}
+
+	ctxCall, cancel := context.WithTimeout(ctx, time.Duration(c.callTimeout.Load()))
+	defer cancel()
+
+	state := &dto.V3PoolState{BlockNumber: blockNumber.Uint64()}
+
+	read := func(method string, decode func(out []interface{}) error) func() error {
+		return func() error {
+			out, err := c.callABI(ctxCall, c.v3PoolABI, pool, blockNumber, method)
+			if err != nil {
+				return errors.Wrapf(err, "failed to call %s", method)
+			}
+			return decode(out)
+		}
+	}
+
+	reads := []func() error{
+		read("slot0", func(out []interface{}) error {
+			if len(out) < 2 {
+				return errors.Errorf("insufficient outputs from slot0 call: expected 2, got %d", len(out))
+			}
+			sqrtPrice, ok0 := out[0].(*big.Int)
+			tick, ok1 := out[1].(*big.Int)
+			if !ok0 || !ok1 {
+				return errors.New("failed to cast slot0 outputs")
+			}
+			state.SqrtPriceX96, state.Tick = sqrtPrice, int32(tick.Int64())
+			return nil
+		}),
+		read("liquidity", func(out []interface{}) (err error) {
+			state.Liquidity, err = firstBigInt(out, "liquidity")
+			return err
+		}),
+		read("fee", func(out []interface{}) error {
+			fee, err := firstBigInt(out, "fee")
+			if err != nil {
+				return err
+			}
+			state.Fee = uint32(fee.Uint64())
+			return nil
+		}),
+		read("tickSpacing", func(out []interface{}) error {
+			spacing, err := firstBigInt(out, "tickSpacing")
+			if err != nil {
+				return err
+			}
+			state.TickSpacing = int32(spacing.Int64())
+			return nil
+		}),
+	}
+
+	// Every read sets its own fields of state and its own error.
+	errs := make([]error, len(reads))
+	var wg sync.WaitGroup
+	wg.Add(len(reads))
+	for i, read := range reads {
+		go func() {
+			defer wg.Done()
+			errs[i] = read()
+		}()
+	}
+	wg.Wait()
+
+	if err := multierr.Combine(errs...); err != nil {
+		return nil, errors.Wrap(err, "failed to get v3 pool state")
+	}
+	if state.TickSpacing <= 0 {
+		return nil, errors.Errorf("bad tick spacing %d", state.TickSpacing)
+	}
+
+	return state, nil
+}
+
+// GetV3Ticks reads the tick bitmap words from minWord to maxWord of a Uniswap
+// V3 pool and the net liquidity of every tick initialized in them, at the given
+// block, which must be set. The ticks are sorted by index.
+func (c *ethClientImpl) GetV3Ticks(
+	ctx context.Context,
+	pool common.Address,
+	tickSpacing int32,
+	minWord, maxWord int16,
+	blockNumber *big.Int,
+) ([]dto.V3Tick, error) {
+	if blockNumber == nil {
+		return nil, errors.New("block number is required")
+	}
+	if tickSpacing <= 0 || minWord > maxWord {
+		return nil, errors.Errorf("bad tick range: spacing %d, words [%d, %d]", tickSpacing, minWord, maxWord)
+	}
+
+	ctxCall, cancel := context.WithTimeout(ctx, time.Duration(c.callTimeout.Load()))
+	defer cancel()
+
+	words := make([]*big.Int, int(maxWord)-int(minWord)+1)
+	if err := c.forEach(len(words), func(i int) error {
+		word := minWord + int16(i)
+		out, err := c.callABI(ctxCall, c.v3PoolABI, pool, blockNumber, "tickBitmap", word)
+		if err != nil {
+			return errors.Wrapf(err, "failed to call tickBitmap(%d)", word)
+		}
+		words[i], err = firstBigInt(out, "tickBitmap")
+		return err
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to get tick bitmap")
+	}
+
+	var ticks []dto.V3Tick
+	for i, bitmap := range words {
+		for bit := 0; bit < 256; bit++ {
+			if bitmap.Bit(bit) == 0 {
+				continue
+			}
+			compressed := (int32(minWord)+int32(i))*256 + int32(bit)
+			ticks = append(ticks, dto.V3Tick{Index: compressed * tickSpacing})
+		}
+	}
+
+	if err := c.forEach(len(ticks), func(i int) error {
+		index := ticks[i].Index
+		out, err := c.callABI(ctxCall, c.v3PoolABI, pool, blockNumber, "ticks", big.NewInt(int64(index)))
+		if err != nil {
+			return errors.Wrapf(err, "failed to call ticks(%d)", index)
+		}
+		if len(out) < 2 {
+			return errors.Errorf("insufficient outputs from ticks call: expected 2, got %d", len(out))
+		}
+		net, ok := out[1].(*big.Int)
+		if !ok {
+			return errors.New("failed to cast liquidityNet to *big.Int")
+		}
+		ticks[i].LiquidityNet = net
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to get ticks")
+	}
+
+	sort.Slice(ticks, func(i, j int) bool { return ticks[i].Index < ticks[j].Index })
+	return ticks, nil
+}
+
+// forEach calls fn for 0..n-1 with at most maxTickCalls calls at a time and
+// combines their errors.
+func (c *ethClientImpl) forEach(n int, fn func(i int) error) error {
+	errs := make([]error, n)
+	sem := make(chan struct{}, maxTickCalls)
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = fn(i)
+		}()
+	}
+	wg.Wait()
+
+	return multierr.Combine(errs...)
+}
+
+// GetV3FactoryPool returns the pool registered by a Uniswap V3 factory for two
+// tokens and a fee, or the zero address.
+func (c *ethClientImpl) GetV3FactoryPool(
+	ctx context.Context,
+	factory, tokenA, tokenB common.Address,
+	fee uint32,
+) (common.Address, error) {
+	out, err := c.callABI(ctx, c.v3FactoryABI, factory, nil, "getPool", tokenA, tokenB, new(big.Int).SetUint64(uint64(fee)))
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "c.callABI")
+	}
+
+	return firstAddress(out, "getPool")
+}
@@ -0,0 +1,203 @@
+package uniswap
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+)
+
+func TestIsV3Pool(t *testing.T) {
+	t.Parallel()
+
+	pool := common.HexToAddress("0x1234")
+
+	tests := []struct {
+		name    string
+		result  func(t *testing.T, c *ethClientImpl) ([]byte, error)
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "v3 pool",
+			result: func(t *testing.T, c *ethClientImpl) ([]byte, error) {
+				out, err := c.v3PoolABI.Methods["tickSpacing"].Outputs.Pack(big.NewInt(60))
+				require.NoError(t, err)
+				return out, nil
+			},
+			want: true,
+		},
+		{
+			name: "reverts",
+			result: func(*testing.T, *ethClientImpl) ([]byte, error) {
+				return nil, revertError{}
+			},
+		},
+		{
+			name: "no code",
+			result: func(*testing.T, *ethClientImpl) ([]byte, error) {
+				return nil, nil
+			},
+		},
+		{
+			name: "reverts without data",
+			result: func(*testing.T, *ethClientImpl) ([]byte, error) {
+				return nil, nodeError{code: -32000, message: "execution reverted"}
+			},
+		},
+		{
+			name: "transport error",
+			result: func(*testing.T, *ethClientImpl) ([]byte, error) {
+				return nil, errors.New("connection refused")
+			},
+			wantErr: true,
+		},
+		{
+			name: "node error",
+			result: func(*testing.T, *ethClientImpl) ([]byte, error) {
+				return nil, nodeError{code: -32000, message: "header not found"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "truncated output",
+			result: func(*testing.T, *ethClientImpl) ([]byte, error) {
+				return []byte{0x3c}, nil
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCaller := mock.NewMockEthCaller(ctrl)
+			client, err := newClientWithCaller(mockCaller, timeout)
+			require.NoError(t, err)
+
+			mockCaller.EXPECT().
+				CallContract(gomock.Any(), gomock.Any(), nil).
+				Return(tt.result(t, client.(*ethClientImpl)))
+
+			got, err := client.IsV3Pool(context.Background(), pool)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetV3PoolState(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pool := common.HexToAddress("0x1234")
+	block := big.NewInt(19_000_000)
+	sqrtPrice, _ := new(big.Int).SetString("1771595571142957166518320255467520", 10)
+
+	mockCaller := mock.NewMockEthCaller(ctrl)
+	client, err := newClientWithCaller(mockCaller, timeout)
+	require.NoError(t, err)
+	poolABI := client.(*ethClientImpl).v3PoolABI
+
+	mockCaller.EXPECT().
+		CallContract(gomock.Any(), gomock.Any(), block).
+		DoAndReturn(func(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+			require.Equal(t, pool, *msg.To)
+
+			method, err := poolABI.MethodById(msg.Data[:4])
+			require.NoError(t, err)
+			switch method.Name {
+			case "slot0":
+				return method.Outputs.Pack(sqrtPrice, big.NewInt(-200_101), uint16(1), uint16(2), uint16(3), uint8(0), true)
+			case "liquidity":
+				return method.Outputs.Pack(big.NewInt(5_000_000))
+			case "fee":
+				return method.Outputs.Pack(big.NewInt(3000))
+			case "tickSpacing":
+				return method.Outputs.Pack(big.NewInt(60))
+			}
+			return nil, errors.Errorf("unexpected method %s", method.Name)
+		}).Times(4)
+
+	got, err := client.GetV3PoolState(context.Background(), pool, block)
+	require.NoError(t, err)
+	require.Equal(t, &dto.V3PoolState{
+		BlockNumber:  block.Uint64(),
+		SqrtPriceX96: sqrtPrice,
+		Tick:         -200_101,
+		Liquidity:    big.NewInt(5_000_000),
+		Fee:          3000,
+		TickSpacing:  60,
+	}, got)
+
+	_, err = client.GetV3PoolState(context.Background(), pool, nil)
+	require.ErrorContains(t, err, "block number is required")
+}
+
+func TestGetV3Ticks(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pool := common.HexToAddress("0x1234")
+	block := big.NewInt(19_000_000)
+
+	mockCaller := mock.NewMockEthCaller(ctrl)
+	client, err := newClientWithCaller(mockCaller, timeout)
+	require.NoError(t, err)
+	poolABI := client.(*ethClientImpl).v3PoolABI
+
+	// Word -1 has bit 255 (tick -60), word 0 bits 0 and 2 (ticks 0 and 120).
+	bitmaps := map[int16]*big.Int{
+		-1: new(big.Int).Lsh(big.NewInt(1), 255),
+		0:  big.NewInt(0b101),
+		1:  new(big.Int),
+	}
+	nets := map[int64]*big.Int{-60: big.NewInt(100), 0: big.NewInt(-40), 120: big.NewInt(-60)}
+
+	mockCaller.EXPECT().
+		CallContract(gomock.Any(), gomock.Any(), block).
+		DoAndReturn(func(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+			method, err := poolABI.MethodById(msg.Data[:4])
+			require.NoError(t, err)
+			args, err := method.Inputs.Unpack(msg.Data[4:])
+			require.NoError(t, err)
+
+			switch method.Name {
+			case "tickBitmap":
+				return method.Outputs.Pack(bitmaps[args[0].(int16)])
+			case "ticks":
+				net := nets[args[0].(*big.Int).Int64()]
+				zero := new(big.Int)
+				return method.Outputs.Pack(new(big.Int).Abs(net), net, zero, zero, zero, zero, uint32(0), true)
+			}
+			return nil, errors.Errorf("unexpected method %s", method.Name)
+		}).Times(6)
+
+	got, err := client.GetV3Ticks(context.Background(), pool, 60, -1, 1, block)
+	require.NoError(t, err)
+	require.Equal(t, []dto.V3Tick{
+		{Index: -60, LiquidityNet: big.NewInt(100)},
+		{Index: 0, LiquidityNet: big.NewInt(-40)},
+		{Index: 120, LiquidityNet: big.NewInt(-60)},
+	}, got)
+}
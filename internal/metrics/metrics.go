@@ -0,0 +1,57 @@
+// Package metrics keeps the service counters, published through expvar
+// and served to admins at /debug/vars.
+package metrics
+
+import (
+	"expvar"
+	"strconv"
+)
+
+// Cross-check results.
+const (
+	CrossCheckMatch    = "match"
+	CrossCheckMismatch = "mismatch"
+	CrossCheckError    = "error"
+)
+
+// crossChecks counts on-chain cross-checks of quotes by "<chain>.<result>".
+var crossChecks = expvar.NewMap("cross_checks")
+
+// RecordCrossCheck counts a cross-check of a quote on chain with the given result.
+func RecordCrossCheck(chain, result string) {
+	crossChecks.Add(chain+"."+result, 1)
+}
+
+// Sources of past reserves.
+const (
+	ReserveReadIndex = "index"
+	ReserveReadRPC   = "rpc"
+)
+
+// reserveReads counts reads of indexed pairs' past reserves by "<chain>.<source>".
+var reserveReads = expvar.NewMap("indexed_reserve_reads")
+
+// RecordReserveRead counts a read of an indexed pair's past reserves on chain
+// from the given source.
+func RecordReserveRead(chain, source string) {
+	reserveReads.Add(chain+"."+source, 1)
+}
+
+// indexedBlocks holds the last block indexed on each chain.
+var indexedBlocks = expvar.NewMap("indexed_blocks")
+
+// RecordIndexedBlock sets the last block indexed on chain.
+func RecordIndexedBlock(chain string, block uint64) {
+	v := new(expvar.Int)
+	v.Set(int64(block)) //nolint:gosec
+	indexedBlocks.Set(chain, v)
+}
+
+// reorgs counts the chain reorganisations seen by the indexers by
+// "<chain>.<depth>", the number of blocks rolled back.
+var reorgs = expvar.NewMap("reorgs")
+
+// RecordReorg counts a reorganisation of chain that rolled back depth blocks.
+func RecordReorg(chain string, depth int) {
+	reorgs.Add(chain+"."+strconv.Itoa(depth), 1)
+}
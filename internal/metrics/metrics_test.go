@@ -0,0 +1,19 @@
+package metrics
+
+import (
+	"expvar"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordCrossCheck(t *testing.T) {
+	t.Parallel()
+
+	RecordCrossCheck("testchain", CrossCheckMismatch)
+	RecordCrossCheck("testchain", CrossCheckMismatch)
+
+	v, ok := expvar.Get("cross_checks").(*expvar.Map).Get("testchain." + CrossCheckMismatch).(*expvar.Int)
+	require.True(t, ok)
+	require.Equal(t, int64(2), v.Value())
+}
@@ -0,0 +1,127 @@
+package safety
+
+import (
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+)
+
+// Rules lists the pools and tokens that may or may not be quoted on a chain.
+type Rules struct {
+	ChainID        uint64
+	PoolAllowlist  []common.Address
+	PoolDenylist   []common.Address
+	TokenAllowlist []common.Address
+	TokenDenylist  []common.Address
+	// TokenListPath is an optional Uniswap token list JSON file whose tokens
+	// for ChainID are added to the token allowlist.
+	TokenListPath string
+}
+
+// Policy decides whether a pool or token may be quoted.
+// Denylists always win; a non-empty allowlist rejects everything not on it.
+type Policy struct {
+	poolAllow  addressSet
+	poolDeny   addressSet
+	tokenAllow addressSet
+	tokenDeny  addressSet
+}
+
+// NewPolicy builds a Policy from rules, reading the token list from disk if set.
+func NewPolicy(rules Rules) (*Policy, error) {
+	tokenAllow := rules.TokenAllowlist
+	if rules.TokenListPath != "" {
+		listed, err := LoadTokenList(rules.TokenListPath, rules.ChainID)
+		if err != nil {
+			return nil, errors.Wrap(err, "LoadTokenList")
+		}
+		if len(listed) == 0 {
+			return nil, errors.Errorf("token list %s has no tokens for chain %d", rules.TokenListPath, rules.ChainID)
+		}
+		tokenAllow = append(append([]common.Address(nil), tokenAllow...), listed...)
+	}
+
+	return &Policy{
+		poolAllow:  newAddressSet(rules.PoolAllowlist),
+		poolDeny:   newAddressSet(rules.PoolDenylist),
+		tokenAllow: newAddressSet(tokenAllow),
+		tokenDeny:  newAddressSet(rules.TokenDenylist),
+	}, nil
+}
+
+// CheckPool returns apperrors.ErrNotAllowed if the pool may not be quoted.
+func (p *Policy) CheckPool(pool common.Address) error {
+	if !allowed(pool, p.poolAllow, p.poolDeny) {
+		return errors.Wrapf(apperrors.ErrNotAllowed, "pool %s", pool.Hex())
+	}
+	return nil
+}
+
+// CheckTokens returns apperrors.ErrNotAllowed if any of the tokens may not be quoted.
+func (p *Policy) CheckTokens(tokens ...common.Address) error {
+	for _, token := range tokens {
+		if !allowed(token, p.tokenAllow, p.tokenDeny) {
+			return errors.Wrapf(apperrors.ErrNotAllowed, "token %s", token.Hex())
+		}
+	}
+	return nil
+}
+
+func allowed(addr common.Address, allow, deny addressSet) bool {
+	if deny.has(addr) {
+		return false
+	}
+	return len(allow) == 0 || allow.has(addr)
+}
+
+// Guard holds the active Policy of a chain and replaces it atomically.
+// A Guard without a policy allows everything.
+type Guard struct {
+	policy atomic.Pointer[Policy]
+}
+
+// NewGuard creates a Guard enforcing p.
+func NewGuard(p *Policy) *Guard {
+	g := &Guard{}
+	g.Store(p)
+	return g
+}
+
+// Store activates p.
+func (g *Guard) Store(p *Policy) {
+	g.policy.Store(p)
+}
+
+// CheckPool checks the pool against the active policy.
+func (g *Guard) CheckPool(pool common.Address) error {
+	if p := g.policy.Load(); p != nil {
+		return p.CheckPool(pool)
+	}
+	return nil
+}
+
+// CheckTokens checks the tokens against the active policy.
+func (g *Guard) CheckTokens(tokens ...common.Address) error {
+	if p := g.policy.Load(); p != nil {
+		return p.CheckTokens(tokens...)
+	}
+	return nil
+}
+
+type addressSet map[common.Address]struct{}
+
+func newAddressSet(addrs []common.Address) addressSet {
+	set := make(addressSet, len(addrs))
+	for _, addr := range addrs {
+		set[addr] = struct{}{}
+	}
+	return set
+}
+
+func (s addressSet) has(addr common.Address) bool {
+	_, ok := s[addr]
+	return ok
+}
@@ -0,0 +1,156 @@
+package safety
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+)
+
+var (
+	poolA  = common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	poolB  = common.HexToAddress("0x00000000000000000000000000000000000000a2")
+	tokenA = common.HexToAddress("0x00000000000000000000000000000000000000b1")
+	tokenB = common.HexToAddress("0x00000000000000000000000000000000000000b2")
+	tokenC = common.HexToAddress("0x00000000000000000000000000000000000000b3")
+)
+
+const tokenListJSON = `{
+	"name": "Test List",
+	"tokens": [
+		{"chainId": 1, "address": "0x00000000000000000000000000000000000000b1", "symbol": "A", "decimals": 18},
+		{"chainId": 1, "address": "0x00000000000000000000000000000000000000B2", "symbol": "B", "decimals": 6},
+		{"chainId": 56, "address": "0x00000000000000000000000000000000000000b3", "symbol": "C", "decimals": 18}
+	]
+}`
+
+func writeTokenList(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		rules      Rules
+		pool       common.Address
+		tokens     []common.Address
+		wantPool   assert.ErrorAssertionFunc
+		wantTokens assert.ErrorAssertionFunc
+	}{
+		{
+			name:       "empty rules allow everything",
+			pool:       poolA,
+			tokens:     []common.Address{tokenA, tokenC},
+			wantPool:   assert.NoError,
+			wantTokens: assert.NoError,
+		},
+		{
+			name:       "denylists",
+			rules:      Rules{PoolDenylist: []common.Address{poolA}, TokenDenylist: []common.Address{tokenC}},
+			pool:       poolA,
+			tokens:     []common.Address{tokenA, tokenC},
+			wantPool:   assert.Error,
+			wantTokens: assert.Error,
+		},
+		{
+			name:       "allowlists reject unlisted",
+			rules:      Rules{PoolAllowlist: []common.Address{poolA}, TokenAllowlist: []common.Address{tokenA}},
+			pool:       poolB,
+			tokens:     []common.Address{tokenA, tokenB},
+			wantPool:   assert.Error,
+			wantTokens: assert.Error,
+		},
+		{
+			name:       "allowlists accept listed",
+			rules:      Rules{PoolAllowlist: []common.Address{poolA}, TokenAllowlist: []common.Address{tokenA, tokenB}},
+			pool:       poolA,
+			tokens:     []common.Address{tokenA, tokenB},
+			wantPool:   assert.NoError,
+			wantTokens: assert.NoError,
+		},
+		{
+			name: "deny wins over allow",
+			rules: Rules{
+				PoolAllowlist: []common.Address{poolA}, PoolDenylist: []common.Address{poolA},
+				TokenAllowlist: []common.Address{tokenA}, TokenDenylist: []common.Address{tokenA},
+			},
+			pool:       poolA,
+			tokens:     []common.Address{tokenA},
+			wantPool:   assert.Error,
+			wantTokens: assert.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			policy, err := NewPolicy(tt.rules)
+			require.NoError(t, err)
+
+			err = policy.CheckPool(tt.pool)
+			tt.wantPool(t, err)
+			if err != nil {
+				require.ErrorIs(t, err, apperrors.ErrNotAllowed)
+			}
+
+			err = policy.CheckTokens(tt.tokens...)
+			tt.wantTokens(t, err)
+			if err != nil {
+				require.ErrorIs(t, err, apperrors.ErrNotAllowed)
+			}
+		})
+	}
+}
+
+func TestPolicy_TokenList(t *testing.T) {
+	t.Parallel()
+
+	path := writeTokenList(t, tokenListJSON)
+
+	policy, err := NewPolicy(Rules{ChainID: 1, TokenListPath: path})
+	require.NoError(t, err)
+
+	require.NoError(t, policy.CheckTokens(tokenA, tokenB))
+	require.ErrorIs(t, policy.CheckTokens(tokenC), apperrors.ErrNotAllowed)
+
+	_, err = NewPolicy(Rules{ChainID: 10, TokenListPath: path})
+	require.Error(t, err, "list without tokens for the chain")
+
+	_, err = NewPolicy(Rules{ChainID: 1, TokenListPath: writeTokenList(t, `{"tokens": [`)})
+	require.Error(t, err)
+
+	_, err = NewPolicy(Rules{ChainID: 1, TokenListPath: writeTokenList(t, `{"tokens": [{"chainId": 1, "address": "0x12"}]}`)})
+	require.Error(t, err)
+
+	_, err = NewPolicy(Rules{ChainID: 1, TokenListPath: filepath.Join(t.TempDir(), "missing.json")})
+	require.Error(t, err)
+}
+
+func TestGuard(t *testing.T) {
+	t.Parallel()
+
+	guard := NewGuard(nil)
+	require.NoError(t, guard.CheckPool(poolA))
+	require.NoError(t, guard.CheckTokens(tokenA))
+
+	policy, err := NewPolicy(Rules{PoolDenylist: []common.Address{poolA}, TokenDenylist: []common.Address{tokenA}})
+	require.NoError(t, err)
+
+	guard.Store(policy)
+	require.ErrorIs(t, guard.CheckPool(poolA), apperrors.ErrNotAllowed)
+	require.ErrorIs(t, guard.CheckTokens(tokenA), apperrors.ErrNotAllowed)
+	require.NoError(t, guard.CheckPool(poolB))
+}
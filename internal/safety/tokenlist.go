@@ -0,0 +1,46 @@
+package safety
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// tokenList is the subset of the Uniswap token list format (https://tokenlists.org) used here.
+type tokenList struct {
+	Name   string `json:"name"`
+	Tokens []struct {
+		ChainID uint64 `json:"chainId"`
+		Address string `json:"address"`
+		Symbol  string `json:"symbol"`
+	} `json:"tokens"`
+}
+
+// LoadTokenList reads a Uniswap format token list and returns the addresses listed for chainID.
+func LoadTokenList(path string, chainID uint64) ([]common.Address, error) {
+	//nolint:gosec
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "os.ReadFile")
+	}
+
+	var list tokenList
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, errors.Wrap(err, "json.Unmarshal")
+	}
+
+	var out []common.Address
+	for _, token := range list.Tokens {
+		if token.ChainID != chainID {
+			continue
+		}
+		if !common.IsHexAddress(token.Address) {
+			return nil, errors.Errorf("token %s: bad address %q", token.Symbol, token.Address)
+		}
+		out = append(out, common.HexToAddress(token.Address))
+	}
+
+	return out, nil
+}
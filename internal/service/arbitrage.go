@@ -0,0 +1,349 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"math"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+	"github.com/fleshka4/1inch-test-task/internal/dexmath"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+)
+
+// ArbitrageConfig configures an ArbitrageScanner.
+type ArbitrageConfig struct {
+	Interval time.Duration
+	// MinProfitBps is the least profit, relative to the input, that is reported.
+	MinProfitBps uint32
+	// MaxCycleLength is the largest number of swaps in a cycle.
+	MaxCycleLength int
+}
+
+// subscriberBuffer is the number of opportunities buffered per subscriber.
+// Opportunities are dropped for subscribers that fall further behind.
+const subscriberBuffer = 64
+
+// ArbitrageScanner periodically reads the tracked pools of every chain at a
+// single block and finds the cycles of swaps through them that end with more
+// of the starting token than they began with: triangular cycles and pairs of
+// pools of the same tokens on different forks alike.
+type ArbitrageScanner struct {
+	svc *EstimatorService
+	cfg ArbitrageConfig
+
+	mu        sync.RWMutex
+	latest    map[uint64][]dto.ArbitrageOpportunity
+	lastBlock map[uint64]uint64
+	subs      map[chan dto.ArbitrageOpportunity]struct{}
+
+	// tokens caches the immutable tokens of tracked pools.
+	tokens sync.Map
+}
+
+// NewArbitrageScanner creates a scanner of the tracked pools of svc's chains.
+func NewArbitrageScanner(svc *EstimatorService, cfg ArbitrageConfig) *ArbitrageScanner {
+	return &ArbitrageScanner{
+		svc:       svc,
+		cfg:       cfg,
+		latest:    make(map[uint64][]dto.ArbitrageOpportunity),
+		lastBlock: make(map[uint64]uint64),
+		subs:      make(map[chan dto.ArbitrageOpportunity]struct{}),
+	}
+}
+
+// Run scans every cfg.Interval until ctx is done.
+func (a *ArbitrageScanner) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		a.scan(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Opportunities implements ArbitrageFeed.
+func (a *ArbitrageScanner) Opportunities(chainID uint64) []dto.ArbitrageOpportunity {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if chainID != 0 {
+		return append([]dto.ArbitrageOpportunity(nil), a.latest[chainID]...)
+	}
+
+	var out []dto.ArbitrageOpportunity
+	for _, opps := range a.latest {
+		out = append(out, opps...)
+	}
+	sortOpportunities(out)
+	return out
+}
+
+// Subscribe implements ArbitrageFeed.
+func (a *ArbitrageScanner) Subscribe() (<-chan dto.ArbitrageOpportunity, func()) {
+	ch := make(chan dto.ArbitrageOpportunity, subscriberBuffer)
+
+	a.mu.Lock()
+	a.subs[ch] = struct{}{}
+	a.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			a.mu.Lock()
+			delete(a.subs, ch)
+			a.mu.Unlock()
+		})
+	}
+}
+
+func (a *ArbitrageScanner) scan(ctx context.Context) {
+	for _, chain := range a.svc.chains {
+		if len(chain.TrackedPools) == 0 {
+			continue
+		}
+
+		block, opps, err := a.scanChain(ctx, chain)
+		if err != nil {
+			log.Printf("arbitrage scan of chain %d: %v", chain.ID, err)
+			continue
+		}
+		a.publish(chain.ID, block, opps)
+	}
+}
+
+// publish replaces the chain's opportunities and streams them, unless the
+// chain has not advanced since the last scan.
+func (a *ArbitrageScanner) publish(chainID, block uint64, opps []dto.ArbitrageOpportunity) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if last, ok := a.lastBlock[chainID]; ok && last == block {
+		return
+	}
+	a.lastBlock[chainID] = block
+	a.latest[chainID] = opps
+
+	for ch := range a.subs {
+		for _, opp := range opps {
+			select {
+			case ch <- opp:
+			default:
+			}
+		}
+	}
+}
+
+// trackedPool is a tracked pool usable in cycles.
+type trackedPool struct {
+	address        common.Address
+	token0, token1 common.Address
+	state          dexmath.PoolState
+}
+
+// edge is a swap from one token to another through a tracked pool.
+type edge struct {
+	pool int
+	to   common.Address
+	dir  dexmath.Direction
+}
+
+func (a *ArbitrageScanner) scanChain(ctx context.Context, chain *Chain) (uint64, []dto.ArbitrageOpportunity, error) {
+	block, err := chain.Client.BlockNumber(ctx)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "chain.Client.BlockNumber")
+	}
+
+	pools := a.loadPools(ctx, chain, new(big.Int).SetUint64(block))
+
+	graph := make(map[common.Address][]edge)
+	for i, p := range pools {
+		graph[p.token0] = append(graph[p.token0], edge{pool: i, to: p.token1, dir: dexmath.ZeroForOne})
+		graph[p.token1] = append(graph[p.token1], edge{pool: i, to: p.token0, dir: dexmath.OneForZero})
+	}
+
+	var opps []dto.ArbitrageOpportunity
+	for start := range graph {
+		a.findCycles(graph, start, func(cycle []edge) {
+			if opp, ok := a.evaluate(pools, start, cycle); ok {
+				opp.ChainID = chain.ID
+				opp.BlockNumber = block
+				opps = append(opps, opp)
+			}
+		})
+	}
+	sortOpportunities(opps)
+
+	return block, opps, nil
+}
+
+// loadPools reads the tracked pools of a chain, skipping those the chain's
+// policies would not quote and those with taxed or rebasing tokens.
+func (a *ArbitrageScanner) loadPools(ctx context.Context, chain *Chain, block *big.Int) []trackedPool {
+	pools := make([]trackedPool, 0, len(chain.TrackedPools))
+
+	for _, address := range chain.TrackedPools {
+		p, err := a.loadPool(ctx, chain, address, block)
+		if err != nil {
+			log.Printf("arbitrage scan of chain %d: pool %s: %v", chain.ID, address.Hex(), err)
+			continue
+		}
+		if p != nil {
+			pools = append(pools, *p)
+		}
+	}
+
+	return pools
+}
+
+func (a *ArbitrageScanner) loadPool(
+	ctx context.Context,
+	chain *Chain,
+	address common.Address,
+	block *big.Int,
+) (*trackedPool, error) {
+	key := verdictKey{chainID: chain.ID, pair: address}
+
+	var tokens [2]common.Address
+	if v, ok := a.tokens.Load(key); ok {
+		tokens = v.([2]common.Address)
+	} else {
+		token0, token1, err := chain.Client.GetPairTokens(ctx, address)
+		if err != nil {
+			return nil, errors.Wrap(err, "chain.Client.GetPairTokens")
+		}
+		tokens = [2]common.Address{token0, token1}
+		a.tokens.Store(key, tokens)
+	}
+
+	resolved, err := a.svc.resolvePool(ctx, chain, address, tokens[0], tokens[1], &tokens)
+	switch {
+	case errors.Is(err, apperrors.ErrNotAllowed), errors.Is(err, apperrors.ErrUnverifiedPool):
+		// Denied and unverified pools are left out of the graph.
+		return nil, nil
+	case err != nil:
+		return nil, errors.Wrap(err, "a.svc.resolvePool")
+	}
+
+	reserves, err := chain.Client.GetPairReservesAt(ctx, address, block)
+	if err != nil {
+		return nil, errors.Wrap(err, "chain.Client.GetPairReservesAt")
+	}
+	r0, r1 := reserves.Reserve0, reserves.Reserve1
+
+	for i, token := range tokens {
+		reserve := r0
+		if i == 1 {
+			reserve = r1
+		}
+		b := a.svc.tokenBehaviour(ctx, chain, address, token, reserve)
+		if b.InputTaxBps > 0 || b.OutputTaxBps > 0 || b.Rebasing {
+			return nil, nil
+		}
+	}
+
+	return &trackedPool{
+		address: address,
+		token0:  tokens[0],
+		token1:  tokens[1],
+		state:   dexmath.PoolState{Reserve0: r0, Reserve1: r1, FeeBps: resolved.feeBps},
+	}, nil
+}
+
+// findCycles calls fn with every cycle of at most cfg.MaxCycleLength swaps
+// that starts and ends at start, uses each pool once and visits each other
+// token once. Each cycle is reported only from its smallest token.
+func (a *ArbitrageScanner) findCycles(graph map[common.Address][]edge, start common.Address, fn func([]edge)) {
+	var (
+		path    []edge
+		visited = map[common.Address]bool{start: true}
+		used    = make(map[int]bool)
+	)
+
+	var walk func(at common.Address)
+	walk = func(at common.Address) {
+		for _, e := range graph[at] {
+			if used[e.pool] {
+				continue
+			}
+
+			if e.to == start {
+				if len(path) >= 1 {
+					fn(append(append([]edge(nil), path...), e))
+				}
+				continue
+			}
+			if visited[e.to] || bytes.Compare(e.to.Bytes(), start.Bytes()) < 0 || len(path)+2 > a.cfg.MaxCycleLength {
+				continue
+			}
+
+			used[e.pool], visited[e.to] = true, true
+			path = append(path, e)
+			walk(e.to)
+			path = path[:len(path)-1]
+			used[e.pool], visited[e.to] = false, false
+		}
+	}
+	walk(start)
+}
+
+// evaluate finds the optimal input of a cycle and reports it if the profit
+// reaches cfg.MinProfitBps.
+func (a *ArbitrageScanner) evaluate(pools []trackedPool, start common.Address, cycle []edge) (dto.ArbitrageOpportunity, bool) {
+	hops := make([]dexmath.Hop, len(cycle))
+	for i, e := range cycle {
+		hops[i] = dexmath.Hop{Pool: pools[e.pool].state, Dir: e.dir}
+	}
+
+	in, ok := dexmath.OptimalCycleInput(hops)
+	if !ok {
+		return dto.ArbitrageOpportunity{}, false
+	}
+	out, ok := dexmath.ApplyPath(in, hops)
+	if !ok || out.Cmp(in) <= 0 {
+		return dto.ArbitrageOpportunity{}, false
+	}
+
+	profit := new(big.Int).Sub(out, in)
+	bps := new(big.Int).Mul(profit, big.NewInt(10000))
+	bps.Quo(bps, in)
+	if !bps.IsUint64() || bps.Uint64() < uint64(a.cfg.MinProfitBps) {
+		return dto.ArbitrageOpportunity{}, false
+	}
+
+	opp := dto.ArbitrageOpportunity{
+		Tokens:    []common.Address{start},
+		AmountIn:  in,
+		AmountOut: out,
+		Profit:    profit,
+		ProfitBps: uint32(min(bps.Uint64(), math.MaxUint32)),
+	}
+	for _, e := range cycle {
+		opp.Tokens = append(opp.Tokens, e.to)
+		opp.Pools = append(opp.Pools, pools[e.pool].address)
+	}
+
+	return opp, true
+}
+
+func sortOpportunities(opps []dto.ArbitrageOpportunity) {
+	sort.SliceStable(opps, func(i, j int) bool {
+		if opps[i].ProfitBps != opps[j].ProfitBps {
+			return opps[i].ProfitBps > opps[j].ProfitBps
+		}
+		return opps[i].Profit.Cmp(opps[j].Profit) > 0
+	})
+}
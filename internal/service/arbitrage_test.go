@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fleshka4/1inch-test-task/internal/dexmath"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+	"github.com/fleshka4/1inch-test-task/internal/safety"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+)
+
+func TestArbitrageScanner(t *testing.T) {
+	t.Parallel()
+
+	tokenA := common.HexToAddress("0xa")
+	tokenB := common.HexToAddress("0xb")
+	tokenC := common.HexToAddress("0xc")
+
+	type pool struct {
+		address        common.Address
+		token0, token1 common.Address
+		r0, r1         int64
+	}
+	// A is worth 2 B on one fork and 3 B on another.
+	forkA := pool{common.HexToAddress("0x1001"), tokenA, tokenB, 10000, 20000}
+	forkB := pool{common.HexToAddress("0x1002"), tokenA, tokenB, 10000, 30000}
+	// A -> B -> C -> A multiplies by 2 * 1 * 2/3.
+	bc := pool{common.HexToAddress("0x1003"), tokenB, tokenC, 10000, 10000}
+	ac := pool{common.HexToAddress("0x1004"), tokenA, tokenC, 10000, 15000}
+
+	hop := func(p pool, dir dexmath.Direction) dexmath.Hop {
+		return dexmath.Hop{Pool: dexmath.PoolState{Reserve0: big.NewInt(p.r0), Reserve1: big.NewInt(p.r1), FeeBps: 30}, Dir: dir}
+	}
+	opportunity := func(tokens []common.Address, pools []pool, hops ...dexmath.Hop) dto.ArbitrageOpportunity {
+		in, ok := dexmath.OptimalCycleInput(hops)
+		require.True(t, ok)
+		out, ok := dexmath.ApplyPath(in, hops)
+		require.True(t, ok)
+
+		profit := new(big.Int).Sub(out, in)
+		opp := dto.ArbitrageOpportunity{
+			ChainID:     1,
+			BlockNumber: 100,
+			Tokens:      tokens,
+			AmountIn:    in,
+			AmountOut:   out,
+			Profit:      profit,
+			ProfitBps:   uint32(new(big.Int).Quo(new(big.Int).Mul(profit, big.NewInt(10000)), in).Uint64()),
+		}
+		for _, p := range pools {
+			opp.Pools = append(opp.Pools, p.address)
+		}
+		return opp
+	}
+
+	tests := []struct {
+		name     string
+		pools    []pool
+		cfg      ArbitrageConfig
+		rules    *safety.Rules
+		want     []dto.ArbitrageOpportunity
+		wantNone bool
+	}{
+		{
+			name:  "cross fork",
+			pools: []pool{forkA, forkB},
+			cfg:   ArbitrageConfig{MaxCycleLength: 3},
+			want: []dto.ArbitrageOpportunity{opportunity(
+				[]common.Address{tokenA, tokenB, tokenA},
+				[]pool{forkB, forkA},
+				hop(forkB, dexmath.ZeroForOne), hop(forkA, dexmath.OneForZero),
+			)},
+		},
+		{
+			name:  "triangle",
+			pools: []pool{forkA, bc, ac},
+			cfg:   ArbitrageConfig{MaxCycleLength: 3},
+			want: []dto.ArbitrageOpportunity{opportunity(
+				[]common.Address{tokenA, tokenB, tokenC, tokenA},
+				[]pool{forkA, bc, ac},
+				hop(forkA, dexmath.ZeroForOne), hop(bc, dexmath.ZeroForOne), hop(ac, dexmath.OneForZero),
+			)},
+		},
+		{
+			name:     "triangle beyond max cycle length",
+			pools:    []pool{forkA, bc, ac},
+			cfg:      ArbitrageConfig{MaxCycleLength: 2},
+			wantNone: true,
+		},
+		{
+			name:     "below min profit",
+			pools:    []pool{forkA, forkB},
+			cfg:      ArbitrageConfig{MaxCycleLength: 3, MinProfitBps: 5000},
+			wantNone: true,
+		},
+		{
+			name:     "denied pool",
+			pools:    []pool{forkA, forkB},
+			cfg:      ArbitrageConfig{MaxCycleLength: 3},
+			rules:    &safety.Rules{PoolDenylist: []common.Address{forkB.address}},
+			wantNone: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockClient(ctrl)
+			mockClient.EXPECT().BlockNumber(gomock.Any()).Return(uint64(100), nil)
+
+			chain := Chain{ID: 1, Client: mockClient, FeeBps: 30}
+			if tt.rules != nil {
+				policy, err := safety.NewPolicy(*tt.rules)
+				require.NoError(t, err)
+				chain.Safety = safety.NewGuard(policy)
+			}
+			for _, p := range tt.pools {
+				chain.TrackedPools = append(chain.TrackedPools, p.address)
+				mockClient.EXPECT().GetPairTokens(gomock.Any(), p.address).Return(p.token0, p.token1, nil).MaxTimes(1)
+				mockClient.EXPECT().GetPairReservesAt(gomock.Any(), p.address, big.NewInt(100)).
+					Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(p.r0), Reserve1: big.NewInt(p.r1)}, nil).MaxTimes(1)
+			}
+
+			scanner := NewArbitrageScanner(NewEstimatorService(chain), tt.cfg)
+			scanner.scan(context.Background())
+
+			got := scanner.Opportunities(0)
+			if tt.wantNone {
+				require.Empty(t, got)
+				return
+			}
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.want, scanner.Opportunities(1))
+			require.Empty(t, scanner.Opportunities(2))
+		})
+	}
+}
+
+func TestArbitrageScanner_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokenA := common.HexToAddress("0xa")
+	tokenB := common.HexToAddress("0xb")
+	forkA := common.HexToAddress("0x1001")
+	forkB := common.HexToAddress("0x1002")
+
+	block := uint64(100)
+	mockClient := mock.NewMockClient(ctrl)
+	mockClient.EXPECT().BlockNumber(gomock.Any()).DoAndReturn(func(context.Context) (uint64, error) {
+		return block, nil
+	}).Times(3)
+	mockClient.EXPECT().GetPairTokens(gomock.Any(), gomock.Any()).Return(tokenA, tokenB, nil).Times(2)
+	mockClient.EXPECT().GetPairReservesAt(gomock.Any(), forkA, gomock.Any()).
+		Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(20000)}, nil).Times(3)
+	mockClient.EXPECT().GetPairReservesAt(gomock.Any(), forkB, gomock.Any()).
+		Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(30000)}, nil).Times(3)
+
+	scanner := NewArbitrageScanner(
+		NewEstimatorService(Chain{ID: 1, Client: mockClient, FeeBps: 30, TrackedPools: []common.Address{forkA, forkB}}),
+		ArbitrageConfig{MaxCycleLength: 2},
+	)
+
+	feed, unsubscribe := scanner.Subscribe()
+
+	scanner.scan(context.Background())
+	require.Len(t, feed, 1)
+	opp := <-feed
+	require.Equal(t, uint64(100), opp.BlockNumber)
+
+	// A scan of the same block publishes nothing.
+	scanner.scan(context.Background())
+	require.Empty(t, feed)
+
+	unsubscribe()
+	unsubscribe()
+
+	block = 101
+	scanner.scan(context.Background())
+	require.Empty(t, feed)
+	require.Equal(t, uint64(101), scanner.Opportunities(1)[0].BlockNumber)
+}
+
+func TestArbitrageScanner_Run(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Chains without tracked pools are not scanned.
+	scanner := NewArbitrageScanner(
+		NewEstimatorService(Chain{ID: 1, Client: mock.NewMockClient(ctrl)}),
+		ArbitrageConfig{Interval: time.Millisecond, MaxCycleLength: 3},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	scanner.Run(ctx)
+	require.Empty(t, scanner.Opportunities(0))
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/amm"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+)
+
+// openBalancer opens a Balancer weighted pool with its Vault balances,
+// weights and swap fee at the latest block. The Vault holds the tokens of
+// every pool, so taxes are measured against it. Requested or sampled quotes
+// are cross-checked against the Vault's queryBatchSwap at that block.
+//
+// The sandwich of a weighted swap is not a constant product one, and the Vault
+// only tracks the block of a pool's last balance change, not its time, so
+// requests with slippage_bps or max_pool_age are rejected. The chain's
+// max_pool_age and TWAP deviation bound are not enforced for Balancer pools
+// either, which have no uniswap-v2 price cumulatives.
+func (s *EstimatorService) openBalancer(
+	ctx context.Context,
+	chain *Chain,
+	req dto.EstimateRequest,
+	_ *dto.EstimateResponse,
+) (*openedPool, error) {
+	if err := rejectV2Guards(PoolTypeBalancerWeighted, req); err != nil {
+		return nil, errors.Wrap(err, "rejectV2Guards")
+	}
+
+	latest, err := chain.Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "chain.Client.BlockNumber")
+	}
+
+	pool, err := amm.NewWeightedPool(chain.Client, req.Pool).StateAt(ctx, new(big.Int).SetUint64(latest))
+	if err != nil {
+		return nil, errors.Wrap(err, "pool.StateAt")
+	}
+
+	dir, err := poolDirection(pool, req.Src, req.Dst)
+	if err != nil {
+		return nil, errors.Wrap(err, "poolDirection")
+	}
+
+	factory, err := s.checkPool(ctx, chain, PoolTypeBalancerWeighted, req.Pool, req.Src, req.Dst, poolParams{})
+	if err != nil {
+		return nil, errors.Wrap(err, "s.checkPool")
+	}
+
+	return &openedPool{pool: pool, dir: dir, factory: factory}, nil
+}
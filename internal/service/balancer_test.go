@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+	"github.com/fleshka4/1inch-test-task/internal/weightedmath"
+)
+
+func TestEstimate_Balancer(t *testing.T) {
+	t.Parallel()
+
+	bal := common.HexToAddress("0xba1")
+	weth := common.HexToAddress("0x5678")
+	pool := common.HexToAddress("0x1234")
+	vault := common.HexToAddress("0xba12")
+	poolID := [32]byte{0x12, 0x34}
+	factory := Factory{Name: "balancer", Type: PoolTypeBalancerWeighted, Address: common.HexToAddress("0xfac7")}
+	block := uint64(19_000_000)
+	blockNumber := new(big.Int).SetUint64(block)
+	pow10 := func(n int64) *big.Int { return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil) }
+
+	state := &uniswapdto.BalancerWeightedPoolState{
+		BlockNumber: block,
+		PoolID:      poolID,
+		Vault:       vault,
+		Tokens:      []common.Address{bal, weth},
+		Balances: []*big.Int{
+			new(big.Int).Mul(big.NewInt(10_000_000), pow10(18)),
+			new(big.Int).Mul(big.NewInt(5_000), pow10(18)),
+		},
+		ScalingFactors: []*big.Int{big.NewInt(1), big.NewInt(1)},
+		Weights:        []*big.Int{big.NewInt(8e17), big.NewInt(2e17)},
+		SwapFee:        big.NewInt(1e16),
+		ExactPow:       true,
+	}
+	quote := func(i, j int, amountIn *big.Int) *big.Int {
+		p := weightedmath.Pool{
+			Balances:       state.Balances,
+			ScalingFactors: state.ScalingFactors,
+			Weights:        state.Weights,
+			SwapFee:        state.SwapFee,
+			ExactPow:       state.ExactPow,
+		}
+		out, ok := p.QuoteExactIn(i, j, amountIn)
+		require.True(t, ok)
+		return out
+	}
+
+	readPool := func(mc *mock.MockClient) {
+		mc.EXPECT().BlockNumber(gomock.Any()).Return(block, nil)
+		mc.EXPECT().GetBalancerWeightedPoolState(gomock.Any(), pool, blockNumber).Return(state, nil)
+	}
+	fromFactory := func(mc *mock.MockClient, deployed bool) {
+		mc.EXPECT().IsBalancerPoolFromFactory(gomock.Any(), factory.Address, pool).Return(deployed, nil)
+	}
+
+	amount := new(big.Int).Mul(big.NewInt(1_000), pow10(18))
+
+	tests := []struct {
+		name      string
+		req       dto.EstimateRequest
+		mockSetup func(mc *mock.MockClient)
+		want      *dto.EstimateResponse
+		wantErr   error
+	}{
+		{
+			name: "detected pool",
+			req:  dto.EstimateRequest{Src: bal, Dst: weth, SrcAmount: amount},
+			mockSetup: func(mc *mock.MockClient) {
+				mc.EXPECT().GetCode(gomock.Any(), pool).Return(poolCode(PoolTypeBalancerWeighted), nil)
+				readPool(mc)
+				fromFactory(mc, true)
+			},
+			want: &dto.EstimateResponse{
+				DstAmount:    quote(0, 1, amount),
+				PoolVerified: true,
+				Factory:      "balancer",
+				PoolType:     "balancer-weighted",
+			},
+		},
+		{
+			name: "selected pool type with cross-check",
+			req: dto.EstimateRequest{
+				Src: weth, Dst: bal, SrcAmount: pow10(18), CrossCheck: true, PoolType: "balancer-weighted",
+			},
+			mockSetup: func(mc *mock.MockClient) {
+				readPool(mc)
+				fromFactory(mc, true)
+				mc.EXPECT().
+					GetBalancerAmountOut(gomock.Any(), vault, poolID, weth, bal, pow10(18), blockNumber).
+					Return(quote(1, 0, pow10(18)), nil)
+			},
+			want: &dto.EstimateResponse{
+				DstAmount:    quote(1, 0, pow10(18)),
+				PoolVerified: true,
+				Factory:      "balancer",
+				PoolType:     "balancer-weighted",
+				CrossCheck: &dto.CrossCheck{
+					BlockNumber:   block,
+					OnChainAmount: quote(1, 0, pow10(18)),
+					Match:         true,
+				},
+			},
+		},
+		{
+			name: "pool not from the factory",
+			req:  dto.EstimateRequest{Src: bal, Dst: weth, SrcAmount: amount, PoolType: "balancer-weighted"},
+			mockSetup: func(mc *mock.MockClient) {
+				readPool(mc)
+				fromFactory(mc, false)
+			},
+			wantErr: apperrors.ErrUnverifiedPool,
+		},
+		{
+			name: "swap above the max in ratio",
+			req: dto.EstimateRequest{
+				Src: weth, Dst: bal, SrcAmount: new(big.Int).Mul(big.NewInt(2_000), pow10(18)), PoolType: "balancer-weighted",
+			},
+			mockSetup: func(mc *mock.MockClient) {
+				readPool(mc)
+				fromFactory(mc, true)
+			},
+			wantErr: apperrors.ErrInsufficientLiquidity,
+		},
+		{
+			name:      "unknown pool type",
+			req:       dto.EstimateRequest{Src: bal, Dst: weth, SrcAmount: amount, PoolType: "balancer-stable"},
+			mockSetup: func(*mock.MockClient) {},
+			wantErr:   apperrors.ErrInvalidArgument,
+		},
+		{
+			name: "snapshot of a balancer pool",
+			req: dto.EstimateRequest{
+				Src: bal, Dst: weth, SrcAmount: amount, PoolType: "balancer-weighted",
+				Snapshot: &dto.PoolSnapshot{Reserve0: pow10(18), Reserve1: pow10(18)},
+			},
+			mockSetup: func(*mock.MockClient) {},
+			wantErr:   apperrors.ErrInvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockClient(ctrl)
+			tt.mockSetup(mockClient)
+
+			service := NewEstimatorService(Chain{
+				ID:               1,
+				Client:           mockClient,
+				FeeBps:           30,
+				Factories:        []Factory{factory},
+				PairVerification: PairVerificationStrict,
+			})
+
+			req := tt.req
+			req.Pool = pool
+			resp, err := service.Estimate(context.Background(), req)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, resp)
+		})
+	}
+}
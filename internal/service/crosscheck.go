@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"math/rand/v2"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/fleshka4/1inch-test-task/internal/metrics"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+)
+
+// wantCrossCheck reports whether a quote is cross-checked: always when the
+// request asks for it, otherwise for a sample of quotes from pools whose
+// factory has a router.
+func wantCrossCheck(chain *Chain, requested bool, factory *Factory) bool {
+	if requested {
+		return true
+	}
+	if factory == nil || factory.Router == (common.Address{}) || chain.CrossCheckRatio <= 0 {
+		return false
+	}
+	return rand.Float64() < chain.CrossCheckRatio //nolint:gosec
+}
+
+// wantPoolCrossCheck is wantCrossCheck for pools that quote swaps themselves
+// and so need no router.
+func wantPoolCrossCheck(chain *Chain, requested bool, factory *Factory) bool {
+	if requested {
+		return true
+	}
+	if factory == nil || chain.CrossCheckRatio <= 0 {
+		return false
+	}
+	return rand.Float64() < chain.CrossCheckRatio //nolint:gosec
+}
+
+// poolCrossCheck compares the off-chain swap output with the pool's own quote,
+// read at the block the pool's state was read at.
+func poolCrossCheck(
+	chain *Chain,
+	blockNumber uint64,
+	want *big.Int,
+	quote func() (*big.Int, error),
+) *dto.CrossCheck {
+	check := &dto.CrossCheck{BlockNumber: blockNumber}
+
+	onChain, err := quote()
+	if err != nil {
+		check.Error = err.Error()
+		metrics.RecordCrossCheck(chain.Name, metrics.CrossCheckError)
+		return check
+	}
+	matchCrossCheck(chain, check, onChain, want)
+
+	return check
+}
+
+// beginCrossCheck pins the block at which the reserves and the router are read.
+// A check that cannot run is returned with Error set.
+func beginCrossCheck(ctx context.Context, chain *Chain, factory *Factory) *dto.CrossCheck {
+	check := &dto.CrossCheck{}
+
+	if factory == nil || factory.Router == (common.Address{}) {
+		check.Error = "pool is not verified against a factory with a router"
+		metrics.RecordCrossCheck(chain.Name, metrics.CrossCheckError)
+		return check
+	}
+	check.Router = factory.Router
+
+	n, err := chain.Client.BlockNumber(ctx)
+	if err != nil {
+		check.Error = "block number: " + err.Error()
+		metrics.RecordCrossCheck(chain.Name, metrics.CrossCheckError)
+		return check
+	}
+	check.BlockNumber = n
+
+	return check
+}
+
+// finishCrossCheck compares the off-chain swap output with the router's
+// getAmountsOut at the pinned block.
+func finishCrossCheck(
+	ctx context.Context,
+	chain *Chain,
+	check *dto.CrossCheck,
+	amountIn *big.Int,
+	src, dst common.Address,
+	want *big.Int,
+) {
+	amounts, err := chain.Client.GetAmountsOut(
+		ctx, check.Router, amountIn, []common.Address{src, dst}, new(big.Int).SetUint64(check.BlockNumber),
+	)
+	if err != nil {
+		check.Error = "getAmountsOut: " + err.Error()
+		metrics.RecordCrossCheck(chain.Name, metrics.CrossCheckError)
+		return
+	}
+
+	matchCrossCheck(chain, check, amounts[len(amounts)-1], want)
+}
+
+// matchCrossCheck records the on-chain output of a check and whether it matches
+// the off-chain one.
+func matchCrossCheck(chain *Chain, check *dto.CrossCheck, onChain, want *big.Int) {
+	check.OnChainAmount = onChain
+	check.Match = onChain.Cmp(want) == 0
+	if check.Match {
+		metrics.RecordCrossCheck(chain.Name, metrics.CrossCheckMatch)
+	} else {
+		metrics.RecordCrossCheck(chain.Name, metrics.CrossCheckMismatch)
+	}
+}
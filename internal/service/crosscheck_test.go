@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+)
+
+func TestEstimate_CrossCheck(t *testing.T) {
+	t.Parallel()
+
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+	router := common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D")
+	factory := Factory{
+		Name:         "uniswap-v2",
+		Address:      common.HexToAddress("0xfac7"),
+		InitCodeHash: common.HexToHash("0x01"),
+		FeeBps:       30,
+		Router:       router,
+	}
+	pool := uniswap.PairAddress(factory.Address, token0, token1, factory.InitCodeHash)
+	block := big.NewInt(19_000_000)
+	path := []common.Address{token0, token1}
+
+	// 1000 in against 10000/20000 reserves with a 0.3% fee yields 1813.
+	const want = 1813
+
+	tests := []struct {
+		name      string
+		requested bool
+		ratio     float64
+		router    common.Address
+		mockSetup func(mc *mock.MockClient)
+		wantCheck *dto.CrossCheck
+	}{
+		{
+			name:      "match",
+			requested: true,
+			router:    router,
+			mockSetup: func(mc *mock.MockClient) {
+				mc.EXPECT().BlockNumber(gomock.Any()).Return(block.Uint64(), nil)
+				mc.EXPECT().GetPairReservesAt(gomock.Any(), pool, block).Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(20000)}, nil)
+				mc.EXPECT().GetAmountsOut(gomock.Any(), router, big.NewInt(1000), path, block).
+					Return([]*big.Int{big.NewInt(1000), big.NewInt(want)}, nil)
+			},
+			wantCheck: &dto.CrossCheck{BlockNumber: block.Uint64(), Router: router, OnChainAmount: big.NewInt(want), Match: true},
+		},
+		{
+			name:   "sampled mismatch",
+			ratio:  1,
+			router: router,
+			mockSetup: func(mc *mock.MockClient) {
+				mc.EXPECT().BlockNumber(gomock.Any()).Return(block.Uint64(), nil)
+				mc.EXPECT().GetPairReservesAt(gomock.Any(), pool, block).Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(20000)}, nil)
+				mc.EXPECT().GetAmountsOut(gomock.Any(), router, big.NewInt(1000), path, block).
+					Return([]*big.Int{big.NewInt(1000), big.NewInt(want - 1)}, nil)
+			},
+			wantCheck: &dto.CrossCheck{BlockNumber: block.Uint64(), Router: router, OnChainAmount: big.NewInt(want - 1)},
+		},
+		{
+			name:   "not sampled",
+			router: router,
+			mockSetup: func(mc *mock.MockClient) {
+				mc.EXPECT().GetPairReserves(gomock.Any(), pool).Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(20000)}, nil)
+			},
+		},
+		{
+			name:      "router call fails",
+			requested: true,
+			router:    router,
+			mockSetup: func(mc *mock.MockClient) {
+				mc.EXPECT().BlockNumber(gomock.Any()).Return(block.Uint64(), nil)
+				mc.EXPECT().GetPairReservesAt(gomock.Any(), pool, block).Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(20000)}, nil)
+				mc.EXPECT().GetAmountsOut(gomock.Any(), router, big.NewInt(1000), path, block).
+					Return(nil, errors.New("execution reverted"))
+			},
+			wantCheck: &dto.CrossCheck{BlockNumber: block.Uint64(), Router: router, Error: "getAmountsOut: execution reverted"},
+		},
+		{
+			name:      "no router",
+			requested: true,
+			mockSetup: func(mc *mock.MockClient) {
+				mc.EXPECT().GetPairReserves(gomock.Any(), pool).Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(20000)}, nil)
+			},
+			wantCheck: &dto.CrossCheck{Error: "pool is not verified against a factory with a router"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockClient(ctrl)
+			mockClient.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil)
+			tt.mockSetup(mockClient)
+
+			f := factory
+			f.Router = tt.router
+			service := NewEstimatorService(Chain{
+				ID:               1,
+				Name:             "ethereum",
+				Client:           mockClient,
+				FeeBps:           30,
+				Factories:        []Factory{f},
+				PairVerification: PairVerificationPermissive,
+				CrossCheckRatio:  tt.ratio,
+			})
+
+			resp, err := service.Estimate(context.Background(), dto.EstimateRequest{
+				Pool:       pool,
+				Src:        token0,
+				Dst:        token1,
+				SrcAmount:  big.NewInt(1000),
+				CrossCheck: tt.requested,
+			})
+			require.NoError(t, err)
+			require.Equal(t, big.NewInt(want), resp.DstAmount)
+			require.Equal(t, tt.wantCheck, resp.CrossCheck)
+		})
+	}
+}
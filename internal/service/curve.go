@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/amm"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+)
+
+// openCurve opens a Curve StableSwap pool, plain or meta, with its balances,
+// rates, amplification and fee at the latest block. Requested or sampled
+// quotes are cross-checked against the pool's own get_dy at that block.
+//
+// Swaps into a metapool's underlying base pool coins are not supported. The
+// sandwich of a StableSwap swap is not a constant product one, and pools keep
+// no time of their last swap, so requests with slippage_bps or max_pool_age
+// are rejected. The chain's max_pool_age and TWAP deviation bound are not
+// enforced for Curve pools either, which have no uniswap-v2 price cumulatives.
+func (s *EstimatorService) openCurve(
+	ctx context.Context,
+	chain *Chain,
+	req dto.EstimateRequest,
+	_ *dto.EstimateResponse,
+) (*openedPool, error) {
+	if err := rejectV2Guards(PoolTypeCurve, req); err != nil {
+		return nil, errors.Wrap(err, "rejectV2Guards")
+	}
+
+	latest, err := chain.Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "chain.Client.BlockNumber")
+	}
+
+	pool, err := amm.NewCurvePool(chain.Client, req.Pool).StateAt(ctx, new(big.Int).SetUint64(latest))
+	if err != nil {
+		return nil, errors.Wrap(err, "pool.StateAt")
+	}
+
+	dir, err := poolDirection(pool, req.Src, req.Dst)
+	if err != nil {
+		return nil, errors.Wrap(err, "poolDirection")
+	}
+
+	factory, err := s.checkPool(ctx, chain, PoolTypeCurve, req.Pool, req.Src, req.Dst, poolParams{})
+	if err != nil {
+		return nil, errors.Wrap(err, "s.checkPool")
+	}
+
+	return &openedPool{pool: pool, dir: dir, factory: factory}, nil
+}
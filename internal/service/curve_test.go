@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+	"github.com/fleshka4/1inch-test-task/internal/dexmath"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+)
+
+func TestEstimate_Curve(t *testing.T) {
+	t.Parallel()
+
+	dai := common.HexToAddress("0xda1")
+	usdc := common.HexToAddress("0x5678")
+	usdt := common.HexToAddress("0x12345678")
+	pool := common.HexToAddress("0x1234")
+	registry := Factory{Name: "curve", Type: PoolTypeCurve, Address: common.HexToAddress("0xfac7")}
+	block := uint64(19_000_000)
+	blockNumber := new(big.Int).SetUint64(block)
+	pow10 := func(n int64) *big.Int { return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil) }
+
+	state := &uniswapdto.CurvePoolState{
+		BlockNumber: block,
+		Coins:       []common.Address{dai, usdc, usdt},
+		Balances: []*big.Int{
+			new(big.Int).Mul(big.NewInt(150_000_000), pow10(18)),
+			new(big.Int).Mul(big.NewInt(160_000_000), pow10(6)),
+			new(big.Int).Mul(big.NewInt(90_000_000), pow10(6)),
+		},
+		Rates:      []*big.Int{pow10(18), pow10(30), pow10(30)},
+		Amp:        big.NewInt(2000),
+		APrecision: big.NewInt(1),
+		Fee:        big.NewInt(1000000),
+	}
+	quote := func(i, j int, dx *big.Int) *big.Int {
+		p := dexmath.CurvePool{
+			Balances: state.Balances, Rates: state.Rates, Amp: state.Amp, APrecision: state.APrecision, Fee: state.Fee,
+		}
+		out, ok := p.GetDy(i, j, dx)
+		require.True(t, ok)
+		return out
+	}
+
+	readPool := func(mc *mock.MockClient) {
+		mc.EXPECT().BlockNumber(gomock.Any()).Return(block, nil)
+		mc.EXPECT().GetCurvePoolState(gomock.Any(), pool, blockNumber).Return(state, nil)
+	}
+
+	amount := new(big.Int).Mul(big.NewInt(1_000_000), pow10(6))
+
+	tests := []struct {
+		name      string
+		req       dto.EstimateRequest
+		mockSetup func(mc *mock.MockClient)
+		want      *dto.EstimateResponse
+		wantErr   error
+	}{
+		{
+			name: "usdc to usdt",
+			req:  dto.EstimateRequest{Src: usdc, Dst: usdt, SrcAmount: amount},
+			mockSetup: func(mc *mock.MockClient) {
+				readPool(mc)
+				mc.EXPECT().IsCurvePoolRegistered(gomock.Any(), registry.Address, pool).Return(true, nil)
+			},
+			want: &dto.EstimateResponse{
+				DstAmount:    quote(1, 2, amount),
+				PoolVerified: true,
+				Factory:      "curve",
+				PoolType:     "curve",
+			},
+		},
+		{
+			name: "usdt to dai with cross-check",
+			req:  dto.EstimateRequest{Src: usdt, Dst: dai, SrcAmount: amount, CrossCheck: true},
+			mockSetup: func(mc *mock.MockClient) {
+				readPool(mc)
+				mc.EXPECT().IsCurvePoolRegistered(gomock.Any(), registry.Address, pool).Return(true, nil)
+				mc.EXPECT().
+					GetCurveAmountOut(gomock.Any(), pool, 2, 0, amount, blockNumber).
+					Return(quote(2, 0, amount), nil)
+			},
+			want: &dto.EstimateResponse{
+				DstAmount:    quote(2, 0, amount),
+				PoolVerified: true,
+				Factory:      "curve",
+				PoolType:     "curve",
+				CrossCheck: &dto.CrossCheck{
+					BlockNumber:   block,
+					OnChainAmount: quote(2, 0, amount),
+					Match:         true,
+				},
+			},
+		},
+		{
+			name: "unregistered pool",
+			req:  dto.EstimateRequest{Src: usdc, Dst: usdt, SrcAmount: amount},
+			mockSetup: func(mc *mock.MockClient) {
+				readPool(mc)
+				mc.EXPECT().IsCurvePoolRegistered(gomock.Any(), registry.Address, pool).Return(false, nil)
+			},
+			wantErr: apperrors.ErrUnverifiedPool,
+		},
+		{
+			name:      "not a coin of the pool",
+			req:       dto.EstimateRequest{Src: usdc, Dst: common.HexToAddress("0xdead"), SrcAmount: amount},
+			mockSetup: readPool,
+			wantErr:   apperrors.ErrInvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockClient(ctrl)
+			mockClient.EXPECT().GetCode(gomock.Any(), pool).Return(poolCode(PoolTypeCurve), nil)
+			tt.mockSetup(mockClient)
+
+			service := NewEstimatorService(Chain{
+				ID:               1,
+				Client:           mockClient,
+				FeeBps:           30,
+				Factories:        []Factory{registry},
+				PairVerification: PairVerificationStrict,
+			})
+
+			req := tt.req
+			req.Pool = pool
+			resp, err := service.Estimate(context.Background(), req)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, resp)
+		})
+	}
+}
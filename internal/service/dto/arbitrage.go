@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ArbitrageOpportunity is a profitable cycle of swaps through tracked pools.
+type ArbitrageOpportunity struct {
+	ChainID     uint64
+	BlockNumber uint64
+	// Tokens is the cycle of tokens, starting and ending with the token the
+	// input and the profit are in.
+	Tokens []common.Address
+	// Pools are the pools swapped through, one per step of Tokens.
+	Pools     []common.Address
+	AmountIn  *big.Int
+	AmountOut *big.Int
+	Profit    *big.Int
+	// ProfitBps is the profit relative to AmountIn.
+	ProfitBps uint32
+}
@@ -2,14 +2,94 @@ package dto
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 )
 
 // EstimateRequest represents a request to calculate an off-chain Uniswap V2 swap.
 type EstimateRequest struct {
+	// ChainID and Chain select the chain by id or by name.
+	// When both are empty the default chain is used.
+	ChainID   uint64
+	Chain     string
 	Pool      common.Address
 	Src       common.Address
 	Dst       common.Address
 	SrcAmount *big.Int
+	// CrossCheck asks to compare the quote with the router's getAmountsOut.
+	CrossCheck bool
+	// Snapshot replaces the pool's on-chain state for a what-if estimate.
+	Snapshot *PoolSnapshot
+	// SlippageBps, when non-zero, asks for the sandwich exposure of a swap
+	// sent with this slippage tolerance.
+	SlippageBps uint32
+	// MaxPoolAge, when non-zero, rejects the quote if the pool's reserves were
+	// last updated longer ago. The chain's limit applies if it is stricter.
+	MaxPoolAge time.Duration
+	// PoolType, when set, selects the kind of AMM the pool is instead of
+	// detecting it, e.g. "balancer-weighted".
+	PoolType string
+}
+
+// PoolSnapshot is caller-supplied pool state used instead of chain state.
+type PoolSnapshot struct {
+	// Token0 and Token1 are optional; when zero, the pool's tokens are read
+	// from the chain to match the swap direction.
+	Token0   common.Address
+	Token1   common.Address
+	Reserve0 *big.Int
+	Reserve1 *big.Int
+}
+
+// EstimateResponse represents the result of an off-chain Uniswap V2 swap calculation.
+type EstimateResponse struct {
+	DstAmount *big.Int
+	// PoolVerified reports whether the pool was proven to be deployed by one of
+	// the chain's configured factories.
+	PoolVerified bool
+	// Factory is the name of the factory that deployed the pool, if verified.
+	Factory string
+	// Taxed reports that a transfer tax of src or dst was deducted from the estimate.
+	Taxed bool
+	// Rebasing reports that src or dst is a rebasing token, so the estimate is approximate.
+	Rebasing bool
+	// CrossCheck is the on-chain verification of the quote, if one was made.
+	CrossCheck *CrossCheck
+	// Hypothetical reports that the estimate used a caller-supplied snapshot
+	// rather than chain state.
+	Hypothetical bool
+	// MEVExposure is the sandwich exposure of the swap, if requested.
+	MEVExposure *MEVExposure
+	// PoolUpdatedAt is the timestamp of the block the pool's reserves were last
+	// updated in, and PoolAge the time since. Both are zero for hypothetical estimates.
+	PoolUpdatedAt time.Time
+	PoolAge       time.Duration
+	// PoolType is the kind of pool quoted, e.g. uniswap-v2 or uniswap-v3.
+	PoolType string
+}
+
+// MEVExposure is the most profitable sandwich of a swap whose output may fall
+// by SlippageBps. Amounts are pool amounts, before transfer taxes.
+type MEVExposure struct {
+	SlippageBps uint32
+	// FrontRun is the attacker's optimal front-run, in src units.
+	FrontRun *big.Int
+	// AttackerProfit is the attacker's profit, in src units.
+	AttackerProfit *big.Int
+	// VictimLoss is how much less dst the swap yields when sandwiched.
+	VictimLoss *big.Int
+}
+
+// CrossCheck compares the off-chain swap output, before transfer taxes, with
+// UniswapV2Router02.getAmountsOut, or the pair's own getAmountOut for solidly
+// pairs, at the block the reserves were read at.
+type CrossCheck struct {
+	BlockNumber uint64
+	// Router is unset for solidly pairs.
+	Router        common.Address
+	OnChainAmount *big.Int
+	Match         bool
+	// Error tells why the check could not be made.
+	Error string
 }
@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EstimateHistoryRequest represents a request for the estimates of a swap at
+// every Step-th block from FromBlock to ToBlock.
+type EstimateHistoryRequest struct {
+	ChainID   uint64
+	Chain     string
+	Pool      common.Address
+	Src       common.Address
+	Dst       common.Address
+	SrcAmount *big.Int
+	FromBlock uint64
+	ToBlock   uint64
+	Step      uint64
+}
+
+// HistoricalEstimate is the estimate of a swap at a past block. Estimates that
+// could not be made, such as before the pool was deployed or on a node without
+// the block's state, have Error set instead.
+type HistoricalEstimate struct {
+	BlockNumber uint64
+	// ReservesTimestamp is when the pool's reserves at the block were last
+	// updated, the timestamp of the block of its last trade, mint, burn or sync.
+	ReservesTimestamp uint64
+	DstAmount         *big.Int
+	// Price is DstAmount per unit of the source amount and SpotPrice the ratio
+	// of the pool's reserves of dst and src, both in raw token units.
+	Price     *big.Rat
+	SpotPrice *big.Rat
+	Error     string
+}
@@ -0,0 +1,90 @@
+package dto
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PairLiquidity is the liquidity state of a pair at a block.
+type PairLiquidity struct {
+	BlockNumber uint64
+	Token0      common.Address
+	Token1      common.Address
+	Reserve0    *big.Int
+	Reserve1    *big.Int
+	// TotalSupply is the supply of the pair's LP token.
+	TotalSupply *big.Int
+	// KLast is the reserve product at the last liquidity event, zero while the
+	// protocol fee is off.
+	KLast *big.Int
+	// FeeTo is the protocol fee recipient of the pair's factory, zero while the
+	// protocol fee is off.
+	FeeTo common.Address
+	// ProtocolFee is the LP tokens the pair mints to FeeTo on the next mint or
+	// burn, already accounted for in the amounts of the response.
+	ProtocolFee *big.Int
+}
+
+// PairLiquidityRequest represents a read of the liquidity state of a pair.
+type PairLiquidityRequest struct {
+	ChainID uint64
+	Chain   string
+	Pool    common.Address
+}
+
+// AddLiquidityRequest represents a deposit of both tokens of a pair.
+type AddLiquidityRequest struct {
+	ChainID uint64
+	Chain   string
+	Pool    common.Address
+	Amount0 *big.Int
+	Amount1 *big.Int
+}
+
+// AddLiquidityResponse represents the LP tokens minted for a deposit.
+type AddLiquidityResponse struct {
+	Pair      PairLiquidity
+	Liquidity *big.Int
+}
+
+// RemoveLiquidityRequest represents a burn of LP tokens of a pair.
+type RemoveLiquidityRequest struct {
+	ChainID   uint64
+	Chain     string
+	Pool      common.Address
+	Liquidity *big.Int
+}
+
+// RemoveLiquidityResponse represents the amounts paid out for burnt LP tokens,
+// which are also the underlying value of the position.
+type RemoveLiquidityResponse struct {
+	Pair    PairLiquidity
+	Amount0 *big.Int
+	Amount1 *big.Int
+	// ValueIn0 and ValueIn1 are the whole position priced in token0 and token1
+	// at the pair's spot price.
+	ValueIn0 *big.Int
+	ValueIn1 *big.Int
+}
+
+// ZapRequest represents a deposit of a single token of a pair.
+type ZapRequest struct {
+	ChainID   uint64
+	Chain     string
+	Pool      common.Address
+	Src       common.Address
+	SrcAmount *big.Int
+}
+
+// ZapResponse represents the optimal split of a single-sided deposit.
+type ZapResponse struct {
+	Pair PairLiquidity
+	// SwapAmount is the part of the input swapped for the other token.
+	SwapAmount *big.Int
+	SwapOut    *big.Int
+	// Amount0 and Amount1 are the amounts deposited after the swap.
+	Amount0   *big.Int
+	Amount1   *big.Int
+	Liquidity *big.Int
+}
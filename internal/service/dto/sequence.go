@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SequenceRequest represents an ordered list of swaps, each applied to the
+// pool state left by the previous ones, e.g. the fills of a bundle.
+type SequenceRequest struct {
+	ChainID uint64
+	Chain   string
+	Swaps   []SequenceSwap
+}
+
+// SequenceSwap is a single swap of a SequenceRequest.
+type SequenceSwap struct {
+	Pool      common.Address
+	Src       common.Address
+	Dst       common.Address
+	SrcAmount *big.Int
+}
+
+// SequenceResponse represents the outcome of a sequence of swaps.
+type SequenceResponse struct {
+	// BlockNumber is the block the initial reserves were read at.
+	BlockNumber uint64
+	// Steps holds the output of every swap, in request order.
+	Steps []SequenceStep
+	// Pools holds the final reserves of every pool, in order of first use.
+	Pools []PoolReserves
+}
+
+// SequenceStep is the output of a single swap of a sequence.
+type SequenceStep struct {
+	Pool      common.Address
+	DstAmount *big.Int
+}
+
+// PoolReserves are the reserves of a pool.
+type PoolReserves struct {
+	Pool     common.Address
+	Reserve0 *big.Int
+	Reserve1 *big.Int
+}
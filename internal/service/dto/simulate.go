@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SimulateRequest represents a request to execute a quoted swap through the
+// router of the pool's factory with eth_call.
+type SimulateRequest struct {
+	ChainID   uint64
+	Chain     string
+	Pool      common.Address
+	Src       common.Address
+	Dst       common.Address
+	SrcAmount *big.Int
+	// Sender executes the swap. It is given SrcAmount of Src and the router's
+	// allowance for the duration of the call, so it needs no real balance.
+	Sender common.Address
+	// Recipient receives Dst. Sender is used when empty.
+	Recipient common.Address
+}
+
+// SimulateResponse represents the outcome of a simulated swap.
+type SimulateResponse struct {
+	// QuotedAmount is the off-chain estimate of the swap.
+	QuotedAmount *big.Int
+	Router       common.Address
+	// DstAmount is what the recipient actually received, unless the swap reverted.
+	DstAmount    *big.Int
+	Reverted     bool
+	RevertReason string
+}
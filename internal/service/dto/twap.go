@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TWAPRequest represents a request for the time-weighted average price of a
+// pool over the window ending at the latest block.
+type TWAPRequest struct {
+	ChainID uint64
+	Chain   string
+	Pool    common.Address
+	Window  time.Duration
+}
+
+// TWAPResponse represents the time-weighted average prices of a pool.
+// Prices are UQ112x112 fixed point numbers in raw token units.
+type TWAPResponse struct {
+	Token0 common.Address
+	Token1 common.Address
+	// StartBlock is the last block at least the window before EndBlock.
+	StartBlock     uint64
+	EndBlock       uint64
+	StartTimestamp uint64
+	EndTimestamp   uint64
+	// Price0 is the average price of token0 in token1, Price1 of token1 in token0.
+	Price0 *big.Int
+	Price1 *big.Int
+	// SpotPrice0 is the price of token0 in token1 at EndBlock.
+	SpotPrice0 *big.Int
+}
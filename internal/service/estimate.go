@@ -4,65 +4,463 @@ import (
 	"context"
 	"math/big"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 
+	"github.com/fleshka4/1inch-test-task/internal/amm"
 	"github.com/fleshka4/1inch-test-task/internal/apperrors"
 	"github.com/fleshka4/1inch-test-task/internal/dexmath"
+	"github.com/fleshka4/1inch-test-task/internal/metrics"
 	"github.com/fleshka4/1inch-test-task/internal/service/dto"
 	"github.com/fleshka4/1inch-test-task/internal/service/validate"
 )
 
 // Estimate performs the complete business logic for off-chain swap calculation.
 //
-// It validates the request parameters, reads the Uniswap V2 pair contract state
-// (tokens and reserves) through the infra client, and calculates the output
-// amount using the Uniswap V2 constant product formula with fee adjustment.
-func (s *EstimatorService) Estimate(ctx context.Context, req dto.EstimateRequest) (*big.Int, error) {
+// It validates the request parameters, selects the requested chain, enforces the
+// chain's pool and token safety policy, reads the Uniswap V2 pair contract state
+// (tokens and reserves) through the chain's infra client, verifies that the pair was
+// deployed by a configured factory, and calculates the output amount using the
+// Uniswap V2 constant product formula with the factory's (or chain's) fee adjustment,
+// net of the transfer taxes of fee-on-transfer tokens. Requested or sampled quotes
+// are cross-checked against the factory's router at the block the reserves were read at.
+//
+// With a slippage tolerance, the response also has the swap's sandwich exposure.
+// The response tells when the pool's reserves were last updated, and quotes from
+// pools inactive for longer than the request's or the chain's pool age limit are rejected.
+// Chains with a TWAP deviation bound reject quotes from pools whose spot price
+// strays too far from their time-weighted average.
+//
+// A request with a snapshot is a what-if estimate: the snapshot's reserves, and
+// tokens if given, replace chain state and the response is marked hypothetical.
+//
+// On chains with uniswap-v3, solidly, curve or balancer-weighted factories, pools
+// are detected as those types by their bytecode, or probes, and read by the
+// type's backend instead. A request's pool type, or the chain's configured type
+// of the pool, skips detection.
+func (s *EstimatorService) Estimate(ctx context.Context, req dto.EstimateRequest) (*dto.EstimateResponse, error) {
 	if err := validate.EstimateRequestValidate(req); err != nil {
 		return nil, errors.Wrap(err, "validate.EstimateRequestValidate")
 	}
 
-	token0, token1, err := s.uniswapClient.GetPairTokens(ctx, req.Pool)
+	chain, err := s.chain(req.ChainID, req.Chain)
 	if err != nil {
-		return nil, errors.Wrap(err, "s.uniswapClient.GetPairTokens")
+		return nil, errors.Wrap(err, "s.chain")
 	}
 
-	var reserves struct {
-		in  *big.Int
-		out *big.Int
+	poolType := PoolType(req.PoolType)
+	if _, ok := poolBackends[poolType]; poolType != "" && !ok {
+		return nil, errors.Wrapf(apperrors.ErrInvalidArgument, "unknown pool type %q", req.PoolType)
 	}
 
-	switch {
-	case isTokenMatch(req.Src, token0) && isTokenMatch(req.Dst, token1):
-		r0, r1, err := s.uniswapClient.GetPairReserves(ctx, req.Pool)
+	// What-if estimates are for uniswap-v2 pairs only.
+	if req.Snapshot != nil {
+		if poolType != "" && poolType != PoolTypeUniswapV2 {
+			return nil, errors.Wrapf(apperrors.ErrInvalidArgument, "snapshot is not supported for %s pools", poolType)
+		}
+		poolType = PoolTypeUniswapV2
+	} else {
+		if err := checkSafety(chain, req.Pool, req.Src, req.Dst); err != nil {
+			return nil, errors.Wrap(err, "checkSafety")
+		}
+
+		if poolType == "" {
+			poolType, err = s.poolType(ctx, chain, req.Pool)
+			if err != nil {
+				return nil, errors.Wrap(err, "s.poolType")
+			}
+		}
+	}
+
+	resp, err := s.estimatePool(ctx, chain, poolType, req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "estimate %s", poolType)
+	}
+	return resp, nil
+}
+
+// estimatePool quotes a swap against the pool its type's backend opens, net
+// of the tokens' transfer taxes. Requested or sampled quotes of pools whose
+// contract quotes swaps itself are cross-checked against it, unless the
+// backend checks the swap its own way.
+func (s *EstimatorService) estimatePool(
+	ctx context.Context,
+	chain *Chain,
+	poolType PoolType,
+	req dto.EstimateRequest,
+) (*dto.EstimateResponse, error) {
+	resp := &dto.EstimateResponse{PoolType: string(poolType)}
+	p, err := poolBackends[poolType].open(s, ctx, chain, req, resp)
+	if err != nil {
+		return nil, errors.Wrap(err, "open")
+	}
+	if p.factory != nil {
+		resp.PoolVerified = true
+		resp.Factory = p.factory.Name
+	}
+
+	// Taxes are measured against the contract holding the pool's tokens, with
+	// transfers sized by its balances. Pools without known balances, such as
+	// V3 ones, only have configured taxes.
+	holder, balanceIn, balanceOut := req.Pool, new(big.Int), new(big.Int)
+	if h, ok := p.pool.(amm.Holding); ok {
+		holder, balanceIn, balanceOut = h.Holder(), h.Balance(p.dir.In), h.Balance(p.dir.Out)
+	}
+	src := s.tokenBehaviour(ctx, chain, holder, req.Src, balanceIn)
+	dst := s.tokenBehaviour(ctx, chain, holder, req.Dst, balanceOut)
+	resp.Taxed = src.InputTaxBps > 0 || dst.OutputTaxBps > 0
+	resp.Rebasing = src.Rebasing || dst.Rebasing
+
+	// The pool swaps what arrives after the input tax, and the output tax is
+	// taken from what the pool sends.
+	amountIn := new(big.Int)
+	if !dexmath.ApplyTransferTaxInto(amountIn, req.SrcAmount, src.InputTaxBps) {
+		return nil, errors.Wrap(apperrors.ErrInsufficientLiquidity, "src token tax is 100%")
+	}
+
+	swapOut, err := quotePool(ctx, p.pool, amountIn, p.dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "quotePool")
+	}
+
+	switch q, ok := p.pool.(amm.ContractQuoter); {
+	case p.check != nil:
+		p.check(ctx, resp, amountIn, swapOut)
+	case !ok:
+		if req.CrossCheck {
+			resp.CrossCheck = &dto.CrossCheck{Error: "not supported for " + string(poolType) + " pools"}
+			metrics.RecordCrossCheck(chain.Name, metrics.CrossCheckError)
+		}
+	case wantPoolCrossCheck(chain, req.CrossCheck, p.factory):
+		resp.CrossCheck = poolCrossCheck(chain, q.BlockNumber(), swapOut, func() (*big.Int, error) {
+			return q.QuoteContract(ctx, amountIn, p.dir)
+		})
+	}
+
+	out := new(big.Int)
+	if !dexmath.ApplyTransferTaxInto(out, swapOut, dst.OutputTaxBps) || out.Sign() == 0 {
+		return nil, errors.Wrap(apperrors.ErrInsufficientLiquidity, "bad estimate")
+	}
+	resp.DstAmount = out
+
+	return resp, nil
+}
+
+// quotePool quotes a swap of amountIn, expanding pools whose state covers only
+// part of the pool until they can make it.
+func quotePool(ctx context.Context, pool amm.Pool, amountIn *big.Int, dir amm.Direction) (*big.Int, error) {
+	for {
+		if out, ok := pool.Quote(amountIn, dir); ok {
+			return out, nil
+		}
+
+		e, ok := pool.(amm.Expander)
+		if !ok {
+			return nil, errors.Wrap(apperrors.ErrInsufficientLiquidity, "bad estimate")
+		}
+		next, more, err := e.Expand(ctx, dir)
+		if err != nil {
+			return nil, errors.Wrap(err, "e.Expand")
+		}
+		if !more {
+			return nil, errors.Wrap(apperrors.ErrInsufficientLiquidity, "swap exceeds the liquidity near the current price")
+		}
+		pool = next
+	}
+}
+
+// poolDirection finds src and dst among a pool's tokens.
+func poolDirection(pool amm.Pool, src, dst common.Address) (amm.Direction, error) {
+	dir, ok := amm.DirectionOf(pool, src, dst)
+	if !ok {
+		tokens := make([]string, len(pool.Tokens()))
+		for i, token := range pool.Tokens() {
+			tokens[i] = token.Hex()
+		}
+		return dir, errors.Wrapf(
+			apperrors.ErrInvalidArgument,
+			"src/dst does not match pool tokens: pool has %s",
+			strings.Join(tokens, ", "),
+		)
+	}
+	return dir, nil
+}
+
+// openV2 opens a Uniswap V2 pair, or the snapshot of one. Requested or sampled
+// quotes are cross-checked against the factory's router instead of the pair,
+// at the block the reserves are read at.
+func (s *EstimatorService) openV2(
+	ctx context.Context,
+	chain *Chain,
+	req dto.EstimateRequest,
+	resp *dto.EstimateResponse,
+) (*openedPool, error) {
+	snap := req.Snapshot
+	var pool *resolvedPool
+	var err error
+	if snap != nil && snap.Token0 != (common.Address{}) {
+		pool, err = s.resolveSnapshotPool(ctx, chain, req.Pool, req.Src, req.Dst, snap.Token0, snap.Token1)
+		if err != nil {
+			return nil, errors.Wrap(err, "s.resolveSnapshotPool")
+		}
+	} else {
+		pool, err = s.resolvePool(ctx, chain, req.Pool, req.Src, req.Dst, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "s.resolvePool")
+		}
+	}
+	resp.Hypothetical = snap != nil
+
+	// A cross-check reads the reserves at the block it compares the router at.
+	var blockNumber *big.Int
+	if wantCrossCheck(chain, req.CrossCheck, pool.factory) {
+		resp.CrossCheck = beginCrossCheck(ctx, chain, pool.factory)
+		if resp.CrossCheck.Error == "" {
+			blockNumber = new(big.Int).SetUint64(resp.CrossCheck.BlockNumber)
+		}
+	}
+
+	pair := amm.NewV2Pair(chain.Client, req.Pool, pool.token0, pool.token1, pool.feeBps)
+	if snap != nil {
+		pair = pair.WithReserves(snap.Reserve0, snap.Reserve1)
+	} else {
+		pair, err = pair.ReservesAt(ctx, blockNumber)
 		if err != nil {
-			return nil, errors.Wrap(err, "s.uniswapClient.GetPairReserves")
+			return nil, errors.Wrap(err, "pair.ReservesAt")
+		}
+
+		resp.PoolUpdatedAt = pair.UpdatedAt()
+		resp.PoolAge = max(time.Since(resp.PoolUpdatedAt).Truncate(time.Second), 0)
+		if maxAge := poolAgeLimit(chain.MaxPoolAge, req.MaxPoolAge); maxAge > 0 && resp.PoolAge > maxAge {
+			return nil, errors.Wrapf(apperrors.ErrStalePool, "pool last updated %s ago, more than %s", resp.PoolAge, maxAge)
+		}
+
+		r0, r1 := pair.Reserves()
+		if err := s.checkTWAPDeviation(ctx, chain, req.Pool, r0, r1); err != nil {
+			return nil, errors.Wrap(err, "s.checkTWAPDeviation")
+		}
+	}
+
+	dir := amm.ZeroForOne
+	if !pool.zeroForOne {
+		dir = amm.OneForZero
+	}
+
+	check := func(ctx context.Context, resp *dto.EstimateResponse, amountIn, swapOut *big.Int) {
+		if req.SlippageBps > 0 {
+			resp.MEVExposure = mevExposure(amountIn, pair.Balance(dir.In), pair.Balance(dir.Out), pool.feeBps, req.SlippageBps)
+		}
+		if blockNumber != nil {
+			finishCrossCheck(ctx, chain, resp.CrossCheck, amountIn, req.Src, req.Dst, swapOut)
 		}
-		reserves.in, reserves.out = r0, r1
+	}
+	return &openedPool{pool: pair, dir: dir, factory: pool.factory, check: check}, nil
+}
+
+// rejectV2Guards rejects a request for sandwich exposure or a pool age limit,
+// which only the uniswap-v2 backend applies, against a pool of another type.
+func rejectV2Guards(poolType PoolType, req dto.EstimateRequest) error {
+	if req.SlippageBps > 0 {
+		return errors.Wrapf(apperrors.ErrInvalidArgument, "slippage_bps is not supported for %s pools", poolType)
+	}
+	if req.MaxPoolAge > 0 {
+		return errors.Wrapf(apperrors.ErrInvalidArgument, "max_pool_age is not supported for %s pools", poolType)
+	}
+	return nil
+}
+
+// poolAgeLimit returns the stricter of the chain's and the request's pool age
+// limits, zero if neither is set.
+func poolAgeLimit(chain, req time.Duration) time.Duration {
+	if chain == 0 || (req > 0 && req < chain) {
+		return req
+	}
+	return chain
+}
+
+// mevExposure computes the optimal sandwich of a swap of amountIn.
+func mevExposure(amountIn, reserveIn, reserveOut *big.Int, feeBps, slippageBps uint32) *dto.MEVExposure {
+	pool := dexmath.PoolState{Reserve0: reserveIn, Reserve1: reserveOut, FeeBps: feeBps}
+	sandwich, ok := dexmath.OptimalSandwich(pool, amountIn, dexmath.ZeroForOne, slippageBps)
+	if !ok {
+		return nil
+	}
+
+	return &dto.MEVExposure{
+		SlippageBps:    slippageBps,
+		FrontRun:       sandwich.FrontRun,
+		AttackerProfit: sandwich.Profit,
+		VictimLoss:     sandwich.VictimLoss,
+	}
+}
+
+// resolvedPool is a pool checked against the chain's policies and matched
+// with the direction of a swap.
+type resolvedPool struct {
+	token0     common.Address
+	token1     common.Address
+	zeroForOne bool
+	// factory is the verified deployer of the pool, or nil.
+	factory *Factory
+	feeBps  uint32
+}
 
-	case isTokenMatch(req.Src, token1) && isTokenMatch(req.Dst, token0):
-		r0, r1, err := s.uniswapClient.GetPairReserves(ctx, req.Pool)
+// resolvePool enforces the chain's safety policy, matches src and dst with the
+// pool's tokens and verifies the pool. tokens are the pool's token0 and token1
+// when the caller knows them already; nil reads them from the pool.
+func (s *EstimatorService) resolvePool(
+	ctx context.Context,
+	chain *Chain,
+	pool, src, dst common.Address,
+	tokens *[2]common.Address,
+) (*resolvedPool, error) {
+	// Checked before any RPC call so that denied contracts are never called.
+	if err := checkSafety(chain, pool, src, dst); err != nil {
+		return nil, errors.Wrap(err, "checkSafety")
+	}
+
+	var token0, token1 common.Address
+	var err error
+	if tokens != nil {
+		token0, token1 = tokens[0], tokens[1]
+	} else {
+		token0, token1, err = chain.Client.GetPairTokens(ctx, pool)
 		if err != nil {
-			return nil, errors.Wrap(err, "s.uniswapClient.GetPairReserves")
+			return nil, errors.Wrap(err, "chain.Client.GetPairTokens")
 		}
-		reserves.in, reserves.out = r1, r0
+	}
+
+	zeroForOne, err := matchTokens(src, dst, token0, token1)
+	if err != nil {
+		return nil, errors.Wrap(err, "matchTokens")
+	}
+
+	res, err := s.verifiedPool(ctx, chain, pool, token0, token1)
+	if err != nil {
+		return nil, errors.Wrap(err, "s.verifiedPool")
+	}
+	res.zeroForOne = zeroForOne
+	return res, nil
+}
+
+// verifiedPool verifies a pool whose tokens passed the chain's safety policy
+// and picks its fee, for a swap from token0 to token1.
+func (s *EstimatorService) verifiedPool(
+	ctx context.Context,
+	chain *Chain,
+	pool, token0, token1 common.Address,
+) (*resolvedPool, error) {
+	factory, err := s.checkPool(ctx, chain, PoolTypeUniswapV2, pool, token0, token1, poolParams{})
+	if err != nil {
+		return nil, errors.Wrap(err, "s.checkPool")
+	}
+	return newResolvedPool(chain, token0, token1, true, factory), nil
+}
+
+// resolveSnapshotPool is resolvePool for a pair whose tokens a snapshot gives.
+// The tokens are only the client's claim, so the pair is verified against them
+// without the verdict cache, which must hold verdicts of on-chain tokens only.
+func (s *EstimatorService) resolveSnapshotPool(
+	ctx context.Context,
+	chain *Chain,
+	pool, src, dst, token0, token1 common.Address,
+) (*resolvedPool, error) {
+	if err := checkSafety(chain, pool, src, dst); err != nil {
+		return nil, errors.Wrap(err, "checkSafety")
+	}
+
+	zeroForOne, err := matchTokens(src, dst, token0, token1)
+	if err != nil {
+		return nil, errors.Wrap(err, "matchTokens")
+	}
+
+	if chain.PairVerification == PairVerificationOff {
+		return newResolvedPool(chain, token0, token1, zeroForOne, nil), nil
+	}
+
+	factory, _, err := findFactory(ctx, chain, PoolTypeUniswapV2, pool, token0, token1, poolParams{})
+	if err != nil {
+		return nil, errors.Wrap(err, "findFactory")
+	}
+	if err := checkVerdict(chain, pool, factory); err != nil {
+		return nil, errors.Wrap(err, "checkVerdict")
+	}
+	return newResolvedPool(chain, token0, token1, zeroForOne, factory), nil
+}
+
+// newResolvedPool creates a resolved pool with the fee of its factory, or of
+// the chain if it is unverified.
+func newResolvedPool(chain *Chain, token0, token1 common.Address, zeroForOne bool, factory *Factory) *resolvedPool {
+	res := &resolvedPool{token0: token0, token1: token1, zeroForOne: zeroForOne, factory: factory, feeBps: chain.FeeBps}
+	if factory != nil {
+		res.feeBps = factory.FeeBps
+	}
+	return res
+}
 
+// matchTokens reports whether src and dst are the pool's token0 and token1
+// (zeroForOne) or the other way round.
+func matchTokens(src, dst, token0, token1 common.Address) (bool, error) {
+	switch {
+	case isTokenMatch(src, token0) && isTokenMatch(dst, token1):
+		return true, nil
+	case isTokenMatch(src, token1) && isTokenMatch(dst, token0):
+		return false, nil
 	default:
-		return nil, errors.Wrapf(
+		return false, errors.Wrapf(
 			apperrors.ErrInvalidArgument,
 			"src/dst does not match pool tokens: pool has %s and %s",
 			token0.Hex(), token1.Hex(),
 		)
 	}
+}
 
-	out := new(big.Int)
-	if !dexmath.GetAmountOutInto(out, req.SrcAmount, reserves.in, reserves.out) || out.Sign() == 0 {
-		return nil, errors.Wrap(apperrors.ErrInsufficientLiquidity, "bad estimate")
+// checkPool verifies a pool under the chain's pair verification mode. It
+// returns the factory that deployed the pool, or nil if it is unverified or
+// verification is off, and rejects unverified pools in strict mode.
+func (s *EstimatorService) checkPool(
+	ctx context.Context,
+	chain *Chain,
+	poolType PoolType,
+	pool, token0, token1 common.Address,
+	params poolParams,
+) (*Factory, error) {
+	if chain.PairVerification == PairVerificationOff {
+		return nil, nil
+	}
+
+	factory, err := s.verifyPool(ctx, chain, poolType, pool, token0, token1, params)
+	if err != nil {
+		return nil, errors.Wrap(err, "s.verifyPool")
 	}
+	if err := checkVerdict(chain, pool, factory); err != nil {
+		return nil, errors.Wrap(err, "checkVerdict")
+	}
+	return factory, nil
+}
+
+// checkVerdict rejects an unverified pool in strict mode.
+func checkVerdict(chain *Chain, pool common.Address, factory *Factory) error {
+	if factory == nil && chain.PairVerification == PairVerificationStrict {
+		return errors.Wrapf(apperrors.ErrUnverifiedPool, "pool %s", pool.Hex())
+	}
+	return nil
+}
 
-	return out, nil
+// checkSafety enforces the chain's pool and token safety policy.
+func checkSafety(chain *Chain, pool, src, dst common.Address) error {
+	if chain.Safety == nil {
+		return nil
+	}
+	if err := chain.Safety.CheckPool(pool); err != nil {
+		return errors.Wrap(err, "chain.Safety.CheckPool")
+	}
+	if err := chain.Safety.CheckTokens(src, dst); err != nil {
+		return errors.Wrap(err, "chain.Safety.CheckTokens")
+	}
+	return nil
 }
 
 func isTokenMatch(addr1, addr2 common.Address) bool {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
@@ -11,7 +12,11 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+	"github.com/fleshka4/1inch-test-task/internal/dexmath"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
 	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+	"github.com/fleshka4/1inch-test-task/internal/safety"
 	"github.com/fleshka4/1inch-test-task/internal/service/dto"
 )
 
@@ -37,7 +42,7 @@ func TestEstimate(t *testing.T) {
 					Return(token0, token1, nil)
 				mc.EXPECT().
 					GetPairReserves(gomock.Any(), poolAddr).
-					Return(big.NewInt(10000), big.NewInt(20000), nil)
+					Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(20000)}, nil)
 			},
 			req: dto.EstimateRequest{
 				Pool:      poolAddr,
@@ -136,7 +141,7 @@ func TestEstimate(t *testing.T) {
 					Return(token0, token1, nil)
 				mc.EXPECT().
 					GetPairReserves(gomock.Any(), poolAddr).
-					Return(nil, nil, errors.New("RPC error"))
+					Return(nil, errors.New("RPC error"))
 			},
 			req: dto.EstimateRequest{
 				Pool:      poolAddr,
@@ -171,7 +176,7 @@ func TestEstimate(t *testing.T) {
 					Return(token0, token1, nil)
 				mc.EXPECT().
 					GetPairReserves(gomock.Any(), poolAddr).
-					Return(bigReserveIn, bigReserveOut, nil)
+					Return(&uniswapdto.PairReserves{Reserve0: bigReserveIn, Reserve1: bigReserveOut}, nil)
 			},
 			req: dto.EstimateRequest{
 				Pool:      poolAddr,
@@ -201,7 +206,7 @@ func TestEstimate(t *testing.T) {
 			defer ctrl.Finish()
 
 			mockClient := mock.NewMockClient(ctrl)
-			service := NewEstimatorService(mockClient)
+			service := NewEstimatorService(Chain{ID: 1, Name: "ethereum", Client: mockClient, FeeBps: 30})
 
 			if tt.mockSetup != nil {
 				tt.mockSetup(mockClient)
@@ -212,8 +217,389 @@ func TestEstimate(t *testing.T) {
 
 			if err == nil {
 				require.NotNil(t, result)
-				require.True(t, result.Cmp(big.NewInt(0)) > 0)
+				require.True(t, result.DstAmount.Sign() > 0)
 			}
 		})
 	}
 }
+
+func TestEstimate_ChainRouting(t *testing.T) {
+	t.Parallel()
+
+	poolAddr := common.HexToAddress("0x1234")
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+
+	newRequest := func(chainID uint64, chain string) dto.EstimateRequest {
+		return dto.EstimateRequest{
+			ChainID:   chainID,
+			Chain:     chain,
+			Pool:      poolAddr,
+			Src:       token0,
+			Dst:       token1,
+			SrcAmount: big.NewInt(1000),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		req     dto.EstimateRequest
+		target  string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{name: "default chain", req: newRequest(0, ""), target: "ethereum", wantErr: assert.NoError},
+		{name: "by chain id", req: newRequest(56, ""), target: "bsc", wantErr: assert.NoError},
+		{name: "by name case insensitive", req: newRequest(0, "BSC"), target: "bsc", wantErr: assert.NoError},
+		{name: "id and name match", req: newRequest(56, "bsc"), target: "bsc", wantErr: assert.NoError},
+		{name: "id and name mismatch", req: newRequest(1, "bsc"), wantErr: assert.Error},
+		{name: "unknown chain id", req: newRequest(137, ""), wantErr: assert.Error},
+		{name: "unknown chain name", req: newRequest(0, "polygon"), wantErr: assert.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			clients := map[string]*mock.MockClient{
+				"ethereum": mock.NewMockClient(ctrl),
+				"bsc":      mock.NewMockClient(ctrl),
+			}
+			if target, ok := clients[tt.target]; ok {
+				target.EXPECT().GetPairTokens(gomock.Any(), poolAddr).Return(token0, token1, nil)
+				target.EXPECT().GetPairReserves(gomock.Any(), poolAddr).Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(20000)}, nil)
+			}
+
+			service := NewEstimatorService(
+				Chain{ID: 1, Name: "ethereum", Client: clients["ethereum"], FeeBps: 30},
+				Chain{ID: 56, Name: "bsc", Client: clients["bsc"], FeeBps: 25},
+			)
+
+			_, err := service.Estimate(context.Background(), tt.req)
+			tt.wantErr(t, err)
+			if err != nil {
+				require.ErrorIs(t, err, apperrors.ErrInvalidArgument)
+			}
+		})
+	}
+}
+
+func TestEstimate_SafetyPolicy(t *testing.T) {
+	t.Parallel()
+
+	poolAddr := common.HexToAddress("0x1234")
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+
+	tests := []struct {
+		name    string
+		rules   safety.Rules
+		wantErr bool
+	}{
+		{name: "allowed", rules: safety.Rules{PoolAllowlist: []common.Address{poolAddr}}},
+		{name: "pool denied", rules: safety.Rules{PoolDenylist: []common.Address{poolAddr}}, wantErr: true},
+		{name: "pool not allowlisted", rules: safety.Rules{PoolAllowlist: []common.Address{token0}}, wantErr: true},
+		{name: "token denied", rules: safety.Rules{TokenDenylist: []common.Address{token1}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Denied requests must not reach the chain.
+			mockClient := mock.NewMockClient(ctrl)
+			if !tt.wantErr {
+				mockClient.EXPECT().GetPairTokens(gomock.Any(), poolAddr).Return(token0, token1, nil)
+				mockClient.EXPECT().GetPairReserves(gomock.Any(), poolAddr).Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(20000)}, nil)
+			}
+
+			policy, err := safety.NewPolicy(tt.rules)
+			require.NoError(t, err)
+
+			service := NewEstimatorService(Chain{ID: 1, Client: mockClient, FeeBps: 30, Safety: safety.NewGuard(policy)})
+
+			_, err = service.Estimate(context.Background(), dto.EstimateRequest{
+				Pool:      poolAddr,
+				Src:       token0,
+				Dst:       token1,
+				SrcAmount: big.NewInt(1000),
+			})
+			if tt.wantErr {
+				require.ErrorIs(t, err, apperrors.ErrNotAllowed)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEstimate_Snapshot(t *testing.T) {
+	t.Parallel()
+
+	pool := common.HexToAddress("0x1234")
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+
+	tests := []struct {
+		name      string
+		snapshot  dto.PoolSnapshot
+		src, dst  common.Address
+		mockSetup func(mc *mock.MockClient)
+		want      int64
+		wantErr   error
+	}{
+		{
+			// 1000 in against 10000/20000 reserves with a 0.3% fee yields 1813.
+			name:     "reserves override chain state",
+			snapshot: dto.PoolSnapshot{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(20000)},
+			src:      token0,
+			dst:      token1,
+			mockSetup: func(mc *mock.MockClient) {
+				mc.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil)
+			},
+			want: 1813,
+		},
+		{
+			name: "full snapshot needs no calls",
+			snapshot: dto.PoolSnapshot{
+				Token0:   token0,
+				Token1:   token1,
+				Reserve0: big.NewInt(20000),
+				Reserve1: big.NewInt(10000),
+			},
+			src:  token1,
+			dst:  token0,
+			want: 1813,
+		},
+		{
+			name: "direction still matched",
+			snapshot: dto.PoolSnapshot{
+				Token0:   token0,
+				Token1:   token1,
+				Reserve0: big.NewInt(10000),
+				Reserve1: big.NewInt(20000),
+			},
+			src:     token0,
+			dst:     common.HexToAddress("0x9999"),
+			wantErr: apperrors.ErrInvalidArgument,
+		},
+		{
+			name: "empty reserves",
+			snapshot: dto.PoolSnapshot{
+				Token0:   token0,
+				Token1:   token1,
+				Reserve0: big.NewInt(0),
+				Reserve1: big.NewInt(20000),
+			},
+			src:     token0,
+			dst:     token1,
+			wantErr: apperrors.ErrInsufficientLiquidity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockClient(ctrl)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockClient)
+			}
+
+			service := NewEstimatorService(Chain{ID: 1, Client: mockClient, FeeBps: 30})
+
+			resp, err := service.Estimate(context.Background(), dto.EstimateRequest{
+				Pool:      pool,
+				Src:       tt.src,
+				Dst:       tt.dst,
+				SrcAmount: big.NewInt(1000),
+				Snapshot:  &tt.snapshot,
+			})
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, big.NewInt(tt.want), resp.DstAmount)
+			require.True(t, resp.Hypothetical)
+		})
+	}
+}
+
+func TestEstimate_MEVExposure(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pool := common.HexToAddress("0x1234")
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+
+	mockClient := mock.NewMockClient(ctrl)
+	mockClient.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil).Times(2)
+	mockClient.EXPECT().GetPairReserves(gomock.Any(), pool).Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(20000)}, nil).Times(2)
+
+	service := NewEstimatorService(Chain{ID: 1, Client: mockClient, FeeBps: 30})
+
+	req := dto.EstimateRequest{Pool: pool, Src: token1, Dst: token0, SrcAmount: big.NewInt(1000)}
+	resp, err := service.Estimate(context.Background(), req)
+	require.NoError(t, err)
+	require.Nil(t, resp.MEVExposure)
+
+	req.SlippageBps = 500
+	resp, err = service.Estimate(context.Background(), req)
+	require.NoError(t, err)
+
+	// The swap sells token1, so the sandwich runs on the reversed reserves.
+	want, ok := dexmath.OptimalSandwich(
+		dexmath.PoolState{Reserve0: big.NewInt(20000), Reserve1: big.NewInt(10000), FeeBps: 30},
+		big.NewInt(1000), dexmath.ZeroForOne, 500,
+	)
+	require.True(t, ok)
+	require.Positive(t, want.Profit.Sign())
+	require.Equal(t, &dto.MEVExposure{
+		SlippageBps:    500,
+		FrontRun:       want.FrontRun,
+		AttackerProfit: want.Profit,
+		VictimLoss:     want.VictimLoss,
+	}, resp.MEVExposure)
+}
+
+func TestEstimate_V2Guards(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		poolType PoolType
+		req      dto.EstimateRequest
+		wantErr  string
+	}{
+		{
+			name:     "slippage on uniswap-v3",
+			poolType: PoolTypeUniswapV3,
+			req:      dto.EstimateRequest{SlippageBps: 50},
+			wantErr:  "slippage_bps is not supported for uniswap-v3 pools",
+		},
+		{
+			name:     "pool age on solidly",
+			poolType: PoolTypeSolidly,
+			req:      dto.EstimateRequest{MaxPoolAge: time.Hour},
+			wantErr:  "max_pool_age is not supported for solidly pools",
+		},
+		{
+			name:     "slippage on curve",
+			poolType: PoolTypeCurve,
+			req:      dto.EstimateRequest{SlippageBps: 50},
+			wantErr:  "slippage_bps is not supported for curve pools",
+		},
+		{
+			name:     "pool age on balancer-weighted",
+			poolType: PoolTypeBalancerWeighted,
+			req:      dto.EstimateRequest{MaxPoolAge: time.Hour},
+			wantErr:  "max_pool_age is not supported for balancer-weighted pools",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := NewEstimatorService(Chain{ID: 1, Client: mock.NewMockClient(ctrl), FeeBps: 30})
+
+			req := tt.req
+			req.Pool = common.HexToAddress("0x1234")
+			req.Src = common.HexToAddress("0x5678")
+			req.Dst = common.HexToAddress("0x12345678")
+			req.SrcAmount = big.NewInt(100)
+			req.PoolType = string(tt.poolType)
+
+			_, err := service.Estimate(context.Background(), req)
+			require.ErrorIs(t, err, apperrors.ErrInvalidArgument)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestEstimate_PoolAge(t *testing.T) {
+	t.Parallel()
+
+	pool := common.HexToAddress("0x1234")
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+
+	tests := []struct {
+		name       string
+		chainLimit time.Duration
+		reqLimit   time.Duration
+		age        time.Duration
+		snapshot   bool
+		wantIs     error
+	}{
+		{name: "no limit", age: 30 * 24 * time.Hour},
+		{name: "within chain limit", chainLimit: time.Hour, age: 10 * time.Minute},
+		{name: "beyond chain limit", chainLimit: time.Hour, age: 2 * time.Hour, wantIs: apperrors.ErrStalePool},
+		{name: "beyond request limit", reqLimit: 5 * time.Minute, age: 10 * time.Minute, wantIs: apperrors.ErrStalePool},
+		{
+			name:       "stricter chain limit applies",
+			chainLimit: time.Hour,
+			reqLimit:   3 * time.Hour,
+			age:        2 * time.Hour,
+			wantIs:     apperrors.ErrStalePool,
+		},
+		{name: "snapshot is not checked", chainLimit: time.Hour, snapshot: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			updatedAt := time.Now().Add(-tt.age).Truncate(time.Second)
+
+			mockClient := mock.NewMockClient(ctrl)
+			mockClient.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil)
+			req := dto.EstimateRequest{
+				Pool: pool, Src: token0, Dst: token1, SrcAmount: big.NewInt(100), MaxPoolAge: tt.reqLimit,
+			}
+			if tt.snapshot {
+				req.Snapshot = &dto.PoolSnapshot{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(20000)}
+			} else {
+				mockClient.EXPECT().GetPairReserves(gomock.Any(), pool).Return(&uniswapdto.PairReserves{
+					Reserve0:           big.NewInt(10000),
+					Reserve1:           big.NewInt(20000),
+					BlockTimestampLast: uint32(updatedAt.Unix()),
+				}, nil)
+			}
+
+			service := NewEstimatorService(Chain{ID: 1, Client: mockClient, FeeBps: 30, MaxPoolAge: tt.chainLimit})
+			resp, err := service.Estimate(context.Background(), req)
+			if tt.wantIs != nil {
+				require.ErrorIs(t, err, tt.wantIs)
+				return
+			}
+			require.NoError(t, err)
+
+			if tt.snapshot {
+				require.True(t, resp.PoolUpdatedAt.IsZero())
+				require.Zero(t, resp.PoolAge)
+				return
+			}
+			require.True(t, updatedAt.Equal(resp.PoolUpdatedAt))
+			require.InDelta(t, tt.age.Seconds(), resp.PoolAge.Seconds(), 2)
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/amm"
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+	"github.com/fleshka4/1inch-test-task/internal/dexmath"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+	"github.com/fleshka4/1inch-test-task/internal/service/validate"
+)
+
+// historyConcurrency bounds the blocks of an estimate history read at a time.
+const historyConcurrency = 8
+
+// EstimateHistory estimates a swap against a Uniswap V2 pair at every Step-th
+// block from FromBlock to ToBlock and passes the estimates to emit in block
+// order. The pair's reserves are read by an archive eth_call at each block, for
+// up to historyConcurrency blocks at a time, unless the chain's indexer has them.
+//
+// The pool is resolved and verified once, at the latest block, and only the
+// tokens' configured transfer taxes are applied. A block whose estimate fails
+// is emitted with the error; an error of emit stops the history.
+func (s *EstimatorService) EstimateHistory(
+	ctx context.Context,
+	req dto.EstimateHistoryRequest,
+	emit func(dto.HistoricalEstimate) error,
+) error {
+	if err := validate.EstimateHistoryRequestValidate(req); err != nil {
+		return errors.Wrap(err, "validate.EstimateHistoryRequestValidate")
+	}
+
+	chain, err := s.chain(req.ChainID, req.Chain)
+	if err != nil {
+		return errors.Wrap(err, "s.chain")
+	}
+
+	if err := checkSafety(chain, req.Pool, req.Src, req.Dst); err != nil {
+		return errors.Wrap(err, "checkSafety")
+	}
+
+	poolType, err := s.poolType(ctx, chain, req.Pool)
+	if err != nil {
+		return errors.Wrap(err, "s.poolType")
+	}
+	if poolType != PoolTypeUniswapV2 {
+		return errors.Wrapf(apperrors.ErrInvalidArgument, "history is not supported for %s pools", poolType)
+	}
+
+	pool, err := s.resolvePool(ctx, chain, req.Pool, req.Src, req.Dst, nil)
+	if err != nil {
+		return errors.Wrap(err, "s.resolvePool")
+	}
+
+	latest, err := chain.Client.BlockNumber(ctx)
+	if err != nil {
+		return errors.Wrap(err, "chain.Client.BlockNumber")
+	}
+	if req.ToBlock > latest {
+		return errors.Wrapf(apperrors.ErrInvalidArgument, "to block %d is after the latest block %d", req.ToBlock, latest)
+	}
+
+	q := historyQuote{
+		pair:      amm.NewV2Pair(chain.Client, req.Pool, pool.token0, pool.token1, pool.feeBps),
+		dir:       amm.ZeroForOne,
+		srcAmount: req.SrcAmount,
+		amountIn:  new(big.Int),
+	}
+	if !pool.zeroForOne {
+		q.dir = amm.OneForZero
+	}
+
+	src := s.tokenBehaviour(ctx, chain, req.Pool, req.Src, new(big.Int))
+	dst := s.tokenBehaviour(ctx, chain, req.Pool, req.Dst, new(big.Int))
+	if !dexmath.ApplyTransferTaxInto(q.amountIn, req.SrcAmount, src.InputTaxBps) {
+		return errors.Wrap(apperrors.ErrInsufficientLiquidity, "src token tax is 100%")
+	}
+	q.outputTaxBps = dst.OutputTaxBps
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Every block is estimated by its own goroutine, started once its result
+	// fits in the queue: with the result being awaited, at most
+	// historyConcurrency run at a time.
+	queue := make(chan chan dto.HistoricalEstimate, historyConcurrency-1)
+	go func() {
+		defer close(queue)
+		for block := req.FromBlock; block <= req.ToBlock; block += req.Step {
+			res := make(chan dto.HistoricalEstimate, 1)
+			select {
+			case queue <- res:
+			case <-ctx.Done():
+				return
+			}
+			go func() { res <- q.at(ctx, block) }()
+		}
+	}()
+
+	for res := range queue {
+		estimate := <-res
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := emit(estimate); err != nil {
+			return errors.Wrap(err, "emit")
+		}
+	}
+
+	return nil
+}
+
+// historyQuote is a swap estimated at past blocks.
+type historyQuote struct {
+	pair      *amm.V2Pair
+	dir       amm.Direction
+	srcAmount *big.Int
+	// amountIn is what the pair receives after the input tax.
+	amountIn     *big.Int
+	outputTaxBps uint32
+}
+
+// at estimates the swap at a block.
+func (q historyQuote) at(ctx context.Context, block uint64) dto.HistoricalEstimate {
+	estimate := dto.HistoricalEstimate{BlockNumber: block}
+
+	pair, err := q.pair.ReservesAt(ctx, new(big.Int).SetUint64(block))
+	if err != nil {
+		estimate.Error = "reserves: " + err.Error()
+		return estimate
+	}
+	estimate.ReservesTimestamp = uint64(pair.UpdatedAt().Unix()) //nolint:gosec
+
+	reserveIn, reserveOut := pair.Reserves()
+	if q.dir == amm.OneForZero {
+		reserveIn, reserveOut = reserveOut, reserveIn
+	}
+	if reserveIn.Sign() <= 0 || reserveOut.Sign() <= 0 {
+		estimate.Error = "pool has no liquidity"
+		return estimate
+	}
+	estimate.SpotPrice = new(big.Rat).SetFrac(reserveOut, reserveIn)
+
+	swapOut, ok := pair.Quote(q.amountIn, q.dir)
+	out := new(big.Int)
+	if !ok || !dexmath.ApplyTransferTaxInto(out, swapOut, q.outputTaxBps) || out.Sign() == 0 {
+		estimate.Error = "insufficient liquidity"
+		return estimate
+	}
+	estimate.DstAmount = out
+	estimate.Price = new(big.Rat).SetFrac(out, q.srcAmount)
+
+	return estimate
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+	"github.com/fleshka4/1inch-test-task/internal/indexer"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+)
+
+func TestEstimateHistory(t *testing.T) {
+	t.Parallel()
+
+	pool := common.HexToAddress("0x1234")
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+	errNoState := errors.New("missing trie node")
+
+	// The pool's reserve1 grows by 1000 every block from 10000 at block 100,
+	// and its state is pruned before block 100.
+	historyChain := func(mc *mock.MockClient) {
+		mc.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil)
+		mc.EXPECT().BlockNumber(gomock.Any()).Return(uint64(200), nil)
+		mc.EXPECT().GetPairReservesAt(gomock.Any(), pool, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ common.Address, n *big.Int) (*uniswapdto.PairReserves, error) {
+				if n.Uint64() < 100 {
+					return nil, errNoState
+				}
+				return &uniswapdto.PairReserves{
+					Reserve0:           big.NewInt(10000),
+					Reserve1:           big.NewInt(10000 + 1000*(n.Int64()-100)),
+					BlockTimestampLast: uint32(1_700_000_000 + 12*n.Uint64()), //nolint:gosec
+				}, nil
+			},
+		).AnyTimes()
+	}
+
+	tests := []struct {
+		name      string
+		req       dto.EstimateHistoryRequest
+		chain     Chain
+		mockSetup func(mc *mock.MockClient)
+		want      []dto.HistoricalEstimate
+		wantIs    error
+	}{
+		{
+			name:      "every step from a block without state",
+			req:       dto.EstimateHistoryRequest{Src: token0, Dst: token1, FromBlock: 99, ToBlock: 104, Step: 2},
+			mockSetup: historyChain,
+			want: []dto.HistoricalEstimate{
+				{BlockNumber: 99, Error: "reserves: p.client.GetPairReserves: missing trie node"},
+				{
+					BlockNumber: 101, ReservesTimestamp: 1_700_001_212, DstAmount: big.NewInt(108),
+					Price: big.NewRat(108, 100), SpotPrice: big.NewRat(11, 10),
+				},
+				{
+					BlockNumber: 103, ReservesTimestamp: 1_700_001_236, DstAmount: big.NewInt(128),
+					Price: big.NewRat(128, 100), SpotPrice: big.NewRat(13, 10),
+				},
+			},
+		},
+		{
+			name:      "reversed direction",
+			req:       dto.EstimateHistoryRequest{Src: token1, Dst: token0, FromBlock: 100, ToBlock: 100, Step: 1},
+			mockSetup: historyChain,
+			want: []dto.HistoricalEstimate{{
+				BlockNumber: 100, ReservesTimestamp: 1_700_001_200, DstAmount: big.NewInt(98),
+				Price: big.NewRat(98, 100), SpotPrice: big.NewRat(1, 1),
+			}},
+		},
+		{
+			name:      "configured output tax",
+			req:       dto.EstimateHistoryRequest{Src: token0, Dst: token1, FromBlock: 100, ToBlock: 100, Step: 1},
+			chain:     Chain{Tokens: map[common.Address]TokenBehaviour{token1: {OutputTaxBps: 1000}}},
+			mockSetup: historyChain,
+			want: []dto.HistoricalEstimate{{
+				BlockNumber: 100, ReservesTimestamp: 1_700_001_200, DstAmount: big.NewInt(88),
+				Price: big.NewRat(88, 100), SpotPrice: big.NewRat(1, 1),
+			}},
+		},
+		{
+			name: "range past the latest block",
+			req:  dto.EstimateHistoryRequest{Src: token0, Dst: token1, FromBlock: 150, ToBlock: 250, Step: 1},
+			mockSetup: func(mc *mock.MockClient) {
+				mc.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil)
+				mc.EXPECT().BlockNumber(gomock.Any()).Return(uint64(200), nil)
+			},
+			wantIs: apperrors.ErrInvalidArgument,
+		},
+		{
+			name:   "range too long",
+			req:    dto.EstimateHistoryRequest{Src: token0, Dst: token1, FromBlock: 0, ToBlock: 20000, Step: 2},
+			wantIs: apperrors.ErrInvalidArgument,
+		},
+		{
+			name:   "zero step",
+			req:    dto.EstimateHistoryRequest{Src: token0, Dst: token1, FromBlock: 100, ToBlock: 100},
+			wantIs: apperrors.ErrInvalidArgument,
+		},
+		{
+			name:   "not a uniswap-v2 pool",
+			req:    dto.EstimateHistoryRequest{Src: token0, Dst: token1, FromBlock: 100, ToBlock: 100, Step: 1},
+			chain:  Chain{PoolTypes: map[common.Address]PoolType{pool: PoolTypeUniswapV3}},
+			wantIs: apperrors.ErrInvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockClient(ctrl)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockClient)
+			}
+
+			chain := tt.chain
+			chain.ID, chain.Client, chain.FeeBps = 1, mockClient, 30
+			service := NewEstimatorService(chain)
+
+			req := tt.req
+			req.Pool, req.SrcAmount = pool, big.NewInt(100)
+			var got []dto.HistoricalEstimate
+			err := service.EstimateHistory(context.Background(), req, func(e dto.HistoricalEstimate) error {
+				got = append(got, e)
+				return nil
+			})
+			if tt.wantIs != nil {
+				require.ErrorIs(t, err, tt.wantIs)
+				return
+			}
+			require.NoError(t, err)
+			requireEstimates(t, tt.want, got)
+		})
+	}
+}
+
+// requireEstimates compares historical estimates, their prices by value.
+func requireEstimates(t *testing.T, want, got []dto.HistoricalEstimate) {
+	t.Helper()
+
+	rat := func(r *big.Rat) string {
+		if r == nil {
+			return ""
+		}
+		return r.RatString()
+	}
+
+	require.Len(t, got, len(want))
+	for i := range want {
+		w, g := want[i], got[i]
+		require.Equal(t, rat(w.Price), rat(g.Price), "block %d price", w.BlockNumber)
+		require.Equal(t, rat(w.SpotPrice), rat(g.SpotPrice), "block %d spot price", w.BlockNumber)
+
+		w.Price, w.SpotPrice, g.Price, g.SpotPrice = nil, nil, nil, nil
+		require.Equal(t, w, g)
+	}
+}
+
+func TestEstimateHistory_Indexed(t *testing.T) {
+	t.Parallel()
+
+	pool := common.HexToAddress("0x1234")
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+
+	store, err := indexer.OpenStore(filepath.Join(t.TempDir(), "store.ndjson"))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, store.Close()) })
+	require.NoError(t, store.Append(indexer.Batch{
+		Pairs: []common.Address{pool},
+		To:    200,
+		Reserves: map[common.Address][]indexer.Reserves{pool: {
+			{BlockNumber: 100, Timestamp: 1_700_001_200, Reserve0: big.NewInt(10000), Reserve1: big.NewInt(10000)},
+			{BlockNumber: 102, Timestamp: 1_700_001_224, Reserve0: big.NewInt(10000), Reserve1: big.NewInt(12000)},
+		}},
+	}))
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The pool is resolved once; no block of the history reaches the node.
+	mockClient := mock.NewMockClient(ctrl)
+	mockClient.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil)
+	mockClient.EXPECT().BlockNumber(gomock.Any()).Return(uint64(300), nil)
+
+	ix := indexer.New("history", mockClient, store, indexer.Config{Pools: []common.Address{pool}})
+	service := NewEstimatorService(Chain{
+		ID:        1,
+		Client:    indexer.NewClient("history", mockClient, ix),
+		FeeBps:    30,
+		PoolTypes: map[common.Address]PoolType{pool: PoolTypeUniswapV2},
+	})
+
+	var got []dto.HistoricalEstimate
+	err = service.EstimateHistory(context.Background(), dto.EstimateHistoryRequest{
+		Pool: pool, Src: token0, Dst: token1, SrcAmount: big.NewInt(100), FromBlock: 101, ToBlock: 103, Step: 1,
+	}, func(e dto.HistoricalEstimate) error {
+		got = append(got, e)
+		return nil
+	})
+	require.NoError(t, err)
+
+	atBlock := func(n uint64, ts uint64, out int64, spot *big.Rat) dto.HistoricalEstimate {
+		return dto.HistoricalEstimate{
+			BlockNumber: n, ReservesTimestamp: ts, DstAmount: big.NewInt(out),
+			Price: big.NewRat(out, 100), SpotPrice: spot,
+		}
+	}
+	requireEstimates(t, []dto.HistoricalEstimate{
+		atBlock(101, 1_700_001_200, 98, big.NewRat(1, 1)),
+		atBlock(102, 1_700_001_224, 118, big.NewRat(6, 5)),
+		atBlock(103, 1_700_001_224, 118, big.NewRat(6, 5)),
+	}, got)
+}
+
+func TestEstimateHistory_Order(t *testing.T) {
+	t.Parallel()
+
+	pool := common.HexToAddress("0x1234")
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mock.NewMockClient(ctrl)
+	mockClient.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil)
+	mockClient.EXPECT().BlockNumber(gomock.Any()).Return(uint64(1000), nil)
+
+	// Later blocks answer first, and no more than historyConcurrency are read
+	// at a time.
+	var mu sync.Mutex
+	var inFlight, peak int
+	mockClient.EXPECT().GetPairReservesAt(gomock.Any(), pool, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ common.Address, n *big.Int) (*uniswapdto.PairReserves, error) {
+			mu.Lock()
+			inFlight++
+			peak = max(peak, inFlight)
+			mu.Unlock()
+
+			time.Sleep(time.Duration(1000-n.Int64()) * 10 * time.Microsecond)
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			return &uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(10000)}, nil
+		},
+	).Times(50)
+
+	service := NewEstimatorService(Chain{ID: 1, Client: mockClient, FeeBps: 30})
+
+	var blocks []uint64
+	err := service.EstimateHistory(context.Background(), dto.EstimateHistoryRequest{
+		Pool: pool, Src: token0, Dst: token1, SrcAmount: big.NewInt(100), FromBlock: 951, ToBlock: 1000, Step: 1,
+	}, func(e dto.HistoricalEstimate) error {
+		blocks = append(blocks, e.BlockNumber)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, blocks, 50)
+	for i, n := range blocks {
+		require.Equal(t, uint64(951+i), n)
+	}
+	require.LessOrEqual(t, peak, historyConcurrency)
+}
+
+func TestEstimateHistory_EmitError(t *testing.T) {
+	t.Parallel()
+
+	pool := common.HexToAddress("0x1234")
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+	errClosed := errors.New("connection closed")
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mock.NewMockClient(ctrl)
+	mockClient.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil)
+	mockClient.EXPECT().BlockNumber(gomock.Any()).Return(uint64(1000), nil)
+	mockClient.EXPECT().GetPairReservesAt(gomock.Any(), pool, gomock.Any()).
+		Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(10000)}, nil).
+		MaxTimes(2 * historyConcurrency)
+
+	service := NewEstimatorService(Chain{ID: 1, Client: mockClient, FeeBps: 30})
+
+	emitted := 0
+	err := service.EstimateHistory(context.Background(), dto.EstimateHistoryRequest{
+		Pool: pool, Src: token0, Dst: token1, SrcAmount: big.NewInt(100), FromBlock: 1, ToBlock: 1000, Step: 1,
+	}, func(dto.HistoricalEstimate) error {
+		emitted++
+		if emitted == historyConcurrency {
+			return errClosed
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, errClosed)
+	require.Equal(t, historyConcurrency, emitted)
+}
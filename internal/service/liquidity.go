@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+	"github.com/fleshka4/1inch-test-task/internal/dexmath"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+	"github.com/fleshka4/1inch-test-task/internal/service/validate"
+)
+
+// PairLiquidity returns the liquidity state of a pair at the latest block: its
+// reserves, LP token supply, kLast and the protocol fee pending for its
+// factory's feeTo.
+func (s *EstimatorService) PairLiquidity(ctx context.Context, req dto.PairLiquidityRequest) (*dto.PairLiquidity, error) {
+	if err := validate.PairLiquidityRequestValidate(req); err != nil {
+		return nil, errors.Wrap(err, "validate.PairLiquidityRequestValidate")
+	}
+
+	pair, err := s.readPair(ctx, req.ChainID, req.Chain, req.Pool)
+	if err != nil {
+		return nil, errors.Wrap(err, "s.readPair")
+	}
+
+	return &pair.info, nil
+}
+
+// AddLiquidity computes the LP tokens that the pair mints for a deposit of
+// both of its tokens at the latest block, as UniswapV2Pair.mint does.
+func (s *EstimatorService) AddLiquidity(ctx context.Context, req dto.AddLiquidityRequest) (*dto.AddLiquidityResponse, error) {
+	if err := validate.AddLiquidityRequestValidate(req); err != nil {
+		return nil, errors.Wrap(err, "validate.AddLiquidityRequestValidate")
+	}
+
+	pair, err := s.readPair(ctx, req.ChainID, req.Chain, req.Pool)
+	if err != nil {
+		return nil, errors.Wrap(err, "s.readPair")
+	}
+
+	liquidity, ok := pair.state.Mint(req.Amount0, req.Amount1)
+	if !ok {
+		return nil, errors.Wrap(apperrors.ErrInsufficientLiquidity, "insufficient liquidity minted")
+	}
+
+	return &dto.AddLiquidityResponse{Pair: pair.info, Liquidity: liquidity}, nil
+}
+
+// RemoveLiquidity computes the amounts that the pair pays out for burning LP
+// tokens at the latest block, as UniswapV2Pair.burn does, and the value of the
+// position in each of the tokens at the pair's spot price.
+func (s *EstimatorService) RemoveLiquidity(
+	ctx context.Context,
+	req dto.RemoveLiquidityRequest,
+) (*dto.RemoveLiquidityResponse, error) {
+	if err := validate.RemoveLiquidityRequestValidate(req); err != nil {
+		return nil, errors.Wrap(err, "validate.RemoveLiquidityRequestValidate")
+	}
+
+	pair, err := s.readPair(ctx, req.ChainID, req.Chain, req.Pool)
+	if err != nil {
+		return nil, errors.Wrap(err, "s.readPair")
+	}
+
+	if req.Liquidity.Cmp(pair.state.TotalSupply) > 0 {
+		return nil, errors.Wrapf(apperrors.ErrInvalidArgument, "liquidity exceeds total supply %s", pair.state.TotalSupply)
+	}
+
+	amount0, amount1, ok := pair.state.Burn(req.Liquidity)
+	if !ok {
+		return nil, errors.Wrap(apperrors.ErrInsufficientLiquidity, "insufficient liquidity burned")
+	}
+
+	r0, r1 := pair.state.Reserve0, pair.state.Reserve1
+	valueIn0 := new(big.Int).Mul(amount1, r0)
+	valueIn0.Quo(valueIn0, r1).Add(valueIn0, amount0)
+	valueIn1 := new(big.Int).Mul(amount0, r1)
+	valueIn1.Quo(valueIn1, r0).Add(valueIn1, amount1)
+
+	return &dto.RemoveLiquidityResponse{
+		Pair:     pair.info,
+		Amount0:  amount0,
+		Amount1:  amount1,
+		ValueIn0: valueIn0,
+		ValueIn1: valueIn1,
+	}, nil
+}
+
+// Zap computes the optimal single-sided deposit of src: the part swapped for
+// the other token of the pair with the pair's fee, and the LP tokens minted
+// for the rest and the swap output at the reserves after the swap.
+func (s *EstimatorService) Zap(ctx context.Context, req dto.ZapRequest) (*dto.ZapResponse, error) {
+	if err := validate.ZapRequestValidate(req); err != nil {
+		return nil, errors.Wrap(err, "validate.ZapRequestValidate")
+	}
+
+	pair, err := s.readPair(ctx, req.ChainID, req.Chain, req.Pool)
+	if err != nil {
+		return nil, errors.Wrap(err, "s.readPair")
+	}
+
+	var dir dexmath.Direction
+	switch {
+	case isTokenMatch(req.Src, pair.info.Token0):
+		dir = dexmath.ZeroForOne
+	case isTokenMatch(req.Src, pair.info.Token1):
+		dir = dexmath.OneForZero
+	default:
+		return nil, errors.Wrapf(
+			apperrors.ErrInvalidArgument,
+			"src does not match pool tokens: pool has %s and %s",
+			pair.info.Token0.Hex(), pair.info.Token1.Hex(),
+		)
+	}
+
+	zap, ok := pair.state.Zap(req.SrcAmount, dir, pair.feeBps)
+	if !ok {
+		return nil, errors.Wrap(apperrors.ErrInsufficientLiquidity, "bad zap")
+	}
+
+	return &dto.ZapResponse{
+		Pair:       pair.info,
+		SwapAmount: zap.Swap,
+		SwapOut:    zap.SwapOut,
+		Amount0:    zap.Amount0,
+		Amount1:    zap.Amount1,
+		Liquidity:  zap.Liquidity,
+	}, nil
+}
+
+// pairLiquidity is the liquidity state of a pair read at a single block.
+type pairLiquidity struct {
+	info   dto.PairLiquidity
+	state  dexmath.PairState
+	feeBps uint32
+}
+
+// readPair checks a pair against the chain's policies like a quote of its two
+// tokens and reads its reserves, LP supply, kLast and its factory's feeTo at the
+// latest block. The factory is the verified one, or the one the pair reports.
+func (s *EstimatorService) readPair(ctx context.Context, chainID uint64, name string, pool common.Address) (*pairLiquidity, error) {
+	chain, err := s.chain(chainID, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "s.chain")
+	}
+
+	// Checked before any RPC call so that denied contracts are never called.
+	if chain.Safety != nil {
+		if err := chain.Safety.CheckPool(pool); err != nil {
+			return nil, errors.Wrap(err, "chain.Safety.CheckPool")
+		}
+	}
+
+	token0, token1, err := chain.Client.GetPairTokens(ctx, pool)
+	if err != nil {
+		return nil, errors.Wrap(err, "chain.Client.GetPairTokens")
+	}
+
+	if chain.Safety != nil {
+		if err := chain.Safety.CheckTokens(token0, token1); err != nil {
+			return nil, errors.Wrap(err, "chain.Safety.CheckTokens")
+		}
+	}
+
+	resolved, err := s.verifiedPool(ctx, chain, pool, token0, token1)
+	if err != nil {
+		return nil, errors.Wrap(err, "s.verifiedPool")
+	}
+
+	block, err := chain.Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "chain.Client.BlockNumber")
+	}
+	blockNumber := new(big.Int).SetUint64(block)
+
+	reserves, err := chain.Client.GetPairReservesAt(ctx, pool, blockNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "chain.Client.GetPairReservesAt")
+	}
+	r0, r1 := reserves.Reserve0, reserves.Reserve1
+
+	totalSupply, err := chain.Client.GetPairTotalSupply(ctx, pool, blockNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "chain.Client.GetPairTotalSupply")
+	}
+
+	kLast, err := chain.Client.GetPairKLast(ctx, pool, blockNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "chain.Client.GetPairKLast")
+	}
+
+	factory, err := s.pairFactory(ctx, chain, pool, resolved)
+	if err != nil {
+		return nil, errors.Wrap(err, "s.pairFactory")
+	}
+
+	feeTo, err := chain.Client.GetFactoryFeeTo(ctx, factory, blockNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "chain.Client.GetFactoryFeeTo")
+	}
+
+	state := dexmath.PairState{
+		Reserve0:    r0,
+		Reserve1:    r1,
+		TotalSupply: totalSupply,
+		KLast:       kLast,
+		FeeOn:       feeTo != (common.Address{}),
+	}
+
+	return &pairLiquidity{
+		info: dto.PairLiquidity{
+			BlockNumber: block,
+			Token0:      token0,
+			Token1:      token1,
+			Reserve0:    r0,
+			Reserve1:    r1,
+			TotalSupply: totalSupply,
+			KLast:       kLast,
+			FeeTo:       feeTo,
+			ProtocolFee: state.MintFee(),
+		},
+		state:  state,
+		feeBps: resolved.feeBps,
+	}, nil
+}
+
+// pairFactory returns the verified factory of a pair, or the factory the pair reports.
+func (s *EstimatorService) pairFactory(ctx context.Context, chain *Chain, pool common.Address, resolved *resolvedPool) (common.Address, error) {
+	if resolved.factory != nil {
+		return resolved.factory.Address, nil
+	}
+
+	factory, err := chain.Client.GetPairFactory(ctx, pool)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "chain.Client.GetPairFactory")
+	}
+	return factory, nil
+}
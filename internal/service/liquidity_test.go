@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+	"github.com/fleshka4/1inch-test-task/internal/safety"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+)
+
+func TestLiquidity(t *testing.T) {
+	t.Parallel()
+
+	pool := common.HexToAddress("0x1234")
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+	block := big.NewInt(19_000_000)
+
+	pair := dto.PairLiquidity{
+		BlockNumber: block.Uint64(),
+		Token0:      token0,
+		Token1:      token1,
+		Reserve0:    big.NewInt(10000),
+		Reserve1:    big.NewInt(20000),
+		TotalSupply: big.NewInt(14142),
+		KLast:       big.NewInt(0),
+		ProtocolFee: big.NewInt(0),
+	}
+
+	factory := common.HexToAddress("0xfac7")
+	feeTo := common.HexToAddress("0xfee")
+	// sqrt(k) grew from 7071 to 14142 since the last liquidity event.
+	feePair := pair
+	feePair.KLast = big.NewInt(50_000_000)
+	feePair.FeeTo = feeTo
+	feePair.ProtocolFee = big.NewInt(1285)
+
+	readPairState := func(mc *mock.MockClient, state dto.PairLiquidity) {
+		mc.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil)
+		mc.EXPECT().BlockNumber(gomock.Any()).Return(block.Uint64(), nil)
+		mc.EXPECT().GetPairReservesAt(gomock.Any(), pool, block).Return(&uniswapdto.PairReserves{Reserve0: state.Reserve0, Reserve1: state.Reserve1}, nil)
+		mc.EXPECT().GetPairTotalSupply(gomock.Any(), pool, block).Return(state.TotalSupply, nil)
+		mc.EXPECT().GetPairKLast(gomock.Any(), pool, block).Return(state.KLast, nil)
+		mc.EXPECT().GetPairFactory(gomock.Any(), pool).Return(factory, nil)
+		mc.EXPECT().GetFactoryFeeTo(gomock.Any(), factory, block).Return(state.FeeTo, nil)
+	}
+	readPair := func(mc *mock.MockClient) { readPairState(mc, pair) }
+
+	tests := []struct {
+		name      string
+		call      func(s *EstimatorService) (any, error)
+		mockSetup func(mc *mock.MockClient)
+		want      any
+		wantErr   string
+		wantIs    error
+	}{
+		{
+			name: "pair",
+			call: func(s *EstimatorService) (any, error) {
+				return s.PairLiquidity(context.Background(), dto.PairLiquidityRequest{Pool: pool})
+			},
+			mockSetup: func(mc *mock.MockClient) { readPairState(mc, feePair) },
+			want:      &feePair,
+		},
+		{
+			name: "add",
+			call: func(s *EstimatorService) (any, error) {
+				return s.AddLiquidity(context.Background(), dto.AddLiquidityRequest{
+					Pool: pool, Amount0: big.NewInt(1000), Amount1: big.NewInt(3000),
+				})
+			},
+			mockSetup: readPair,
+			want:      &dto.AddLiquidityResponse{Pair: pair, Liquidity: big.NewInt(1414)},
+		},
+		{
+			name: "add mints nothing",
+			call: func(s *EstimatorService) (any, error) {
+				return s.AddLiquidity(context.Background(), dto.AddLiquidityRequest{
+					Pool: pool, Amount0: big.NewInt(1000), Amount1: big.NewInt(0),
+				})
+			},
+			mockSetup: readPair,
+			wantErr:   "insufficient liquidity minted",
+			wantIs:    apperrors.ErrInsufficientLiquidity,
+		},
+		{
+			name: "add without amounts",
+			call: func(s *EstimatorService) (any, error) {
+				return s.AddLiquidity(context.Background(), dto.AddLiquidityRequest{
+					Pool: pool, Amount0: big.NewInt(0), Amount1: big.NewInt(0),
+				})
+			},
+			wantErr: "amounts cannot both be zero",
+			wantIs:  apperrors.ErrInvalidArgument,
+		},
+		{
+			name: "remove",
+			call: func(s *EstimatorService) (any, error) {
+				return s.RemoveLiquidity(context.Background(), dto.RemoveLiquidityRequest{
+					Pool: pool, Liquidity: big.NewInt(1414),
+				})
+			},
+			mockSetup: readPair,
+			want: &dto.RemoveLiquidityResponse{
+				Pair:     pair,
+				Amount0:  big.NewInt(999),
+				Amount1:  big.NewInt(1999),
+				ValueIn0: big.NewInt(1998),
+				ValueIn1: big.NewInt(3997),
+			},
+		},
+		{
+			name: "remove with protocol fee",
+			call: func(s *EstimatorService) (any, error) {
+				return s.RemoveLiquidity(context.Background(), dto.RemoveLiquidityRequest{
+					Pool: pool, Liquidity: big.NewInt(1414),
+				})
+			},
+			mockSetup: func(mc *mock.MockClient) { readPairState(mc, feePair) },
+			want: &dto.RemoveLiquidityResponse{
+				Pair:     feePair,
+				Amount0:  big.NewInt(916),
+				Amount1:  big.NewInt(1833),
+				ValueIn0: big.NewInt(1832),
+				ValueIn1: big.NewInt(3665),
+			},
+		},
+		{
+			name: "remove more than supply",
+			call: func(s *EstimatorService) (any, error) {
+				return s.RemoveLiquidity(context.Background(), dto.RemoveLiquidityRequest{
+					Pool: pool, Liquidity: big.NewInt(20000),
+				})
+			},
+			mockSetup: readPair,
+			wantErr:   "liquidity exceeds total supply 14142",
+			wantIs:    apperrors.ErrInvalidArgument,
+		},
+		{
+			name: "zap",
+			call: func(s *EstimatorService) (any, error) {
+				return s.Zap(context.Background(), dto.ZapRequest{
+					Pool: pool, Src: token1, SrcAmount: big.NewInt(2000),
+				})
+			},
+			mockSetup: readPair,
+			want: &dto.ZapResponse{
+				Pair:       pair,
+				SwapAmount: big.NewInt(977),
+				SwapOut:    big.NewInt(464),
+				Amount0:    big.NewInt(464),
+				Amount1:    big.NewInt(1023),
+				Liquidity:  big.NewInt(688),
+			},
+		},
+		{
+			name: "zap with foreign token",
+			call: func(s *EstimatorService) (any, error) {
+				return s.Zap(context.Background(), dto.ZapRequest{
+					Pool: pool, Src: common.HexToAddress("0x9999"), SrcAmount: big.NewInt(2000),
+				})
+			},
+			mockSetup: readPair,
+			wantErr:   "src does not match pool tokens",
+			wantIs:    apperrors.ErrInvalidArgument,
+		},
+		{
+			name: "denied pool",
+			call: func(s *EstimatorService) (any, error) {
+				return s.RemoveLiquidity(context.Background(), dto.RemoveLiquidityRequest{
+					Pool: common.HexToAddress("0xdead"), Liquidity: big.NewInt(1),
+				})
+			},
+			wantIs: apperrors.ErrNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockClient(ctrl)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockClient)
+			}
+
+			policy, err := safety.NewPolicy(safety.Rules{PoolDenylist: []common.Address{common.HexToAddress("0xdead")}})
+			require.NoError(t, err)
+
+			service := NewEstimatorService(Chain{ID: 1, Client: mockClient, FeeBps: 30, Safety: safety.NewGuard(policy)})
+
+			got, err := tt.call(service)
+			if tt.wantIs != nil {
+				require.ErrorIs(t, err, tt.wantIs)
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
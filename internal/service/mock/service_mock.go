@@ -11,7 +11,6 @@ package mock
 
 import (
 	context "context"
-	big "math/big"
 	reflect "reflect"
 
 	dto "github.com/fleshka4/1inch-test-task/internal/service/dto"
@@ -42,11 +41,26 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// AddLiquidity mocks base method.
+func (m *MockService) AddLiquidity(ctx context.Context, req dto.AddLiquidityRequest) (*dto.AddLiquidityResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddLiquidity", ctx, req)
+	ret0, _ := ret[0].(*dto.AddLiquidityResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddLiquidity indicates an expected call of AddLiquidity.
+func (mr *MockServiceMockRecorder) AddLiquidity(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLiquidity", reflect.TypeOf((*MockService)(nil).AddLiquidity), ctx, req)
+}
+
 // Estimate mocks base method.
-func (m *MockService) Estimate(ctx context.Context, req dto.EstimateRequest) (*big.Int, error) {
+func (m *MockService) Estimate(ctx context.Context, req dto.EstimateRequest) (*dto.EstimateResponse, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Estimate", ctx, req)
-	ret0, _ := ret[0].(*big.Int)
+	ret0, _ := ret[0].(*dto.EstimateResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -56,3 +70,160 @@ func (mr *MockServiceMockRecorder) Estimate(ctx, req any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Estimate", reflect.TypeOf((*MockService)(nil).Estimate), ctx, req)
 }
+
+// EstimateHistory mocks base method.
+func (m *MockService) EstimateHistory(ctx context.Context, req dto.EstimateHistoryRequest, emit func(dto.HistoricalEstimate) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EstimateHistory", ctx, req, emit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EstimateHistory indicates an expected call of EstimateHistory.
+func (mr *MockServiceMockRecorder) EstimateHistory(ctx, req, emit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EstimateHistory", reflect.TypeOf((*MockService)(nil).EstimateHistory), ctx, req, emit)
+}
+
+// PairLiquidity mocks base method.
+func (m *MockService) PairLiquidity(ctx context.Context, req dto.PairLiquidityRequest) (*dto.PairLiquidity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PairLiquidity", ctx, req)
+	ret0, _ := ret[0].(*dto.PairLiquidity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PairLiquidity indicates an expected call of PairLiquidity.
+func (mr *MockServiceMockRecorder) PairLiquidity(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PairLiquidity", reflect.TypeOf((*MockService)(nil).PairLiquidity), ctx, req)
+}
+
+// RemoveLiquidity mocks base method.
+func (m *MockService) RemoveLiquidity(ctx context.Context, req dto.RemoveLiquidityRequest) (*dto.RemoveLiquidityResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveLiquidity", ctx, req)
+	ret0, _ := ret[0].(*dto.RemoveLiquidityResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveLiquidity indicates an expected call of RemoveLiquidity.
+func (mr *MockServiceMockRecorder) RemoveLiquidity(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveLiquidity", reflect.TypeOf((*MockService)(nil).RemoveLiquidity), ctx, req)
+}
+
+// Sequence mocks base method.
+func (m *MockService) Sequence(ctx context.Context, req dto.SequenceRequest) (*dto.SequenceResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sequence", ctx, req)
+	ret0, _ := ret[0].(*dto.SequenceResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sequence indicates an expected call of Sequence.
+func (mr *MockServiceMockRecorder) Sequence(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sequence", reflect.TypeOf((*MockService)(nil).Sequence), ctx, req)
+}
+
+// Simulate mocks base method.
+func (m *MockService) Simulate(ctx context.Context, req dto.SimulateRequest) (*dto.SimulateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Simulate", ctx, req)
+	ret0, _ := ret[0].(*dto.SimulateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Simulate indicates an expected call of Simulate.
+func (mr *MockServiceMockRecorder) Simulate(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Simulate", reflect.TypeOf((*MockService)(nil).Simulate), ctx, req)
+}
+
+// TWAP mocks base method.
+func (m *MockService) TWAP(ctx context.Context, req dto.TWAPRequest) (*dto.TWAPResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TWAP", ctx, req)
+	ret0, _ := ret[0].(*dto.TWAPResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TWAP indicates an expected call of TWAP.
+func (mr *MockServiceMockRecorder) TWAP(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TWAP", reflect.TypeOf((*MockService)(nil).TWAP), ctx, req)
+}
+
+// Zap mocks base method.
+func (m *MockService) Zap(ctx context.Context, req dto.ZapRequest) (*dto.ZapResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Zap", ctx, req)
+	ret0, _ := ret[0].(*dto.ZapResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Zap indicates an expected call of Zap.
+func (mr *MockServiceMockRecorder) Zap(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Zap", reflect.TypeOf((*MockService)(nil).Zap), ctx, req)
+}
+
+// MockArbitrageFeed is a mock of ArbitrageFeed interface.
+type MockArbitrageFeed struct {
+	ctrl     *gomock.Controller
+	recorder *MockArbitrageFeedMockRecorder
+	isgomock struct{}
+}
+
+// MockArbitrageFeedMockRecorder is the mock recorder for MockArbitrageFeed.
+type MockArbitrageFeedMockRecorder struct {
+	mock *MockArbitrageFeed
+}
+
+// NewMockArbitrageFeed creates a new mock instance.
+func NewMockArbitrageFeed(ctrl *gomock.Controller) *MockArbitrageFeed {
+	mock := &MockArbitrageFeed{ctrl: ctrl}
+	mock.recorder = &MockArbitrageFeedMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArbitrageFeed) EXPECT() *MockArbitrageFeedMockRecorder {
+	return m.recorder
+}
+
+// Opportunities mocks base method.
+func (m *MockArbitrageFeed) Opportunities(chainID uint64) []dto.ArbitrageOpportunity {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Opportunities", chainID)
+	ret0, _ := ret[0].([]dto.ArbitrageOpportunity)
+	return ret0
+}
+
+// Opportunities indicates an expected call of Opportunities.
+func (mr *MockArbitrageFeedMockRecorder) Opportunities(chainID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Opportunities", reflect.TypeOf((*MockArbitrageFeed)(nil).Opportunities), chainID)
+}
+
+// Subscribe mocks base method.
+func (m *MockArbitrageFeed) Subscribe() (<-chan dto.ArbitrageOpportunity, func()) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe")
+	ret0, _ := ret[0].(<-chan dto.ArbitrageOpportunity)
+	ret1, _ := ret[1].(func())
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockArbitrageFeedMockRecorder) Subscribe() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockArbitrageFeed)(nil).Subscribe))
+}
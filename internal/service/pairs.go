@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap"
+)
+
+// PairVerification selects how pools that cannot be traced to a configured factory are treated.
+type PairVerification int
+
+const (
+	// PairVerificationOff skips the check.
+	PairVerificationOff PairVerification = iota
+	// PairVerificationPermissive quotes unverified pools and flags them in the response.
+	PairVerificationPermissive
+	// PairVerificationStrict rejects unverified pools with apperrors.ErrUnverifiedPool.
+	PairVerificationStrict
+)
+
+// PoolType is the kind of AMM a pool is.
+type PoolType string
+
+const (
+	// PoolTypeUniswapV2 is a constant product pair with reserves.
+	PoolTypeUniswapV2 PoolType = "uniswap-v2"
+	// PoolTypeUniswapV3 is a concentrated liquidity pool with ticks.
+	PoolTypeUniswapV3 PoolType = "uniswap-v3"
+	// PoolTypeSolidly is a Solidly style pair on the stable x³y + xy³ or the
+	// volatile constant product curve.
+	PoolTypeSolidly PoolType = "solidly"
+	// PoolTypeCurve is a Curve StableSwap pool of two or more coins.
+	PoolTypeCurve PoolType = "curve"
+	// PoolTypeBalancerWeighted is a Balancer V2 weighted pool of two or more tokens.
+	PoolTypeBalancerWeighted PoolType = "balancer-weighted"
+)
+
+// poolParams are the parameters that, with its tokens, identify a pool of a
+// factory: the fee tier of uniswap-v3 pools and the curve of solidly pairs.
+type poolParams struct {
+	fee    uint32
+	stable bool
+}
+
+// maxVerdicts bounds the verdict cache, which is reset when full so that
+// arbitrary addresses cannot grow it without limit.
+const maxVerdicts = 10000
+
+// Factory is a factory trusted on a chain.
+type Factory struct {
+	Name string
+	// Type is the kind of pools the factory deploys. Empty means PoolTypeUniswapV2.
+	Type PoolType
+	// Address is the factory's, or for curve the address of a registry
+	// answering is_registered(pool), such as the MetaRegistry. Balancer
+	// factories answer isPoolFromFactory(pool).
+	Address common.Address
+	// InitCodeHash is the pool init code hash used to recompute CREATE2 addresses.
+	// When zero, the factory is cross-checked through factory() and getPair
+	// (getPool for uniswap-v3) instead.
+	InitCodeHash common.Hash
+	// FeeBps is the swap fee of the factory's uniswap-v2 pairs.
+	FeeBps uint32
+	// Router is the UniswapV2Router02 of the factory, used to cross-check quotes.
+	Router common.Address
+}
+
+// poolType returns the kind of pools the factory deploys.
+func (f *Factory) poolType() PoolType {
+	if f.Type == "" {
+		return PoolTypeUniswapV2
+	}
+	return f.Type
+}
+
+// pairVerdict is the factory, if any, of the pool's type that deployed a pool.
+type pairVerdict struct {
+	factory  *Factory
+	poolType PoolType
+}
+
+type verdictKey struct {
+	chainID uint64
+	pair    common.Address
+}
+
+// verdictCache remembers which factory, if any, deployed a pair.
+// Verdicts never change because pair tokens and deployers are immutable,
+// but each holds for the pool type it was reached for only.
+type verdictCache struct {
+	mu       sync.RWMutex
+	verdicts map[verdictKey]pairVerdict
+}
+
+func (c *verdictCache) get(key verdictKey) (pairVerdict, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	v, ok := c.verdicts[key]
+	return v, ok
+}
+
+func (c *verdictCache) put(key verdictKey, v pairVerdict) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.verdicts == nil || len(c.verdicts) >= maxVerdicts {
+		c.verdicts = make(map[verdictKey]pairVerdict)
+	}
+	c.verdicts[key] = v
+}
+
+// verifyPair returns the configured factory that deployed the pair, or nil
+// if the pair cannot be traced to any of them.
+//
+// Factories with an init code hash are checked by recomputing the CREATE2 address
+// offline; the others by asking the pair for its factory() and the factory for getPair.
+func (s *EstimatorService) verifyPair(
+	ctx context.Context,
+	chain *Chain,
+	pair, token0, token1 common.Address,
+) (*Factory, error) {
+	return s.verifyPool(ctx, chain, PoolTypeUniswapV2, pair, token0, token1, poolParams{})
+}
+
+// verifyPool is verifyPair for pools of any type. Only factories of the pool's
+// type are considered, and params are part of the pool's address. A cached
+// verdict reached for another type is not used.
+func (s *EstimatorService) verifyPool(
+	ctx context.Context,
+	chain *Chain,
+	poolType PoolType,
+	pair, token0, token1 common.Address,
+	params poolParams,
+) (*Factory, error) {
+	key := verdictKey{chainID: chain.ID, pair: pair}
+	if v, ok := s.verdicts.get(key); ok && v.poolType == poolType {
+		return v.factory, nil
+	}
+
+	factory, final, err := findFactory(ctx, chain, poolType, pair, token0, token1, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if final {
+		s.verdicts.put(key, pairVerdict{factory: factory, poolType: poolType})
+	}
+	return factory, nil
+}
+
+// findFactory reports whether the verdict is final, i.e. safe to cache.
+func findFactory(
+	ctx context.Context,
+	chain *Chain,
+	poolType PoolType,
+	pair, token0, token1 common.Address,
+	params poolParams,
+) (*Factory, bool, error) {
+	switch poolType {
+	case PoolTypeCurve, PoolTypeBalancerWeighted:
+		return findListingFactory(ctx, chain, poolType, pair)
+	}
+
+	var onChain []*Factory
+	for i := range chain.Factories {
+		f := &chain.Factories[i]
+		if f.poolType() != poolType {
+			continue
+		}
+		if f.InitCodeHash == (common.Hash{}) {
+			onChain = append(onChain, f)
+			continue
+		}
+
+		if poolAddress(f, token0, token1, params) == pair {
+			return f, true, nil
+		}
+	}
+
+	if len(onChain) == 0 {
+		return nil, true, nil
+	}
+
+	deployer, err := chain.Client.GetPairFactory(ctx, pair)
+	if err != nil {
+		// Contracts without factory() are not pools, but the call may also
+		// have failed transiently, so the verdict is not cached.
+		return nil, false, nil //nolint:nilerr
+	}
+
+	for _, f := range onChain {
+		if f.Address != deployer {
+			continue
+		}
+
+		registered, err := registeredPool(ctx, chain, f, token0, token1, params)
+		if err != nil {
+			return nil, false, errors.Wrap(err, "registeredPool")
+		}
+		if registered == pair {
+			return f, true, nil
+		}
+	}
+
+	return nil, true, nil
+}
+
+// poolAddress computes the CREATE2 address of the pool a factory deploys.
+func poolAddress(f *Factory, token0, token1 common.Address, params poolParams) common.Address {
+	switch f.poolType() {
+	case PoolTypeUniswapV3:
+		return uniswap.V3PoolAddress(f.Address, token0, token1, params.fee, f.InitCodeHash)
+	case PoolTypeSolidly:
+		return uniswap.SolidlyPairAddress(f.Address, token0, token1, params.stable, f.InitCodeHash)
+	default:
+		return uniswap.PairAddress(f.Address, token0, token1, f.InitCodeHash)
+	}
+}
+
+// registeredPool returns the pool a factory registers for two tokens.
+func registeredPool(
+	ctx context.Context,
+	chain *Chain,
+	f *Factory,
+	token0, token1 common.Address,
+	params poolParams,
+) (common.Address, error) {
+	switch f.poolType() {
+	case PoolTypeUniswapV3:
+		pool, err := chain.Client.GetV3FactoryPool(ctx, f.Address, token0, token1, params.fee)
+		if err != nil {
+			return common.Address{}, errors.Wrap(err, "chain.Client.GetV3FactoryPool")
+		}
+		return pool, nil
+	case PoolTypeSolidly:
+		pair, err := chain.Client.GetSolidlyFactoryPair(ctx, f.Address, token0, token1, params.stable)
+		if err != nil {
+			return common.Address{}, errors.Wrap(err, "chain.Client.GetSolidlyFactoryPair")
+		}
+		return pair, nil
+	}
+
+	pair, err := chain.Client.GetFactoryPair(ctx, f.Address, token0, token1)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "chain.Client.GetFactoryPair")
+	}
+	return pair, nil
+}
+
+// findListingFactory returns the first of the chain's factories of the pool's
+// type that lists the pool: curve registries through is_registered and
+// balancer factories through isPoolFromFactory. Curve registries list new
+// pools over time, so only their positive verdicts are final.
+func findListingFactory(
+	ctx context.Context,
+	chain *Chain,
+	poolType PoolType,
+	pool common.Address,
+) (*Factory, bool, error) {
+	for i := range chain.Factories {
+		f := &chain.Factories[i]
+		if f.poolType() != poolType {
+			continue
+		}
+
+		listed, err := listsPool(ctx, chain, f, pool)
+		if err != nil {
+			return nil, false, errors.Wrap(err, "listsPool")
+		}
+		if listed {
+			return f, true, nil
+		}
+	}
+
+	return nil, poolType == PoolTypeBalancerWeighted, nil
+}
+
+// listsPool asks a curve registry or a balancer factory whether it lists a pool.
+func listsPool(ctx context.Context, chain *Chain, f *Factory, pool common.Address) (bool, error) {
+	if f.poolType() == PoolTypeCurve {
+		registered, err := chain.Client.IsCurvePoolRegistered(ctx, f.Address, pool)
+		if err != nil {
+			return false, errors.Wrap(err, "chain.Client.IsCurvePoolRegistered")
+		}
+		return registered, nil
+	}
+
+	deployed, err := chain.Client.IsBalancerPoolFromFactory(ctx, f.Address, pool)
+	if err != nil {
+		return false, errors.Wrap(err, "chain.Client.IsBalancerPoolFromFactory")
+	}
+	return deployed, nil
+}
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+	"github.com/fleshka4/1inch-test-task/internal/v3math"
+)
+
+func TestEstimate_PairVerification(t *testing.T) {
+	t.Parallel()
+
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+	factoryAddr := common.HexToAddress("0xfac7")
+	initCodeHash := common.HexToHash("0x96e8ac4277198ff8b6f785478aa9a39f403cb768dd02cbee326c3e7da348845f")
+	create2Pool := uniswap.PairAddress(factoryAddr, token0, token1, initCodeHash)
+	otherPool := common.HexToAddress("0x1234")
+
+	withHash := Factory{Name: "uniswap-v2", Address: factoryAddr, InitCodeHash: initCodeHash, FeeBps: 25}
+	onChain := Factory{Name: "sushiswap", Address: factoryAddr, FeeBps: 30}
+
+	tests := []struct {
+		name         string
+		pool         common.Address
+		factory      Factory
+		mode         PairVerification
+		mockSetup    func(mc *mock.MockClient, pool common.Address)
+		wantVerified bool
+		wantErr      error
+	}{
+		{
+			name:         "create2 address matches",
+			pool:         create2Pool,
+			factory:      withHash,
+			mode:         PairVerificationStrict,
+			wantVerified: true,
+		},
+		{
+			name:    "create2 address differs, strict",
+			pool:    otherPool,
+			factory: withHash,
+			mode:    PairVerificationStrict,
+			wantErr: apperrors.ErrUnverifiedPool,
+		},
+		{
+			name:    "create2 address differs, permissive",
+			pool:    otherPool,
+			factory: withHash,
+			mode:    PairVerificationPermissive,
+		},
+		{
+			name:    "on-chain factory registers the pair",
+			pool:    otherPool,
+			factory: onChain,
+			mode:    PairVerificationStrict,
+			mockSetup: func(mc *mock.MockClient, pool common.Address) {
+				mc.EXPECT().GetPairFactory(gomock.Any(), pool).Return(factoryAddr, nil)
+				mc.EXPECT().GetFactoryPair(gomock.Any(), factoryAddr, token0, token1).Return(pool, nil)
+			},
+			wantVerified: true,
+		},
+		{
+			name:    "pair claims a factory that does not register it",
+			pool:    otherPool,
+			factory: onChain,
+			mode:    PairVerificationStrict,
+			mockSetup: func(mc *mock.MockClient, pool common.Address) {
+				mc.EXPECT().GetPairFactory(gomock.Any(), pool).Return(factoryAddr, nil)
+				mc.EXPECT().GetFactoryPair(gomock.Any(), factoryAddr, token0, token1).Return(common.Address{}, nil)
+			},
+			wantErr: apperrors.ErrUnverifiedPool,
+		},
+		{
+			name:    "pair reports an unknown factory",
+			pool:    otherPool,
+			factory: onChain,
+			mode:    PairVerificationStrict,
+			mockSetup: func(mc *mock.MockClient, pool common.Address) {
+				mc.EXPECT().GetPairFactory(gomock.Any(), pool).Return(common.HexToAddress("0xbad"), nil)
+			},
+			wantErr: apperrors.ErrUnverifiedPool,
+		},
+		{
+			name:    "contract without factory()",
+			pool:    otherPool,
+			factory: onChain,
+			mode:    PairVerificationPermissive,
+			mockSetup: func(mc *mock.MockClient, pool common.Address) {
+				mc.EXPECT().GetPairFactory(gomock.Any(), pool).Return(common.Address{}, errors.New("execution reverted"))
+			},
+		},
+		{
+			name:    "verification off",
+			pool:    otherPool,
+			factory: withHash,
+			mode:    PairVerificationOff,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockClient(ctrl)
+			mockClient.EXPECT().GetPairTokens(gomock.Any(), tt.pool).Return(token0, token1, nil)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockClient, tt.pool)
+			}
+			if tt.wantErr == nil {
+				mockClient.EXPECT().GetPairReserves(gomock.Any(), tt.pool).Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(20000)}, nil)
+			}
+
+			service := NewEstimatorService(Chain{
+				ID:               1,
+				Client:           mockClient,
+				FeeBps:           30,
+				Factories:        []Factory{tt.factory},
+				PairVerification: tt.mode,
+			})
+
+			resp, err := service.Estimate(context.Background(), dto.EstimateRequest{
+				Pool:      tt.pool,
+				Src:       token0,
+				Dst:       token1,
+				SrcAmount: big.NewInt(1000),
+			})
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantVerified, resp.PoolVerified)
+			if tt.wantVerified {
+				require.Equal(t, tt.factory.Name, resp.Factory)
+			}
+		})
+	}
+}
+
+func TestEstimate_PairVerificationFee(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+	factory := Factory{
+		Address:      common.HexToAddress("0xfac7"),
+		InitCodeHash: common.HexToHash("0x01"),
+		FeeBps:       0,
+	}
+	pool := uniswap.PairAddress(factory.Address, token0, token1, factory.InitCodeHash)
+
+	mockClient := mock.NewMockClient(ctrl)
+	mockClient.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil)
+	mockClient.EXPECT().GetPairReserves(gomock.Any(), pool).Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(10000)}, nil)
+
+	service := NewEstimatorService(Chain{
+		ID:               1,
+		Client:           mockClient,
+		FeeBps:           30,
+		Factories:        []Factory{factory},
+		PairVerification: PairVerificationPermissive,
+	})
+
+	resp, err := service.Estimate(context.Background(), dto.EstimateRequest{
+		Pool:      pool,
+		Src:       token0,
+		Dst:       token1,
+		SrcAmount: big.NewInt(10000),
+	})
+	require.NoError(t, err)
+
+	// Without a fee, 10000 in against 10000/10000 reserves yields exactly half.
+	require.Equal(t, big.NewInt(5000), resp.DstAmount)
+}
+
+func TestEstimate_PairVerdictCached(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pool := common.HexToAddress("0x1234")
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+	factoryAddr := common.HexToAddress("0xfac7")
+
+	mockClient := mock.NewMockClient(ctrl)
+	mockClient.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil).Times(2)
+	mockClient.EXPECT().GetPairReserves(gomock.Any(), pool).Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(20000)}, nil).Times(2)
+	mockClient.EXPECT().GetPairFactory(gomock.Any(), pool).Return(factoryAddr, nil).Times(1)
+	mockClient.EXPECT().GetFactoryPair(gomock.Any(), factoryAddr, token0, token1).Return(pool, nil).Times(1)
+
+	service := NewEstimatorService(Chain{
+		ID:               1,
+		Client:           mockClient,
+		FeeBps:           30,
+		Factories:        []Factory{{Name: "sushiswap", Address: factoryAddr, FeeBps: 30}},
+		PairVerification: PairVerificationStrict,
+	})
+
+	for range 2 {
+		resp, err := service.Estimate(context.Background(), dto.EstimateRequest{
+			Pool:      pool,
+			Src:       token0,
+			Dst:       token1,
+			SrcAmount: big.NewInt(1000),
+		})
+		require.NoError(t, err)
+		require.True(t, resp.PoolVerified)
+	}
+}
+
+func TestEstimate_PairVerdictPerPoolType(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+	factory := Factory{
+		Name:         "uniswap-v3",
+		Type:         PoolTypeUniswapV3,
+		Address:      common.HexToAddress("0xfac7"),
+		InitCodeHash: common.HexToHash("0xe34f199b19b2b4f47f68442619d555527d244f78a3297ea89325f843f87b8b54"),
+	}
+	pool := uniswap.V3PoolAddress(factory.Address, token0, token1, 3000, factory.InitCodeHash)
+	block := uint64(19_000_000)
+	ticks := []uniswapdto.V3Tick{
+		{Index: -600, LiquidityNet: big.NewInt(1e18)},
+		{Index: 600, LiquidityNet: big.NewInt(-1e18)},
+	}
+
+	mockClient := mock.NewMockClient(ctrl)
+	mockClient.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil).Times(2)
+	mockClient.EXPECT().BlockNumber(gomock.Any()).Return(block, nil)
+	mockClient.EXPECT().GetV3PoolState(gomock.Any(), pool, new(big.Int).SetUint64(block)).Return(&uniswapdto.V3PoolState{
+		BlockNumber:  block,
+		SqrtPriceX96: new(big.Int).Set(v3math.Q96),
+		Liquidity:    big.NewInt(1e18),
+		Fee:          3000,
+		TickSpacing:  60,
+	}, nil)
+	readWords(mockClient, pool, 60, block, ticks, [2]int16{-2, 2})
+
+	service := NewEstimatorService(Chain{
+		ID:               1,
+		Client:           mockClient,
+		Factories:        []Factory{factory},
+		PairVerification: PairVerificationStrict,
+	})
+
+	// No uniswap-v2 factory deployed the pool, which does not decide whether
+	// the uniswap-v3 factory did.
+	_, err := service.Estimate(context.Background(), dto.EstimateRequest{
+		Pool:      pool,
+		Src:       token0,
+		Dst:       token1,
+		SrcAmount: big.NewInt(1e15),
+		PoolType:  string(PoolTypeUniswapV2),
+	})
+	require.ErrorIs(t, err, apperrors.ErrUnverifiedPool)
+
+	resp, err := service.Estimate(context.Background(), dto.EstimateRequest{
+		Pool:      pool,
+		Src:       token0,
+		Dst:       token1,
+		SrcAmount: big.NewInt(1e15),
+		PoolType:  string(PoolTypeUniswapV3),
+	})
+	require.NoError(t, err)
+	require.True(t, resp.PoolVerified)
+	require.Equal(t, "uniswap-v3", resp.Factory)
+}
+
+func TestEstimate_SnapshotVerdictNotCached(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pool := common.HexToAddress("0x1234")
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+	fake := common.HexToAddress("0xbad")
+	factoryAddr := common.HexToAddress("0xfac7")
+
+	mockClient := mock.NewMockClient(ctrl)
+	mockClient.EXPECT().GetPairFactory(gomock.Any(), pool).Return(factoryAddr, nil).Times(2)
+	mockClient.EXPECT().GetFactoryPair(gomock.Any(), factoryAddr, token0, fake).Return(common.Address{}, nil)
+	mockClient.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil)
+	mockClient.EXPECT().GetFactoryPair(gomock.Any(), factoryAddr, token0, token1).Return(pool, nil)
+	mockClient.EXPECT().GetPairReserves(gomock.Any(), pool).Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(20000)}, nil)
+
+	service := NewEstimatorService(Chain{
+		ID:               1,
+		Client:           mockClient,
+		FeeBps:           30,
+		Factories:        []Factory{{Name: "sushiswap", Address: factoryAddr, FeeBps: 30}},
+		PairVerification: PairVerificationStrict,
+	})
+
+	// A snapshot claiming other tokens fails verification, but does not
+	// decide the verdict of quotes of the pair's on-chain tokens.
+	_, err := service.Estimate(context.Background(), dto.EstimateRequest{
+		Pool:      pool,
+		Src:       token0,
+		Dst:       fake,
+		SrcAmount: big.NewInt(1000),
+		Snapshot: &dto.PoolSnapshot{
+			Token0:   token0,
+			Token1:   fake,
+			Reserve0: big.NewInt(10000),
+			Reserve1: big.NewInt(20000),
+		},
+	})
+	require.ErrorIs(t, err, apperrors.ErrUnverifiedPool)
+
+	resp, err := service.Estimate(context.Background(), dto.EstimateRequest{
+		Pool:      pool,
+		Src:       token0,
+		Dst:       token1,
+		SrcAmount: big.NewInt(1000),
+	})
+	require.NoError(t, err)
+	require.True(t, resp.PoolVerified)
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/amm"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+)
+
+// poolTypeCache remembers the detected type of pools. Types never change
+// because pool code is immutable.
+type poolTypeCache struct {
+	mu    sync.RWMutex
+	types map[verdictKey]PoolType
+}
+
+func (c *poolTypeCache) get(key verdictKey) (PoolType, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	t, ok := c.types[key]
+	return t, ok
+}
+
+func (c *poolTypeCache) put(key verdictKey, t PoolType) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.types == nil || len(c.types) >= maxVerdicts {
+		c.types = make(map[verdictKey]PoolType)
+	}
+	c.types[key] = t
+}
+
+// hasFactoryType reports whether any of the chain's factories deploys pools of the type.
+func (c *Chain) hasFactoryType(t PoolType) bool {
+	for i := range c.Factories {
+		if c.Factories[i].poolType() == t {
+			return true
+		}
+	}
+	return false
+}
+
+// poolBackend detects and opens the pools of one type.
+type poolBackend struct {
+	// selectors are functions that pools of the type, and few other
+	// contracts, have. A pool whose bytecode has all of them is of the type.
+	selectors [][4]byte
+	// probe reports whether a pool is of the type, for pools whose bytecode
+	// matches no type, such as those behind upgradeable proxies. uniswap-v2,
+	// the type of pools no other backend claims, has none.
+	probe func(client uniswap.Client, ctx context.Context, pool common.Address) (bool, error)
+	// open reads and verifies the pool of a request, for estimatePool to quote
+	// the request's swap against. It adds what it learns of the pool to resp.
+	open func(
+		s *EstimatorService,
+		ctx context.Context,
+		chain *Chain,
+		req dto.EstimateRequest,
+		resp *dto.EstimateResponse,
+	) (*openedPool, error)
+}
+
+// openedPool is a pool read and verified for a swap.
+type openedPool struct {
+	pool amm.Pool
+	dir  amm.Direction
+	// factory is the verified deployer of the pool, or nil.
+	factory *Factory
+	// check, if set, checks the swap of amountIn, net of the input tax, whose
+	// output the pool quoted as swapOut. It replaces the cross-check against
+	// the pool's contract.
+	check func(ctx context.Context, resp *dto.EstimateResponse, amountIn, swapOut *big.Int)
+}
+
+// selectors returns the selectors of function signatures.
+func selectors(signatures ...string) [][4]byte {
+	out := make([][4]byte, len(signatures))
+	for i, sig := range signatures {
+		out[i] = amm.Selector(sig)
+	}
+	return out
+}
+
+// poolBackends has a backend for every pool type. A new type is a backend
+// here and its place in detectOrder.
+var poolBackends = map[PoolType]poolBackend{
+	PoolTypeUniswapV2: {
+		selectors: selectors("getReserves()", "token0()", "token1()"),
+		open:      (*EstimatorService).openV2,
+	},
+	PoolTypeUniswapV3: {
+		selectors: selectors("slot0()", "tickSpacing()"),
+		probe:     uniswap.Client.IsV3Pool,
+		open:      (*EstimatorService).openV3,
+	},
+	PoolTypeSolidly: {
+		selectors: selectors("metadata()", "stable()"),
+		probe:     uniswap.Client.IsSolidlyPair,
+		open:      (*EstimatorService).openSolidly,
+	},
+	PoolTypeCurve: {
+		selectors: selectors("A()", "get_dy(int128,int128,uint256)"),
+		probe:     uniswap.Client.IsCurvePool,
+		open:      (*EstimatorService).openCurve,
+	},
+	PoolTypeBalancerWeighted: {
+		selectors: selectors("getNormalizedWeights()", "getPoolId()"),
+		probe:     uniswap.Client.IsBalancerWeightedPool,
+		open:      (*EstimatorService).openBalancer,
+	},
+}
+
+// detectOrder is the order in which pools are matched with the types that
+// have a probe. Solidly pairs also have the uniswap-v2 functions, so every
+// type is tried before uniswap-v2.
+var detectOrder = []PoolType{PoolTypeUniswapV3, PoolTypeSolidly, PoolTypeCurve, PoolTypeBalancerWeighted}
+
+// poolType returns the type of a pool: the chain's configured type for it,
+// or else the type detected from its bytecode, or else from probing it. Pools
+// are only matched with the types the chain has factories of, in detectOrder;
+// everything else is a uniswap-v2 pair.
+func (s *EstimatorService) poolType(ctx context.Context, chain *Chain, pool common.Address) (PoolType, error) {
+	if t, ok := chain.PoolTypes[pool]; ok {
+		return t, nil
+	}
+
+	var candidates []PoolType
+	for _, t := range detectOrder {
+		if chain.hasFactoryType(t) {
+			candidates = append(candidates, t)
+		}
+	}
+	if len(candidates) == 0 {
+		return PoolTypeUniswapV2, nil
+	}
+
+	key := verdictKey{chainID: chain.ID, pair: pool}
+	if t, ok := s.types.get(key); ok {
+		return t, nil
+	}
+
+	t, err := detectPoolType(ctx, chain.Client, pool, candidates)
+	if err != nil {
+		return "", err
+	}
+	s.types.put(key, t)
+
+	return t, nil
+}
+
+// detectPoolType matches a pool's bytecode with the candidate types' and then
+// uniswap-v2's selectors, and probes the pool for the candidates if none match.
+func detectPoolType(
+	ctx context.Context,
+	client uniswap.Client,
+	pool common.Address,
+	candidates []PoolType,
+) (PoolType, error) {
+	code, err := client.GetCode(ctx, pool)
+	if err != nil {
+		return "", errors.Wrap(err, "client.GetCode")
+	}
+
+	for _, t := range append(candidates, PoolTypeUniswapV2) {
+		if amm.HasSelectors(code, poolBackends[t].selectors...) {
+			return t, nil
+		}
+	}
+
+	for _, t := range candidates {
+		ok, err := poolBackends[t].probe(client, ctx, pool)
+		if err != nil {
+			return "", errors.Wrapf(err, "probe %s", t)
+		}
+		if ok {
+			return t, nil
+		}
+	}
+
+	return PoolTypeUniswapV2, nil
+}
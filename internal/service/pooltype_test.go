@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+)
+
+// poolCode returns bytecode with the selectors of a pool type, as the
+// dispatcher of a pool of the type has them.
+func poolCode(t PoolType) []byte {
+	code := common.FromHex("0x608060405234801561001057600080fd5b50")
+	for _, sel := range poolBackends[t].selectors {
+		code = append(code, 0x63)
+		code = append(code, sel[:]...)
+	}
+	return code
+}
+
+func TestPoolType(t *testing.T) {
+	t.Parallel()
+
+	pool := common.HexToAddress("0x1234")
+	factories := []Factory{
+		{Name: "uniswap-v3", Type: PoolTypeUniswapV3, Address: common.HexToAddress("0xa")},
+		{Name: "velodrome", Type: PoolTypeSolidly, Address: common.HexToAddress("0xb")},
+		{Name: "balancer", Type: PoolTypeBalancerWeighted, Address: common.HexToAddress("0xc")},
+	}
+
+	tests := []struct {
+		name       string
+		configured map[common.Address]PoolType
+		mockSetup  func(mc *mock.MockClient)
+		want       PoolType
+	}{
+		{
+			name:       "configured",
+			configured: map[common.Address]PoolType{pool: PoolTypeCurve},
+			mockSetup:  func(*mock.MockClient) {},
+			want:       PoolTypeCurve,
+		},
+		{
+			name: "solidly bytecode",
+			mockSetup: func(mc *mock.MockClient) {
+				// Solidly pairs have the uniswap-v2 functions as well.
+				code := append(poolCode(PoolTypeUniswapV2), poolCode(PoolTypeSolidly)...)
+				mc.EXPECT().GetCode(gomock.Any(), pool).Return(code, nil)
+			},
+			want: PoolTypeSolidly,
+		},
+		{
+			name: "balancer bytecode",
+			mockSetup: func(mc *mock.MockClient) {
+				mc.EXPECT().GetCode(gomock.Any(), pool).Return(poolCode(PoolTypeBalancerWeighted), nil)
+			},
+			want: PoolTypeBalancerWeighted,
+		},
+		{
+			name: "uniswap-v2 bytecode",
+			mockSetup: func(mc *mock.MockClient) {
+				mc.EXPECT().GetCode(gomock.Any(), pool).Return(poolCode(PoolTypeUniswapV2), nil)
+			},
+			want: PoolTypeUniswapV2,
+		},
+		{
+			name: "curve bytecode without a curve registry",
+			mockSetup: func(mc *mock.MockClient) {
+				mc.EXPECT().GetCode(gomock.Any(), pool).Return(poolCode(PoolTypeCurve), nil)
+				mc.EXPECT().IsV3Pool(gomock.Any(), pool).Return(false, nil)
+				mc.EXPECT().IsSolidlyPair(gomock.Any(), pool).Return(false, nil)
+				mc.EXPECT().IsBalancerWeightedPool(gomock.Any(), pool).Return(false, nil)
+			},
+			want: PoolTypeUniswapV2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockClient(ctrl)
+			tt.mockSetup(mockClient)
+
+			service := NewEstimatorService(Chain{
+				ID:        1,
+				Client:    mockClient,
+				Factories: factories,
+				PoolTypes: tt.configured,
+			})
+
+			got, err := service.poolType(context.Background(), service.chains[1], pool)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPoolType_ProbeError(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pool := common.HexToAddress("0x1234")
+
+	mockClient := mock.NewMockClient(ctrl)
+	gomock.InOrder(
+		mockClient.EXPECT().GetCode(gomock.Any(), pool).Return(poolCode(PoolTypeCurve), nil),
+		mockClient.EXPECT().IsV3Pool(gomock.Any(), pool).Return(false, errors.New("header not found")),
+		// The failed detection is not cached as the uniswap-v2 fallback.
+		mockClient.EXPECT().GetCode(gomock.Any(), pool).Return(poolCode(PoolTypeCurve), nil),
+		mockClient.EXPECT().IsV3Pool(gomock.Any(), pool).Return(true, nil),
+	)
+
+	service := NewEstimatorService(Chain{
+		ID:        1,
+		Client:    mockClient,
+		Factories: []Factory{{Name: "uniswap-v3", Type: PoolTypeUniswapV3, Address: common.HexToAddress("0xa")}},
+	})
+
+	_, err := service.poolType(context.Background(), service.chains[1], pool)
+	require.ErrorContains(t, err, "probe uniswap-v3: header not found")
+
+	for range 2 {
+		got, err := service.poolType(context.Background(), service.chains[1], pool)
+		require.NoError(t, err)
+		require.Equal(t, PoolTypeUniswapV3, got)
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+	"github.com/fleshka4/1inch-test-task/internal/dexmath"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+	"github.com/fleshka4/1inch-test-task/internal/service/validate"
+)
+
+// Sequence applies swaps to their pools in order, each one seeing the reserves
+// left by the previous ones, and returns every output and the final reserves.
+//
+// Every swap goes through the same policy checks, pair verification and
+// transfer tax handling as Estimate. All pools are read at the same block.
+func (s *EstimatorService) Sequence(ctx context.Context, req dto.SequenceRequest) (*dto.SequenceResponse, error) {
+	if err := validate.SequenceRequestValidate(req); err != nil {
+		return nil, errors.Wrap(err, "validate.SequenceRequestValidate")
+	}
+
+	chain, err := s.chain(req.ChainID, req.Chain)
+	if err != nil {
+		return nil, errors.Wrap(err, "s.chain")
+	}
+
+	block, err := chain.Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "chain.Client.BlockNumber")
+	}
+	blockNumber := new(big.Int).SetUint64(block)
+
+	resp := &dto.SequenceResponse{BlockNumber: block}
+	states := make(map[common.Address]dexmath.PoolState)
+	var order []common.Address
+
+	for i, swap := range req.Swaps {
+		pool, err := s.resolvePool(ctx, chain, swap.Pool, swap.Src, swap.Dst, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "swap %d: s.resolvePool", i)
+		}
+
+		state, ok := states[swap.Pool]
+		if !ok {
+			reserves, err := chain.Client.GetPairReservesAt(ctx, swap.Pool, blockNumber)
+			if err != nil {
+				return nil, errors.Wrapf(err, "swap %d: chain.Client.GetPairReservesAt", i)
+			}
+			state = dexmath.PoolState{Reserve0: reserves.Reserve0, Reserve1: reserves.Reserve1, FeeBps: pool.feeBps}
+			order = append(order, swap.Pool)
+		}
+
+		dir := dexmath.ZeroForOne
+		reserveIn, reserveOut := state.Reserve0, state.Reserve1
+		if !pool.zeroForOne {
+			dir = dexmath.OneForZero
+			reserveIn, reserveOut = state.Reserve1, state.Reserve0
+		}
+
+		src := s.tokenBehaviour(ctx, chain, swap.Pool, swap.Src, reserveIn)
+		dst := s.tokenBehaviour(ctx, chain, swap.Pool, swap.Dst, reserveOut)
+
+		amountIn := new(big.Int)
+		if !dexmath.ApplyTransferTaxInto(amountIn, swap.SrcAmount, src.InputTaxBps) {
+			return nil, errors.Wrapf(apperrors.ErrInsufficientLiquidity, "swap %d: src token tax is 100%%", i)
+		}
+
+		swapOut, next, ok := state.ApplySwap(amountIn, dir)
+		if !ok {
+			return nil, errors.Wrapf(apperrors.ErrInsufficientLiquidity, "swap %d: bad estimate", i)
+		}
+		states[swap.Pool] = next
+
+		out := new(big.Int)
+		if !dexmath.ApplyTransferTaxInto(out, swapOut, dst.OutputTaxBps) || out.Sign() == 0 {
+			return nil, errors.Wrapf(apperrors.ErrInsufficientLiquidity, "swap %d: bad estimate", i)
+		}
+
+		resp.Steps = append(resp.Steps, dto.SequenceStep{Pool: swap.Pool, DstAmount: out})
+	}
+
+	for _, pool := range order {
+		state := states[pool]
+		resp.Pools = append(resp.Pools, dto.PoolReserves{Pool: pool, Reserve0: state.Reserve0, Reserve1: state.Reserve1})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+)
+
+func TestSequence(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	poolA := common.HexToAddress("0x1234")
+	poolB := common.HexToAddress("0x4321")
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+	block := big.NewInt(19_000_000)
+
+	mockClient := mock.NewMockClient(ctrl)
+	mockClient.EXPECT().BlockNumber(gomock.Any()).Return(block.Uint64(), nil)
+	mockClient.EXPECT().GetPairTokens(gomock.Any(), poolA).Return(token0, token1, nil).Times(2)
+	mockClient.EXPECT().GetPairTokens(gomock.Any(), poolB).Return(token0, token1, nil)
+	// Reserves are read once per pool, at the same block.
+	mockClient.EXPECT().GetPairReservesAt(gomock.Any(), poolA, block).Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(20000)}, nil)
+	mockClient.EXPECT().GetPairReservesAt(gomock.Any(), poolB, block).Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(20000)}, nil)
+
+	service := NewEstimatorService(Chain{ID: 1, Client: mockClient, FeeBps: 30})
+
+	resp, err := service.Sequence(context.Background(), dto.SequenceRequest{
+		Swaps: []dto.SequenceSwap{
+			{Pool: poolA, Src: token0, Dst: token1, SrcAmount: big.NewInt(1000)},
+			{Pool: poolB, Src: token1, Dst: token0, SrcAmount: big.NewInt(1000)},
+			{Pool: poolA, Src: token0, Dst: token1, SrcAmount: big.NewInt(1000)},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, &dto.SequenceResponse{
+		BlockNumber: block.Uint64(),
+		Steps: []dto.SequenceStep{
+			{Pool: poolA, DstAmount: big.NewInt(1813)},
+			{Pool: poolB, DstAmount: big.NewInt(474)},
+			// The second swap on poolA sees the reserves left by the first.
+			{Pool: poolA, DstAmount: big.NewInt(1511)},
+		},
+		Pools: []dto.PoolReserves{
+			{Pool: poolA, Reserve0: big.NewInt(12000), Reserve1: big.NewInt(16676)},
+			{Pool: poolB, Reserve0: big.NewInt(9526), Reserve1: big.NewInt(21000)},
+		},
+	}, resp)
+}
+
+func TestSequence_Errors(t *testing.T) {
+	t.Parallel()
+
+	pool := common.HexToAddress("0x1234")
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+
+	tests := []struct {
+		name      string
+		swaps     []dto.SequenceSwap
+		mockSetup func(mc *mock.MockClient)
+		wantErr   string
+		wantIs    error
+	}{
+		{
+			name:    "empty",
+			wantErr: "sequence cannot be empty",
+			wantIs:  apperrors.ErrInvalidArgument,
+		},
+		{
+			name:    "too long",
+			swaps:   make([]dto.SequenceSwap, 33),
+			wantErr: "sequence cannot have more than 32 swaps",
+			wantIs:  apperrors.ErrInvalidArgument,
+		},
+		{
+			name:    "invalid swap",
+			swaps:   []dto.SequenceSwap{{Pool: pool, Src: token0, Dst: token1}},
+			wantErr: "swap 0: source amount cannot be zero or negative",
+			wantIs:  apperrors.ErrInvalidArgument,
+		},
+		{
+			name: "pool drained",
+			swaps: []dto.SequenceSwap{
+				{Pool: pool, Src: token0, Dst: token1, SrcAmount: big.NewInt(1000)},
+				{Pool: pool, Src: token0, Dst: token1, SrcAmount: big.NewInt(1)},
+			},
+			mockSetup: func(mc *mock.MockClient) {
+				mc.EXPECT().BlockNumber(gomock.Any()).Return(uint64(1), nil)
+				mc.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil).Times(2)
+				mc.EXPECT().GetPairReservesAt(gomock.Any(), pool, big.NewInt(1)).Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10), Reserve1: big.NewInt(10)}, nil)
+			},
+			wantErr: "swap 1: bad estimate",
+			wantIs:  apperrors.ErrInsufficientLiquidity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockClient(ctrl)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockClient)
+			}
+
+			service := NewEstimatorService(Chain{ID: 1, Client: mockClient, FeeBps: 30})
+
+			_, err := service.Sequence(context.Background(), dto.SequenceRequest{Swaps: tt.swaps})
+			require.ErrorContains(t, err, tt.wantErr)
+			require.ErrorIs(t, err, tt.wantIs)
+		})
+	}
+}
@@ -2,23 +2,139 @@ package service
 
 import (
 	"context"
-	"math/big"
+	"strings"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
 	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap"
+	"github.com/fleshka4/1inch-test-task/internal/safety"
 	"github.com/fleshka4/1inch-test-task/internal/service/dto"
 )
 
 // Service represents interface for business logic.
 type Service interface {
-	Estimate(ctx context.Context, req dto.EstimateRequest) (*big.Int, error)
+	Estimate(ctx context.Context, req dto.EstimateRequest) (*dto.EstimateResponse, error)
+	Simulate(ctx context.Context, req dto.SimulateRequest) (*dto.SimulateResponse, error)
+	Sequence(ctx context.Context, req dto.SequenceRequest) (*dto.SequenceResponse, error)
+	PairLiquidity(ctx context.Context, req dto.PairLiquidityRequest) (*dto.PairLiquidity, error)
+	AddLiquidity(ctx context.Context, req dto.AddLiquidityRequest) (*dto.AddLiquidityResponse, error)
+	RemoveLiquidity(ctx context.Context, req dto.RemoveLiquidityRequest) (*dto.RemoveLiquidityResponse, error)
+	Zap(ctx context.Context, req dto.ZapRequest) (*dto.ZapResponse, error)
+	TWAP(ctx context.Context, req dto.TWAPRequest) (*dto.TWAPResponse, error)
+	EstimateHistory(ctx context.Context, req dto.EstimateHistoryRequest, emit func(dto.HistoricalEstimate) error) error
+}
+
+// ArbitrageFeed serves the results of an arbitrage scanner.
+type ArbitrageFeed interface {
+	// Opportunities returns the opportunities of the last scan of a chain,
+	// or of every chain when chainID is zero, most profitable first.
+	Opportunities(chainID uint64) []dto.ArbitrageOpportunity
+	// Subscribe streams the opportunities of every following scan until
+	// the returned function is called.
+	Subscribe() (<-chan dto.ArbitrageOpportunity, func())
+}
+
+// Chain binds a chain to the client used to read its pools.
+type Chain struct {
+	ID     uint64
+	Name   string
+	Client uniswap.Client
+	// FeeBps is the swap fee in basis points applied to pools of the chain.
+	FeeBps uint32
+	// Safety restricts the pools and tokens that may be quoted. Nil allows everything.
+	Safety *safety.Guard
+	// Factories are the trusted factories used to verify pairs; a verified
+	// pair is quoted with its factory's fee. A uniswap-v3 factory enables
+	// quoting Uniswap V3 pools.
+	Factories        []Factory
+	PairVerification PairVerification
+	// Tokens holds the configured behaviour of fee-on-transfer and rebasing tokens.
+	Tokens map[common.Address]TokenBehaviour
+	// DetectTokenTax measures the tax of tokens missing from Tokens with a
+	// simulated transfer, which needs eth_call state override support.
+	DetectTokenTax bool
+	// CrossCheckRatio is the share of quotes, from 0 to 1, compared with the
+	// router of the pool's factory at the same block.
+	CrossCheckRatio float64
+	// TrackedPools are scanned for arbitrage by an ArbitrageScanner.
+	TrackedPools []common.Address
+	// MaxTWAPDeviationBps rejects quotes whose pool's spot price deviates from
+	// its TWAP over TWAPWindow by more. Zero disables the check.
+	MaxTWAPDeviationBps uint32
+	TWAPWindow          time.Duration
+	// MaxPoolAge rejects quotes from pools whose reserves were last updated
+	// longer ago. Zero disables the check.
+	MaxPoolAge time.Duration
+	// PoolTypes fixes the type of pools, which are then not detected.
+	PoolTypes map[common.Address]PoolType
 }
 
 // EstimatorService represents struct for business logic.
 type EstimatorService struct {
-	uniswapClient uniswap.Client
+	chains       map[uint64]*Chain
+	chainsByName map[string]*Chain
+	defaultChain *Chain
+
+	verdicts   verdictCache
+	types      poolTypeCache
+	behaviours behaviourCache
+	mappings   mappingCache
+	windows    windowCache
+	ticks      v3TickCache
 }
 
 // NewEstimatorService creates EstimatorService.
-func NewEstimatorService(cli uniswap.Client) *EstimatorService {
-	return &EstimatorService{uniswapClient: cli}
+// The first chain is used for requests that do not specify one.
+func NewEstimatorService(chains ...Chain) *EstimatorService {
+	s := &EstimatorService{
+		chains:       make(map[uint64]*Chain, len(chains)),
+		chainsByName: make(map[string]*Chain, len(chains)),
+	}
+
+	for i := range chains {
+		chain := &chains[i]
+		s.chains[chain.ID] = chain
+		if chain.Name != "" {
+			s.chainsByName[strings.ToLower(chain.Name)] = chain
+		}
+		if s.defaultChain == nil {
+			s.defaultChain = chain
+		}
+	}
+
+	return s
+}
+
+// chain returns the chain selected by the request.
+func (s *EstimatorService) chain(chainID uint64, name string) (*Chain, error) {
+	if chainID == 0 && name == "" {
+		if s.defaultChain == nil {
+			return nil, errors.Wrap(apperrors.ErrInvalidArgument, "no chains configured")
+		}
+		return s.defaultChain, nil
+	}
+
+	var chain *Chain
+	if chainID != 0 {
+		chain = s.chains[chainID]
+		if chain == nil {
+			return nil, errors.Wrapf(apperrors.ErrInvalidArgument, "unknown chain_id %d", chainID)
+		}
+	}
+
+	if name != "" {
+		byName := s.chainsByName[strings.ToLower(name)]
+		if byName == nil {
+			return nil, errors.Wrapf(apperrors.ErrInvalidArgument, "unknown chain %s", name)
+		}
+		if chain != nil && chain != byName {
+			return nil, errors.Wrapf(apperrors.ErrInvalidArgument, "chain %s does not match chain_id %d", name, chainID)
+		}
+		chain = byName
+	}
+
+	return chain, nil
 }
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+)
+
+// Simulate quotes the swap with Estimate and then executes it through the
+// router of the pool's factory with eth_call, reporting what the recipient
+// actually receives or why the swap reverts.
+//
+// The sender's balance of src and its allowance for the router are patched
+// into the token's storage for the call, so any address can be simulated.
+func (s *EstimatorService) Simulate(ctx context.Context, req dto.SimulateRequest) (*dto.SimulateResponse, error) {
+	if req.Sender == (common.Address{}) {
+		return nil, errors.Wrap(apperrors.ErrInvalidArgument, "sender cannot be empty")
+	}
+
+	quote, err := s.Estimate(ctx, dto.EstimateRequest{
+		ChainID:   req.ChainID,
+		Chain:     req.Chain,
+		Pool:      req.Pool,
+		Src:       req.Src,
+		Dst:       req.Dst,
+		SrcAmount: req.SrcAmount,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "s.Estimate")
+	}
+
+	chain, err := s.chain(req.ChainID, req.Chain)
+	if err != nil {
+		return nil, errors.Wrap(err, "s.chain")
+	}
+
+	router := chain.router(quote.Factory)
+	if router == (common.Address{}) {
+		return nil, errors.Wrap(apperrors.ErrInvalidArgument, "pool is not verified against a factory with a router")
+	}
+
+	storage, err := s.approvalStorage(ctx, chain, req.Src, req.Sender, router, req.SrcAmount)
+	if err != nil {
+		return nil, errors.Wrap(err, "s.approvalStorage")
+	}
+
+	recipient := req.Recipient
+	if recipient == (common.Address{}) {
+		recipient = req.Sender
+	}
+
+	res, err := chain.Client.SimulateSwap(ctx, &uniswapdto.SwapSimulation{
+		Router:       router,
+		Sender:       req.Sender,
+		Recipient:    recipient,
+		Path:         []common.Address{req.Src, req.Dst},
+		AmountIn:     req.SrcAmount,
+		AmountOutMin: new(big.Int),
+		Storage:      storage,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "chain.Client.SimulateSwap")
+	}
+
+	return &dto.SimulateResponse{
+		QuotedAmount: quote.DstAmount,
+		Router:       router,
+		DstAmount:    res.Received,
+		Reverted:     res.Reverted,
+		RevertReason: res.RevertReason,
+	}, nil
+}
+
+// router returns the router of the named factory, or the zero address.
+func (c *Chain) router(factory string) common.Address {
+	if factory == "" {
+		return common.Address{}
+	}
+	for _, f := range c.Factories {
+		if f.Name == factory {
+			return f.Router
+		}
+	}
+	return common.Address{}
+}
+
+// approvalStorage returns the storage patch of token giving owner a balance
+// of amount and spender an allowance of amount.
+func (s *EstimatorService) approvalStorage(
+	ctx context.Context,
+	chain *Chain,
+	token, owner, spender common.Address,
+	amount *big.Int,
+) (map[common.Hash]common.Hash, error) {
+	key := mappingKey{chainID: chain.ID, token: token}
+	layout, ok := s.mappings.get(key)
+	if !ok {
+		balance, err := chain.Client.FindBalanceMapping(ctx, token, owner)
+		if err != nil {
+			return nil, errors.Wrap(err, "chain.Client.FindBalanceMapping")
+		}
+		allowance, err := chain.Client.FindAllowanceMapping(ctx, token, owner, spender)
+		if err != nil {
+			return nil, errors.Wrap(err, "chain.Client.FindAllowanceMapping")
+		}
+
+		layout = tokenLayout{balance: *balance, allowance: *allowance}
+		s.mappings.put(key, layout)
+	}
+
+	value := common.BigToHash(amount)
+	return map[common.Hash]common.Hash{
+		layout.balance.BalanceSlot(owner):              value,
+		layout.allowance.AllowanceSlot(owner, spender): value,
+	}, nil
+}
+
+// maxMappings bounds the token layout cache, which is reset when full.
+const maxMappings = 10000
+
+type mappingKey struct {
+	chainID uint64
+	token   common.Address
+}
+
+type tokenLayout struct {
+	balance   uniswapdto.StorageMapping
+	allowance uniswapdto.StorageMapping
+}
+
+// mappingCache remembers where tokens keep balances and allowances.
+// Layouts do not change unless a proxied token is upgraded, which is rare
+// enough to be fixed by a restart.
+type mappingCache struct {
+	mu      sync.RWMutex
+	layouts map[mappingKey]tokenLayout
+}
+
+func (c *mappingCache) get(key mappingKey) (tokenLayout, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	l, ok := c.layouts[key]
+	return l, ok
+}
+
+func (c *mappingCache) put(key mappingKey, l tokenLayout) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.layouts == nil || len(c.layouts) >= maxMappings {
+		c.layouts = make(map[mappingKey]tokenLayout)
+	}
+	c.layouts[key] = l
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+)
+
+func TestSimulate(t *testing.T) {
+	t.Parallel()
+
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+	sender := common.HexToAddress("0xa11ce")
+	recipient := common.HexToAddress("0xb0b")
+	router := common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D")
+	factory := Factory{
+		Name:         "uniswap-v2",
+		Address:      common.HexToAddress("0xfac7"),
+		InitCodeHash: common.HexToHash("0x01"),
+		FeeBps:       30,
+		Router:       router,
+	}
+	pool := uniswap.PairAddress(factory.Address, token0, token1, factory.InitCodeHash)
+
+	balance := uniswapdto.StorageMapping{Index: 3}
+	allowance := uniswapdto.StorageMapping{Index: 4}
+	wantStorage := map[common.Hash]common.Hash{
+		balance.BalanceSlot(sender):             common.BigToHash(big.NewInt(1000)),
+		allowance.AllowanceSlot(sender, router): common.BigToHash(big.NewInt(1000)),
+	}
+
+	tests := []struct {
+		name      string
+		router    common.Address
+		recipient common.Address
+		mockSetup func(mc *mock.MockClient)
+		want      *dto.SimulateResponse
+		wantErr   error
+	}{
+		{
+			name:   "success",
+			router: router,
+			mockSetup: func(mc *mock.MockClient) {
+				mc.EXPECT().FindBalanceMapping(gomock.Any(), token0, sender).Return(&balance, nil)
+				mc.EXPECT().FindAllowanceMapping(gomock.Any(), token0, sender, router).Return(&allowance, nil)
+				mc.EXPECT().SimulateSwap(gomock.Any(), &uniswapdto.SwapSimulation{
+					Router:       router,
+					Sender:       sender,
+					Recipient:    sender,
+					Path:         []common.Address{token0, token1},
+					AmountIn:     big.NewInt(1000),
+					AmountOutMin: new(big.Int),
+					Storage:      wantStorage,
+				}).Return(&uniswapdto.SwapSimulationResult{Received: big.NewInt(1800)}, nil)
+			},
+			want: &dto.SimulateResponse{QuotedAmount: big.NewInt(1813), Router: router, DstAmount: big.NewInt(1800)},
+		},
+		{
+			name:      "reverted",
+			router:    router,
+			recipient: recipient,
+			mockSetup: func(mc *mock.MockClient) {
+				mc.EXPECT().FindBalanceMapping(gomock.Any(), token0, sender).Return(&balance, nil)
+				mc.EXPECT().FindAllowanceMapping(gomock.Any(), token0, sender, router).Return(&allowance, nil)
+				mc.EXPECT().SimulateSwap(gomock.Any(), gomock.Cond(func(swap *uniswapdto.SwapSimulation) bool {
+					return swap.Recipient == recipient
+				})).Return(&uniswapdto.SwapSimulationResult{Reverted: true, RevertReason: "TRANSFER_FAILED"}, nil)
+			},
+			want: &dto.SimulateResponse{
+				QuotedAmount: big.NewInt(1813),
+				Router:       router,
+				Reverted:     true,
+				RevertReason: "TRANSFER_FAILED",
+			},
+		},
+		{
+			name:    "no router",
+			wantErr: apperrors.ErrInvalidArgument,
+		},
+		{
+			name:   "unknown storage layout",
+			router: router,
+			mockSetup: func(mc *mock.MockClient) {
+				mc.EXPECT().FindBalanceMapping(gomock.Any(), token0, sender).Return(nil, errors.New("not found"))
+			},
+			wantErr: errors.New("s.approvalStorage: chain.Client.FindBalanceMapping: not found"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockClient(ctrl)
+			mockClient.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil)
+			mockClient.EXPECT().GetPairReserves(gomock.Any(), pool).Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(20000)}, nil)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockClient)
+			}
+
+			f := factory
+			f.Router = tt.router
+			service := NewEstimatorService(Chain{
+				ID:               1,
+				Client:           mockClient,
+				Factories:        []Factory{f},
+				PairVerification: PairVerificationPermissive,
+			})
+
+			resp, err := service.Simulate(context.Background(), dto.SimulateRequest{
+				Pool:      pool,
+				Src:       token0,
+				Dst:       token1,
+				SrcAmount: big.NewInt(1000),
+				Sender:    sender,
+				Recipient: tt.recipient,
+			})
+			switch {
+			case errors.Is(tt.wantErr, apperrors.ErrInvalidArgument):
+				require.ErrorIs(t, err, tt.wantErr)
+			case tt.wantErr != nil:
+				require.EqualError(t, err, tt.wantErr.Error())
+			default:
+				require.NoError(t, err)
+				require.Equal(t, tt.want, resp)
+			}
+		})
+	}
+}
+
+func TestSimulate_NoSender(t *testing.T) {
+	t.Parallel()
+
+	service := NewEstimatorService()
+
+	_, err := service.Simulate(context.Background(), dto.SimulateRequest{})
+	require.ErrorIs(t, err, apperrors.ErrInvalidArgument)
+}
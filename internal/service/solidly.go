@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/amm"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+)
+
+// openSolidly opens a Solidly style pair, stable or volatile, with its
+// metadata() and its factory's fee at the latest block. Requested or sampled
+// quotes are cross-checked against the pair's own getAmountOut at that block.
+//
+// The sandwich of a stable pair's swap is not a constant product one, and
+// metadata() has no time of the pair's last update, so requests with
+// slippage_bps or max_pool_age are rejected. The chain's max_pool_age and TWAP
+// deviation bound are not enforced for Solidly pairs either, whose oracle
+// accumulates reserves rather than uniswap-v2 price cumulatives.
+func (s *EstimatorService) openSolidly(
+	ctx context.Context,
+	chain *Chain,
+	req dto.EstimateRequest,
+	_ *dto.EstimateResponse,
+) (*openedPool, error) {
+	if err := rejectV2Guards(PoolTypeSolidly, req); err != nil {
+		return nil, errors.Wrap(err, "rejectV2Guards")
+	}
+
+	latest, err := chain.Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "chain.Client.BlockNumber")
+	}
+
+	pair, err := amm.NewSolidlyPair(chain.Client, req.Pool).MetadataAt(ctx, new(big.Int).SetUint64(latest))
+	if err != nil {
+		return nil, errors.Wrap(err, "pair.MetadataAt")
+	}
+
+	dir, err := poolDirection(pair, req.Src, req.Dst)
+	if err != nil {
+		return nil, errors.Wrap(err, "poolDirection")
+	}
+
+	tokens := pair.Tokens()
+	factory, err := s.checkPool(ctx, chain, PoolTypeSolidly, req.Pool, tokens[0], tokens[1], poolParams{stable: pair.Stable()})
+	if err != nil {
+		return nil, errors.Wrap(err, "s.checkPool")
+	}
+
+	// The fee is read from the verified factory, or else the one the pair reports.
+	var factoryAddr common.Address
+	if factory != nil {
+		factoryAddr = factory.Address
+	}
+	pair, err = pair.FeeAt(ctx, factoryAddr)
+	if err != nil {
+		return nil, errors.Wrap(err, "pair.FeeAt")
+	}
+
+	return &openedPool{pool: pair, dir: dir, factory: factory}, nil
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+	"github.com/fleshka4/1inch-test-task/internal/dexmath"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+)
+
+func TestEstimate_Solidly(t *testing.T) {
+	t.Parallel()
+
+	// A 6 decimals token and an 18 decimals one, as USDC and DAI.
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+	factory := Factory{
+		Name:         "velodrome",
+		Type:         PoolTypeSolidly,
+		Address:      common.HexToAddress("0xfac7"),
+		InitCodeHash: common.HexToHash("0xc1ac28b1c4ebe53c0cff67bab5878c4eb68759bb1e9f73977cd266b247d149f0"),
+	}
+	stablePair := uniswap.SolidlyPairAddress(factory.Address, token0, token1, true, factory.InitCodeHash)
+	volatilePair := uniswap.SolidlyPairAddress(factory.Address, token0, token1, false, factory.InitCodeHash)
+	otherPair := common.HexToAddress("0x1234")
+	block := uint64(120_000_000)
+	blockNumber := new(big.Int).SetUint64(block)
+
+	metadata := func(stable bool) *uniswapdto.SolidlyMetadata {
+		return &uniswapdto.SolidlyMetadata{
+			BlockNumber: block,
+			Decimals0:   big.NewInt(1e6),
+			Decimals1:   big.NewInt(1e18),
+			Reserve0:    big.NewInt(5_000_000e6),
+			Reserve1:    new(big.Int).Mul(big.NewInt(5_100_000), big.NewInt(1e18)),
+			Stable:      stable,
+			Token0:      token0,
+			Token1:      token1,
+		}
+	}
+	quote := func(stable bool, feeBps uint32, amountIn *big.Int, dir dexmath.Direction) *big.Int {
+		m := metadata(stable)
+		pool := dexmath.SolidlyPool{
+			Reserve0: m.Reserve0, Reserve1: m.Reserve1, Decimals0: m.Decimals0, Decimals1: m.Decimals1,
+			Stable: stable, FeeBps: feeBps,
+		}
+		out, ok := pool.GetAmountOut(amountIn, dir)
+		require.True(t, ok)
+		return out
+	}
+
+	readPair := func(mc *mock.MockClient, pair common.Address, stable bool) {
+		mc.EXPECT().BlockNumber(gomock.Any()).Return(block, nil)
+		mc.EXPECT().GetSolidlyMetadata(gomock.Any(), pair, blockNumber).Return(metadata(stable), nil)
+	}
+	readFee := func(mc *mock.MockClient, pair common.Address, stable bool, feeBps int64) {
+		mc.EXPECT().GetSolidlyFee(gomock.Any(), factory.Address, pair, stable, blockNumber).Return(big.NewInt(feeBps), nil)
+	}
+
+	usdc := big.NewInt(1000e6)
+	dai := new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+
+	tests := []struct {
+		name       string
+		pair       common.Address
+		req        dto.EstimateRequest
+		mockSetup  func(mc *mock.MockClient, pair common.Address)
+		want       *dto.EstimateResponse
+		wantErr    error
+		wantErrMsg string
+	}{
+		{
+			name: "stable token0 to token1",
+			pair: stablePair,
+			req:  dto.EstimateRequest{Src: token0, Dst: token1, SrcAmount: usdc},
+			mockSetup: func(mc *mock.MockClient, pair common.Address) {
+				readPair(mc, pair, true)
+				readFee(mc, pair, true, 5)
+			},
+			want: &dto.EstimateResponse{
+				DstAmount:    quote(true, 5, usdc, dexmath.ZeroForOne),
+				PoolVerified: true,
+				Factory:      "velodrome",
+				PoolType:     "solidly",
+			},
+		},
+		{
+			name: "stable token1 to token0 with cross-check",
+			pair: stablePair,
+			req:  dto.EstimateRequest{Src: token1, Dst: token0, SrcAmount: dai, CrossCheck: true},
+			mockSetup: func(mc *mock.MockClient, pair common.Address) {
+				readPair(mc, pair, true)
+				readFee(mc, pair, true, 5)
+				mc.EXPECT().
+					GetSolidlyAmountOut(gomock.Any(), pair, dai, token1, blockNumber).
+					Return(quote(true, 5, dai, dexmath.OneForZero), nil)
+			},
+			want: &dto.EstimateResponse{
+				DstAmount:    quote(true, 5, dai, dexmath.OneForZero),
+				PoolVerified: true,
+				Factory:      "velodrome",
+				PoolType:     "solidly",
+				CrossCheck: &dto.CrossCheck{
+					BlockNumber:   block,
+					OnChainAmount: quote(true, 5, dai, dexmath.OneForZero),
+					Match:         true,
+				},
+			},
+		},
+		{
+			name: "volatile with failed cross-check",
+			pair: volatilePair,
+			req:  dto.EstimateRequest{Src: token0, Dst: token1, SrcAmount: usdc, CrossCheck: true},
+			mockSetup: func(mc *mock.MockClient, pair common.Address) {
+				readPair(mc, pair, false)
+				readFee(mc, pair, false, 30)
+				mc.EXPECT().
+					GetSolidlyAmountOut(gomock.Any(), pair, usdc, token0, blockNumber).
+					Return(nil, errors.New("rpc error"))
+			},
+			want: &dto.EstimateResponse{
+				DstAmount:    quote(false, 30, usdc, dexmath.ZeroForOne),
+				PoolVerified: true,
+				Factory:      "velodrome",
+				PoolType:     "solidly",
+				CrossCheck:   &dto.CrossCheck{BlockNumber: block, Error: "getAmountOut: rpc error"},
+			},
+		},
+		{
+			name: "custom fee",
+			pair: volatilePair,
+			req:  dto.EstimateRequest{Src: token0, Dst: token1, SrcAmount: usdc},
+			mockSetup: func(mc *mock.MockClient, pair common.Address) {
+				readPair(mc, pair, false)
+				readFee(mc, pair, false, 1)
+			},
+			want: &dto.EstimateResponse{
+				DstAmount:    quote(false, 1, usdc, dexmath.ZeroForOne),
+				PoolVerified: true,
+				Factory:      "velodrome",
+				PoolType:     "solidly",
+			},
+		},
+		{
+			name: "fee out of range",
+			pair: volatilePair,
+			req:  dto.EstimateRequest{Src: token0, Dst: token1, SrcAmount: usdc},
+			mockSetup: func(mc *mock.MockClient, pair common.Address) {
+				readPair(mc, pair, false)
+				readFee(mc, pair, false, 10000)
+			},
+			wantErrMsg: "fee of 10000 bps out of range",
+		},
+		{
+			name: "unverified pair",
+			pair: otherPair,
+			req:  dto.EstimateRequest{Src: token0, Dst: token1, SrcAmount: usdc},
+			mockSetup: func(mc *mock.MockClient, pair common.Address) {
+				readPair(mc, pair, true)
+			},
+			wantErr: apperrors.ErrUnverifiedPool,
+		},
+		{
+			name: "tokens do not match",
+			pair: stablePair,
+			req:  dto.EstimateRequest{Src: token0, Dst: common.HexToAddress("0xdead"), SrcAmount: usdc},
+			mockSetup: func(mc *mock.MockClient, pair common.Address) {
+				readPair(mc, pair, true)
+			},
+			wantErr: apperrors.ErrInvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockClient(ctrl)
+			mockClient.EXPECT().GetCode(gomock.Any(), tt.pair).Return(poolCode(PoolTypeSolidly), nil)
+			tt.mockSetup(mockClient, tt.pair)
+
+			service := NewEstimatorService(Chain{
+				ID:               10,
+				Client:           mockClient,
+				FeeBps:           30,
+				Factories:        []Factory{factory},
+				PairVerification: PairVerificationStrict,
+			})
+
+			req := tt.req
+			req.Pool = tt.pair
+			resp, err := service.Estimate(context.Background(), req)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			if tt.wantErrMsg != "" {
+				require.ErrorContains(t, err, tt.wantErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, resp)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// maxTokenBehaviours bounds the detected behaviour cache like maxVerdicts.
+	maxTokenBehaviours = 10000
+	// tokenBehaviourTTL limits how long a detected tax is trusted, as token
+	// owners can usually change it.
+	tokenBehaviourTTL = 10 * time.Minute
+	// probeDivisor sizes the detection transfer as a share of the pool's
+	// reserve, small enough to stay under typical max transaction limits.
+	probeDivisor = 1000
+)
+
+// TokenBehaviour describes how a token deviates from a plain ERC-20 transfer.
+type TokenBehaviour struct {
+	// InputTaxBps is the share of a transfer to a pool that the token keeps,
+	// i.e. the tax on selling it.
+	InputTaxBps uint32
+	// OutputTaxBps is the share of a transfer from a pool that the token keeps,
+	// i.e. the tax on buying it.
+	OutputTaxBps uint32
+	// Rebasing tokens change balances without transfers, so pool reserves
+	// may be out of date and quotes are approximate.
+	Rebasing bool
+}
+
+type behaviourKey struct {
+	chainID uint64
+	token   common.Address
+}
+
+type cachedBehaviour struct {
+	behaviour TokenBehaviour
+	expires   time.Time
+}
+
+// behaviourCache remembers detected token behaviours until they expire.
+type behaviourCache struct {
+	mu         sync.RWMutex
+	behaviours map[behaviourKey]cachedBehaviour
+}
+
+func (c *behaviourCache) get(key behaviourKey, now time.Time) (TokenBehaviour, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	v, ok := c.behaviours[key]
+	if !ok || now.After(v.expires) {
+		return TokenBehaviour{}, false
+	}
+	return v.behaviour, true
+}
+
+func (c *behaviourCache) put(key behaviourKey, b TokenBehaviour, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.behaviours == nil || len(c.behaviours) >= maxTokenBehaviours {
+		c.behaviours = make(map[behaviourKey]cachedBehaviour)
+	}
+	c.behaviours[key] = cachedBehaviour{behaviour: b, expires: now.Add(tokenBehaviourTTL)}
+}
+
+// tokenBehaviour returns the configured behaviour of a token or, when the chain
+// detects taxes, the one measured by moving a share of reserve through the pool.
+// Detection failures are treated as a plain token and not cached.
+func (s *EstimatorService) tokenBehaviour(
+	ctx context.Context,
+	chain *Chain,
+	pool, token common.Address,
+	reserve *big.Int,
+) TokenBehaviour {
+	if b, ok := chain.Tokens[token]; ok {
+		return b
+	}
+	if !chain.DetectTokenTax || reserve.Sign() <= 0 {
+		return TokenBehaviour{}
+	}
+
+	key := behaviourKey{chainID: chain.ID, token: token}
+	now := time.Now()
+	if b, ok := s.behaviours.get(key, now); ok {
+		return b
+	}
+
+	amount := new(big.Int).Quo(reserve, big.NewInt(probeDivisor))
+	if amount.Sign() == 0 {
+		amount.Set(reserve)
+	}
+
+	probe, err := chain.Client.ProbeTransfer(ctx, pool, token, amount)
+	if err != nil || probe.Received.Sign() == 0 {
+		return TokenBehaviour{}
+	}
+
+	b := TokenBehaviour{
+		OutputTaxBps: lossBps(probe.Sent, probe.Received),
+		InputTaxBps:  lossBps(probe.Received, probe.Returned),
+	}
+	s.behaviours.put(key, b, now)
+
+	return b
+}
+
+// lossBps returns the share of sent that did not arrive, rounded up.
+func lossBps(sent, arrived *big.Int) uint32 {
+	if arrived.Cmp(sent) >= 0 {
+		return 0
+	}
+
+	loss := new(big.Int).Sub(sent, arrived)
+	loss.Mul(loss, big.NewInt(10000))
+	loss.Add(loss, new(big.Int).Sub(sent, big.NewInt(1)))
+	loss.Quo(loss, sent)
+
+	return uint32(loss.Uint64())
+}
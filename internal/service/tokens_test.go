@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+)
+
+func TestEstimate_TransferTax(t *testing.T) {
+	t.Parallel()
+
+	pool := common.HexToAddress("0x1234")
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+
+	probe := func(sent, received, returned int64) *uniswapdto.TransferProbe {
+		return &uniswapdto.TransferProbe{
+			Sent:     big.NewInt(sent),
+			Received: big.NewInt(received),
+			Returned: big.NewInt(returned),
+		}
+	}
+
+	tests := []struct {
+		name         string
+		tokens       map[common.Address]TokenBehaviour
+		detect       bool
+		mockSetup    func(mc *mock.MockClient)
+		want         int64
+		wantTaxed    bool
+		wantRebasing bool
+	}{
+		{
+			// 10000 in against 1e6/1e6 reserves without a fee yields 9900.
+			name: "plain tokens",
+			want: 9900,
+		},
+		{
+			name:      "configured input tax",
+			tokens:    map[common.Address]TokenBehaviour{token0: {InputTaxBps: 1000}},
+			want:      8919, // 9000 arrives at the pool.
+			wantTaxed: true,
+		},
+		{
+			name:      "configured output tax",
+			tokens:    map[common.Address]TokenBehaviour{token1: {OutputTaxBps: 1000}},
+			want:      8910,
+			wantTaxed: true,
+		},
+		{
+			name:         "rebasing token",
+			tokens:       map[common.Address]TokenBehaviour{token1: {Rebasing: true}},
+			want:         9900,
+			wantRebasing: true,
+		},
+		{
+			name:   "detected taxes",
+			detect: true,
+			mockSetup: func(mc *mock.MockClient) {
+				// Probes move 1/1000 of the reserve.
+				mc.EXPECT().ProbeTransfer(gomock.Any(), pool, token0, big.NewInt(1000)).Return(probe(1000, 1000, 900), nil)
+				mc.EXPECT().ProbeTransfer(gomock.Any(), pool, token1, big.NewInt(1000)).Return(probe(1000, 900, 810), nil)
+			},
+			want:      8027,
+			wantTaxed: true,
+		},
+		{
+			name:   "detection failure",
+			detect: true,
+			mockSetup: func(mc *mock.MockClient) {
+				mc.EXPECT().ProbeTransfer(gomock.Any(), pool, gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("state overrides are not supported")).Times(2)
+			},
+			want: 9900,
+		},
+		{
+			name:   "configured tokens are not probed",
+			tokens: map[common.Address]TokenBehaviour{token0: {}, token1: {}},
+			detect: true,
+			want:   9900,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockClient(ctrl)
+			mockClient.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil)
+			mockClient.EXPECT().GetPairReserves(gomock.Any(), pool).Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(1_000_000), Reserve1: big.NewInt(1_000_000)}, nil)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockClient)
+			}
+
+			service := NewEstimatorService(Chain{
+				ID:             1,
+				Client:         mockClient,
+				Tokens:         tt.tokens,
+				DetectTokenTax: tt.detect,
+			})
+
+			resp, err := service.Estimate(context.Background(), dto.EstimateRequest{
+				Pool:      pool,
+				Src:       token0,
+				Dst:       token1,
+				SrcAmount: big.NewInt(10000),
+			})
+			require.NoError(t, err)
+			require.Equal(t, big.NewInt(tt.want), resp.DstAmount)
+			require.Equal(t, tt.wantTaxed, resp.Taxed)
+			require.Equal(t, tt.wantRebasing, resp.Rebasing)
+		})
+	}
+}
+
+func TestEstimate_DetectedTaxCached(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pool := common.HexToAddress("0x1234")
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+
+	mockClient := mock.NewMockClient(ctrl)
+	mockClient.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil).Times(2)
+	mockClient.EXPECT().GetPairReserves(gomock.Any(), pool).Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(1_000_000), Reserve1: big.NewInt(1_000_000)}, nil).Times(2)
+	mockClient.EXPECT().ProbeTransfer(gomock.Any(), pool, gomock.Any(), gomock.Any()).
+		Return(&uniswapdto.TransferProbe{Sent: big.NewInt(1000), Received: big.NewInt(1000), Returned: big.NewInt(1000)}, nil).
+		Times(2)
+
+	service := NewEstimatorService(Chain{ID: 1, Client: mockClient, DetectTokenTax: true})
+
+	for range 2 {
+		_, err := service.Estimate(context.Background(), dto.EstimateRequest{
+			Pool:      pool,
+			Src:       token0,
+			Dst:       token1,
+			SrcAmount: big.NewInt(10000),
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestLossBps(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		sent, arrived int64
+		want          uint32
+	}{
+		{sent: 1000, arrived: 1000, want: 0},
+		{sent: 1000, arrived: 1001, want: 0},
+		{sent: 1000, arrived: 900, want: 1000},
+		{sent: 3, arrived: 2, want: 3334}, // 33.33...% rounded up.
+		{sent: 1000, arrived: 0, want: 10000},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, lossBps(big.NewInt(tt.sent), big.NewInt(tt.arrived)))
+	}
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+	"github.com/fleshka4/1inch-test-task/internal/dexmath"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+	"github.com/fleshka4/1inch-test-task/internal/service/validate"
+)
+
+// TWAP returns the time-weighted average prices of a pool over the window
+// ending at the latest block, computed from the pool's price0CumulativeLast
+// and price1CumulativeLast at the first and the last block of the window.
+func (s *EstimatorService) TWAP(ctx context.Context, req dto.TWAPRequest) (*dto.TWAPResponse, error) {
+	if err := validate.TWAPRequestValidate(req); err != nil {
+		return nil, errors.Wrap(err, "validate.TWAPRequestValidate")
+	}
+
+	chain, err := s.chain(req.ChainID, req.Chain)
+	if err != nil {
+		return nil, errors.Wrap(err, "s.chain")
+	}
+
+	if chain.Safety != nil {
+		if err := chain.Safety.CheckPool(req.Pool); err != nil {
+			return nil, errors.Wrap(err, "chain.Safety.CheckPool")
+		}
+	}
+
+	token0, token1, err := chain.Client.GetPairTokens(ctx, req.Pool)
+	if err != nil {
+		return nil, errors.Wrap(err, "chain.Client.GetPairTokens")
+	}
+
+	if chain.Safety != nil {
+		if err := chain.Safety.CheckTokens(token0, token1); err != nil {
+			return nil, errors.Wrap(err, "chain.Safety.CheckTokens")
+		}
+	}
+
+	if _, err := s.verifiedPool(ctx, chain, req.Pool, token0, token1); err != nil {
+		return nil, errors.Wrap(err, "s.verifiedPool")
+	}
+
+	twap, err := s.twap(ctx, chain, req.Pool, req.Window)
+	if err != nil {
+		return nil, errors.Wrap(err, "s.twap")
+	}
+
+	twap.Token0, twap.Token1 = token0, token1
+	return twap, nil
+}
+
+// twap reads the oracle state of pool at the latest block and at the last
+// block at least window before it, and averages the prices between them.
+func (s *EstimatorService) twap(ctx context.Context, chain *Chain, pool common.Address, window time.Duration) (*dto.TWAPResponse, error) {
+	latest, err := chain.Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "chain.Client.BlockNumber")
+	}
+
+	start, err := s.windowStart(ctx, chain, latest, window)
+	if err != nil {
+		return nil, errors.Wrap(err, "s.windowStart")
+	}
+
+	first, err := chain.Client.GetPairObservation(ctx, pool, new(big.Int).SetUint64(start))
+	if err != nil {
+		return nil, errors.Wrap(err, "chain.Client.GetPairObservation")
+	}
+	last, err := chain.Client.GetPairObservation(ctx, pool, new(big.Int).SetUint64(latest))
+	if err != nil {
+		return nil, errors.Wrap(err, "chain.Client.GetPairObservation")
+	}
+
+	begin := dexmath.CurrentObservation(
+		first.Price0CumulativeLast, first.Price1CumulativeLast,
+		first.Reserve0, first.Reserve1, first.BlockTimestampLast, first.BlockTimestamp,
+	)
+	end := dexmath.CurrentObservation(
+		last.Price0CumulativeLast, last.Price1CumulativeLast,
+		last.Reserve0, last.Reserve1, last.BlockTimestampLast, last.BlockTimestamp,
+	)
+
+	price0, price1, ok := dexmath.TWAP(begin, end)
+	if !ok {
+		return nil, errors.Wrap(apperrors.ErrInsufficientLiquidity, "no time elapsed in the window")
+	}
+
+	return &dto.TWAPResponse{
+		StartBlock:     start,
+		EndBlock:       latest,
+		StartTimestamp: first.BlockTimestamp,
+		EndTimestamp:   last.BlockTimestamp,
+		Price0:         price0,
+		Price1:         price1,
+		SpotPrice0:     dexmath.EncodePrice(last.Reserve1, last.Reserve0),
+	}, nil
+}
+
+// checkTWAPDeviation rejects a quote when the spot price of reserves deviates
+// from the pool's TWAP over the chain's window by more than it allows.
+func (s *EstimatorService) checkTWAPDeviation(ctx context.Context, chain *Chain, pool common.Address, r0, r1 *big.Int) error {
+	if chain.MaxTWAPDeviationBps == 0 {
+		return nil
+	}
+
+	twap, err := s.twap(ctx, chain, pool, chain.TWAPWindow)
+	if err != nil {
+		return errors.Wrap(err, "s.twap")
+	}
+
+	if r0.Sign() <= 0 {
+		return nil
+	}
+	deviation := dexmath.PriceDeviationBps(dexmath.EncodePrice(r1, r0), twap.Price0)
+	if deviation > uint64(chain.MaxTWAPDeviationBps) {
+		return errors.Wrapf(
+			apperrors.ErrPriceDeviation,
+			"spot price deviates from %s twap by %d bps, more than %d",
+			chain.TWAPWindow, deviation, chain.MaxTWAPDeviationBps,
+		)
+	}
+	return nil
+}
+
+// windowStart returns the last block whose timestamp is at least window
+// before that of the latest block, searching back from it exponentially and
+// then bisecting. Results are cached until the latest block changes.
+func (s *EstimatorService) windowStart(ctx context.Context, chain *Chain, latest uint64, window time.Duration) (uint64, error) {
+	key := windowKey{chainID: chain.ID, window: window}
+	if start, ok := s.windows.get(key, latest); ok {
+		return start, nil
+	}
+
+	timestamp := func(n uint64) (uint64, error) {
+		ts, err := chain.Client.BlockTimestamp(ctx, new(big.Int).SetUint64(n))
+		if err != nil {
+			return 0, errors.Wrapf(err, "chain.Client.BlockTimestamp(%d)", n)
+		}
+		return ts, nil
+	}
+
+	end, err := timestamp(latest)
+	if err != nil {
+		return 0, err
+	}
+	secs := uint64(window / time.Second)
+	if end < secs {
+		return 0, errors.Wrapf(apperrors.ErrInvalidArgument, "window %s exceeds chain history", window)
+	}
+	target := end - secs
+
+	// lo is at or before target, hi after it.
+	hi, lo := latest, uint64(0)
+	found := false
+	for step := uint64(1); ; step *= 2 {
+		if step >= latest {
+			break
+		}
+		n := latest - step
+		ts, err := timestamp(n)
+		if err != nil {
+			return 0, err
+		}
+		if ts <= target {
+			lo, found = n, true
+			break
+		}
+		hi = n
+	}
+	if !found {
+		ts, err := timestamp(0)
+		if err != nil {
+			return 0, err
+		}
+		if ts > target {
+			return 0, errors.Wrapf(apperrors.ErrInvalidArgument, "window %s exceeds chain history", window)
+		}
+	}
+
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		ts, err := timestamp(mid)
+		if err != nil {
+			return 0, err
+		}
+		if ts <= target {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	s.windows.put(key, latest, lo)
+	return lo, nil
+}
+
+// maxWindows bounds the windowCache, as windows come from requests.
+const maxWindows = 1000
+
+type windowKey struct {
+	chainID uint64
+	window  time.Duration
+}
+
+type windowEntry struct {
+	latest uint64
+	start  uint64
+}
+
+// windowCache remembers the first block of a window for the latest block it
+// was found for, so that quotes in the same block share a single search.
+type windowCache struct {
+	mu     sync.Mutex
+	starts map[windowKey]windowEntry
+}
+
+func (c *windowCache) get(key windowKey, latest uint64) (uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, ok := c.starts[key]
+	if !ok || v.latest != latest {
+		return 0, false
+	}
+	return v.start, true
+}
+
+func (c *windowCache) put(key windowKey, latest, start uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.starts == nil || len(c.starts) >= maxWindows {
+		c.starts = make(map[windowKey]windowEntry)
+	}
+	c.starts[key] = windowEntry{latest: latest, start: start}
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+	"github.com/fleshka4/1inch-test-task/internal/dexmath"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+)
+
+// twapChain mocks a chain with a block every 12 seconds whose pool traded at a
+// price of 2 token1 per token0 from block start to block latest.
+func twapChain(mc *mock.MockClient, pool common.Address, start, latest uint64) {
+	const genesis = 1_700_000_000
+	ts := func(n uint64) uint64 { return genesis + 12*n }
+
+	mc.EXPECT().BlockNumber(gomock.Any()).Return(latest, nil).AnyTimes()
+	mc.EXPECT().BlockTimestamp(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, n *big.Int) (uint64, error) { return ts(n.Uint64()), nil },
+	).AnyTimes()
+
+	r0, r1 := big.NewInt(10000), big.NewInt(20000)
+	cum0, cum1 := big.NewInt(12345), big.NewInt(67890)
+	elapsed := big.NewInt(int64(ts(latest) - ts(start)))
+	mc.EXPECT().GetPairObservation(gomock.Any(), pool, new(big.Int).SetUint64(start)).Return(&uniswapdto.PairObservation{
+		BlockNumber:          start,
+		BlockTimestamp:       ts(start),
+		Price0CumulativeLast: cum0,
+		Price1CumulativeLast: cum1,
+		PairReserves:         uniswapdto.PairReserves{Reserve0: r0, Reserve1: r1, BlockTimestampLast: uint32(ts(start))},
+	}, nil).AnyTimes()
+	mc.EXPECT().GetPairObservation(gomock.Any(), pool, new(big.Int).SetUint64(latest)).Return(&uniswapdto.PairObservation{
+		BlockNumber:          latest,
+		BlockTimestamp:       ts(latest),
+		Price0CumulativeLast: new(big.Int).Add(cum0, new(big.Int).Mul(dexmath.EncodePrice(r1, r0), elapsed)),
+		Price1CumulativeLast: new(big.Int).Add(cum1, new(big.Int).Mul(dexmath.EncodePrice(r0, r1), elapsed)),
+		PairReserves:         uniswapdto.PairReserves{Reserve0: r0, Reserve1: r1, BlockTimestampLast: uint32(ts(latest))},
+	}, nil).AnyTimes()
+}
+
+func TestTWAP(t *testing.T) {
+	t.Parallel()
+
+	pool := common.HexToAddress("0x1234")
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+
+	tests := []struct {
+		name      string
+		window    time.Duration
+		mockSetup func(mc *mock.MockClient)
+		want      *dto.TWAPResponse
+		wantIs    error
+	}{
+		{
+			name:   "window ends on a block",
+			window: 30 * time.Minute,
+			mockSetup: func(mc *mock.MockClient) {
+				twapChain(mc, pool, 850, 1000)
+			},
+			want: &dto.TWAPResponse{
+				Token0:         token0,
+				Token1:         token1,
+				StartBlock:     850,
+				EndBlock:       1000,
+				StartTimestamp: 1_700_010_200,
+				EndTimestamp:   1_700_012_000,
+				Price0:         dexmath.EncodePrice(big.NewInt(2), big.NewInt(1)),
+				Price1:         dexmath.EncodePrice(big.NewInt(1), big.NewInt(2)),
+				SpotPrice0:     dexmath.EncodePrice(big.NewInt(2), big.NewInt(1)),
+			},
+		},
+		{
+			name:   "window between blocks starts before it",
+			window: 30*time.Minute - 5*time.Second,
+			mockSetup: func(mc *mock.MockClient) {
+				twapChain(mc, pool, 850, 1000)
+			},
+			want: &dto.TWAPResponse{
+				Token0:         token0,
+				Token1:         token1,
+				StartBlock:     850,
+				EndBlock:       1000,
+				StartTimestamp: 1_700_010_200,
+				EndTimestamp:   1_700_012_000,
+				Price0:         dexmath.EncodePrice(big.NewInt(2), big.NewInt(1)),
+				Price1:         dexmath.EncodePrice(big.NewInt(1), big.NewInt(2)),
+				SpotPrice0:     dexmath.EncodePrice(big.NewInt(2), big.NewInt(1)),
+			},
+		},
+		{
+			name:   "window exceeds chain history",
+			window: 7 * 24 * time.Hour,
+			mockSetup: func(mc *mock.MockClient) {
+				twapChain(mc, pool, 0, 1000)
+			},
+			wantIs: apperrors.ErrInvalidArgument,
+		},
+		{
+			name:   "window too long",
+			window: 8 * 24 * time.Hour,
+			wantIs: apperrors.ErrInvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockClient(ctrl)
+			if tt.mockSetup != nil {
+				mockClient.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil)
+				tt.mockSetup(mockClient)
+			}
+
+			service := NewEstimatorService(Chain{ID: 1, Client: mockClient})
+			got, err := service.TWAP(context.Background(), dto.TWAPRequest{Pool: pool, Window: tt.window})
+			if tt.wantIs != nil {
+				require.ErrorIs(t, err, tt.wantIs)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEstimate_TWAPDeviation(t *testing.T) {
+	t.Parallel()
+
+	pool := common.HexToAddress("0x1234")
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+
+	tests := []struct {
+		name     string
+		reserve1 int64
+		wantIs   error
+	}{
+		{name: "spot at twap", reserve1: 20000},
+		{name: "spot within bound", reserve1: 20150},
+		{name: "spot moved past bound", reserve1: 30000, wantIs: apperrors.ErrPriceDeviation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockClient(ctrl)
+			mockClient.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil)
+			mockClient.EXPECT().GetPairReserves(gomock.Any(), pool).Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(tt.reserve1)}, nil)
+			twapChain(mockClient, pool, 850, 1000)
+
+			service := NewEstimatorService(Chain{
+				ID:                  1,
+				Client:              mockClient,
+				FeeBps:              30,
+				MaxTWAPDeviationBps: 100,
+				TWAPWindow:          30 * time.Minute,
+			})
+			_, err := service.Estimate(context.Background(), dto.EstimateRequest{
+				Pool: pool, Src: token0, Dst: token1, SrcAmount: big.NewInt(100),
+			})
+			if tt.wantIs != nil {
+				require.ErrorIs(t, err, tt.wantIs)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
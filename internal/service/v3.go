@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"maps"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/amm"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+	"github.com/fleshka4/1inch-test-task/internal/v3math"
+)
+
+// openV3 opens a Uniswap V3 pool at the latest block, whose swap loop is
+// replayed over its ticks near the current price. The tick bitmap words are
+// read as the swap needs them and kept for the following quotes of the pool in
+// the same block.
+//
+// Only configured token taxes apply, as V3 pools have no reserves to size a
+// detection transfer with, and there is no cross-check. The sandwich of a swap
+// through concentrated liquidity is not a constant product one, and slot0 has
+// no time of the pool's last swap, so requests with slippage_bps or
+// max_pool_age are rejected. The chain's max_pool_age and TWAP deviation bound
+// are not enforced either: V3 pools keep no price cumulatives, only observe().
+func (s *EstimatorService) openV3(
+	ctx context.Context,
+	chain *Chain,
+	req dto.EstimateRequest,
+	_ *dto.EstimateResponse,
+) (*openedPool, error) {
+	if err := rejectV2Guards(PoolTypeUniswapV3, req); err != nil {
+		return nil, errors.Wrap(err, "rejectV2Guards")
+	}
+
+	token0, token1, err := chain.Client.GetPairTokens(ctx, req.Pool)
+	if err != nil {
+		return nil, errors.Wrap(err, "chain.Client.GetPairTokens")
+	}
+
+	ticks := func(
+		ctx context.Context,
+		pool common.Address,
+		tickSpacing int32,
+		minWord, maxWord int16,
+		blockNumber *big.Int,
+	) ([]v3math.Tick, error) {
+		return s.v3Ticks(ctx, chain, pool, tickSpacing, minWord, maxWord, blockNumber)
+	}
+	pool := amm.NewV3Pool(chain.Client, req.Pool, token0, token1, ticks)
+
+	dir, err := poolDirection(pool, req.Src, req.Dst)
+	if err != nil {
+		return nil, errors.Wrap(err, "poolDirection")
+	}
+
+	latest, err := chain.Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "chain.Client.BlockNumber")
+	}
+
+	pool, err = pool.SlotAt(ctx, new(big.Int).SetUint64(latest))
+	if err != nil {
+		return nil, errors.Wrap(err, "pool.SlotAt")
+	}
+
+	factory, err := s.checkPool(ctx, chain, PoolTypeUniswapV3, req.Pool, token0, token1, poolParams{fee: pool.Fee()})
+	if err != nil {
+		return nil, errors.Wrap(err, "s.checkPool")
+	}
+
+	return &openedPool{pool: pool, dir: dir, factory: factory}, nil
+}
+
+// v3Ticks returns the initialized ticks of the tick bitmap words from minWord
+// to maxWord of a V3 pool at a block, sorted by index. Words missing from the
+// cache are read in runs of consecutive words and cached.
+func (s *EstimatorService) v3Ticks(
+	ctx context.Context,
+	chain *Chain,
+	pool common.Address,
+	tickSpacing int32,
+	minWord, maxWord int16,
+	blockNumber *big.Int,
+) ([]v3math.Tick, error) {
+	key := verdictKey{chainID: chain.ID, pair: pool}
+	block := blockNumber.Uint64()
+	words := s.ticks.get(key, block, minWord, maxWord)
+
+	read := make(map[int16][]v3math.Tick)
+	for from := minWord; from <= maxWord; {
+		if _, ok := words[from]; ok {
+			from++
+			continue
+		}
+		to := from
+		for to < maxWord {
+			if _, ok := words[to+1]; ok {
+				break
+			}
+			to++
+		}
+
+		ticks, err := chain.Client.GetV3Ticks(ctx, pool, tickSpacing, from, to, blockNumber)
+		if err != nil {
+			return nil, errors.Wrap(err, "chain.Client.GetV3Ticks")
+		}
+		for w := from; w <= to; w++ {
+			read[w] = nil
+		}
+		for _, t := range ticks {
+			w := v3math.TickWord(t.Index, tickSpacing)
+			read[w] = append(read[w], v3math.Tick{Index: t.Index, LiquidityNet: t.LiquidityNet})
+		}
+		from = to + 1
+	}
+
+	if len(read) > 0 {
+		s.ticks.put(key, block, read)
+		maps.Copy(words, read)
+	}
+
+	var ticks []v3math.Tick
+	for w := minWord; w <= maxWord; w++ {
+		ticks = append(ticks, words[w]...)
+	}
+	return ticks, nil
+}
+
+// v3TickCache keeps the tick bitmap words read of V3 pools at the latest
+// block they were read at, so that quotes in the same block share the reads.
+type v3TickCache struct {
+	mu    sync.Mutex
+	pools map[verdictKey]v3Words
+}
+
+// v3Words are the initialized ticks of a pool's words at a block, by word.
+// Words without initialized ticks have nil ticks.
+type v3Words struct {
+	block uint64
+	words map[int16][]v3math.Tick
+}
+
+// get returns the cached words from minWord to maxWord of a pool at a block.
+func (c *v3TickCache) get(key verdictKey, block uint64, minWord, maxWord int16) map[int16][]v3math.Tick {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make(map[int16][]v3math.Tick)
+	entry, ok := c.pools[key]
+	if !ok || entry.block != block {
+		return out
+	}
+	for w, ticks := range entry.words {
+		if w >= minWord && w <= maxWord {
+			out[w] = ticks
+		}
+	}
+	return out
+}
+
+// put caches words of a pool at a block, replacing those of earlier blocks.
+func (c *v3TickCache) put(key verdictKey, block uint64, words map[int16][]v3math.Tick) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.pools[key]
+	switch {
+	case ok && entry.block > block:
+		return
+	case !ok || entry.block < block:
+		if c.pools == nil || len(c.pools) >= maxVerdicts {
+			c.pools = make(map[verdictKey]v3Words)
+		}
+		entry = v3Words{block: block, words: make(map[int16][]v3math.Tick, len(words))}
+		c.pools[key] = entry
+	}
+	maps.Copy(entry.words, words)
+}
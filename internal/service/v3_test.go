@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap"
+	uniswapdto "github.com/fleshka4/1inch-test-task/internal/infra/uniswap/dto"
+	"github.com/fleshka4/1inch-test-task/internal/infra/uniswap/mock"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+	"github.com/fleshka4/1inch-test-task/internal/v3math"
+)
+
+func TestEstimate_UniswapV3(t *testing.T) {
+	t.Parallel()
+
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+	factory := Factory{
+		Name:         "uniswap-v3",
+		Type:         PoolTypeUniswapV3,
+		Address:      common.HexToAddress("0xfac7"),
+		InitCodeHash: common.HexToHash("0xe34f199b19b2b4f47f68442619d555527d244f78a3297ea89325f843f87b8b54"),
+	}
+	v3Pool := uniswap.V3PoolAddress(factory.Address, token0, token1, 3000, factory.InitCodeHash)
+	otherPool := common.HexToAddress("0x1234")
+	block := uint64(19_000_000)
+
+	// One position over [-600, 600] with the price at tick 0.
+	state := &uniswapdto.V3PoolState{
+		BlockNumber:  block,
+		SqrtPriceX96: new(big.Int).Set(v3math.Q96),
+		Liquidity:    big.NewInt(1e18),
+		Fee:          3000,
+		TickSpacing:  60,
+	}
+	ticks := []uniswapdto.V3Tick{
+		{Index: -600, LiquidityNet: big.NewInt(1e18)},
+		{Index: 600, LiquidityNet: big.NewInt(-1e18)},
+	}
+	// A second position over [-46080, -600], in words -3 to -1, takes over
+	// below the first one.
+	deepTicks := []uniswapdto.V3Tick{
+		{Index: -46080, LiquidityNet: big.NewInt(1e18)},
+		{Index: -600, LiquidityNet: big.NewInt(0)},
+		{Index: 600, LiquidityNet: big.NewInt(-1e18)},
+	}
+	quote := func(ticks []uniswapdto.V3Tick, amountIn *big.Int, zeroForOne bool) *big.Int {
+		pool := v3math.Pool{
+			SqrtPriceX96: state.SqrtPriceX96, Liquidity: state.Liquidity, Fee: state.Fee, TickSpacing: 60,
+			MinWord: -58, MaxWord: 57,
+		}
+		for _, tick := range ticks {
+			pool.Ticks = append(pool.Ticks, v3math.Tick{Index: tick.Index, LiquidityNet: tick.LiquidityNet})
+		}
+		out, ok := pool.QuoteExactIn(amountIn, zeroForOne)
+		require.True(t, ok)
+		return out
+	}
+
+	readPool := func(mc *mock.MockClient, pool common.Address) {
+		mc.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil)
+		mc.EXPECT().BlockNumber(gomock.Any()).Return(block, nil)
+		mc.EXPECT().GetV3PoolState(gomock.Any(), pool, new(big.Int).SetUint64(block)).Return(state, nil)
+	}
+	readTicks := func(mc *mock.MockClient, pool common.Address) {
+		readWords(mc, pool, 60, block, ticks, [2]int16{-2, 2})
+	}
+
+	tests := []struct {
+		name      string
+		pool      common.Address
+		req       dto.EstimateRequest
+		mockSetup func(mc *mock.MockClient, pool common.Address)
+		want      *dto.EstimateResponse
+		wantErr   error
+	}{
+		{
+			name: "token0 to token1",
+			pool: v3Pool,
+			req:  dto.EstimateRequest{Src: token0, Dst: token1, SrcAmount: big.NewInt(1e15)},
+			mockSetup: func(mc *mock.MockClient, pool common.Address) {
+				readPool(mc, pool)
+				readTicks(mc, pool)
+			},
+			want: &dto.EstimateResponse{
+				DstAmount:    quote(ticks, big.NewInt(1e15), true),
+				PoolVerified: true,
+				Factory:      "uniswap-v3",
+				PoolType:     "uniswap-v3",
+			},
+		},
+		{
+			name: "token1 to token0 with cross-check",
+			pool: v3Pool,
+			req:  dto.EstimateRequest{Src: token1, Dst: token0, SrcAmount: big.NewInt(1e15), CrossCheck: true},
+			mockSetup: func(mc *mock.MockClient, pool common.Address) {
+				readPool(mc, pool)
+				readTicks(mc, pool)
+			},
+			want: &dto.EstimateResponse{
+				DstAmount:    quote(ticks, big.NewInt(1e15), false),
+				PoolVerified: true,
+				Factory:      "uniswap-v3",
+				PoolType:     "uniswap-v3",
+				CrossCheck:   &dto.CrossCheck{Error: "not supported for uniswap-v3 pools"},
+			},
+		},
+		{
+			name:      "unverified pool",
+			pool:      otherPool,
+			req:       dto.EstimateRequest{Src: token0, Dst: token1, SrcAmount: big.NewInt(1e15)},
+			mockSetup: readPool,
+			wantErr:   apperrors.ErrUnverifiedPool,
+		},
+		{
+			name: "swap into further words",
+			pool: v3Pool,
+			req:  dto.EstimateRequest{Src: token0, Dst: token1, SrcAmount: big.NewInt(4e18)},
+			mockSetup: func(mc *mock.MockClient, pool common.Address) {
+				readPool(mc, pool)
+				readWords(mc, pool, 60, block, deepTicks, [2]int16{-2, 2}, [2]int16{-4, -3})
+			},
+			want: &dto.EstimateResponse{
+				DstAmount:    quote(deepTicks, big.NewInt(4e18), true),
+				PoolVerified: true,
+				Factory:      "uniswap-v3",
+				PoolType:     "uniswap-v3",
+			},
+		},
+		{
+			name: "swap beyond the liquidity",
+			pool: v3Pool,
+			req:  dto.EstimateRequest{Src: token0, Dst: token1, SrcAmount: new(big.Int).Exp(big.NewInt(10), big.NewInt(24), nil)},
+			mockSetup: func(mc *mock.MockClient, pool common.Address) {
+				readPool(mc, pool)
+				// The words read double in the swap's direction, up to 32 of them.
+				readWords(mc, pool, 60, block, ticks,
+					[2]int16{-2, 2}, [2]int16{-4, -3}, [2]int16{-8, -5}, [2]int16{-16, -9}, [2]int16{-32, -17})
+			},
+			wantErr: apperrors.ErrInsufficientLiquidity,
+		},
+		{
+			name:    "tokens do not match",
+			pool:    v3Pool,
+			req:     dto.EstimateRequest{Src: token0, Dst: common.HexToAddress("0xdead"), SrcAmount: big.NewInt(1e15)},
+			wantErr: apperrors.ErrInvalidArgument,
+			mockSetup: func(mc *mock.MockClient, pool common.Address) {
+				mc.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockClient(ctrl)
+			mockClient.EXPECT().GetCode(gomock.Any(), tt.pool).Return(poolCode(PoolTypeUniswapV3), nil)
+			tt.mockSetup(mockClient, tt.pool)
+
+			service := NewEstimatorService(Chain{
+				ID:               1,
+				Client:           mockClient,
+				FeeBps:           30,
+				Factories:        []Factory{factory},
+				PairVerification: PairVerificationStrict,
+			})
+
+			req := tt.req
+			req.Pool = tt.pool
+			resp, err := service.Estimate(context.Background(), req)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, resp)
+		})
+	}
+}
+
+// readWords expects reads of the tick bitmap words of a V3 pool in runs of
+// consecutive words, answered with the ticks in them.
+func readWords(mc *mock.MockClient, pool common.Address, tickSpacing int32, block uint64, ticks []uniswapdto.V3Tick, runs ...[2]int16) {
+	for _, run := range runs {
+		var inRun []uniswapdto.V3Tick
+		for _, tick := range ticks {
+			if w := v3math.TickWord(tick.Index, tickSpacing); w >= run[0] && w <= run[1] {
+				inRun = append(inRun, tick)
+			}
+		}
+		mc.EXPECT().
+			GetV3Ticks(gomock.Any(), pool, tickSpacing, run[0], run[1], new(big.Int).SetUint64(block)).
+			Return(inRun, nil)
+	}
+}
+
+func TestEstimate_UniswapV3TickCache(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pool := common.HexToAddress("0x1234")
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+	ticks := []uniswapdto.V3Tick{
+		{Index: -46080, LiquidityNet: big.NewInt(1e18)},
+		{Index: 600, LiquidityNet: big.NewInt(-1e18)},
+	}
+	state := func(block uint64) *uniswapdto.V3PoolState {
+		return &uniswapdto.V3PoolState{
+			BlockNumber:  block,
+			SqrtPriceX96: new(big.Int).Set(v3math.Q96),
+			Liquidity:    big.NewInt(1e18),
+			Fee:          3000,
+			TickSpacing:  60,
+		}
+	}
+
+	mockClient := mock.NewMockClient(ctrl)
+	mockClient.EXPECT().GetPairTokens(gomock.Any(), pool).Return(token0, token1, nil).Times(3)
+	for _, block := range []uint64{100, 100, 101} {
+		mockClient.EXPECT().BlockNumber(gomock.Any()).Return(block, nil)
+		mockClient.EXPECT().GetV3PoolState(gomock.Any(), pool, new(big.Int).SetUint64(block)).Return(state(block), nil)
+	}
+	// The first quote reads words -2 to 2; the second, in the same block and
+	// going further, only words -4 and -3; the third, in the next block, all
+	// of its words again.
+	readWords(mockClient, pool, 60, 100, ticks, [2]int16{-2, 2}, [2]int16{-4, -3})
+	readWords(mockClient, pool, 60, 101, ticks, [2]int16{-2, 2})
+
+	service := NewEstimatorService(Chain{
+		ID:        1,
+		Client:    mockClient,
+		PoolTypes: map[common.Address]PoolType{pool: PoolTypeUniswapV3},
+	})
+
+	for _, amount := range []int64{1e15, 4e18, 1e15} {
+		_, err := service.Estimate(context.Background(), dto.EstimateRequest{
+			Pool: pool, Src: token0, Dst: token1, SrcAmount: big.NewInt(amount),
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestEstimate_PoolTypeDetection(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pair := common.HexToAddress("0x1234")
+	token0 := common.HexToAddress("0x5678")
+	token1 := common.HexToAddress("0x12345678")
+
+	// A pair on a chain with a uniswap-v3 factory is detected once and then
+	// quoted as uniswap-v2.
+	mockClient := mock.NewMockClient(ctrl)
+	mockClient.EXPECT().GetCode(gomock.Any(), pair).Return(poolCode(PoolTypeUniswapV2), nil).Times(1)
+	mockClient.EXPECT().GetPairTokens(gomock.Any(), pair).Return(token0, token1, nil).Times(2)
+	mockClient.EXPECT().
+		GetPairReserves(gomock.Any(), pair).
+		Return(&uniswapdto.PairReserves{Reserve0: big.NewInt(10000), Reserve1: big.NewInt(20000)}, nil).
+		Times(2)
+
+	service := NewEstimatorService(Chain{
+		ID:               1,
+		Client:           mockClient,
+		FeeBps:           30,
+		Factories:        []Factory{{Name: "uniswap-v3", Type: PoolTypeUniswapV3, Address: common.HexToAddress("0xfac7")}},
+		PairVerification: PairVerificationOff,
+	})
+
+	for range 2 {
+		resp, err := service.Estimate(context.Background(), dto.EstimateRequest{
+			Pool:      pair,
+			Src:       token0,
+			Dst:       token1,
+			SrcAmount: big.NewInt(1000),
+		})
+		require.NoError(t, err)
+		require.Equal(t, "uniswap-v2", resp.PoolType)
+		require.Equal(t, big.NewInt(1813), resp.DstAmount)
+	}
+}
+
+func TestEstimate_PoolTypeProbeOrder(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pool := common.HexToAddress("0x1234")
+
+	// Pools whose bytecode matches no type are probed in order until one
+	// type claims them.
+	mockClient := mock.NewMockClient(ctrl)
+	gomock.InOrder(
+		mockClient.EXPECT().GetCode(gomock.Any(), pool).Return(common.FromHex("0x6080604052"), nil),
+		mockClient.EXPECT().IsV3Pool(gomock.Any(), pool).Return(false, nil),
+		mockClient.EXPECT().IsSolidlyPair(gomock.Any(), pool).Return(false, nil),
+		mockClient.EXPECT().IsCurvePool(gomock.Any(), pool).Return(true, nil),
+	)
+
+	service := NewEstimatorService(Chain{
+		ID:     1,
+		Client: mockClient,
+		Factories: []Factory{
+			{Name: "curve", Type: PoolTypeCurve, Address: common.HexToAddress("0xc")},
+			{Name: "velodrome", Type: PoolTypeSolidly, Address: common.HexToAddress("0xb")},
+			{Name: "uniswap-v3", Type: PoolTypeUniswapV3, Address: common.HexToAddress("0xa")},
+		},
+	})
+
+	for range 2 {
+		got, err := service.poolType(context.Background(), service.chains[1], pool)
+		require.NoError(t, err)
+		require.Equal(t, PoolTypeCurve, got)
+	}
+}
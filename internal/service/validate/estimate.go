@@ -24,5 +24,25 @@ func EstimateRequestValidate(req dto.EstimateRequest) error {
 		return errors.Wrap(apperrors.ErrInvalidArgument, "source amount cannot be zero or negative")
 	}
 
+	if req.SlippageBps >= 10000 {
+		return errors.Wrap(apperrors.ErrInvalidArgument, "slippage must be below 100%")
+	}
+
+	if req.MaxPoolAge < 0 {
+		return errors.Wrap(apperrors.ErrInvalidArgument, "max pool age cannot be negative")
+	}
+
+	if snap := req.Snapshot; snap != nil {
+		if snap.Reserve0 == nil || snap.Reserve1 == nil || snap.Reserve0.Sign() < 0 || snap.Reserve1.Sign() < 0 {
+			return errors.Wrap(apperrors.ErrInvalidArgument, "snapshot reserves cannot be empty or negative")
+		}
+		if (snap.Token0 == zeroAddress) != (snap.Token1 == zeroAddress) {
+			return errors.Wrap(apperrors.ErrInvalidArgument, "snapshot must have both tokens or none")
+		}
+		if req.CrossCheck {
+			return errors.Wrap(apperrors.ErrInvalidArgument, "cross check cannot be made against a snapshot")
+		}
+	}
+
 	return nil
 }
@@ -150,6 +150,66 @@ func TestEstimateRequestValidate_EdgeCases(t *testing.T) {
 	})
 }
 
+func TestEstimateRequestValidate_Snapshot(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		snapshot   dto.PoolSnapshot
+		crossCheck bool
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		{
+			name:     "reserves only",
+			snapshot: dto.PoolSnapshot{Reserve0: big.NewInt(1), Reserve1: big.NewInt(2)},
+			wantErr:  assert.NoError,
+		},
+		{
+			name: "with tokens",
+			snapshot: dto.PoolSnapshot{
+				Token0:   common.HexToAddress("0x1"),
+				Token1:   common.HexToAddress("0x2"),
+				Reserve0: big.NewInt(1),
+				Reserve1: big.NewInt(2),
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:     "missing reserve",
+			snapshot: dto.PoolSnapshot{Reserve0: big.NewInt(1)},
+			wantErr:  assert.Error,
+		},
+		{
+			name:     "negative reserve",
+			snapshot: dto.PoolSnapshot{Reserve0: big.NewInt(1), Reserve1: big.NewInt(-2)},
+			wantErr:  assert.Error,
+		},
+		{
+			name:     "one token",
+			snapshot: dto.PoolSnapshot{Token0: common.HexToAddress("0x1"), Reserve0: big.NewInt(1), Reserve1: big.NewInt(2)},
+			wantErr:  assert.Error,
+		},
+		{
+			name:       "with cross check",
+			snapshot:   dto.PoolSnapshot{Reserve0: big.NewInt(1), Reserve1: big.NewInt(2)},
+			crossCheck: true,
+			wantErr:    assert.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := createValidRequest()
+			req.Snapshot = &tt.snapshot
+			req.CrossCheck = tt.crossCheck
+
+			tt.wantErr(t, EstimateRequestValidate(req))
+		})
+	}
+}
+
 // Вспомогательная функция для создания валидного запроса
 func createValidRequest() dto.EstimateRequest {
 	return dto.EstimateRequest{
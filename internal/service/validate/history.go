@@ -0,0 +1,37 @@
+package validate
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+)
+
+// MaxHistoryBlocks bounds the number of blocks of an estimate history request.
+const MaxHistoryBlocks = 1000
+
+// EstimateHistoryRequestValidate validates business logic request for an estimate history.
+func EstimateHistoryRequestValidate(req dto.EstimateHistoryRequest) error {
+	if err := EstimateRequestValidate(dto.EstimateRequest{
+		Pool:      req.Pool,
+		Src:       req.Src,
+		Dst:       req.Dst,
+		SrcAmount: req.SrcAmount,
+	}); err != nil {
+		return err
+	}
+
+	if req.Step == 0 {
+		return errors.Wrap(apperrors.ErrInvalidArgument, "step cannot be zero")
+	}
+
+	if req.FromBlock > req.ToBlock {
+		return errors.Wrap(apperrors.ErrInvalidArgument, "from block cannot be after to block")
+	}
+
+	if (req.ToBlock-req.FromBlock)/req.Step >= MaxHistoryBlocks {
+		return errors.Wrapf(apperrors.ErrInvalidArgument, "range cannot have more than %d blocks", MaxHistoryBlocks)
+	}
+
+	return nil
+}
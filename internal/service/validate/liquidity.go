@@ -0,0 +1,61 @@
+package validate
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+)
+
+// PairLiquidityRequestValidate validates business logic request for a pair's state.
+func PairLiquidityRequestValidate(req dto.PairLiquidityRequest) error {
+	if req.Pool == (common.Address{}) {
+		return errors.Wrap(apperrors.ErrInvalidArgument, "address cannot be empty")
+	}
+
+	return nil
+}
+
+// AddLiquidityRequestValidate validates business logic request for a deposit.
+func AddLiquidityRequestValidate(req dto.AddLiquidityRequest) error {
+	if req.Pool == (common.Address{}) {
+		return errors.Wrap(apperrors.ErrInvalidArgument, "address cannot be empty")
+	}
+
+	if req.Amount0 == nil || req.Amount1 == nil || req.Amount0.Sign() < 0 || req.Amount1.Sign() < 0 {
+		return errors.Wrap(apperrors.ErrInvalidArgument, "amounts cannot be empty or negative")
+	}
+
+	if req.Amount0.Sign() == 0 && req.Amount1.Sign() == 0 {
+		return errors.Wrap(apperrors.ErrInvalidArgument, "amounts cannot both be zero")
+	}
+
+	return nil
+}
+
+// RemoveLiquidityRequestValidate validates business logic request for a burn.
+func RemoveLiquidityRequestValidate(req dto.RemoveLiquidityRequest) error {
+	if req.Pool == (common.Address{}) {
+		return errors.Wrap(apperrors.ErrInvalidArgument, "address cannot be empty")
+	}
+
+	if req.Liquidity == nil || req.Liquidity.Sign() <= 0 {
+		return errors.Wrap(apperrors.ErrInvalidArgument, "liquidity cannot be zero or negative")
+	}
+
+	return nil
+}
+
+// ZapRequestValidate validates business logic request for a single-sided deposit.
+func ZapRequestValidate(req dto.ZapRequest) error {
+	if req.Pool == (common.Address{}) || req.Src == (common.Address{}) {
+		return errors.Wrap(apperrors.ErrInvalidArgument, "address cannot be empty")
+	}
+
+	if req.SrcAmount == nil || req.SrcAmount.Sign() <= 0 {
+		return errors.Wrap(apperrors.ErrInvalidArgument, "source amount cannot be zero or negative")
+	}
+
+	return nil
+}
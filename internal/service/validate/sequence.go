@@ -0,0 +1,35 @@
+package validate
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+)
+
+// MaxSequenceSwaps bounds the number of swaps in a sequence.
+const MaxSequenceSwaps = 32
+
+// SequenceRequestValidate validates business logic request for a sequence of swaps.
+func SequenceRequestValidate(req dto.SequenceRequest) error {
+	if len(req.Swaps) == 0 {
+		return errors.Wrap(apperrors.ErrInvalidArgument, "sequence cannot be empty")
+	}
+	if len(req.Swaps) > MaxSequenceSwaps {
+		return errors.Wrapf(apperrors.ErrInvalidArgument, "sequence cannot have more than %d swaps", MaxSequenceSwaps)
+	}
+
+	for i, swap := range req.Swaps {
+		err := EstimateRequestValidate(dto.EstimateRequest{
+			Pool:      swap.Pool,
+			Src:       swap.Src,
+			Dst:       swap.Dst,
+			SrcAmount: swap.SrcAmount,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "swap %d", i)
+		}
+	}
+
+	return nil
+}
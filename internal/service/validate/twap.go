@@ -0,0 +1,27 @@
+package validate
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/apperrors"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+)
+
+// MaxTWAPWindow bounds the window of a TWAP request.
+const MaxTWAPWindow = 7 * 24 * time.Hour
+
+// TWAPRequestValidate validates business logic request for a TWAP.
+func TWAPRequestValidate(req dto.TWAPRequest) error {
+	if req.Pool == (common.Address{}) {
+		return errors.Wrap(apperrors.ErrInvalidArgument, "address cannot be empty")
+	}
+
+	if req.Window < time.Second || req.Window > MaxTWAPWindow {
+		return errors.Wrapf(apperrors.ErrInvalidArgument, "window must be in [1s, %s]", MaxTWAPWindow)
+	}
+
+	return nil
+}
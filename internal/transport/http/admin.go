@@ -0,0 +1,66 @@
+package http
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"expvar"
+	"log"
+	"net/http"
+	"strings"
+)
+
+type reloadResponse struct {
+	Changes []string `json:"changes"`
+}
+
+func (s *Server) handleReload(w http.ResponseWriter, r *http.Request, reload ReloadFunc) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "invalid http method: "+r.Method, http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !s.authorizeAdmin(r) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	changes, err := reload()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if changes == nil {
+		changes = []string{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(reloadResponse{Changes: changes}); err != nil {
+		log.Printf("reload write error: %v", err)
+	}
+}
+
+// handleDebugVars serves the expvar variables to admins only: they include
+// the process command line, whose flags may carry the admin token and RPC keys.
+func (s *Server) handleDebugVars(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeAdmin(r) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	expvar.Handler().ServeHTTP(w, r)
+}
+
+// authorizeAdmin checks the bearer token. Admin endpoints are disabled
+// while no token is configured.
+func (s *Server) authorizeAdmin(r *http.Request) bool {
+	token := *s.adminToken.Load()
+	if token == "" {
+		return false
+	}
+
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
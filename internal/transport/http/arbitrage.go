@@ -0,0 +1,112 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/fleshka4/1inch-test-task/internal/service"
+	servicedto "github.com/fleshka4/1inch-test-task/internal/service/dto"
+	"github.com/fleshka4/1inch-test-task/internal/transport/http/dto"
+	"github.com/fleshka4/1inch-test-task/internal/transport/http/validate"
+)
+
+// EnableArbitrage registers the GET /arbitrage and GET /arbitrage/stream
+// endpoints serving the opportunities found by feed.
+func (s *Server) EnableArbitrage(feed service.ArbitrageFeed) {
+	s.mux.HandleFunc("/arbitrage", func(w http.ResponseWriter, r *http.Request) {
+		s.handleArbitrage(w, r, feed)
+	})
+	s.mux.HandleFunc("/arbitrage/stream", func(w http.ResponseWriter, r *http.Request) {
+		s.handleArbitrageStream(w, r, feed)
+	})
+}
+
+func (s *Server) handleArbitrage(w http.ResponseWriter, r *http.Request, feed service.ArbitrageFeed) {
+	chainID, code, err := validate.ArbitrageRequestValidate(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	opps := feed.Opportunities(chainID)
+	resp := dto.ArbitrageResponse{Opportunities: make([]dto.ArbitrageOpportunity, 0, len(opps))}
+	for _, opp := range opps {
+		resp.Opportunities = append(resp.Opportunities, toArbitrageOpportunity(opp))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("arbitrage write error: %v", err)
+	}
+}
+
+// handleArbitrageStream sends every new opportunity as a server-sent event
+// until the client disconnects.
+func (s *Server) handleArbitrageStream(w http.ResponseWriter, r *http.Request, feed service.ArbitrageFeed) {
+	chainID, code, err := validate.ArbitrageRequestValidate(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	opps, unsubscribe := feed.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case opp, ok := <-opps:
+			if !ok {
+				return
+			}
+			if chainID != 0 && opp.ChainID != chainID {
+				continue
+			}
+
+			data, err := json.Marshal(toArbitrageOpportunity(opp))
+			if err != nil {
+				log.Printf("arbitrage stream marshal error: %v", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				log.Printf("arbitrage stream write error: %v", err)
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func toArbitrageOpportunity(opp servicedto.ArbitrageOpportunity) dto.ArbitrageOpportunity {
+	out := dto.ArbitrageOpportunity{
+		ChainID:     opp.ChainID,
+		BlockNumber: opp.BlockNumber,
+		Tokens:      make([]string, 0, len(opp.Tokens)),
+		Pools:       make([]string, 0, len(opp.Pools)),
+		AmountIn:    opp.AmountIn.String(),
+		AmountOut:   opp.AmountOut.String(),
+		Profit:      opp.Profit.String(),
+		ProfitBps:   opp.ProfitBps,
+	}
+	for _, token := range opp.Tokens {
+		out.Tokens = append(out.Tokens, token.Hex())
+	}
+	for _, pool := range opp.Pools {
+		out.Pools = append(out.Pools, pool.Hex())
+	}
+	return out
+}
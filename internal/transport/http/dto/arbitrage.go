@@ -0,0 +1,19 @@
+package dto
+
+// ArbitrageResponse represents the JSON body of the /arbitrage response.
+type ArbitrageResponse struct {
+	Opportunities []ArbitrageOpportunity `json:"opportunities"`
+}
+
+// ArbitrageOpportunity is a profitable cycle of swaps, also sent as a single
+// event of /arbitrage/stream.
+type ArbitrageOpportunity struct {
+	ChainID     uint64   `json:"chain_id"`
+	BlockNumber uint64   `json:"block_number"`
+	Tokens      []string `json:"tokens"`
+	Pools       []string `json:"pools"`
+	AmountIn    string   `json:"amount_in"`
+	AmountOut   string   `json:"amount_out"`
+	Profit      string   `json:"profit"`
+	ProfitBps   uint32   `json:"profit_bps"`
+}
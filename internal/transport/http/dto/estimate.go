@@ -2,14 +2,94 @@ package dto
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 )
 
 // EstimateRequest represents a parsed HTTP request for the /estimate endpoint.
 type EstimateRequest struct {
-	Pool      common.Address
-	Src       common.Address
-	Dst       common.Address
-	SrcAmount *big.Int
+	ChainID    uint64
+	Chain      string
+	Pool       common.Address
+	Src        common.Address
+	Dst        common.Address
+	SrcAmount  *big.Int
+	CrossCheck bool
+	// Snapshot is set for what-if estimates against caller-supplied reserves.
+	Snapshot    *PoolSnapshot
+	SlippageBps uint32
+	MaxPoolAge  time.Duration
+	// PoolType is passed on unchecked; the service knows the pool types.
+	PoolType string
+}
+
+// PoolSnapshot is the parsed pool state of a what-if estimate.
+type PoolSnapshot struct {
+	Token0   common.Address
+	Token1   common.Address
+	Reserve0 *big.Int
+	Reserve1 *big.Int
+}
+
+// PoolSnapshotJSON is the JSON form of the snapshot query parameter.
+type PoolSnapshotJSON struct {
+	Token0   string `json:"token0,omitempty"`
+	Token1   string `json:"token1,omitempty"`
+	Reserve0 string `json:"reserve0"`
+	Reserve1 string `json:"reserve1"`
+}
+
+// EstimateResponse represents the JSON body of the /estimate endpoint,
+// returned when the client accepts application/json.
+type EstimateResponse struct {
+	DstAmount    string `json:"dst_amount"`
+	PoolVerified bool   `json:"pool_verified"`
+	Factory      string `json:"factory,omitempty"`
+	PoolType     string `json:"pool_type,omitempty"`
+	Taxed        bool   `json:"taxed"`
+	Rebasing     bool   `json:"rebasing"`
+
+	CrossCheck   *CrossCheck  `json:"cross_check,omitempty"`
+	Hypothetical bool         `json:"hypothetical,omitempty"`
+	MEVExposure  *MEVExposure `json:"mev_exposure,omitempty"`
+
+	// PoolUpdatedAt is the unix timestamp of the pool's last reserve update and
+	// PoolAgeSeconds the time since; both are left out of hypothetical estimates.
+	PoolUpdatedAt  int64 `json:"pool_updated_at,omitempty"`
+	PoolAgeSeconds int64 `json:"pool_age_seconds,omitempty"`
+}
+
+// MEVExposure is the most profitable sandwich of the quoted swap.
+type MEVExposure struct {
+	SlippageBps    uint32 `json:"slippage_bps"`
+	FrontRun       string `json:"front_run_amount"`
+	AttackerProfit string `json:"attacker_profit"`
+	VictimLoss     string `json:"victim_loss"`
+}
+
+// CrossCheck is the on-chain verification of a quote against the router.
+type CrossCheck struct {
+	BlockNumber   uint64 `json:"block_number,omitempty"`
+	Router        string `json:"router,omitempty"`
+	OnChainAmount string `json:"on_chain_amount,omitempty"`
+	Match         bool   `json:"match"`
+	Error         string `json:"error,omitempty"`
+}
+
+// SimulateRequest represents a parsed HTTP request for the /simulate endpoint.
+type SimulateRequest struct {
+	EstimateRequest
+
+	Sender    common.Address
+	Recipient common.Address
+}
+
+// SimulateResponse represents the JSON body of the /simulate endpoint.
+type SimulateResponse struct {
+	Success         bool   `json:"success"`
+	DstAmount       string `json:"dst_amount,omitempty"`
+	QuotedDstAmount string `json:"quoted_dst_amount"`
+	Router          string `json:"router"`
+	RevertReason    string `json:"revert_reason,omitempty"`
 }
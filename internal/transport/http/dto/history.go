@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EstimateHistoryRequest represents a parsed HTTP request for the /estimate/history endpoint.
+type EstimateHistoryRequest struct {
+	PairRequest
+	Src       common.Address
+	Dst       common.Address
+	SrcAmount *big.Int
+	FromBlock uint64
+	ToBlock   uint64
+	Step      uint64
+}
+
+// HistoricalEstimate represents a line of the /estimate/history NDJSON body.
+type HistoricalEstimate struct {
+	BlockNumber       uint64 `json:"block_number"`
+	ReservesTimestamp uint64 `json:"reserves_timestamp,omitempty"`
+	DstAmount         string `json:"dst_amount,omitempty"`
+	Price             string `json:"price,omitempty"`
+	SpotPrice         string `json:"spot_price,omitempty"`
+	Error             string `json:"error,omitempty"`
+}
+
+// StreamError is the last line of an NDJSON body cut short by an error.
+type StreamError struct {
+	Error string `json:"error"`
+}
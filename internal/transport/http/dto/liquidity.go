@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PairRequest is the pair selected by a /liquidity or /twap request.
+type PairRequest struct {
+	ChainID uint64
+	Chain   string
+	Pool    common.Address
+}
+
+// AddLiquidityRequest represents a parsed HTTP request for the /liquidity/add endpoint.
+type AddLiquidityRequest struct {
+	PairRequest
+	Amount0 *big.Int
+	Amount1 *big.Int
+}
+
+// RemoveLiquidityRequest represents a parsed HTTP request for the /liquidity/remove endpoint.
+type RemoveLiquidityRequest struct {
+	PairRequest
+	Liquidity *big.Int
+}
+
+// ZapRequest represents a parsed HTTP request for the /liquidity/zap endpoint.
+type ZapRequest struct {
+	PairRequest
+	Src       common.Address
+	SrcAmount *big.Int
+}
+
+// PairLiquidity is the JSON body of the /liquidity/pair response, and the pair
+// state the other /liquidity responses are computed at.
+type PairLiquidity struct {
+	BlockNumber uint64 `json:"block_number"`
+	Token0      string `json:"token0"`
+	Token1      string `json:"token1"`
+	Reserve0    string `json:"reserve0"`
+	Reserve1    string `json:"reserve1"`
+	TotalSupply string `json:"total_supply"`
+	KLast       string `json:"k_last"`
+	FeeTo       string `json:"fee_to"`
+	ProtocolFee string `json:"protocol_fee"`
+}
+
+// AddLiquidityResponse represents the JSON body of the /liquidity/add response.
+type AddLiquidityResponse struct {
+	Pair      PairLiquidity `json:"pair"`
+	Liquidity string        `json:"liquidity"`
+}
+
+// RemoveLiquidityResponse represents the JSON body of the /liquidity/remove response.
+type RemoveLiquidityResponse struct {
+	Pair          PairLiquidity `json:"pair"`
+	Amount0       string        `json:"amount0"`
+	Amount1       string        `json:"amount1"`
+	ValueInToken0 string        `json:"value_in_token0"`
+	ValueInToken1 string        `json:"value_in_token1"`
+}
+
+// ZapResponse represents the JSON body of the /liquidity/zap response.
+type ZapResponse struct {
+	Pair       PairLiquidity `json:"pair"`
+	SwapAmount string        `json:"swap_amount"`
+	SwapOut    string        `json:"swap_out"`
+	Amount0    string        `json:"amount0"`
+	Amount1    string        `json:"amount1"`
+	Liquidity  string        `json:"liquidity"`
+}
@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SequenceRequestJSON is the JSON body of the /sequence endpoint.
+type SequenceRequestJSON struct {
+	ChainID uint64             `json:"chain_id,omitempty"`
+	Chain   string             `json:"chain,omitempty"`
+	Swaps   []SequenceSwapJSON `json:"swaps"`
+}
+
+// SequenceSwapJSON is a single swap of the /sequence body.
+type SequenceSwapJSON struct {
+	Pool      string `json:"pool"`
+	Src       string `json:"src"`
+	Dst       string `json:"dst"`
+	SrcAmount string `json:"src_amount"`
+}
+
+// SequenceRequest represents a parsed HTTP request for the /sequence endpoint.
+type SequenceRequest struct {
+	ChainID uint64
+	Chain   string
+	Swaps   []SequenceSwap
+}
+
+// SequenceSwap is a parsed swap of the /sequence body.
+type SequenceSwap struct {
+	Pool      common.Address
+	Src       common.Address
+	Dst       common.Address
+	SrcAmount *big.Int
+}
+
+// SequenceResponse represents the JSON body of the /sequence response.
+type SequenceResponse struct {
+	BlockNumber uint64         `json:"block_number"`
+	Steps       []SequenceStep `json:"steps"`
+	Pools       []PoolReserves `json:"pools"`
+}
+
+// SequenceStep is the output of a single swap of a sequence.
+type SequenceStep struct {
+	Pool      string `json:"pool"`
+	DstAmount string `json:"dst_amount"`
+}
+
+// PoolReserves are the final reserves of a pool.
+type PoolReserves struct {
+	Pool     string `json:"pool"`
+	Reserve0 string `json:"reserve0"`
+	Reserve1 string `json:"reserve1"`
+}
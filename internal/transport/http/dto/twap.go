@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+// TWAPRequest represents a parsed HTTP request for the /twap endpoint.
+type TWAPRequest struct {
+	PairRequest
+	Window time.Duration
+}
+
+// TWAPResponse represents the JSON body of the /twap response. Prices are in
+// raw token units, as decimals and as the UQ112x112 numbers the pair accumulates.
+type TWAPResponse struct {
+	Token0         string `json:"token0"`
+	Token1         string `json:"token1"`
+	StartBlock     uint64 `json:"start_block"`
+	EndBlock       uint64 `json:"end_block"`
+	StartTimestamp uint64 `json:"start_timestamp"`
+	EndTimestamp   uint64 `json:"end_timestamp"`
+	Price0         string `json:"price0"`
+	Price1         string `json:"price1"`
+	Price0X112     string `json:"price0_x112"`
+	Price1X112     string `json:"price1_x112"`
+	SpotPrice0     string `json:"spot_price0"`
+}
@@ -2,12 +2,18 @@ package http
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"log"
 	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/fleshka4/1inch-test-task/internal/apperrors"
-	"github.com/fleshka4/1inch-test-task/internal/service/dto"
+	servicedto "github.com/fleshka4/1inch-test-task/internal/service/dto"
+	"github.com/fleshka4/1inch-test-task/internal/transport/http/dto"
 	"github.com/fleshka4/1inch-test-task/internal/transport/http/validate"
 )
 
@@ -21,27 +27,116 @@ func (s *Server) handleEstimate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), s.requestTimeout)
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(s.requestTimeout.Load()))
 	defer cancel()
 
-	out, err := s.est.Estimate(ctx, dto.EstimateRequest{
-		Pool:      req.Pool,
-		Src:       req.Src,
-		Dst:       req.Dst,
-		SrcAmount: req.SrcAmount,
+	out, err := s.est.Estimate(ctx, servicedto.EstimateRequest{
+		ChainID:     req.ChainID,
+		Chain:       req.Chain,
+		Pool:        req.Pool,
+		Src:         req.Src,
+		Dst:         req.Dst,
+		SrcAmount:   req.SrcAmount,
+		CrossCheck:  req.CrossCheck,
+		Snapshot:    toSnapshot(req.Snapshot),
+		SlippageBps: req.SlippageBps,
+		MaxPoolAge:  req.MaxPoolAge,
+		PoolType:    req.PoolType,
 	})
 	if err != nil {
-		switch {
-		case errors.Is(err, apperrors.ErrInsufficientLiquidity), errors.Is(err, apperrors.ErrInvalidArgument):
-			http.Error(w, err.Error(), http.StatusBadRequest)
-		default:
-			http.Error(w, "internal error", http.StatusInternalServerError)
+		writeServiceError(w, err)
+		return
+	}
+
+	if out.Hypothetical {
+		w.Header().Set("X-Estimate-Hypothetical", "true")
+	}
+
+	if !acceptsJSON(r) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if _, err := w.Write([]byte(out.DstAmount.String())); err != nil {
+			log.Printf("estimate write error: %v", err)
 		}
 		return
 	}
 
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-	if _, err := w.Write([]byte(out.String())); err != nil {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toEstimateResponse(out)); err != nil {
 		log.Printf("estimate write error: %v", err)
 	}
 }
+
+func toEstimateResponse(out *servicedto.EstimateResponse) dto.EstimateResponse {
+	resp := dto.EstimateResponse{
+		DstAmount:    out.DstAmount.String(),
+		PoolVerified: out.PoolVerified,
+		Factory:      out.Factory,
+		PoolType:     out.PoolType,
+		Taxed:        out.Taxed,
+		Rebasing:     out.Rebasing,
+		Hypothetical: out.Hypothetical,
+	}
+
+	if !out.PoolUpdatedAt.IsZero() {
+		resp.PoolUpdatedAt = out.PoolUpdatedAt.Unix()
+		resp.PoolAgeSeconds = int64(out.PoolAge / time.Second)
+	}
+
+	if cc := out.CrossCheck; cc != nil {
+		resp.CrossCheck = &dto.CrossCheck{
+			BlockNumber: cc.BlockNumber,
+			Match:       cc.Match,
+			Error:       cc.Error,
+		}
+		if cc.Router != (common.Address{}) {
+			resp.CrossCheck.Router = cc.Router.Hex()
+		}
+		if cc.OnChainAmount != nil {
+			resp.CrossCheck.OnChainAmount = cc.OnChainAmount.String()
+		}
+	}
+
+	if mev := out.MEVExposure; mev != nil {
+		resp.MEVExposure = &dto.MEVExposure{
+			SlippageBps:    mev.SlippageBps,
+			FrontRun:       mev.FrontRun.String(),
+			AttackerProfit: mev.AttackerProfit.String(),
+			VictimLoss:     mev.VictimLoss.String(),
+		}
+	}
+
+	return resp
+}
+
+func toSnapshot(s *dto.PoolSnapshot) *servicedto.PoolSnapshot {
+	if s == nil {
+		return nil
+	}
+	return &servicedto.PoolSnapshot{
+		Token0:   s.Token0,
+		Token1:   s.Token1,
+		Reserve0: s.Reserve0,
+		Reserve1: s.Reserve1,
+	}
+}
+
+// writeServiceError maps service errors to HTTP statuses. Unexpected errors
+// are not echoed to the client.
+func writeServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrInsufficientLiquidity), errors.Is(err, apperrors.ErrInvalidArgument):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, apperrors.ErrNotAllowed), errors.Is(err, apperrors.ErrUnverifiedPool):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, apperrors.ErrPriceDeviation), errors.Is(err, apperrors.ErrStalePool):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+// acceptsJSON reports whether the client asked for a JSON response.
+// Plain text stays the default for backwards compatibility.
+func acceptsJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
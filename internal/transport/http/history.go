@@ -0,0 +1,103 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	servicedto "github.com/fleshka4/1inch-test-task/internal/service/dto"
+	"github.com/fleshka4/1inch-test-task/internal/transport/http/dto"
+	"github.com/fleshka4/1inch-test-task/internal/transport/http/validate"
+)
+
+// historyPricePrecision is the number of decimals of the prices in /estimate/history responses.
+const historyPricePrecision = 18
+
+// handleEstimateHistory streams the estimates of a block range as NDJSON, a
+// line per block, flushed as they arrive. Errors before the first estimate get
+// the usual error response, later ones end the body with an error line.
+//
+// Every call of a history hits the archive node, so the whole stream is bounded
+// by the history timeout rather than the request one, and the streams served at
+// once are limited; requests past the limit are rejected as unavailable.
+func (s *Server) handleEstimateHistory(w http.ResponseWriter, r *http.Request) {
+	req, code, err := validate.EstimateHistoryRequestValidate(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	select {
+	case s.histories <- struct{}{}:
+		defer func() { <-s.histories }()
+	default:
+		http.Error(w, "too many history requests", http.StatusServiceUnavailable)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(s.historyTimeout.Load()))
+	defer cancel()
+
+	enc := json.NewEncoder(w)
+	started := false
+	err = s.est.EstimateHistory(ctx, servicedto.EstimateHistoryRequest{
+		ChainID:   req.ChainID,
+		Chain:     req.Chain,
+		Pool:      req.Pool,
+		Src:       req.Src,
+		Dst:       req.Dst,
+		SrcAmount: req.SrcAmount,
+		FromBlock: req.FromBlock,
+		ToBlock:   req.ToBlock,
+		Step:      req.Step,
+	}, func(estimate servicedto.HistoricalEstimate) error {
+		if !started {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+		if err := enc.Encode(toHistoricalEstimate(estimate)); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+	if err == nil {
+		return
+	}
+	if !started {
+		writeServiceError(w, err)
+		return
+	}
+
+	log.Printf("estimate history error: %v", err)
+	if err := enc.Encode(dto.StreamError{Error: "history interrupted"}); err != nil {
+		log.Printf("estimate history write error: %v", err)
+	}
+}
+
+func toHistoricalEstimate(estimate servicedto.HistoricalEstimate) dto.HistoricalEstimate {
+	out := dto.HistoricalEstimate{
+		BlockNumber:       estimate.BlockNumber,
+		ReservesTimestamp: estimate.ReservesTimestamp,
+		Error:             estimate.Error,
+	}
+	if estimate.DstAmount != nil {
+		out.DstAmount = estimate.DstAmount.String()
+	}
+	if estimate.Price != nil {
+		out.Price = estimate.Price.FloatString(historyPricePrecision)
+	}
+	if estimate.SpotPrice != nil {
+		out.SpotPrice = estimate.SpotPrice.FloatString(historyPricePrecision)
+	}
+	return out
+}
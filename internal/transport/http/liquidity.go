@@ -0,0 +1,147 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	servicedto "github.com/fleshka4/1inch-test-task/internal/service/dto"
+	"github.com/fleshka4/1inch-test-task/internal/transport/http/dto"
+	"github.com/fleshka4/1inch-test-task/internal/transport/http/validate"
+)
+
+func (s *Server) handlePairLiquidity(w http.ResponseWriter, r *http.Request) {
+	req, code, err := validate.PairLiquidityRequestValidate(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(s.requestTimeout.Load()))
+	defer cancel()
+
+	out, err := s.est.PairLiquidity(ctx, servicedto.PairLiquidityRequest{
+		ChainID: req.ChainID,
+		Chain:   req.Chain,
+		Pool:    req.Pool,
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeLiquidity(w, toPairLiquidity(*out))
+}
+
+func (s *Server) handleAddLiquidity(w http.ResponseWriter, r *http.Request) {
+	req, code, err := validate.AddLiquidityRequestValidate(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(s.requestTimeout.Load()))
+	defer cancel()
+
+	out, err := s.est.AddLiquidity(ctx, servicedto.AddLiquidityRequest{
+		ChainID: req.ChainID,
+		Chain:   req.Chain,
+		Pool:    req.Pool,
+		Amount0: req.Amount0,
+		Amount1: req.Amount1,
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeLiquidity(w, dto.AddLiquidityResponse{
+		Pair:      toPairLiquidity(out.Pair),
+		Liquidity: out.Liquidity.String(),
+	})
+}
+
+func (s *Server) handleRemoveLiquidity(w http.ResponseWriter, r *http.Request) {
+	req, code, err := validate.RemoveLiquidityRequestValidate(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(s.requestTimeout.Load()))
+	defer cancel()
+
+	out, err := s.est.RemoveLiquidity(ctx, servicedto.RemoveLiquidityRequest{
+		ChainID:   req.ChainID,
+		Chain:     req.Chain,
+		Pool:      req.Pool,
+		Liquidity: req.Liquidity,
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeLiquidity(w, dto.RemoveLiquidityResponse{
+		Pair:          toPairLiquidity(out.Pair),
+		Amount0:       out.Amount0.String(),
+		Amount1:       out.Amount1.String(),
+		ValueInToken0: out.ValueIn0.String(),
+		ValueInToken1: out.ValueIn1.String(),
+	})
+}
+
+func (s *Server) handleZap(w http.ResponseWriter, r *http.Request) {
+	req, code, err := validate.ZapRequestValidate(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(s.requestTimeout.Load()))
+	defer cancel()
+
+	out, err := s.est.Zap(ctx, servicedto.ZapRequest{
+		ChainID:   req.ChainID,
+		Chain:     req.Chain,
+		Pool:      req.Pool,
+		Src:       req.Src,
+		SrcAmount: req.SrcAmount,
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeLiquidity(w, dto.ZapResponse{
+		Pair:       toPairLiquidity(out.Pair),
+		SwapAmount: out.SwapAmount.String(),
+		SwapOut:    out.SwapOut.String(),
+		Amount0:    out.Amount0.String(),
+		Amount1:    out.Amount1.String(),
+		Liquidity:  out.Liquidity.String(),
+	})
+}
+
+func writeLiquidity(w http.ResponseWriter, resp any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("liquidity write error: %v", err)
+	}
+}
+
+func toPairLiquidity(p servicedto.PairLiquidity) dto.PairLiquidity {
+	return dto.PairLiquidity{
+		BlockNumber: p.BlockNumber,
+		Token0:      p.Token0.Hex(),
+		Token1:      p.Token1.Hex(),
+		Reserve0:    p.Reserve0.String(),
+		Reserve1:    p.Reserve1.String(),
+		TotalSupply: p.TotalSupply.String(),
+		KLast:       p.KLast.String(),
+		FeeTo:       p.FeeTo.Hex(),
+		ProtocolFee: p.ProtocolFee.String(),
+	}
+}
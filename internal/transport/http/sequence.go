@@ -0,0 +1,68 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	servicedto "github.com/fleshka4/1inch-test-task/internal/service/dto"
+	"github.com/fleshka4/1inch-test-task/internal/transport/http/dto"
+	"github.com/fleshka4/1inch-test-task/internal/transport/http/validate"
+)
+
+func (s *Server) handleSequence(w http.ResponseWriter, r *http.Request) {
+	req, code, err := validate.SequenceRequestValidate(w, r)
+	if err != nil {
+		if code == 0 {
+			code = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(s.requestTimeout.Load()))
+	defer cancel()
+
+	swaps := make([]servicedto.SequenceSwap, 0, len(req.Swaps))
+	for _, swap := range req.Swaps {
+		swaps = append(swaps, servicedto.SequenceSwap{
+			Pool:      swap.Pool,
+			Src:       swap.Src,
+			Dst:       swap.Dst,
+			SrcAmount: swap.SrcAmount,
+		})
+	}
+
+	out, err := s.est.Sequence(ctx, servicedto.SequenceRequest{
+		ChainID: req.ChainID,
+		Chain:   req.Chain,
+		Swaps:   swaps,
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	resp := dto.SequenceResponse{
+		BlockNumber: out.BlockNumber,
+		Steps:       make([]dto.SequenceStep, 0, len(out.Steps)),
+		Pools:       make([]dto.PoolReserves, 0, len(out.Pools)),
+	}
+	for _, step := range out.Steps {
+		resp.Steps = append(resp.Steps, dto.SequenceStep{Pool: step.Pool.Hex(), DstAmount: step.DstAmount.String()})
+	}
+	for _, pool := range out.Pools {
+		resp.Pools = append(resp.Pools, dto.PoolReserves{
+			Pool:     pool.Pool.Hex(),
+			Reserve0: pool.Reserve0.String(),
+			Reserve1: pool.Reserve1.String(),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("sequence write error: %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -15,14 +16,23 @@ import (
 	"github.com/fleshka4/1inch-test-task/internal/service"
 )
 
+// ReloadFunc reloads the configuration and returns the changed fields.
+type ReloadFunc func() ([]string, error)
+
 // Server represents the HTTP transport layer.
 type Server struct {
 	est service.Service
 	mux *http.ServeMux
 
-	graceTimeout      time.Duration
 	readHeaderTimeout time.Duration
-	requestTimeout    time.Duration
+	// histories holds a slot per /estimate/history stream being served.
+	histories chan struct{}
+
+	// Runtime-reloadable settings, replaced by ApplyConfig.
+	graceTimeout   atomic.Int64
+	requestTimeout atomic.Int64
+	historyTimeout atomic.Int64
+	adminToken     atomic.Pointer[string]
 }
 
 // NewServer creates a new HTTP server with registered routes.
@@ -35,12 +45,21 @@ func NewServer(est service.Service, cfg *config.Config) (*Server, error) {
 		est: est,
 		mux: http.NewServeMux(),
 
-		graceTimeout:      cfg.GraceTimeout,
 		readHeaderTimeout: cfg.ReadHeaderTimeout,
-		requestTimeout:    cfg.RequestTimeout,
+		histories:         make(chan struct{}, cfg.MaxHistoryRequests),
 	}
+	s.ApplyConfig(cfg)
 
 	s.mux.HandleFunc("/estimate", s.handleEstimate)
+	s.mux.HandleFunc("/estimate/history", s.handleEstimateHistory)
+	s.mux.HandleFunc("/simulate", s.handleSimulate)
+	s.mux.HandleFunc("/sequence", s.handleSequence)
+	s.mux.HandleFunc("/liquidity/pair", s.handlePairLiquidity)
+	s.mux.HandleFunc("/liquidity/add", s.handleAddLiquidity)
+	s.mux.HandleFunc("/liquidity/remove", s.handleRemoveLiquidity)
+	s.mux.HandleFunc("/liquidity/zap", s.handleZap)
+	s.mux.HandleFunc("/twap", s.handleTWAP)
+	s.mux.HandleFunc("/debug/vars", s.handleDebugVars)
 	s.mux.HandleFunc("/ping", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		if _, err := w.Write([]byte("pong")); err != nil {
@@ -51,6 +70,24 @@ func NewServer(est service.Service, cfg *config.Config) (*Server, error) {
 	return s, nil
 }
 
+// ApplyConfig activates the runtime-reloadable settings of cfg.
+func (s *Server) ApplyConfig(cfg *config.Config) {
+	s.graceTimeout.Store(int64(cfg.GraceTimeout))
+	s.requestTimeout.Store(int64(cfg.RequestTimeout))
+	s.historyTimeout.Store(int64(cfg.HistoryTimeout))
+
+	token := cfg.AdminToken
+	s.adminToken.Store(&token)
+}
+
+// EnableReload registers the POST /admin/reload endpoint calling reload.
+// The endpoint requires the configured admin token and is disabled without one.
+func (s *Server) EnableReload(reload ReloadFunc) {
+	s.mux.HandleFunc("/admin/reload", func(w http.ResponseWriter, r *http.Request) {
+		s.handleReload(w, r, reload)
+	})
+}
+
 // ListenAndServe starts the HTTP server and enables graceful shutdown.
 func (s *Server) ListenAndServe(addr string) error {
 	srv := &http.Server{
@@ -73,7 +110,7 @@ func (s *Server) ListenAndServe(addr string) error {
 	<-stop
 	log.Println("shutting down server...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), s.graceTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.graceTimeout.Load()))
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
 		return errors.Wrap(err, "srv.Shutdown")
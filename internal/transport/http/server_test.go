@@ -2,6 +2,7 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"log"
 	"math/big"
@@ -11,12 +12,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
 	"github.com/fleshka4/1inch-test-task/internal/apperrors"
 	"github.com/fleshka4/1inch-test-task/internal/config"
+	"github.com/fleshka4/1inch-test-task/internal/service/dto"
 	"github.com/fleshka4/1inch-test-task/internal/service/mock"
 )
 
@@ -79,6 +82,7 @@ func TestEstimateHandler(t *testing.T) {
 		name           string
 		method         string
 		queryParams    map[string]string
+		accept         string
 		mockSetup      func(*mock.MockService)
 		expectedStatus int
 		expectedBody   string
@@ -94,11 +98,152 @@ func TestEstimateHandler(t *testing.T) {
 			},
 			mockSetup: func(ms *mock.MockService) {
 				ms.EXPECT().Estimate(gomock.Any(), gomock.Any()).
-					Return(big.NewInt(1000000000000000000), nil)
+					Return(&dto.EstimateResponse{DstAmount: big.NewInt(1000000000000000000)}, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody:   srcAmount,
 		},
+		{
+			name:   "success - json",
+			method: http.MethodGet,
+			queryParams: map[string]string{
+				"pool":       pool,
+				"src":        src,
+				"dst":        dst,
+				"src_amount": srcAmount,
+			},
+			accept: "application/json",
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().Estimate(gomock.Any(), gomock.Any()).
+					Return(&dto.EstimateResponse{
+						DstAmount:    big.NewInt(1000000000000000000),
+						PoolVerified: true,
+						Factory:      "uniswap-v2",
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"dst_amount":"1000000000000000000","pool_verified":true,"factory":"uniswap-v2","taxed":false,"rebasing":false}` + "\n",
+		},
+		{
+			name:   "success - json with cross check",
+			method: http.MethodGet,
+			queryParams: map[string]string{
+				"pool":        pool,
+				"src":         src,
+				"dst":         dst,
+				"src_amount":  srcAmount,
+				"cross_check": "true",
+			},
+			accept: "application/json",
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().Estimate(gomock.Any(), gomock.Cond(func(req dto.EstimateRequest) bool { return req.CrossCheck })).
+					Return(&dto.EstimateResponse{
+						DstAmount: big.NewInt(1813),
+						CrossCheck: &dto.CrossCheck{
+							BlockNumber:   19000000,
+							Router:        common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D"),
+							OnChainAmount: big.NewInt(1812),
+						},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"dst_amount":"1813","pool_verified":false,"taxed":false,"rebasing":false,` +
+				`"cross_check":{"block_number":19000000,"router":"0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D",` +
+				`"on_chain_amount":"1812","match":false}}` + "\n",
+		},
+		{
+			name:   "success - hypothetical",
+			method: http.MethodGet,
+			queryParams: map[string]string{
+				"pool":       pool,
+				"src":        src,
+				"dst":        dst,
+				"src_amount": srcAmount,
+				"reserve0":   "10000",
+				"reserve1":   "20000",
+			},
+			accept: "application/json",
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().Estimate(gomock.Any(), gomock.Cond(func(req dto.EstimateRequest) bool {
+					return req.Snapshot != nil && req.Snapshot.Reserve1.Cmp(big.NewInt(20000)) == 0
+				})).Return(&dto.EstimateResponse{DstAmount: big.NewInt(1813), Hypothetical: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"dst_amount":"1813","pool_verified":false,"taxed":false,"rebasing":false,"hypothetical":true}` + "\n",
+		},
+		{
+			name:   "success - json with pool age",
+			method: http.MethodGet,
+			queryParams: map[string]string{
+				"pool":         pool,
+				"src":          src,
+				"dst":          dst,
+				"src_amount":   srcAmount,
+				"max_pool_age": "72h",
+			},
+			accept: "application/json",
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().Estimate(gomock.Any(), gomock.Cond(func(req dto.EstimateRequest) bool {
+					return req.MaxPoolAge == 72*time.Hour
+				})).Return(&dto.EstimateResponse{
+					DstAmount:     big.NewInt(1813),
+					PoolUpdatedAt: time.Unix(1700000000, 0),
+					PoolAge:       90 * time.Second,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"dst_amount":"1813","pool_verified":false,"taxed":false,"rebasing":false,` +
+				`"pool_updated_at":1700000000,"pool_age_seconds":90}` + "\n",
+		},
+		{
+			name:   "success - json with pool type",
+			method: http.MethodGet,
+			queryParams: map[string]string{
+				"pool":       pool,
+				"src":        src,
+				"dst":        dst,
+				"src_amount": srcAmount,
+			},
+			accept: "application/json",
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().Estimate(gomock.Any(), gomock.Any()).Return(&dto.EstimateResponse{
+					DstAmount:    big.NewInt(1813),
+					PoolVerified: true,
+					Factory:      "uniswap-v3",
+					PoolType:     "uniswap-v3",
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"dst_amount":"1813","pool_verified":true,"factory":"uniswap-v3","pool_type":"uniswap-v3",` +
+				`"taxed":false,"rebasing":false}` + "\n",
+		},
+		{
+			name:   "success - json with mev exposure",
+			method: http.MethodGet,
+			queryParams: map[string]string{
+				"pool":         pool,
+				"src":          src,
+				"dst":          dst,
+				"src_amount":   srcAmount,
+				"slippage_bps": "50",
+			},
+			accept: "application/json",
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().Estimate(gomock.Any(), gomock.Cond(func(req dto.EstimateRequest) bool { return req.SlippageBps == 50 })).
+					Return(&dto.EstimateResponse{
+						DstAmount: big.NewInt(1813),
+						MEVExposure: &dto.MEVExposure{
+							SlippageBps:    50,
+							FrontRun:       big.NewInt(29),
+							AttackerProfit: big.NewInt(5),
+							VictimLoss:     big.NewInt(10),
+						},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"dst_amount":"1813","pool_verified":false,"taxed":false,"rebasing":false,` +
+				`"mev_exposure":{"slippage_bps":50,"front_run_amount":"29","attacker_profit":"5","victim_loss":"10"}}` + "\n",
+		},
 		{
 			name:   "validation error - missing params",
 			method: http.MethodGet,
@@ -168,6 +313,70 @@ func TestEstimateHandler(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   "",
 		},
+		{
+			name:   "service error - price deviates from twap",
+			method: http.MethodGet,
+			queryParams: map[string]string{
+				"pool":       pool,
+				"src":        src,
+				"dst":        dst,
+				"src_amount": srcAmount,
+			},
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().Estimate(gomock.Any(), gomock.Any()).
+					Return(nil, apperrors.ErrPriceDeviation)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   "",
+		},
+		{
+			name:   "service error - stale pool",
+			method: http.MethodGet,
+			queryParams: map[string]string{
+				"pool":       pool,
+				"src":        src,
+				"dst":        dst,
+				"src_amount": srcAmount,
+			},
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().Estimate(gomock.Any(), gomock.Any()).
+					Return(nil, apperrors.ErrStalePool)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   "",
+		},
+		{
+			name:   "service error - not allowed",
+			method: http.MethodGet,
+			queryParams: map[string]string{
+				"pool":       pool,
+				"src":        src,
+				"dst":        dst,
+				"src_amount": srcAmount,
+			},
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().Estimate(gomock.Any(), gomock.Any()).
+					Return(nil, apperrors.ErrNotAllowed)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "",
+		},
+		{
+			name:   "service error - unverified pool",
+			method: http.MethodGet,
+			queryParams: map[string]string{
+				"pool":       pool,
+				"src":        src,
+				"dst":        dst,
+				"src_amount": srcAmount,
+			},
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().Estimate(gomock.Any(), gomock.Any()).
+					Return(nil, apperrors.ErrUnverifiedPool)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "",
+		},
 		{
 			name:   "service error - unknown error",
 			method: http.MethodGet,
@@ -214,6 +423,9 @@ func TestEstimateHandler(t *testing.T) {
 				}
 				req.URL.RawQuery = q.Encode()
 			}
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
 
 			w := httptest.NewRecorder()
 
@@ -236,12 +448,839 @@ func TestEstimateHandler(t *testing.T) {
 
 			if tt.expectedStatus == http.StatusOK {
 				contentType := resp.Header.Get("Content-Type")
-				require.Equal(t, "text/plain; charset=utf-8", contentType)
+				if tt.accept != "" {
+					require.Equal(t, "application/json", contentType)
+				} else {
+					require.Equal(t, "text/plain; charset=utf-8", contentType)
+				}
+			}
+		})
+	}
+}
+
+func TestSimulateHandler(t *testing.T) {
+	t.Parallel()
+
+	const query = "pool=0x1234567890123456789012345678901234567890" +
+		"&src=0x1234567890123456789012345678901234567891" +
+		"&dst=0x1234567890123456789012345678901234567892" +
+		"&src_amount=1000&sender=0x00000000000000000000000000000000000a11cE"
+
+	router := common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D")
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*mock.MockService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "success",
+			query: query,
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().Simulate(gomock.Any(), gomock.Cond(func(req dto.SimulateRequest) bool {
+					return req.Sender == common.HexToAddress("0xa11ce")
+				})).Return(&dto.SimulateResponse{
+					QuotedAmount: big.NewInt(1813),
+					Router:       router,
+					DstAmount:    big.NewInt(1800),
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"success":true,"dst_amount":"1800","quoted_dst_amount":"1813",` +
+				`"router":"0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D"}` + "\n",
+		},
+		{
+			name:  "reverted",
+			query: query,
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().Simulate(gomock.Any(), gomock.Any()).Return(&dto.SimulateResponse{
+					QuotedAmount: big.NewInt(1813),
+					Router:       router,
+					Reverted:     true,
+					RevertReason: "UniswapV2: K",
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"success":false,"quoted_dst_amount":"1813",` +
+				`"router":"0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D","revert_reason":"UniswapV2: K"}` + "\n",
+		},
+		{
+			name:           "missing sender",
+			query:          "pool=0x1234567890123456789012345678901234567890",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "service error - invalid argument",
+			query: query,
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().Simulate(gomock.Any(), gomock.Any()).Return(nil, apperrors.ErrInvalidArgument)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mock.NewMockService(ctrl)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockService)
+			}
+
+			server, err := NewServer(mockService, &config.Config{})
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			server.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/simulate?"+tt.query, nil))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+				require.Equal(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSequenceHandler(t *testing.T) {
+	t.Parallel()
+
+	const body = `{"swaps":[{"pool":"0x1234567890123456789012345678901234567890",` +
+		`"src":"0x1234567890123456789012345678901234567891",` +
+		`"dst":"0x1234567890123456789012345678901234567892","src_amount":"1000"}]}`
+
+	pool := common.HexToAddress("0x1234567890123456789012345678901234567890")
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		mockSetup      func(*mock.MockService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "success",
+			method: http.MethodPost,
+			body:   body,
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().Sequence(gomock.Any(), gomock.Cond(func(req dto.SequenceRequest) bool {
+					return len(req.Swaps) == 1 && req.Swaps[0].Pool == pool
+				})).Return(&dto.SequenceResponse{
+					BlockNumber: 19000000,
+					Steps:       []dto.SequenceStep{{Pool: pool, DstAmount: big.NewInt(1813)}},
+					Pools:       []dto.PoolReserves{{Pool: pool, Reserve0: big.NewInt(11000), Reserve1: big.NewInt(18187)}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"block_number":19000000,` +
+				`"steps":[{"pool":"0x1234567890123456789012345678901234567890","dst_amount":"1813"}],` +
+				`"pools":[{"pool":"0x1234567890123456789012345678901234567890","reserve0":"11000","reserve1":"18187"}]}` + "\n",
+		},
+		{
+			name:           "wrong http method",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:   "service error - insufficient liquidity",
+			method: http.MethodPost,
+			body:   body,
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().Sequence(gomock.Any(), gomock.Any()).Return(nil, apperrors.ErrInsufficientLiquidity)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mock.NewMockService(ctrl)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockService)
+			}
+
+			server, err := NewServer(mockService, &config.Config{})
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			server.mux.ServeHTTP(w, httptest.NewRequest(tt.method, "/sequence", bytes.NewBufferString(tt.body)))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				require.Equal(t, tt.expectedBody, w.Body.String())
 			}
 		})
 	}
 }
 
+func TestLiquidityHandlers(t *testing.T) {
+	t.Parallel()
+
+	const poolHex = "0x1234567890123456789012345678901234567890"
+	pool := common.HexToAddress(poolHex)
+	pair := dto.PairLiquidity{
+		BlockNumber: 19000000,
+		Token0:      common.HexToAddress("0x01"),
+		Token1:      common.HexToAddress("0x02"),
+		Reserve0:    big.NewInt(10000),
+		Reserve1:    big.NewInt(20000),
+		TotalSupply: big.NewInt(14142),
+		KLast:       big.NewInt(0),
+		ProtocolFee: big.NewInt(0),
+	}
+	const pairJSON = `"pair":{"block_number":19000000,` +
+		`"token0":"0x0000000000000000000000000000000000000001","token1":"0x0000000000000000000000000000000000000002",` +
+		`"reserve0":"10000","reserve1":"20000","total_supply":"14142","k_last":"0",` +
+		`"fee_to":"0x0000000000000000000000000000000000000000","protocol_fee":"0"}`
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		mockSetup      func(*mock.MockService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "pair",
+			method: http.MethodGet,
+			url:    "/liquidity/pair?pool=" + poolHex + "&chain=ethereum",
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().PairLiquidity(gomock.Any(), dto.PairLiquidityRequest{Chain: "ethereum", Pool: pool}).
+					Return(&pair, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   pairJSON[len(`"pair":`):] + "\n",
+		},
+		{
+			name:           "pair without pool",
+			method:         http.MethodGet,
+			url:            "/liquidity/pair",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "add",
+			method: http.MethodGet,
+			url:    "/liquidity/add?pool=" + poolHex + "&amount0=1000&amount1=3000&chain_id=1",
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().AddLiquidity(gomock.Any(), dto.AddLiquidityRequest{
+					ChainID: 1, Pool: pool, Amount0: big.NewInt(1000), Amount1: big.NewInt(3000),
+				}).Return(&dto.AddLiquidityResponse{Pair: pair, Liquidity: big.NewInt(1414)}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{` + pairJSON + `,"liquidity":"1414"}` + "\n",
+		},
+		{
+			name:           "add with negative amount",
+			method:         http.MethodGet,
+			url:            "/liquidity/add?pool=" + poolHex + "&amount0=-1&amount1=3000",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "remove",
+			method: http.MethodGet,
+			url:    "/liquidity/remove?pool=" + poolHex + "&liquidity=1414",
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().RemoveLiquidity(gomock.Any(), dto.RemoveLiquidityRequest{
+					Pool: pool, Liquidity: big.NewInt(1414),
+				}).Return(&dto.RemoveLiquidityResponse{
+					Pair:     pair,
+					Amount0:  big.NewInt(999),
+					Amount1:  big.NewInt(1999),
+					ValueIn0: big.NewInt(1998),
+					ValueIn1: big.NewInt(3997),
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{` + pairJSON + `,"amount0":"999","amount1":"1999",` +
+				`"value_in_token0":"1998","value_in_token1":"3997"}` + "\n",
+		},
+		{
+			name:   "remove more than supply",
+			method: http.MethodGet,
+			url:    "/liquidity/remove?pool=" + poolHex + "&liquidity=20000",
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().RemoveLiquidity(gomock.Any(), gomock.Any()).Return(nil, apperrors.ErrInvalidArgument)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "zap",
+			method: http.MethodGet,
+			url:    "/liquidity/zap?pool=" + poolHex + "&src=0x0000000000000000000000000000000000000002&src_amount=2000",
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().Zap(gomock.Any(), dto.ZapRequest{
+					Pool: pool, Src: common.HexToAddress("0x02"), SrcAmount: big.NewInt(2000),
+				}).Return(&dto.ZapResponse{
+					Pair:       pair,
+					SwapAmount: big.NewInt(977),
+					SwapOut:    big.NewInt(464),
+					Amount0:    big.NewInt(464),
+					Amount1:    big.NewInt(1023),
+					Liquidity:  big.NewInt(688),
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{` + pairJSON + `,"swap_amount":"977","swap_out":"464",` +
+				`"amount0":"464","amount1":"1023","liquidity":"688"}` + "\n",
+		},
+		{
+			name:           "zap without src",
+			method:         http.MethodGet,
+			url:            "/liquidity/zap?pool=" + poolHex + "&src_amount=2000",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "wrong http method",
+			method:         http.MethodPost,
+			url:            "/liquidity/remove?pool=" + poolHex + "&liquidity=1414",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mock.NewMockService(ctrl)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockService)
+			}
+
+			server, err := NewServer(mockService, &config.Config{})
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			server.mux.ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, nil))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				require.Equal(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestTWAPHandler(t *testing.T) {
+	t.Parallel()
+
+	const poolHex = "0x1234567890123456789012345678901234567890"
+	pool := common.HexToAddress(poolHex)
+	q112 := new(big.Int).Lsh(big.NewInt(1), 112)
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		mockSetup      func(*mock.MockService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "ok",
+			method: http.MethodGet,
+			url:    "/twap?pool=" + poolHex + "&window=30m&chain=ethereum",
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().TWAP(gomock.Any(), dto.TWAPRequest{
+					Chain: "ethereum", Pool: pool, Window: 30 * time.Minute,
+				}).Return(&dto.TWAPResponse{
+					Token0:         common.HexToAddress("0x01"),
+					Token1:         common.HexToAddress("0x02"),
+					StartBlock:     18999850,
+					EndBlock:       19000000,
+					StartTimestamp: 1700000000,
+					EndTimestamp:   1700001800,
+					Price0:         new(big.Int).Lsh(big.NewInt(2), 112),
+					Price1:         new(big.Int).Rsh(q112, 1),
+					SpotPrice0:     new(big.Int).Lsh(big.NewInt(4), 111),
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"token0":"0x0000000000000000000000000000000000000001",` +
+				`"token1":"0x0000000000000000000000000000000000000002",` +
+				`"start_block":18999850,"end_block":19000000,"start_timestamp":1700000000,"end_timestamp":1700001800,` +
+				`"price0":"2.000000000000000000","price1":"0.500000000000000000",` +
+				`"price0_x112":"10384593717069655257060992658440192","price1_x112":"2596148429267413814265248164610048",` +
+				`"spot_price0":"2.000000000000000000"}` + "\n",
+		},
+		{
+			name:           "missing window",
+			method:         http.MethodGet,
+			url:            "/twap?pool=" + poolHex,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "bad window",
+			method:         http.MethodGet,
+			url:            "/twap?pool=" + poolHex + "&window=-5m",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "window exceeds chain history",
+			method: http.MethodGet,
+			url:    "/twap?pool=" + poolHex + "&window=168h",
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().TWAP(gomock.Any(), gomock.Any()).Return(nil, apperrors.ErrInvalidArgument)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "wrong http method",
+			method:         http.MethodPost,
+			url:            "/twap?pool=" + poolHex + "&window=30m",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mock.NewMockService(ctrl)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockService)
+			}
+
+			server, err := NewServer(mockService, &config.Config{})
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			server.mux.ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, nil))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				require.Equal(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestEstimateHistoryHandler(t *testing.T) {
+	t.Parallel()
+
+	const (
+		poolHex = "0x1234567890123456789012345678901234567890"
+		srcHex  = "0x0000000000000000000000000000000000000001"
+		dstHex  = "0x0000000000000000000000000000000000000002"
+		query   = "/estimate/history?pool=" + poolHex + "&src=" + srcHex + "&dst=" + dstHex + "&src_amount=100"
+	)
+
+	wantReq := dto.EstimateHistoryRequest{
+		Pool:      common.HexToAddress(poolHex),
+		Src:       common.HexToAddress(srcHex),
+		Dst:       common.HexToAddress(dstHex),
+		SrcAmount: big.NewInt(100),
+		FromBlock: 100,
+		ToBlock:   104,
+		Step:      2,
+	}
+	estimates := []dto.HistoricalEstimate{
+		{BlockNumber: 100, Error: "reserves: missing trie node"},
+		{
+			BlockNumber: 102, ReservesTimestamp: 1700001224, DstAmount: big.NewInt(108),
+			Price: big.NewRat(108, 100), SpotPrice: big.NewRat(11, 10),
+		},
+	}
+
+	tests := []struct {
+		name      string
+		method    string
+		url       string
+		mockSetup func(*mock.MockService)
+		// busy fills the only history slot before the request.
+		busy           bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "ok",
+			method: http.MethodGet,
+			url:    query + "&from_block=100&to_block=104&step=2",
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().EstimateHistory(gomock.Any(), wantReq, gomock.Any()).DoAndReturn(
+					func(ctx context.Context, _ dto.EstimateHistoryRequest, emit func(dto.HistoricalEstimate) error) error {
+						if _, ok := ctx.Deadline(); !ok {
+							return errors.New("history without a deadline")
+						}
+						for _, e := range estimates {
+							if err := emit(e); err != nil {
+								return err
+							}
+						}
+						return nil
+					},
+				)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"block_number":100,"error":"reserves: missing trie node"}` + "\n" +
+				`{"block_number":102,"reserves_timestamp":1700001224,"dst_amount":"108",` +
+				`"price":"1.080000000000000000","spot_price":"1.100000000000000000"}` + "\n",
+		},
+		{
+			name:   "interrupted",
+			method: http.MethodGet,
+			url:    query + "&from_block=100&to_block=104&step=2",
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().EstimateHistory(gomock.Any(), wantReq, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ dto.EstimateHistoryRequest, emit func(dto.HistoricalEstimate) error) error {
+						if err := emit(estimates[0]); err != nil {
+							return err
+						}
+						return context.Canceled
+					},
+				)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"block_number":100,"error":"reserves: missing trie node"}` + "\n" +
+				`{"error":"history interrupted"}` + "\n",
+		},
+		{
+			name:   "rejected before the first estimate",
+			method: http.MethodGet,
+			url:    query + "&from_block=100&to_block=104",
+			mockSetup: func(ms *mock.MockService) {
+				ms.EXPECT().EstimateHistory(gomock.Any(), gomock.Any(), gomock.Any()).Return(apperrors.ErrInvalidArgument)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "too many history requests",
+			method:         http.MethodGet,
+			url:            query + "&from_block=100&to_block=104&step=2",
+			busy:           true,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "missing to_block",
+			method:         http.MethodGet,
+			url:            query + "&from_block=100",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "reversed range",
+			method:         http.MethodGet,
+			url:            query + "&from_block=104&to_block=100",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "zero step",
+			method:         http.MethodGet,
+			url:            query + "&from_block=100&to_block=104&step=0",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "wrong http method",
+			method:         http.MethodPost,
+			url:            query + "&from_block=100&to_block=104",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mock.NewMockService(ctrl)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockService)
+			}
+
+			server, err := NewServer(mockService, &config.Config{HistoryTimeout: time.Minute, MaxHistoryRequests: 1})
+			require.NoError(t, err)
+			if tt.busy {
+				server.histories <- struct{}{}
+			}
+
+			w := httptest.NewRecorder()
+			server.mux.ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, nil))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				require.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+				require.Equal(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestArbitrageHandler(t *testing.T) {
+	t.Parallel()
+
+	opp := dto.ArbitrageOpportunity{
+		ChainID:     1,
+		BlockNumber: 19000000,
+		Tokens:      []common.Address{common.HexToAddress("0xa"), common.HexToAddress("0xb"), common.HexToAddress("0xa")},
+		Pools:       []common.Address{common.HexToAddress("0x1001"), common.HexToAddress("0x1002")},
+		AmountIn:    big.NewInt(1000),
+		AmountOut:   big.NewInt(1100),
+		Profit:      big.NewInt(100),
+		ProfitBps:   1000,
+	}
+	const oppJSON = `{"chain_id":1,"block_number":19000000,` +
+		`"tokens":["0x000000000000000000000000000000000000000A","0x000000000000000000000000000000000000000b","0x000000000000000000000000000000000000000A"],` +
+		`"pools":["0x0000000000000000000000000000000000001001","0x0000000000000000000000000000000000001002"],` +
+		`"amount_in":"1000","amount_out":"1100","profit":"100","profit_bps":1000}`
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		mockSetup      func(*mock.MockArbitrageFeed)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "success",
+			method: http.MethodGet,
+			url:    "/arbitrage?chain_id=1",
+			mockSetup: func(mf *mock.MockArbitrageFeed) {
+				mf.EXPECT().Opportunities(uint64(1)).Return([]dto.ArbitrageOpportunity{opp})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"opportunities":[` + oppJSON + `]}` + "\n",
+		},
+		{
+			name:   "no opportunities",
+			method: http.MethodGet,
+			url:    "/arbitrage",
+			mockSetup: func(mf *mock.MockArbitrageFeed) {
+				mf.EXPECT().Opportunities(uint64(0)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"opportunities":[]}` + "\n",
+		},
+		{
+			name:           "bad chain id",
+			method:         http.MethodGet,
+			url:            "/arbitrage?chain_id=x",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "wrong http method",
+			method:         http.MethodPost,
+			url:            "/arbitrage",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:   "stream",
+			method: http.MethodGet,
+			url:    "/arbitrage/stream?chain_id=1",
+			mockSetup: func(mf *mock.MockArbitrageFeed) {
+				other := opp
+				other.ChainID = 56
+
+				ch := make(chan dto.ArbitrageOpportunity, 2)
+				ch <- other
+				ch <- opp
+				close(ch)
+				mf.EXPECT().Subscribe().Return(ch, func() {})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "data: " + oppJSON + "\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockFeed := mock.NewMockArbitrageFeed(ctrl)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockFeed)
+			}
+
+			server, err := NewServer(mock.NewMockService(ctrl), &config.Config{})
+			require.NoError(t, err)
+			server.EnableArbitrage(mockFeed)
+
+			w := httptest.NewRecorder()
+			server.mux.ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, nil))
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				require.Equal(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestReloadHandler(t *testing.T) {
+	t.Parallel()
+
+	const token = "s3cret"
+
+	tests := []struct {
+		name           string
+		method         string
+		token          string
+		adminToken     string
+		reloadErr      error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "success",
+			method:         http.MethodPost,
+			token:          token,
+			adminToken:     token,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"changes":["request_timeout: 5s -> 8s"]}` + "\n",
+		},
+		{
+			name:           "rejected reload",
+			method:         http.MethodPost,
+			token:          token,
+			adminToken:     token,
+			reloadErr:      errors.New("fields require restart"),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "wrong token",
+			method:         http.MethodPost,
+			token:          "guess",
+			adminToken:     token,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "disabled without admin token",
+			method:         http.MethodPost,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "wrong http method",
+			method:         http.MethodGet,
+			token:          token,
+			adminToken:     token,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server, err := NewServer(mock.NewMockService(ctrl), &config.Config{AdminToken: tt.adminToken})
+			require.NoError(t, err)
+
+			server.EnableReload(func() ([]string, error) {
+				if tt.reloadErr != nil {
+					return nil, tt.reloadErr
+				}
+				return []string{"request_timeout: 5s -> 8s"}, nil
+			})
+
+			req := httptest.NewRequest(tt.method, "/admin/reload", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			server.mux.ServeHTTP(w, req)
+
+			resp := w.Result()
+			defer func() {
+				if err := resp.Body.Close(); err != nil {
+					t.Logf("Body.Close: %v", err)
+				}
+			}()
+
+			require.Equal(t, tt.expectedStatus, resp.StatusCode)
+			if tt.expectedBody != "" {
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedBody, string(body))
+			}
+		})
+	}
+}
+
+func TestDebugVarsHandler(t *testing.T) {
+	t.Parallel()
+
+	const token = "s3cret"
+
+	tests := []struct {
+		name           string
+		token          string
+		adminToken     string
+		expectedStatus int
+	}{
+		{name: "admin", token: token, adminToken: token, expectedStatus: http.StatusOK},
+		{name: "without token", adminToken: token, expectedStatus: http.StatusForbidden},
+		{name: "wrong token", token: "guess", adminToken: token, expectedStatus: http.StatusForbidden},
+		{name: "disabled without admin token", expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server, err := NewServer(mock.NewMockService(ctrl), &config.Config{AdminToken: tt.adminToken})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/debug/vars", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			server.mux.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				require.Contains(t, w.Body.String(), `"cmdline"`)
+			} else {
+				require.NotContains(t, w.Body.String(), "cmdline")
+			}
+		})
+	}
+}
+
+func TestServer_ApplyConfig(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server, err := NewServer(mock.NewMockService(ctrl), &config.Config{RequestTimeout: time.Second})
+	require.NoError(t, err)
+
+	server.ApplyConfig(&config.Config{RequestTimeout: 3 * time.Second, GraceTimeout: 2 * time.Second})
+
+	require.Equal(t, int64(3*time.Second), server.requestTimeout.Load())
+	require.Equal(t, int64(2*time.Second), server.graceTimeout.Load())
+}
+
 func TestLogMiddleware(t *testing.T) {
 	t.Parallel()
 
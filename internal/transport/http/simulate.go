@@ -0,0 +1,57 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	servicedto "github.com/fleshka4/1inch-test-task/internal/service/dto"
+	"github.com/fleshka4/1inch-test-task/internal/transport/http/dto"
+	"github.com/fleshka4/1inch-test-task/internal/transport/http/validate"
+)
+
+func (s *Server) handleSimulate(w http.ResponseWriter, r *http.Request) {
+	req, code, err := validate.SimulateRequestValidate(r)
+	if err != nil {
+		if code == 0 {
+			code = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(s.requestTimeout.Load()))
+	defer cancel()
+
+	out, err := s.est.Simulate(ctx, servicedto.SimulateRequest{
+		ChainID:   req.ChainID,
+		Chain:     req.Chain,
+		Pool:      req.Pool,
+		Src:       req.Src,
+		Dst:       req.Dst,
+		SrcAmount: req.SrcAmount,
+		Sender:    req.Sender,
+		Recipient: req.Recipient,
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	resp := dto.SimulateResponse{
+		Success:         !out.Reverted,
+		QuotedDstAmount: out.QuotedAmount.String(),
+		Router:          out.Router.Hex(),
+		RevertReason:    out.RevertReason,
+	}
+	if out.DstAmount != nil {
+		resp.DstAmount = out.DstAmount.String()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("simulate write error: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/fleshka4/1inch-test-task/internal/dexmath"
+	servicedto "github.com/fleshka4/1inch-test-task/internal/service/dto"
+	"github.com/fleshka4/1inch-test-task/internal/transport/http/dto"
+	"github.com/fleshka4/1inch-test-task/internal/transport/http/validate"
+)
+
+// twapPricePrecision is the number of decimals of the prices in /twap responses.
+const twapPricePrecision = 18
+
+func (s *Server) handleTWAP(w http.ResponseWriter, r *http.Request) {
+	req, code, err := validate.TWAPRequestValidate(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(s.requestTimeout.Load()))
+	defer cancel()
+
+	out, err := s.est.TWAP(ctx, servicedto.TWAPRequest{
+		ChainID: req.ChainID,
+		Chain:   req.Chain,
+		Pool:    req.Pool,
+		Window:  req.Window,
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(dto.TWAPResponse{
+		Token0:         out.Token0.Hex(),
+		Token1:         out.Token1.Hex(),
+		StartBlock:     out.StartBlock,
+		EndBlock:       out.EndBlock,
+		StartTimestamp: out.StartTimestamp,
+		EndTimestamp:   out.EndTimestamp,
+		Price0:         dexmath.PriceRat(out.Price0).FloatString(twapPricePrecision),
+		Price1:         dexmath.PriceRat(out.Price1).FloatString(twapPricePrecision),
+		Price0X112:     out.Price0.String(),
+		Price1X112:     out.Price1.String(),
+		SpotPrice0:     dexmath.PriceRat(out.SpotPrice0).FloatString(twapPricePrecision),
+	}); err != nil {
+		log.Printf("twap write error: %v", err)
+	}
+}
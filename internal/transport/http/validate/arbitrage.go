@@ -0,0 +1,27 @@
+package validate
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// ArbitrageRequestValidate validates /arbitrage and /arbitrage/stream requests
+// and returns the requested chain id, zero for every chain.
+func ArbitrageRequestValidate(r *http.Request) (uint64, int, error) {
+	if r.Method != http.MethodGet {
+		return 0, http.StatusMethodNotAllowed, errors.Errorf("invalid http method: %s", r.Method)
+	}
+
+	v := r.URL.Query().Get("chain_id")
+	if v == "" {
+		return 0, 0, nil
+	}
+
+	id, err := strconv.ParseUint(v, 10, 64)
+	if err != nil || id == 0 {
+		return 0, http.StatusBadRequest, errors.New("bad chain_id")
+	}
+	return id, 0, nil
+}
@@ -1,8 +1,13 @@
 package validate
 
 import (
+	"encoding/json"
 	"math/big"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
@@ -34,10 +39,129 @@ func EstimateRequestValidate(r *http.Request) (*dto.EstimateRequest, int, error)
 		return nil, http.StatusBadRequest, errors.New("bad src_amount")
 	}
 
+	var chainID uint64
+	if v := q.Get("chain_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || id == 0 {
+			return nil, http.StatusBadRequest, errors.New("bad chain_id")
+		}
+		chainID = id
+	}
+
+	var crossCheck bool
+	if v := q.Get("cross_check"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, http.StatusBadRequest, errors.New("bad cross_check")
+		}
+		crossCheck = b
+	}
+
+	var slippageBps uint32
+	if v := q.Get("slippage_bps"); v != "" {
+		bps, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || bps >= 10000 {
+			return nil, http.StatusBadRequest, errors.New("bad slippage_bps")
+		}
+		slippageBps = uint32(bps)
+	}
+
+	var maxPoolAge time.Duration
+	if v := q.Get("max_pool_age"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, http.StatusBadRequest, errors.New("bad max_pool_age")
+		}
+		maxPoolAge = d
+	}
+
+	snapshot, err := parseSnapshot(q)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
 	return &dto.EstimateRequest{
-		Pool:      common.HexToAddress(p),
-		Src:       common.HexToAddress(src),
-		Dst:       common.HexToAddress(dst),
-		SrcAmount: a,
+		ChainID:     chainID,
+		Chain:       q.Get("chain"),
+		Pool:        common.HexToAddress(p),
+		Src:         common.HexToAddress(src),
+		Dst:         common.HexToAddress(dst),
+		SrcAmount:   a,
+		CrossCheck:  crossCheck,
+		Snapshot:    snapshot,
+		SlippageBps: slippageBps,
+		MaxPoolAge:  maxPoolAge,
+		PoolType:    q.Get("pool_type"),
+	}, 0, nil
+}
+
+// parseSnapshot reads the what-if pool state from either the reserve0 and
+// reserve1 parameters or the snapshot JSON parameter.
+func parseSnapshot(q url.Values) (*dto.PoolSnapshot, error) {
+	raw := dto.PoolSnapshotJSON{Reserve0: q.Get("reserve0"), Reserve1: q.Get("reserve1")}
+
+	if v := q.Get("snapshot"); v != "" {
+		if raw.Reserve0 != "" || raw.Reserve1 != "" {
+			return nil, errors.New("snapshot cannot be combined with reserve0/reserve1")
+		}
+		dec := json.NewDecoder(strings.NewReader(v))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&raw); err != nil {
+			return nil, errors.New("bad snapshot")
+		}
+	} else if raw.Reserve0 == "" && raw.Reserve1 == "" {
+		return nil, nil
+	}
+
+	r0, ok0 := new(big.Int).SetString(raw.Reserve0, 10)
+	r1, ok1 := new(big.Int).SetString(raw.Reserve1, 10)
+	if !ok0 || !ok1 || r0.Sign() < 0 || r1.Sign() < 0 {
+		return nil, errors.New("bad reserves")
+	}
+
+	snapshot := &dto.PoolSnapshot{Reserve0: r0, Reserve1: r1}
+	if raw.Token0 != "" || raw.Token1 != "" {
+		if !common.IsHexAddress(raw.Token0) || !common.IsHexAddress(raw.Token1) {
+			return nil, errors.New("bad address format")
+		}
+		snapshot.Token0 = common.HexToAddress(raw.Token0)
+		snapshot.Token1 = common.HexToAddress(raw.Token1)
+	}
+
+	return snapshot, nil
+}
+
+// SimulateRequestValidate validates /simulate request and returns dto.
+func SimulateRequestValidate(r *http.Request) (*dto.SimulateRequest, int, error) {
+	req, code, err := EstimateRequestValidate(r)
+	if err != nil {
+		return nil, code, err
+	}
+	// A swap is executed against chain state, which a snapshot cannot replace.
+	if req.Snapshot != nil {
+		return nil, http.StatusBadRequest, errors.New("snapshot is not supported by /simulate")
+	}
+
+	q := r.URL.Query()
+	sender := q.Get("sender")
+	if sender == "" {
+		return nil, http.StatusBadRequest, errors.New("missing params")
+	}
+	if !common.IsHexAddress(sender) {
+		return nil, http.StatusBadRequest, errors.New("bad address format")
+	}
+
+	var recipient common.Address
+	if v := q.Get("recipient"); v != "" {
+		if !common.IsHexAddress(v) {
+			return nil, http.StatusBadRequest, errors.New("bad address format")
+		}
+		recipient = common.HexToAddress(v)
+	}
+
+	return &dto.SimulateRequest{
+		EstimateRequest: *req,
+		Sender:          common.HexToAddress(sender),
+		Recipient:       recipient,
 	}, 0, nil
 }
@@ -4,11 +4,15 @@ import (
 	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/fleshka4/1inch-test-task/internal/transport/http/dto"
 )
 
 const (
@@ -40,6 +44,32 @@ func TestEstimateRequestValidate(t *testing.T) {
 			expectedStatus: 0,
 			wantErr:        assert.NoError,
 		},
+		{
+			name: "valid request with cross check",
+			queryParams: map[string]string{
+				"pool":        pool,
+				"src":         src,
+				"dst":         dst,
+				"src_amount":  srcAmount,
+				"cross_check": "true",
+			},
+			method:         http.MethodGet,
+			expectedStatus: 0,
+			wantErr:        assert.NoError,
+		},
+		{
+			name: "bad cross_check",
+			queryParams: map[string]string{
+				"pool":        pool,
+				"src":         src,
+				"dst":         dst,
+				"src_amount":  srcAmount,
+				"cross_check": "maybe",
+			},
+			method:         http.MethodGet,
+			expectedStatus: http.StatusBadRequest,
+			wantErr:        assert.Error,
+		},
 		{
 			name: "wrong http method",
 			queryParams: map[string]string{
@@ -168,6 +198,98 @@ func TestEstimateRequestValidate(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			wantErr:        assert.Error,
 		},
+		{
+			name: "with chain and chain_id",
+			queryParams: map[string]string{
+				"chain":      "arbitrum",
+				"chain_id":   "42161",
+				"pool":       pool,
+				"src":        src,
+				"dst":        dst,
+				"src_amount": srcAmount,
+			},
+			method:         http.MethodGet,
+			expectedStatus: 0,
+			wantErr:        assert.NoError,
+		},
+		{
+			name: "invalid chain_id",
+			queryParams: map[string]string{
+				"chain_id":   "arbitrum",
+				"pool":       pool,
+				"src":        src,
+				"dst":        dst,
+				"src_amount": srcAmount,
+			},
+			method:         http.MethodGet,
+			expectedStatus: http.StatusBadRequest,
+			wantErr:        assert.Error,
+		},
+		{
+			name: "slippage_bps at 100%",
+			queryParams: map[string]string{
+				"pool":         pool,
+				"src":          src,
+				"dst":          dst,
+				"src_amount":   srcAmount,
+				"slippage_bps": "10000",
+			},
+			method:         http.MethodGet,
+			expectedStatus: http.StatusBadRequest,
+			wantErr:        assert.Error,
+		},
+		{
+			name: "valid request with max_pool_age",
+			queryParams: map[string]string{
+				"pool":         pool,
+				"src":          src,
+				"dst":          dst,
+				"src_amount":   srcAmount,
+				"max_pool_age": "72h",
+			},
+			method:         http.MethodGet,
+			expectedStatus: 0,
+			wantErr:        assert.NoError,
+		},
+		{
+			name: "bad max_pool_age",
+			queryParams: map[string]string{
+				"pool":         pool,
+				"src":          src,
+				"dst":          dst,
+				"src_amount":   srcAmount,
+				"max_pool_age": "3 days",
+			},
+			method:         http.MethodGet,
+			expectedStatus: http.StatusBadRequest,
+			wantErr:        assert.Error,
+		},
+		{
+			name: "valid request with pool_type",
+			queryParams: map[string]string{
+				"pool":       pool,
+				"src":        src,
+				"dst":        dst,
+				"src_amount": srcAmount,
+				"pool_type":  "balancer-weighted",
+			},
+			method:         http.MethodGet,
+			expectedStatus: 0,
+			wantErr:        assert.NoError,
+		},
+		{
+			name: "zero chain_id",
+			queryParams: map[string]string{
+				"chain_id":   "0",
+				"pool":       pool,
+				"src":        src,
+				"dst":        dst,
+				"src_amount": srcAmount,
+			},
+			method:         http.MethodGet,
+			expectedStatus: http.StatusBadRequest,
+			wantErr:        assert.Error,
+		},
 		{
 			name: "very large src_amount",
 			queryParams: map[string]string{
@@ -199,6 +321,9 @@ func TestEstimateRequestValidate(t *testing.T) {
 			require.Equal(t, tt.expectedStatus, status)
 
 			if result != nil {
+				require.Equal(t, tt.queryParams["chain"], result.Chain)
+				require.Equal(t, tt.queryParams["pool_type"], result.PoolType)
+				require.Equal(t, tt.queryParams["chain_id"], chainIDString(result.ChainID))
 				require.Equal(t, common.HexToAddress(tt.queryParams["pool"]), result.Pool)
 				require.Equal(t, common.HexToAddress(tt.queryParams["src"]), result.Src)
 				require.Equal(t, common.HexToAddress(tt.queryParams["dst"]), result.Dst)
@@ -207,6 +332,12 @@ func TestEstimateRequestValidate(t *testing.T) {
 				require.True(t, ok)
 				require.Equal(t, expectedAmount, result.SrcAmount)
 				require.True(t, result.SrcAmount.Sign() > 0)
+
+				if v := tt.queryParams["max_pool_age"]; v != "" {
+					maxPoolAge, err := time.ParseDuration(v)
+					require.NoError(t, err)
+					require.Equal(t, maxPoolAge, result.MaxPoolAge)
+				}
 			}
 		})
 	}
@@ -249,3 +380,173 @@ func TestEstimateRequestValidate_EdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func chainIDString(id uint64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatUint(id, 10)
+}
+
+func TestSimulateRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	const (
+		sender    = "0x00000000000000000000000000000000000a11cE"
+		recipient = "0x0000000000000000000000000000000000000b0b"
+	)
+
+	tests := []struct {
+		name          string
+		queryParams   map[string]string
+		wantStatus    int
+		wantRecipient common.Address
+	}{
+		{
+			name:        "sender only",
+			queryParams: map[string]string{"sender": sender},
+		},
+		{
+			name:          "with recipient",
+			queryParams:   map[string]string{"sender": sender, "recipient": recipient},
+			wantRecipient: common.HexToAddress(recipient),
+		},
+		{
+			name:        "missing sender",
+			queryParams: map[string]string{},
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "bad sender",
+			queryParams: map[string]string{"sender": "alice"},
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "bad recipient",
+			queryParams: map[string]string{"sender": sender, "recipient": "bob"},
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "reserves",
+			queryParams: map[string]string{"sender": sender, "reserve0": "100", "reserve1": "200"},
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "snapshot",
+			queryParams: map[string]string{"sender": sender, "snapshot": `{"reserve0":"100","reserve1":"200"}`},
+			wantStatus:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/simulate", nil)
+			q := req.URL.Query()
+			q.Add("pool", pool)
+			q.Add("src", src)
+			q.Add("dst", dst)
+			q.Add("src_amount", srcAmount)
+			for key, value := range tt.queryParams {
+				q.Add(key, value)
+			}
+			req.URL.RawQuery = q.Encode()
+
+			result, status, err := SimulateRequestValidate(req)
+			require.Equal(t, tt.wantStatus, status)
+			if tt.wantStatus != 0 {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, common.HexToAddress(pool), result.Pool)
+			require.Equal(t, common.HexToAddress(sender), result.Sender)
+			require.Equal(t, tt.wantRecipient, result.Recipient)
+		})
+	}
+}
+
+func TestEstimateRequestValidate_Snapshot(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		queryParams map[string]string
+		want        *dto.PoolSnapshot
+		wantErr     bool
+	}{
+		{
+			name: "no snapshot",
+		},
+		{
+			name:        "reserves",
+			queryParams: map[string]string{"reserve0": "100", "reserve1": "200"},
+			want:        &dto.PoolSnapshot{Reserve0: big.NewInt(100), Reserve1: big.NewInt(200)},
+		},
+		{
+			name: "snapshot json",
+			queryParams: map[string]string{
+				"snapshot": `{"token0":"` + src + `","token1":"` + dst + `","reserve0":"100","reserve1":"200"}`,
+			},
+			want: &dto.PoolSnapshot{
+				Token0:   common.HexToAddress(src),
+				Token1:   common.HexToAddress(dst),
+				Reserve0: big.NewInt(100),
+				Reserve1: big.NewInt(200),
+			},
+		},
+		{
+			name:        "one reserve",
+			queryParams: map[string]string{"reserve0": "100"},
+			wantErr:     true,
+		},
+		{
+			name:        "negative reserve",
+			queryParams: map[string]string{"reserve0": "100", "reserve1": "-1"},
+			wantErr:     true,
+		},
+		{
+			name:        "snapshot and reserves",
+			queryParams: map[string]string{"reserve0": "100", "snapshot": `{"reserve0":"1","reserve1":"2"}`},
+			wantErr:     true,
+		},
+		{
+			name:        "unknown snapshot field",
+			queryParams: map[string]string{"snapshot": `{"reserve0":"1","reserve1":"2","fee":"30"}`},
+			wantErr:     true,
+		},
+		{
+			name:        "bad snapshot token",
+			queryParams: map[string]string{"snapshot": `{"token0":"x","token1":"y","reserve0":"1","reserve1":"2"}`},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/estimate", nil)
+			q := req.URL.Query()
+			q.Add("pool", pool)
+			q.Add("src", src)
+			q.Add("dst", dst)
+			q.Add("src_amount", srcAmount)
+			for key, value := range tt.queryParams {
+				q.Add(key, value)
+			}
+			req.URL.RawQuery = q.Encode()
+
+			result, status, err := EstimateRequestValidate(req)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Equal(t, http.StatusBadRequest, status)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, result.Snapshot)
+		})
+	}
+}
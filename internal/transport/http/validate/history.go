@@ -0,0 +1,61 @@
+package validate
+
+import (
+	"math/big"
+	"net/http"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/transport/http/dto"
+)
+
+// EstimateHistoryRequestValidate validates /estimate/history request and returns dto.
+func EstimateHistoryRequestValidate(r *http.Request) (*dto.EstimateHistoryRequest, int, error) {
+	pair, q, code, err := pairRequestValidate(r)
+	if err != nil {
+		return nil, code, err
+	}
+
+	src, dst, amt := q.Get("src"), q.Get("dst"), q.Get("src_amount")
+	from, to := q.Get("from_block"), q.Get("to_block")
+	if src == "" || dst == "" || amt == "" || from == "" || to == "" {
+		return nil, http.StatusBadRequest, errors.New("missing params")
+	}
+	if !common.IsHexAddress(src) || !common.IsHexAddress(dst) {
+		return nil, http.StatusBadRequest, errors.New("bad address format")
+	}
+
+	a, ok := new(big.Int).SetString(amt, 10)
+	if !ok || a.Sign() <= 0 {
+		return nil, http.StatusBadRequest, errors.New("bad src_amount")
+	}
+
+	fromBlock, err := strconv.ParseUint(from, 10, 64)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("bad from_block")
+	}
+	toBlock, err := strconv.ParseUint(to, 10, 64)
+	if err != nil || toBlock < fromBlock {
+		return nil, http.StatusBadRequest, errors.New("bad to_block")
+	}
+
+	step := uint64(1)
+	if v := q.Get("step"); v != "" {
+		step, err = strconv.ParseUint(v, 10, 64)
+		if err != nil || step == 0 {
+			return nil, http.StatusBadRequest, errors.New("bad step")
+		}
+	}
+
+	return &dto.EstimateHistoryRequest{
+		PairRequest: *pair,
+		Src:         common.HexToAddress(src),
+		Dst:         common.HexToAddress(dst),
+		SrcAmount:   a,
+		FromBlock:   fromBlock,
+		ToBlock:     toBlock,
+		Step:        step,
+	}, 0, nil
+}
@@ -0,0 +1,114 @@
+package validate
+
+import (
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/transport/http/dto"
+)
+
+// PairLiquidityRequestValidate validates /liquidity/pair request and returns dto.
+func PairLiquidityRequestValidate(r *http.Request) (*dto.PairRequest, int, error) {
+	pair, _, code, err := pairRequestValidate(r)
+	if err != nil {
+		return nil, code, err
+	}
+	return pair, 0, nil
+}
+
+// AddLiquidityRequestValidate validates /liquidity/add request and returns dto.
+func AddLiquidityRequestValidate(r *http.Request) (*dto.AddLiquidityRequest, int, error) {
+	pair, q, code, err := pairRequestValidate(r)
+	if err != nil {
+		return nil, code, err
+	}
+
+	a0, a1 := q.Get("amount0"), q.Get("amount1")
+	if a0 == "" || a1 == "" {
+		return nil, http.StatusBadRequest, errors.New("missing params")
+	}
+
+	amount0, ok0 := new(big.Int).SetString(a0, 10)
+	amount1, ok1 := new(big.Int).SetString(a1, 10)
+	if !ok0 || !ok1 || amount0.Sign() < 0 || amount1.Sign() < 0 {
+		return nil, http.StatusBadRequest, errors.New("bad amounts")
+	}
+
+	return &dto.AddLiquidityRequest{PairRequest: *pair, Amount0: amount0, Amount1: amount1}, 0, nil
+}
+
+// RemoveLiquidityRequestValidate validates /liquidity/remove request and returns dto.
+func RemoveLiquidityRequestValidate(r *http.Request) (*dto.RemoveLiquidityRequest, int, error) {
+	pair, q, code, err := pairRequestValidate(r)
+	if err != nil {
+		return nil, code, err
+	}
+
+	v := q.Get("liquidity")
+	if v == "" {
+		return nil, http.StatusBadRequest, errors.New("missing params")
+	}
+
+	liquidity, ok := new(big.Int).SetString(v, 10)
+	if !ok || liquidity.Sign() <= 0 {
+		return nil, http.StatusBadRequest, errors.New("bad liquidity")
+	}
+
+	return &dto.RemoveLiquidityRequest{PairRequest: *pair, Liquidity: liquidity}, 0, nil
+}
+
+// ZapRequestValidate validates /liquidity/zap request and returns dto.
+func ZapRequestValidate(r *http.Request) (*dto.ZapRequest, int, error) {
+	pair, q, code, err := pairRequestValidate(r)
+	if err != nil {
+		return nil, code, err
+	}
+
+	src, amt := q.Get("src"), q.Get("src_amount")
+	if src == "" || amt == "" {
+		return nil, http.StatusBadRequest, errors.New("missing params")
+	}
+	if !common.IsHexAddress(src) {
+		return nil, http.StatusBadRequest, errors.New("bad address format")
+	}
+
+	a, ok := new(big.Int).SetString(amt, 10)
+	if !ok || a.Sign() <= 0 {
+		return nil, http.StatusBadRequest, errors.New("bad src_amount")
+	}
+
+	return &dto.ZapRequest{PairRequest: *pair, Src: common.HexToAddress(src), SrcAmount: a}, 0, nil
+}
+
+// pairRequestValidate validates the method and the pair selection shared by
+// the /liquidity and /twap endpoints.
+func pairRequestValidate(r *http.Request) (*dto.PairRequest, url.Values, int, error) {
+	if r.Method != http.MethodGet {
+		return nil, nil, http.StatusMethodNotAllowed, errors.Errorf("invalid http method: %s", r.Method)
+	}
+
+	q := r.URL.Query()
+	p := q.Get("pool")
+	if p == "" {
+		return nil, nil, http.StatusBadRequest, errors.New("missing params")
+	}
+	if !common.IsHexAddress(p) {
+		return nil, nil, http.StatusBadRequest, errors.New("bad address format")
+	}
+
+	var chainID uint64
+	if v := q.Get("chain_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || id == 0 {
+			return nil, nil, http.StatusBadRequest, errors.New("bad chain_id")
+		}
+		chainID = id
+	}
+
+	return &dto.PairRequest{ChainID: chainID, Chain: q.Get("chain"), Pool: common.HexToAddress(p)}, q, 0, nil
+}
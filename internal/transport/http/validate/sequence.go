@@ -0,0 +1,59 @@
+package validate
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/transport/http/dto"
+)
+
+// maxSequenceBody bounds the size of a /sequence body.
+const maxSequenceBody = 64 << 10
+
+// SequenceRequestValidate validates /sequence request and returns dto.
+func SequenceRequestValidate(w http.ResponseWriter, r *http.Request) (*dto.SequenceRequest, int, error) {
+	if r.Method != http.MethodPost {
+		return nil, http.StatusMethodNotAllowed, errors.Errorf("invalid http method: %s", r.Method)
+	}
+
+	var body dto.SequenceRequestJSON
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSequenceBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&body); err != nil {
+		return nil, http.StatusBadRequest, errors.New("bad body")
+	}
+
+	req := &dto.SequenceRequest{
+		ChainID: body.ChainID,
+		Chain:   body.Chain,
+		Swaps:   make([]dto.SequenceSwap, 0, len(body.Swaps)),
+	}
+
+	for i, swap := range body.Swaps {
+		if swap.Pool == "" || swap.Src == "" || swap.Dst == "" || swap.SrcAmount == "" {
+			return nil, http.StatusBadRequest, errors.Errorf("swap %d: missing params", i)
+		}
+
+		if !common.IsHexAddress(swap.Pool) || !common.IsHexAddress(swap.Src) || !common.IsHexAddress(swap.Dst) {
+			return nil, http.StatusBadRequest, errors.Errorf("swap %d: bad address format", i)
+		}
+
+		a, ok := new(big.Int).SetString(swap.SrcAmount, 10)
+		if !ok || a.Sign() <= 0 {
+			return nil, http.StatusBadRequest, errors.Errorf("swap %d: bad src_amount", i)
+		}
+
+		req.Swaps = append(req.Swaps, dto.SequenceSwap{
+			Pool:      common.HexToAddress(swap.Pool),
+			Src:       common.HexToAddress(swap.Src),
+			Dst:       common.HexToAddress(swap.Dst),
+			SrcAmount: a,
+		})
+	}
+
+	return req, 0, nil
+}
@@ -0,0 +1,106 @@
+package validate
+
+import (
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fleshka4/1inch-test-task/internal/transport/http/dto"
+)
+
+func TestSequenceRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	swap := `{"pool":"` + pool + `","src":"` + src + `","dst":"` + dst + `","src_amount":"` + srcAmount + `"}`
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		want       *dto.SequenceRequest
+	}{
+		{
+			name:   "valid",
+			method: http.MethodPost,
+			body:   `{"chain_id":1,"swaps":[` + swap + `,` + swap + `]}`,
+			want: &dto.SequenceRequest{
+				ChainID: 1,
+				Swaps: []dto.SequenceSwap{
+					{Pool: common.HexToAddress(pool), Src: common.HexToAddress(src), Dst: common.HexToAddress(dst), SrcAmount: mustBig(srcAmount)},
+					{Pool: common.HexToAddress(pool), Src: common.HexToAddress(src), Dst: common.HexToAddress(dst), SrcAmount: mustBig(srcAmount)},
+				},
+			},
+		},
+		{
+			name:       "wrong method",
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "bad json",
+			method:     http.MethodPost,
+			body:       `{"swaps":`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown field",
+			method:     http.MethodPost,
+			body:       `{"swaps":[],"deadline":1}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing params",
+			method:     http.MethodPost,
+			body:       `{"swaps":[{"pool":"` + pool + `"}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "bad address",
+			method:     http.MethodPost,
+			body:       `{"swaps":[{"pool":"x","src":"` + src + `","dst":"` + dst + `","src_amount":"1"}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "bad amount",
+			method:     http.MethodPost,
+			body:       `{"swaps":[{"pool":"` + pool + `","src":"` + src + `","dst":"` + dst + `","src_amount":"-1"}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "too large",
+			method:     http.MethodPost,
+			body:       `{"chain":"` + strings.Repeat("x", maxSequenceBody) + `"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(tt.method, "/sequence", strings.NewReader(tt.body))
+			result, status, err := SequenceRequestValidate(httptest.NewRecorder(), req)
+			require.Equal(t, tt.wantStatus, status)
+			if tt.wantStatus != 0 {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, result)
+		})
+	}
+}
+
+func mustBig(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("bad big.Int " + s)
+	}
+	return v
+}
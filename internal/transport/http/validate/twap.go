@@ -0,0 +1,30 @@
+package validate
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/fleshka4/1inch-test-task/internal/transport/http/dto"
+)
+
+// TWAPRequestValidate validates /twap request and returns dto.
+func TWAPRequestValidate(r *http.Request) (*dto.TWAPRequest, int, error) {
+	pair, q, code, err := pairRequestValidate(r)
+	if err != nil {
+		return nil, code, err
+	}
+
+	v := q.Get("window")
+	if v == "" {
+		return nil, http.StatusBadRequest, errors.New("missing params")
+	}
+
+	window, err := time.ParseDuration(v)
+	if err != nil || window <= 0 {
+		return nil, http.StatusBadRequest, errors.New("bad window")
+	}
+
+	return &dto.TWAPRequest{PairRequest: *pair, Window: window}, 0, nil
+}
@@ -0,0 +1,64 @@
+// Package v3math reproduces the integer math of Uniswap V3 concentrated
+// liquidity pools: FullMath, TickMath, SqrtPriceMath and SwapMath with Q64.96
+// fixed point square root prices, rounding exactly as the contracts do.
+//
+// Functions return false where the contracts revert.
+package v3math
+
+import "math/big"
+
+var (
+	// Q96 is 2^96, the scale of Q64.96 square root prices.
+	Q96 = new(big.Int).Lsh(big.NewInt(1), 96)
+
+	one        = big.NewInt(1)
+	maxUint128 = new(big.Int).Sub(new(big.Int).Lsh(one, 128), one)
+	maxUint160 = new(big.Int).Sub(new(big.Int).Lsh(one, 160), one)
+	maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(one, 256), one)
+)
+
+// MulDiv returns a * b / denominator rounded down, as FullMath.mulDiv does.
+//
+// Returns false if denominator is zero or the result overflows uint256.
+func MulDiv(a, b, denominator *big.Int) (*big.Int, bool) {
+	if denominator.Sign() == 0 {
+		return new(big.Int), false
+	}
+
+	out := new(big.Int).Mul(a, b)
+	out.Quo(out, denominator)
+	if out.Cmp(maxUint256) > 0 {
+		return out, false
+	}
+	return out, true
+}
+
+// MulDivRoundingUp returns a * b / denominator rounded up, as
+// FullMath.mulDivRoundingUp does.
+//
+// Returns false if denominator is zero or the result overflows uint256.
+func MulDivRoundingUp(a, b, denominator *big.Int) (*big.Int, bool) {
+	if denominator.Sign() == 0 {
+		return new(big.Int), false
+	}
+
+	product := new(big.Int).Mul(a, b)
+	out, rem := new(big.Int).QuoRem(product, denominator, new(big.Int))
+	if rem.Sign() > 0 {
+		out.Add(out, one)
+	}
+	if out.Cmp(maxUint256) > 0 {
+		return out, false
+	}
+	return out, true
+}
+
+// divRoundingUp returns x / y rounded up, as UnsafeMath.divRoundingUp does
+// for a non-zero y.
+func divRoundingUp(x, y *big.Int) *big.Int {
+	out, rem := new(big.Int).QuoRem(x, y, new(big.Int))
+	if rem.Sign() > 0 {
+		out.Add(out, one)
+	}
+	return out
+}
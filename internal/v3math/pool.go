@@ -0,0 +1,249 @@
+package v3math
+
+import (
+	"math/big"
+	"sort"
+)
+
+// Tick is an initialized tick of a pool.
+type Tick struct {
+	Index int32
+	// LiquidityNet is the liquidity added when the price crosses the tick
+	// upwards and removed when it crosses downwards.
+	LiquidityNet *big.Int
+}
+
+// Pool is the state of a Uniswap V3 pool that a swap depends on.
+type Pool struct {
+	SqrtPriceX96 *big.Int
+	Tick         int32
+	// Liquidity is the liquidity in range at the current price.
+	Liquidity *big.Int
+	// Fee is the swap fee in pips, e.g. 3000 for 0.3%.
+	Fee         uint32
+	TickSpacing int32
+	// Ticks are the initialized ticks of the tick bitmap words from MinWord to
+	// MaxWord, sorted by index. A swap that needs another word fails.
+	Ticks   []Tick
+	MinWord int16
+	MaxWord int16
+}
+
+// SwapResult is the outcome of a swap against a Pool.
+type SwapResult struct {
+	// Amount0 and Amount1 are the pool's balance changes: positive amounts
+	// are paid in, negative ones paid out.
+	Amount0 *big.Int
+	Amount1 *big.Int
+	// Pool is the state after the swap.
+	Pool Pool
+}
+
+// CompressTick returns the tick divided by the tick spacing, rounded towards
+// negative infinity, which is its position in the tick bitmap.
+func CompressTick(tick, tickSpacing int32) int32 {
+	compressed := tick / tickSpacing
+	if tick < 0 && tick%tickSpacing != 0 {
+		compressed--
+	}
+	return compressed
+}
+
+// TickWord returns the tick bitmap word holding tick.
+func TickWord(tick, tickSpacing int32) int16 {
+	return int16(CompressTick(tick, tickSpacing) >> 8)
+}
+
+// nextInitializedTick returns the next initialized tick at or below (lte) or
+// above tick within the same bitmap word, or the word's last tick in that
+// direction, as TickBitmap.nextInitializedTickWithinOneWord does.
+//
+// Returns false if the word was not loaded.
+func (p *Pool) nextInitializedTick(tick int32, lte bool) (int32, bool, bool) {
+	compressed := CompressTick(tick, p.TickSpacing)
+	if !lte {
+		compressed++
+	}
+
+	word := int16(compressed >> 8)
+	if word < p.MinWord || word > p.MaxWord {
+		return 0, false, false
+	}
+	wordStart := int32(word) << 8
+
+	if lte {
+		// The greatest initialized tick in [wordStart, compressed].
+		i := sort.Search(len(p.Ticks), func(i int) bool {
+			return CompressTick(p.Ticks[i].Index, p.TickSpacing) > compressed
+		})
+		if i > 0 {
+			if c := CompressTick(p.Ticks[i-1].Index, p.TickSpacing); c >= wordStart {
+				return c * p.TickSpacing, true, true
+			}
+		}
+		return wordStart * p.TickSpacing, false, true
+	}
+
+	// The least initialized tick in [compressed, wordStart+255].
+	i := sort.Search(len(p.Ticks), func(i int) bool {
+		return CompressTick(p.Ticks[i].Index, p.TickSpacing) >= compressed
+	})
+	if i < len(p.Ticks) {
+		if c := CompressTick(p.Ticks[i].Index, p.TickSpacing); c <= wordStart+255 {
+			return c * p.TickSpacing, true, true
+		}
+	}
+	return (wordStart + 255) * p.TickSpacing, false, true
+}
+
+// liquidityNet returns the net liquidity of an initialized tick.
+func (p *Pool) liquidityNet(tick int32) *big.Int {
+	i := sort.Search(len(p.Ticks), func(i int) bool { return p.Ticks[i].Index >= tick })
+	if i < len(p.Ticks) && p.Ticks[i].Index == tick {
+		return p.Ticks[i].LiquidityNet
+	}
+	return new(big.Int)
+}
+
+// Swap swaps amountSpecified in the given direction until it is used up or
+// the price reaches sqrtPriceLimitX96, as UniswapV3Pool.swap does. A positive
+// amountSpecified is an exact input and a negative one an exact output. The
+// receiver is not modified.
+//
+// Returns false if the pool would revert or the swap runs past the loaded words.
+func (p Pool) Swap(zeroForOne bool, amountSpecified, sqrtPriceLimitX96 *big.Int) (*SwapResult, bool) {
+	if amountSpecified.Sign() == 0 || p.TickSpacing <= 0 {
+		return nil, false
+	}
+	if zeroForOne {
+		if sqrtPriceLimitX96.Cmp(p.SqrtPriceX96) >= 0 || sqrtPriceLimitX96.Cmp(MinSqrtRatio) <= 0 {
+			return nil, false
+		}
+	} else if sqrtPriceLimitX96.Cmp(p.SqrtPriceX96) <= 0 || sqrtPriceLimitX96.Cmp(MaxSqrtRatio) >= 0 {
+		return nil, false
+	}
+
+	exactInput := amountSpecified.Sign() > 0
+	remaining := new(big.Int).Set(amountSpecified)
+	calculated := new(big.Int)
+	state := p
+	state.SqrtPriceX96 = new(big.Int).Set(p.SqrtPriceX96)
+	state.Liquidity = new(big.Int).Set(p.Liquidity)
+
+	for remaining.Sign() != 0 && state.SqrtPriceX96.Cmp(sqrtPriceLimitX96) != 0 {
+		sqrtPriceStart := state.SqrtPriceX96
+
+		tickNext, initialized, ok := p.nextInitializedTick(state.Tick, zeroForOne)
+		if !ok {
+			return nil, false
+		}
+		tickNext = min(max(tickNext, MinTick), MaxTick)
+
+		sqrtPriceNext, _ := GetSqrtRatioAtTick(tickNext)
+		target := sqrtPriceNext
+		if (zeroForOne && sqrtPriceNext.Cmp(sqrtPriceLimitX96) < 0) ||
+			(!zeroForOne && sqrtPriceNext.Cmp(sqrtPriceLimitX96) > 0) {
+			target = sqrtPriceLimitX96
+		}
+
+		step, ok := ComputeSwapStep(state.SqrtPriceX96, target, state.Liquidity, remaining, p.Fee)
+		if !ok {
+			return nil, false
+		}
+		state.SqrtPriceX96 = step.SqrtRatioNextX96
+
+		if exactInput {
+			remaining.Sub(remaining, step.AmountIn).Sub(remaining, step.FeeAmount)
+			calculated.Sub(calculated, step.AmountOut)
+		} else {
+			remaining.Add(remaining, step.AmountOut)
+			calculated.Add(calculated, step.AmountIn).Add(calculated, step.FeeAmount)
+		}
+
+		switch {
+		case state.SqrtPriceX96.Cmp(sqrtPriceNext) == 0:
+			if initialized {
+				net := new(big.Int).Set(p.liquidityNet(tickNext))
+				if zeroForOne {
+					net.Neg(net)
+				}
+				state.Liquidity = new(big.Int).Add(state.Liquidity, net)
+				if state.Liquidity.Sign() < 0 || state.Liquidity.Cmp(maxUint128) > 0 {
+					return nil, false
+				}
+			}
+			state.Tick = tickNext
+			if zeroForOne {
+				state.Tick = tickNext - 1
+			}
+		case state.SqrtPriceX96.Cmp(sqrtPriceStart) != 0:
+			state.Tick, _ = GetTickAtSqrtRatio(state.SqrtPriceX96)
+		}
+	}
+
+	specified := new(big.Int).Sub(amountSpecified, remaining)
+	res := &SwapResult{Amount0: specified, Amount1: calculated, Pool: state}
+	if zeroForOne != exactInput {
+		res.Amount0, res.Amount1 = calculated, specified
+	}
+	return res, true
+}
+
+// priceLimit returns the most extreme price limit a swap may pass, which lets
+// it run until its amount is used up.
+func priceLimit(zeroForOne bool) *big.Int {
+	if zeroForOne {
+		return new(big.Int).Add(MinSqrtRatio, one)
+	}
+	return new(big.Int).Sub(MaxSqrtRatio, one)
+}
+
+// QuoteExactIn returns the output of selling amountIn of token0 (zeroForOne)
+// or token1 to the pool, as QuoterV2.quoteExactInputSingle does without a
+// price limit.
+//
+// Returns false if the pool cannot take the whole of amountIn.
+func (p Pool) QuoteExactIn(amountIn *big.Int, zeroForOne bool) (*big.Int, bool) {
+	if amountIn.Sign() <= 0 {
+		return new(big.Int), false
+	}
+
+	res, ok := p.Swap(zeroForOne, amountIn, priceLimit(zeroForOne))
+	if !ok {
+		return new(big.Int), false
+	}
+
+	in, out := res.Amount0, res.Amount1
+	if !zeroForOne {
+		in, out = res.Amount1, res.Amount0
+	}
+	if in.Cmp(amountIn) != 0 {
+		return new(big.Int), false
+	}
+	return new(big.Int).Neg(out), true
+}
+
+// QuoteExactOut returns the input needed to buy amountOut of token1
+// (zeroForOne) or token0 from the pool, as QuoterV2.quoteExactOutputSingle
+// does without a price limit.
+//
+// Returns false if the pool cannot pay out the whole of amountOut.
+func (p Pool) QuoteExactOut(amountOut *big.Int, zeroForOne bool) (*big.Int, bool) {
+	if amountOut.Sign() <= 0 {
+		return new(big.Int), false
+	}
+
+	res, ok := p.Swap(zeroForOne, new(big.Int).Neg(amountOut), priceLimit(zeroForOne))
+	if !ok {
+		return new(big.Int), false
+	}
+
+	in, out := res.Amount0, res.Amount1
+	if !zeroForOne {
+		in, out = res.Amount1, res.Amount0
+	}
+	if new(big.Int).Neg(out).Cmp(amountOut) != 0 {
+		return new(big.Int), false
+	}
+	return in, true
+}
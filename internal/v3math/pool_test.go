@@ -0,0 +1,110 @@
+package v3math
+
+import (
+	"math/big"
+	"testing"
+)
+
+// testPool has a position over [-600, 600] and a larger one over [120, 1200],
+// with the price at tick 0.
+func testPool() Pool {
+	return Pool{
+		SqrtPriceX96: new(big.Int).Set(Q96),
+		Tick:         0,
+		Liquidity:    bi("1000000000000000000"),
+		Fee:          3000,
+		TickSpacing:  60,
+		Ticks: []Tick{
+			{Index: -600, LiquidityNet: bi("1000000000000000000")},
+			{Index: 120, LiquidityNet: bi("2000000000000000000")},
+			{Index: 600, LiquidityNet: bi("-1000000000000000000")},
+			{Index: 1200, LiquidityNet: bi("-2000000000000000000")},
+		},
+		MinWord: -1,
+		MaxWord: 0,
+	}
+}
+
+func TestPool_SwapCrossesTick(t *testing.T) {
+	t.Parallel()
+
+	pool := testPool()
+	amountIn := bi("10000000000000000") // 0.01 token1 moves the price past tick 120.
+
+	res, ok := pool.Swap(false, amountIn, priceLimit(false))
+	if !ok {
+		t.Fatal("ok=false")
+	}
+
+	// The same swap in two steps: up to tick 120 at the initial liquidity,
+	// then the rest with the second position added.
+	sqrt120, _ := GetSqrtRatioAtTick(120)
+	first, ok := ComputeSwapStep(Q96, sqrt120, pool.Liquidity, amountIn, pool.Fee)
+	if !ok || first.SqrtRatioNextX96.Cmp(sqrt120) != 0 {
+		t.Fatal("first step does not reach tick 120")
+	}
+	rest := new(big.Int).Sub(amountIn, first.AmountIn)
+	rest.Sub(rest, first.FeeAmount)
+	second, ok := ComputeSwapStep(sqrt120, priceLimit(false), bi("3000000000000000000"), rest, pool.Fee)
+	if !ok {
+		t.Fatal("second step ok=false")
+	}
+	wantOut := new(big.Int).Add(first.AmountOut, second.AmountOut)
+
+	if res.Amount1.Cmp(amountIn) != 0 {
+		t.Fatalf("amount1 = %s, want %s", res.Amount1, amountIn)
+	}
+	if got := new(big.Int).Neg(res.Amount0); got.Cmp(wantOut) != 0 {
+		t.Fatalf("amount0 out = %s, want %s", got, wantOut)
+	}
+	if res.Pool.Liquidity.String() != "3000000000000000000" {
+		t.Fatalf("liquidity = %s, want 3e18", res.Pool.Liquidity)
+	}
+	if res.Pool.Tick < 120 || res.Pool.Tick >= 600 {
+		t.Fatalf("tick = %d, want in [120, 600)", res.Pool.Tick)
+	}
+	if pool.Liquidity.String() != "1000000000000000000" || pool.SqrtPriceX96.Cmp(Q96) != 0 {
+		t.Fatal("Swap modified the pool")
+	}
+}
+
+func TestPool_Quote(t *testing.T) {
+	t.Parallel()
+
+	pool := testPool()
+
+	for _, zeroForOne := range []bool{true, false} {
+		amountIn := bi("10000000000000000")
+
+		out, ok := pool.QuoteExactIn(amountIn, zeroForOne)
+		if !ok || out.Sign() <= 0 {
+			t.Fatalf("zeroForOne=%v: QuoteExactIn = %s, ok=%v", zeroForOne, out, ok)
+		}
+
+		// Buying the quoted output back costs at most the input, and at most
+		// a few units less as every step rounds in the pool's favour.
+		in, ok := pool.QuoteExactOut(out, zeroForOne)
+		if !ok {
+			t.Fatalf("zeroForOne=%v: QuoteExactOut ok=false", zeroForOne)
+		}
+		diff := new(big.Int).Sub(amountIn, in)
+		if diff.Sign() < 0 || diff.Cmp(big.NewInt(10)) > 0 {
+			t.Fatalf("zeroForOne=%v: QuoteExactOut = %s, want at most %s", zeroForOne, in, amountIn)
+		}
+	}
+}
+
+func TestPool_QuoteBeyondLoadedTicks(t *testing.T) {
+	t.Parallel()
+
+	pool := testPool()
+
+	// Past tick 1200 the pool has no liquidity, so the swap walks to the end
+	// of the loaded words and fails there.
+	if out, ok := pool.QuoteExactIn(bi("1000000000000000000000"), false); ok {
+		t.Fatalf("QuoteExactIn = %s, ok=true", out)
+	}
+	if in, ok := pool.QuoteExactOut(bi("1000000000000000000000"), true); ok {
+		t.Fatalf("QuoteExactOut = %s, ok=true", in)
+	}
+}
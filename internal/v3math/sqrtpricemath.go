@@ -0,0 +1,132 @@
+package v3math
+
+import "math/big"
+
+// GetNextSqrtPriceFromAmount0RoundingUp returns the square root price after
+// adding or removing amount of token0 at liquidity, rounded up, as
+// SqrtPriceMath.getNextSqrtPriceFromAmount0RoundingUp does.
+func GetNextSqrtPriceFromAmount0RoundingUp(sqrtPX96, liquidity, amount *big.Int, add bool) (*big.Int, bool) {
+	if amount.Sign() == 0 {
+		return new(big.Int).Set(sqrtPX96), true
+	}
+	numerator1 := new(big.Int).Lsh(liquidity, 96)
+	product := new(big.Int).Mul(amount, sqrtPX96)
+
+	if add {
+		// The contract takes the precise path unless amount * sqrtP or the
+		// denominator overflow uint256.
+		if product.Cmp(maxUint256) <= 0 {
+			denominator := new(big.Int).Add(numerator1, product)
+			if denominator.Cmp(maxUint256) <= 0 {
+				return MulDivRoundingUp(numerator1, sqrtPX96, denominator)
+			}
+		}
+		denominator := new(big.Int).Quo(numerator1, sqrtPX96)
+		denominator.Add(denominator, amount)
+		if denominator.Cmp(maxUint256) > 0 {
+			return new(big.Int), false
+		}
+		return divRoundingUp(numerator1, denominator), true
+	}
+
+	if product.Cmp(maxUint256) > 0 || numerator1.Cmp(product) <= 0 {
+		return new(big.Int), false
+	}
+	denominator := new(big.Int).Sub(numerator1, product)
+	out, ok := MulDivRoundingUp(numerator1, sqrtPX96, denominator)
+	if !ok || out.Cmp(maxUint160) > 0 {
+		return out, false
+	}
+	return out, true
+}
+
+// GetNextSqrtPriceFromAmount1RoundingDown returns the square root price after
+// adding or removing amount of token1 at liquidity, rounded down, as
+// SqrtPriceMath.getNextSqrtPriceFromAmount1RoundingDown does.
+func GetNextSqrtPriceFromAmount1RoundingDown(sqrtPX96, liquidity, amount *big.Int, add bool) (*big.Int, bool) {
+	shifted := new(big.Int).Lsh(amount, 96)
+
+	if add {
+		out := shifted.Quo(shifted, liquidity)
+		out.Add(out, sqrtPX96)
+		if out.Cmp(maxUint160) > 0 {
+			return out, false
+		}
+		return out, true
+	}
+
+	quotient := divRoundingUp(shifted, liquidity)
+	if sqrtPX96.Cmp(quotient) <= 0 {
+		return new(big.Int), false
+	}
+	return quotient.Sub(sqrtPX96, quotient), true
+}
+
+// GetNextSqrtPriceFromInput returns the square root price after swapping
+// amountIn of token0 (zeroForOne) or token1 in, as
+// SqrtPriceMath.getNextSqrtPriceFromInput does.
+func GetNextSqrtPriceFromInput(sqrtPX96, liquidity, amountIn *big.Int, zeroForOne bool) (*big.Int, bool) {
+	if sqrtPX96.Sign() <= 0 || liquidity.Sign() <= 0 {
+		return new(big.Int), false
+	}
+	if zeroForOne {
+		return GetNextSqrtPriceFromAmount0RoundingUp(sqrtPX96, liquidity, amountIn, true)
+	}
+	return GetNextSqrtPriceFromAmount1RoundingDown(sqrtPX96, liquidity, amountIn, true)
+}
+
+// GetNextSqrtPriceFromOutput returns the square root price after swapping
+// amountOut of token1 (zeroForOne) or token0 out, as
+// SqrtPriceMath.getNextSqrtPriceFromOutput does.
+func GetNextSqrtPriceFromOutput(sqrtPX96, liquidity, amountOut *big.Int, zeroForOne bool) (*big.Int, bool) {
+	if sqrtPX96.Sign() <= 0 || liquidity.Sign() <= 0 {
+		return new(big.Int), false
+	}
+	if zeroForOne {
+		return GetNextSqrtPriceFromAmount1RoundingDown(sqrtPX96, liquidity, amountOut, false)
+	}
+	return GetNextSqrtPriceFromAmount0RoundingUp(sqrtPX96, liquidity, amountOut, false)
+}
+
+// GetAmount0Delta returns the amount of token0 between two square root prices
+// at liquidity, liquidity * (sqrtB - sqrtA) / (sqrtA * sqrtB), as
+// SqrtPriceMath.getAmount0Delta does.
+func GetAmount0Delta(sqrtRatioAX96, sqrtRatioBX96, liquidity *big.Int, roundUp bool) (*big.Int, bool) {
+	if sqrtRatioAX96.Cmp(sqrtRatioBX96) > 0 {
+		sqrtRatioAX96, sqrtRatioBX96 = sqrtRatioBX96, sqrtRatioAX96
+	}
+	if sqrtRatioAX96.Sign() <= 0 {
+		return new(big.Int), false
+	}
+
+	numerator1 := new(big.Int).Lsh(liquidity, 96)
+	numerator2 := new(big.Int).Sub(sqrtRatioBX96, sqrtRatioAX96)
+
+	if roundUp {
+		out, ok := MulDivRoundingUp(numerator1, numerator2, sqrtRatioBX96)
+		if !ok {
+			return out, false
+		}
+		return divRoundingUp(out, sqrtRatioAX96), true
+	}
+
+	out, ok := MulDiv(numerator1, numerator2, sqrtRatioBX96)
+	if !ok {
+		return out, false
+	}
+	return out.Quo(out, sqrtRatioAX96), true
+}
+
+// GetAmount1Delta returns the amount of token1 between two square root prices
+// at liquidity, liquidity * (sqrtB - sqrtA), as SqrtPriceMath.getAmount1Delta does.
+func GetAmount1Delta(sqrtRatioAX96, sqrtRatioBX96, liquidity *big.Int, roundUp bool) (*big.Int, bool) {
+	if sqrtRatioAX96.Cmp(sqrtRatioBX96) > 0 {
+		sqrtRatioAX96, sqrtRatioBX96 = sqrtRatioBX96, sqrtRatioAX96
+	}
+
+	diff := new(big.Int).Sub(sqrtRatioBX96, sqrtRatioAX96)
+	if roundUp {
+		return MulDivRoundingUp(liquidity, diff, Q96)
+	}
+	return MulDiv(liquidity, diff, Q96)
+}
@@ -0,0 +1,129 @@
+package v3math
+
+import "math/big"
+
+// FeeDenominator is the denominator of pool fees, which are in hundredths of
+// a basis point (pips): 3000 is 0.3%.
+const FeeDenominator = 1_000_000
+
+var feeDen = big.NewInt(FeeDenominator)
+
+// SwapStep is the result of swapping within a single tick range.
+type SwapStep struct {
+	// SqrtRatioNextX96 is the square root price after the step.
+	SqrtRatioNextX96 *big.Int
+	// AmountIn is the input without the fee, and FeeAmount the fee on it.
+	AmountIn  *big.Int
+	AmountOut *big.Int
+	FeeAmount *big.Int
+}
+
+// ComputeSwapStep swaps amountRemaining between the current and the target
+// square root price at constant liquidity, as SwapMath.computeSwapStep does.
+// A positive amountRemaining is an exact input, including the fee, and a
+// negative one an exact output. The direction follows from the prices.
+func ComputeSwapStep(
+	sqrtRatioCurrentX96, sqrtRatioTargetX96, liquidity, amountRemaining *big.Int,
+	feePips uint32,
+) (*SwapStep, bool) {
+	zeroForOne := sqrtRatioCurrentX96.Cmp(sqrtRatioTargetX96) >= 0
+	exactIn := amountRemaining.Sign() >= 0
+	feeComplement := big.NewInt(FeeDenominator - int64(feePips))
+
+	var (
+		amountIn, amountOut *big.Int
+		ok                  bool
+	)
+	step := &SwapStep{}
+
+	if exactIn {
+		amountRemainingLessFee, ok := MulDiv(amountRemaining, feeComplement, feeDen)
+		if !ok {
+			return nil, false
+		}
+		if zeroForOne {
+			amountIn, ok = GetAmount0Delta(sqrtRatioTargetX96, sqrtRatioCurrentX96, liquidity, true)
+		} else {
+			amountIn, ok = GetAmount1Delta(sqrtRatioCurrentX96, sqrtRatioTargetX96, liquidity, true)
+		}
+		if !ok {
+			return nil, false
+		}
+		if amountRemainingLessFee.Cmp(amountIn) >= 0 {
+			step.SqrtRatioNextX96 = new(big.Int).Set(sqrtRatioTargetX96)
+		} else {
+			step.SqrtRatioNextX96, ok = GetNextSqrtPriceFromInput(sqrtRatioCurrentX96, liquidity, amountRemainingLessFee, zeroForOne)
+			if !ok {
+				return nil, false
+			}
+		}
+	} else {
+		if zeroForOne {
+			amountOut, ok = GetAmount1Delta(sqrtRatioTargetX96, sqrtRatioCurrentX96, liquidity, false)
+		} else {
+			amountOut, ok = GetAmount0Delta(sqrtRatioCurrentX96, sqrtRatioTargetX96, liquidity, false)
+		}
+		if !ok {
+			return nil, false
+		}
+		remainingOut := new(big.Int).Neg(amountRemaining)
+		if remainingOut.Cmp(amountOut) >= 0 {
+			step.SqrtRatioNextX96 = new(big.Int).Set(sqrtRatioTargetX96)
+		} else {
+			step.SqrtRatioNextX96, ok = GetNextSqrtPriceFromOutput(sqrtRatioCurrentX96, liquidity, remainingOut, zeroForOne)
+			if !ok {
+				return nil, false
+			}
+		}
+	}
+
+	reachedTarget := sqrtRatioTargetX96.Cmp(step.SqrtRatioNextX96) == 0
+	next := step.SqrtRatioNextX96
+
+	// The amounts of a step that stops at the target were computed above.
+	if zeroForOne {
+		if !reachedTarget || !exactIn {
+			amountIn, ok = GetAmount0Delta(next, sqrtRatioCurrentX96, liquidity, true)
+			if !ok {
+				return nil, false
+			}
+		}
+		if !reachedTarget || exactIn {
+			amountOut, ok = GetAmount1Delta(next, sqrtRatioCurrentX96, liquidity, false)
+			if !ok {
+				return nil, false
+			}
+		}
+	} else {
+		if !reachedTarget || !exactIn {
+			amountIn, ok = GetAmount1Delta(sqrtRatioCurrentX96, next, liquidity, true)
+			if !ok {
+				return nil, false
+			}
+		}
+		if !reachedTarget || exactIn {
+			amountOut, ok = GetAmount0Delta(sqrtRatioCurrentX96, next, liquidity, false)
+			if !ok {
+				return nil, false
+			}
+		}
+	}
+
+	// The output cannot exceed what an exact output swap asked for.
+	if !exactIn && amountOut.Cmp(new(big.Int).Neg(amountRemaining)) > 0 {
+		amountOut = new(big.Int).Neg(amountRemaining)
+	}
+
+	if exactIn && !reachedTarget {
+		// The whole remainder is taken: what is not swapped is the fee.
+		step.FeeAmount = new(big.Int).Sub(amountRemaining, amountIn)
+	} else {
+		step.FeeAmount, ok = MulDivRoundingUp(amountIn, big.NewInt(int64(feePips)), feeComplement)
+		if !ok {
+			return nil, false
+		}
+	}
+
+	step.AmountIn, step.AmountOut = amountIn, amountOut
+	return step, true
+}
@@ -0,0 +1,94 @@
+package v3math
+
+import (
+	"math/big"
+	"testing"
+)
+
+// encodePriceSqrt returns sqrt(reserve1 / reserve0) as a Q64.96 number.
+func encodePriceSqrt(reserve1, reserve0 int64) *big.Int {
+	x := new(big.Int).Lsh(big.NewInt(reserve1), 192)
+	x.Quo(x, big.NewInt(reserve0))
+	return x.Sqrt(x)
+}
+
+// The cases are the SwapMath.computeSwapStep tests of Uniswap v3-core.
+func TestComputeSwapStep(t *testing.T) {
+	t.Parallel()
+
+	oneEther := bi("1000000000000000000")
+
+	tests := []struct {
+		name          string
+		target        *big.Int
+		amount        *big.Int
+		wantIn        string
+		wantOut       string
+		wantFee       string
+		wantAtTarget  bool
+		wantNextBelow bool
+	}{
+		{
+			name:         "exact in capped at target",
+			target:       encodePriceSqrt(101, 100),
+			amount:       oneEther,
+			wantIn:       "9975124224178055",
+			wantOut:      "9925619580021728",
+			wantFee:      "5988667735148",
+			wantAtTarget: true,
+		},
+		{
+			name:         "exact out capped at target",
+			target:       encodePriceSqrt(101, 100),
+			amount:       new(big.Int).Neg(oneEther),
+			wantIn:       "9975124224178055",
+			wantOut:      "9925619580021728",
+			wantFee:      "5988667735148",
+			wantAtTarget: true,
+		},
+		{
+			name:          "exact in fully spent",
+			target:        encodePriceSqrt(1000, 100),
+			amount:        oneEther,
+			wantIn:        "999400000000000000",
+			wantOut:       "666399946655997866",
+			wantFee:       "600000000000000",
+			wantNextBelow: true,
+		},
+		{
+			name:          "exact out fully received",
+			target:        encodePriceSqrt(10000, 100),
+			amount:        new(big.Int).Neg(oneEther),
+			wantIn:        "2000000000000000000",
+			wantOut:       "1000000000000000000",
+			wantFee:       "1200720432259356",
+			wantNextBelow: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			step, ok := ComputeSwapStep(encodePriceSqrt(1, 1), tt.target, bi("2000000000000000000"), tt.amount, 600)
+			if !ok {
+				t.Fatal("ok=false")
+			}
+			if step.AmountIn.String() != tt.wantIn {
+				t.Fatalf("amountIn = %s, want %s", step.AmountIn, tt.wantIn)
+			}
+			if step.AmountOut.String() != tt.wantOut {
+				t.Fatalf("amountOut = %s, want %s", step.AmountOut, tt.wantOut)
+			}
+			if step.FeeAmount.String() != tt.wantFee {
+				t.Fatalf("feeAmount = %s, want %s", step.FeeAmount, tt.wantFee)
+			}
+			if got := step.SqrtRatioNextX96.Cmp(tt.target) == 0; got != tt.wantAtTarget {
+				t.Fatalf("reached target = %v, want %v", got, tt.wantAtTarget)
+			}
+			if got := step.SqrtRatioNextX96.Cmp(tt.target) < 0; got != tt.wantNextBelow {
+				t.Fatalf("next below target = %v, want %v", got, tt.wantNextBelow)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package v3math
+
+import "math/big"
+
+const (
+	// MinTick is the lowest tick, whose price 1.0001^MinTick is about 2^-128.
+	MinTick = -887272
+	// MaxTick is the highest tick, whose price is about 2^128.
+	MaxTick = -MinTick
+)
+
+var (
+	// MinSqrtRatio is the square root price of MinTick.
+	MinSqrtRatio = big.NewInt(4295128739)
+	// MaxSqrtRatio is the square root price of MaxTick.
+	MaxSqrtRatio, _ = new(big.Int).SetString("1461446703485210103287273052203988822378723970342", 10)
+
+	// tickRatios are the Q128.128 values of 1/sqrt(1.0001)^(2^i) for the bits
+	// of the absolute tick, as hardcoded in TickMath.getSqrtRatioAtTick.
+	tickRatios = hexInts(
+		"fffcb933bd6fad37aa2d162d1a594001",
+		"fff97272373d413259a46990580e213a",
+		"fff2e50f5f656932ef12357cf3c7fdcc",
+		"ffe5caca7e10e4e61c3624eaa0941cd0",
+		"ffcb9843d60f6159c9db58835c926644",
+		"ff973b41fa98c081472e6896dfb254c0",
+		"ff2ea16466c96a3843ec78b326b52861",
+		"fe5dee046a99a2a811c461f1969c3053",
+		"fcbe86c7900a88aedcffc83b479aa3a4",
+		"f987a7253ac413176f2b074cf7815e54",
+		"f3392b0822b70005940c7a398e4b70f3",
+		"e7159475a2c29b7443b29c7fa6e889d9",
+		"d097f3bdfd2022b8845ad8f792aa5825",
+		"a9f746462d870fdf8a65dc1f90e061e5",
+		"70d869a156d2a1b890bb3df62baf32f7",
+		"31be135f97d08fd981231505542fcfa6",
+		"9aa508b5b7a84e1c677de54f3e99bc9",
+		"5d6af8dedb81196699c329225ee604",
+		"2216e584f5fa1ea926041bedfe98",
+		"48a170391f7dc42444e8fa2",
+	)
+
+	q128 = new(big.Int).Lsh(one, 128)
+)
+
+func hexInts(values ...string) []*big.Int {
+	out := make([]*big.Int, len(values))
+	for i, v := range values {
+		out[i], _ = new(big.Int).SetString(v, 16)
+	}
+	return out
+}
+
+// GetSqrtRatioAtTick returns sqrt(1.0001^tick) as a Q64.96 number, as
+// TickMath.getSqrtRatioAtTick computes it, rounded up.
+//
+// Returns false if the tick is outside [MinTick, MaxTick].
+func GetSqrtRatioAtTick(tick int32) (*big.Int, bool) {
+	if tick < MinTick || tick > MaxTick {
+		return new(big.Int), false
+	}
+
+	absTick := tick
+	if absTick < 0 {
+		absTick = -absTick
+	}
+
+	ratio := new(big.Int).Set(q128)
+	if absTick&1 != 0 {
+		ratio.Set(tickRatios[0])
+	}
+	for i := 1; i < len(tickRatios); i++ {
+		if absTick&(1<<i) != 0 {
+			ratio.Mul(ratio, tickRatios[i]).Rsh(ratio, 128)
+		}
+	}
+	if tick > 0 {
+		ratio.Quo(maxUint256, ratio)
+	}
+
+	// Down from Q128.128 to Q128.96, rounding up.
+	rem := new(big.Int).And(ratio, big.NewInt(1<<32-1))
+	ratio.Rsh(ratio, 32)
+	if rem.Sign() != 0 {
+		ratio.Add(ratio, one)
+	}
+	return ratio, true
+}
+
+// GetTickAtSqrtRatio returns the greatest tick whose square root price is at
+// most sqrtPriceX96, which is what TickMath.getTickAtSqrtRatio computes.
+//
+// Returns false if sqrtPriceX96 is outside [MinSqrtRatio, MaxSqrtRatio).
+func GetTickAtSqrtRatio(sqrtPriceX96 *big.Int) (int32, bool) {
+	if sqrtPriceX96.Cmp(MinSqrtRatio) < 0 || sqrtPriceX96.Cmp(MaxSqrtRatio) >= 0 {
+		return 0, false
+	}
+
+	// The ratio is increasing in the tick, so the tick is found by bisection:
+	// lo is always at most the price and hi above it.
+	lo, hi := int32(MinTick), int32(MaxTick)
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		ratio, _ := GetSqrtRatioAtTick(mid)
+		if ratio.Cmp(sqrtPriceX96) <= 0 {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo, true
+}
@@ -0,0 +1,76 @@
+package v3math
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestGetSqrtRatioAtTick(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		tick int32
+		want *big.Int
+	}{
+		{name: "min tick", tick: MinTick, want: MinSqrtRatio},
+		{name: "max tick", tick: MaxTick, want: MaxSqrtRatio},
+		{name: "zero", tick: 0, want: Q96},
+		// sqrt(1.0001) * 2^96, rounded up.
+		{name: "one", tick: 1, want: bi("79232123823359799118286999568")},
+		{name: "minus one", tick: -1, want: bi("79224201403219477170569942574")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := GetSqrtRatioAtTick(tt.tick)
+			if !ok {
+				t.Fatal("ok=false")
+			}
+			if got.Cmp(tt.want) != 0 {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	for _, tick := range []int32{MinTick - 1, MaxTick + 1} {
+		if _, ok := GetSqrtRatioAtTick(tick); ok {
+			t.Fatalf("tick %d: ok=true", tick)
+		}
+	}
+}
+
+func TestGetTickAtSqrtRatio(t *testing.T) {
+	t.Parallel()
+
+	for _, tick := range []int32{MinTick, -200000, -60, -1, 0, 1, 60, 200000, MaxTick - 1} {
+		ratio, _ := GetSqrtRatioAtTick(tick)
+
+		got, ok := GetTickAtSqrtRatio(ratio)
+		if !ok || got != tick {
+			t.Fatalf("tick %d: got %d, ok=%v", tick, got, ok)
+		}
+
+		// Just below the ratio of a tick is the tick before it.
+		if tick > MinTick {
+			got, ok = GetTickAtSqrtRatio(new(big.Int).Sub(ratio, big.NewInt(1)))
+			if !ok || got != tick-1 {
+				t.Fatalf("below tick %d: got %d, ok=%v", tick, got, ok)
+			}
+		}
+	}
+
+	if _, ok := GetTickAtSqrtRatio(MaxSqrtRatio); ok {
+		t.Fatal("MaxSqrtRatio: ok=true")
+	}
+}
+
+func bi(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("bad big.Int " + s)
+	}
+	return v
+}
@@ -0,0 +1,95 @@
+package weightedmath
+
+import "math/big"
+
+var (
+	// One is 1 in 18 decimals fixed point.
+	One = new(big.Int).Set(one18)
+
+	two  = new(big.Int).Mul(big.NewInt(2), one18)
+	four = new(big.Int).Mul(big.NewInt(4), one18)
+
+	// maxPowRelativeError is 10^-14 in 18 decimals, the error pow is
+	// assumed to have.
+	maxPowRelativeError = big.NewInt(10000)
+)
+
+// MulDown returns a * b rounded down, as FixedPoint.mulDown does.
+func MulDown(a, b *big.Int) *big.Int {
+	out := new(big.Int).Mul(a, b)
+	return out.Quo(out, one18)
+}
+
+// MulUp returns a * b rounded up, as FixedPoint.mulUp does.
+func MulUp(a, b *big.Int) *big.Int {
+	out := new(big.Int).Mul(a, b)
+	if out.Sign() == 0 {
+		return out
+	}
+	// (a * b - 1) / 1e18 + 1.
+	out.Sub(out, big.NewInt(1))
+	out.Quo(out, one18)
+	return out.Add(out, big.NewInt(1))
+}
+
+// DivDown returns a / b rounded down, as FixedPoint.divDown does.
+//
+// Returns false if b is zero.
+func DivDown(a, b *big.Int) (*big.Int, bool) {
+	if b.Sign() == 0 {
+		return new(big.Int), false
+	}
+	out := new(big.Int).Mul(a, one18)
+	return out.Quo(out, b), true
+}
+
+// DivUp returns a / b rounded up, as FixedPoint.divUp does.
+//
+// Returns false if b is zero.
+func DivUp(a, b *big.Int) (*big.Int, bool) {
+	if b.Sign() == 0 {
+		return new(big.Int), false
+	}
+	if a.Sign() == 0 {
+		return new(big.Int), true
+	}
+	// (a * 1e18 - 1) / b + 1.
+	out := new(big.Int).Mul(a, one18)
+	out.Sub(out, big.NewInt(1))
+	out.Quo(out, b)
+	return out.Add(out, big.NewInt(1)), true
+}
+
+// PowUp returns x^y rounded up by pow's maximum relative error, as
+// FixedPoint.powUp does. With exact, exponents of 1, 2 and 4 are computed by
+// multiplication, as pools deployed since the FixedPoint optimization do.
+func PowUp(x, y *big.Int, exact bool) (*big.Int, bool) {
+	if exact {
+		switch {
+		case y.Cmp(one18) == 0:
+			return new(big.Int).Set(x), true
+		case y.Cmp(two) == 0:
+			return MulUp(x, x), true
+		case y.Cmp(four) == 0:
+			square := MulUp(x, x)
+			return MulUp(square, square), true
+		}
+	}
+
+	raw, ok := Pow(x, y)
+	if !ok {
+		return new(big.Int), false
+	}
+	// raw + raw * 1e-14 rounded up + 1.
+	maxError := MulUp(raw, maxPowRelativeError)
+	maxError.Add(maxError, big.NewInt(1))
+	return raw.Add(raw, maxError), true
+}
+
+// Complement returns 1 - x, or 0 if x exceeds 1, as FixedPoint.complement does.
+func Complement(x *big.Int) *big.Int {
+	if x.Cmp(one18) >= 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).Sub(one18, x)
+}
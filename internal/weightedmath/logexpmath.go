@@ -0,0 +1,243 @@
+// Package weightedmath reproduces the integer math of Balancer V2 weighted
+// pools: LogExpMath, FixedPoint and WeightedMath in 18 decimals fixed point,
+// rounding exactly as the contracts do.
+//
+// Functions return false where the contracts revert.
+package weightedmath
+
+import "math/big"
+
+var (
+	one18 = big.NewInt(1e18)
+	one20 = new(big.Int).Mul(big.NewInt(100), one18)
+	one36 = new(big.Int).Mul(one18, one18)
+
+	maxNaturalExponent = new(big.Int).Mul(big.NewInt(130), one18)
+	minNaturalExponent = new(big.Int).Mul(big.NewInt(-41), one18)
+
+	// ln36LowerBound and ln36UpperBound bound the bases whose logarithm is
+	// computed with 36 decimals.
+	ln36LowerBound = new(big.Int).Sub(one18, big.NewInt(1e17))
+	ln36UpperBound = new(big.Int).Add(one18, big.NewInt(1e17))
+
+	// mildExponentBound is 2^254 / 1e20, the bound of pow's exponent.
+	mildExponentBound = new(big.Int).Quo(new(big.Int).Lsh(big.NewInt(1), 254), one20)
+
+	// x0 and x1 are 2^7 and 2^6 in 18 decimals, a0 and a1 e^x0 and e^x1
+	// without decimals.
+	x0 = bi("128000000000000000000")
+	a0 = bi("38877084059945950922200000000000000000000000000000000000")
+	x1 = bi("64000000000000000000")
+	a1 = bi("6235149080811616882910000000")
+
+	// xs are 2^5 down to 2^-4 in 20 decimals, as are their exponentials as.
+	xs = []*big.Int{
+		bi("3200000000000000000000"),
+		bi("1600000000000000000000"),
+		bi("800000000000000000000"),
+		bi("400000000000000000000"),
+		bi("200000000000000000000"),
+		bi("100000000000000000000"),
+		bi("50000000000000000000"),
+		bi("25000000000000000000"),
+		bi("12500000000000000000"),
+		bi("6250000000000000000"),
+	}
+	as = []*big.Int{
+		bi("7896296018268069516100000000000000"),
+		bi("888611052050787263676000000"),
+		bi("298095798704172827474000"),
+		bi("5459815003314423907810"),
+		bi("738905609893065022723"),
+		bi("271828182845904523536"),
+		bi("164872127070012814685"),
+		bi("128402541668774148407"),
+		bi("113314845306682631683"),
+		bi("106449445891785942956"),
+	}
+)
+
+// expTerms is how many of xs exp uses; x10 and x11 are only needed by ln.
+const expTerms = 8
+
+func bi(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("weightedmath: bad constant " + s)
+	}
+	return v
+}
+
+// Pow returns x^y, both in 18 decimals, as LogExpMath.pow does.
+//
+// Returns false if x or y is out of bounds or x^y overflows the natural exponent bounds.
+func Pow(x, y *big.Int) (*big.Int, bool) {
+	if y.Sign() == 0 {
+		return new(big.Int).Set(one18), true
+	}
+	if x.Sign() == 0 {
+		return new(big.Int), true
+	}
+	if x.Sign() < 0 || x.BitLen() > 255 || y.Sign() < 0 || y.Cmp(mildExponentBound) >= 0 {
+		return new(big.Int), false
+	}
+
+	var logXTimesY *big.Int
+	if ln36LowerBound.Cmp(x) < 0 && x.Cmp(ln36UpperBound) < 0 {
+		// (ln36(x) / 1e18) * y + ((ln36(x) % 1e18) * y) / 1e18.
+		ln36 := ln36(x)
+		q, r := new(big.Int).QuoRem(ln36, one18, new(big.Int))
+		logXTimesY = q.Mul(q, y)
+		r.Mul(r, y)
+		logXTimesY.Add(logXTimesY, r.Quo(r, one18))
+	} else {
+		l, ok := ln(x)
+		if !ok {
+			return new(big.Int), false
+		}
+		logXTimesY = l.Mul(l, y)
+	}
+	logXTimesY.Quo(logXTimesY, one18)
+
+	if logXTimesY.Cmp(minNaturalExponent) < 0 || logXTimesY.Cmp(maxNaturalExponent) > 0 {
+		return new(big.Int), false
+	}
+	return Exp(logXTimesY)
+}
+
+// Exp returns e^x, both in 18 decimals, as LogExpMath.exp does.
+//
+// Returns false if x is out of the natural exponent bounds.
+func Exp(x *big.Int) (*big.Int, bool) {
+	if x.Cmp(minNaturalExponent) < 0 || x.Cmp(maxNaturalExponent) > 0 {
+		return new(big.Int), false
+	}
+
+	if x.Sign() < 0 {
+		// 1e36 / exp(-x).
+		e, ok := Exp(new(big.Int).Neg(x))
+		if !ok || e.Sign() == 0 {
+			return new(big.Int), false
+		}
+		return e.Quo(one36, e), true
+	}
+
+	x = new(big.Int).Set(x)
+	firstAN := big.NewInt(1)
+	switch {
+	case x.Cmp(x0) >= 0:
+		x.Sub(x, x0)
+		firstAN = a0
+	case x.Cmp(x1) >= 0:
+		x.Sub(x, x1)
+		firstAN = a1
+	}
+
+	// From here on in 20 decimals.
+	x.Mul(x, big.NewInt(100))
+
+	product := new(big.Int).Set(one20)
+	for i := range expTerms {
+		if x.Cmp(xs[i]) >= 0 {
+			x.Sub(x, xs[i])
+			product.Mul(product, as[i])
+			product.Quo(product, one20)
+		}
+	}
+
+	// The Taylor series of e^x to the 12th term.
+	seriesSum := new(big.Int).Set(one20)
+	term := new(big.Int).Set(x)
+	seriesSum.Add(seriesSum, term)
+	for n := int64(2); n <= 12; n++ {
+		term.Mul(term, x)
+		term.Quo(term, one20)
+		term.Quo(term, big.NewInt(n))
+		seriesSum.Add(seriesSum, term)
+	}
+
+	// product * seriesSum / 1e20 * firstAN / 100.
+	out := product.Mul(product, seriesSum)
+	out.Quo(out, one20)
+	out.Mul(out, firstAN)
+	return out.Quo(out, big.NewInt(100)), true
+}
+
+// ln returns the natural logarithm of a positive a, both in 18 decimals, as
+// LogExpMath._ln does.
+func ln(a *big.Int) (*big.Int, bool) {
+	if a.Sign() <= 0 {
+		return new(big.Int), false
+	}
+	if a.Cmp(one18) < 0 {
+		// -ln(1e36 / a).
+		l, ok := ln(new(big.Int).Quo(one36, a))
+		return l.Neg(l), ok
+	}
+
+	a = new(big.Int).Set(a)
+	sum := new(big.Int)
+	tmp := new(big.Int)
+	if a.Cmp(tmp.Mul(a0, one18)) >= 0 {
+		a.Quo(a, a0)
+		sum.Add(sum, x0)
+	}
+	if a.Cmp(tmp.Mul(a1, one18)) >= 0 {
+		a.Quo(a, a1)
+		sum.Add(sum, x1)
+	}
+
+	// From here on in 20 decimals.
+	sum.Mul(sum, big.NewInt(100))
+	a.Mul(a, big.NewInt(100))
+
+	for i := range xs {
+		if a.Cmp(as[i]) >= 0 {
+			a.Mul(a, one20)
+			a.Quo(a, as[i])
+			sum.Add(sum, xs[i])
+		}
+	}
+
+	// z = (a - 1) / (a + 1), and ln(a) = 2 * (z + z^3 / 3 + z^5 / 5 + ...)
+	// to the z^11 term.
+	z := new(big.Int).Sub(a, one20)
+	z.Mul(z, one20)
+	z.Quo(z, tmp.Add(a, one20))
+
+	seriesSum := series(z, one20, 11)
+
+	sum.Add(sum, seriesSum)
+	return sum.Quo(sum, big.NewInt(100)), true
+}
+
+// ln36 returns the natural logarithm of x, in 18 decimals, with 36 decimals,
+// as LogExpMath._ln_36 does for x close to 1.
+func ln36(x *big.Int) *big.Int {
+	x = new(big.Int).Mul(x, one18)
+
+	// z = (x - 1) / (x + 1), the series to the z^15 term.
+	z := new(big.Int).Sub(x, one36)
+	z.Mul(z, one36)
+	z.Quo(z, new(big.Int).Add(x, one36))
+
+	return series(z, one36, 15)
+}
+
+// series returns 2 * (z + z^3 / 3 + ... + z^last / last) with z in the
+// fixed point scale one, as the logarithms compute it.
+func series(z, one *big.Int, last int64) *big.Int {
+	zSquared := new(big.Int).Mul(z, z)
+	zSquared.Quo(zSquared, one)
+
+	num := new(big.Int).Set(z)
+	seriesSum := new(big.Int).Set(z)
+	tmp := new(big.Int)
+	for n := int64(3); n <= last; n += 2 {
+		num.Mul(num, zSquared)
+		num.Quo(num, one)
+		seriesSum.Add(seriesSum, tmp.Quo(num, big.NewInt(n)))
+	}
+
+	return seriesSum.Lsh(seriesSum, 1)
+}
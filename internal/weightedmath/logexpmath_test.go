@@ -0,0 +1,128 @@
+package weightedmath
+
+import (
+	"math/big"
+	"testing"
+)
+
+// The expected values are computed with LogExpMath transcribed to Python
+// integers, and agree with e^x, ln(x) and x^y to within 1e-17.
+
+func TestExp(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		x    *big.Int
+		want *big.Int
+	}{
+		{name: "one", x: bi("1000000000000000000"), want: bi("2718281828459045235")},
+		{name: "three", x: bi("3000000000000000000"), want: bi("20085536923187667740")},
+		{name: "fraction", x: bi("123456789012345678"), want: bi("1131401114526201517")},
+		{name: "negative", x: bi("-5000000000000000000"), want: bi("6737946999085467")},
+		{
+			name: "max natural exponent",
+			x:    bi("130000000000000000000"),
+			want: bi("287264955081783193326519143742863858051506000000000000000000000000000000000"),
+		},
+		{name: "min natural exponent", x: bi("-41000000000000000000"), want: bi("1")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := Exp(tt.x)
+			if !ok {
+				t.Fatal("ok=false")
+			}
+			if got.Cmp(tt.want) != 0 {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, ok := Exp(bi("130000000000000000001")); ok {
+		t.Fatal("exponent above the bound: ok=true")
+	}
+}
+
+func TestLn(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		a    *big.Int
+		want *big.Int
+	}{
+		{name: "one", a: bi("1000000000000000000"), want: new(big.Int)},
+		{name: "two", a: bi("2000000000000000000"), want: bi("693147180559945309")},
+		{name: "tenth", a: bi("100000000000000000"), want: bi("-2302585092994045683")},
+		{name: "large", a: bi("1000000000000000000000000000000"), want: bi("27631021115928548208")},
+		{name: "tiny", a: bi("12345"), want: bi("-32025525272113542434")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := ln(tt.a)
+			if !ok {
+				t.Fatal("ok=false")
+			}
+			if got.Cmp(tt.want) != 0 {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLn36(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		x    *big.Int
+		want *big.Int
+	}{
+		{x: bi("950000000000000000"), want: bi("-51293294387550533426196144149312054")},
+		{x: bi("1050000000000000000"), want: bi("48790164169432003065374404178136230")},
+	}
+
+	for _, tt := range tests {
+		if got := ln36(tt.x); got.Cmp(tt.want) != 0 {
+			t.Fatalf("ln36(%s): got %s, want %s", tt.x, got, tt.want)
+		}
+	}
+}
+
+func TestPow(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		x, y *big.Int
+		want *big.Int
+	}{
+		{name: "square root", x: bi("2000000000000000000"), y: bi("500000000000000000"), want: bi("1414213562373095047")},
+		// Bases close to 1 take the 36 decimals logarithm.
+		{name: "near one", x: bi("950000000000000000"), y: bi("4000000000000000000"), want: bi("814506250000000001")},
+		{name: "fourth root", x: bi("3000000000000000000"), y: bi("250000000000000000"), want: bi("1316074012952492459")},
+		{name: "large exponent", x: bi("999000000000000000"), y: bi("1000000000000000000000"), want: bi("367695424770964044")},
+		{name: "zero exponent", x: bi("123"), y: new(big.Int), want: bi("1000000000000000000")},
+		{name: "zero base", x: new(big.Int), y: bi("123"), want: new(big.Int)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := Pow(tt.x, tt.y)
+			if !ok {
+				t.Fatal("ok=false")
+			}
+			if got.Cmp(tt.want) != 0 {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
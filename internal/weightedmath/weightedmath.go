@@ -0,0 +1,79 @@
+package weightedmath
+
+import "math/big"
+
+// maxInRatio is the largest share of the input balance a swap may add, 30%.
+var maxInRatio = big.NewInt(3e17)
+
+// CalcOutGivenIn returns the output of swapping amountIn, all amounts upscaled
+// to 18 decimals, as WeightedMath._calcOutGivenIn does:
+//
+//	balanceOut * (1 - (balanceIn / (balanceIn + amountIn))^(weightIn / weightOut)).
+//
+// Returns false if amountIn exceeds 30% of balanceIn.
+func CalcOutGivenIn(balanceIn, weightIn, balanceOut, weightOut, amountIn *big.Int, exactPow bool) (*big.Int, bool) {
+	if amountIn.Cmp(MulDown(balanceIn, maxInRatio)) > 0 {
+		return new(big.Int), false
+	}
+
+	base, ok := DivUp(balanceIn, new(big.Int).Add(balanceIn, amountIn))
+	if !ok {
+		return new(big.Int), false
+	}
+	exponent, ok := DivDown(weightIn, weightOut)
+	if !ok {
+		return new(big.Int), false
+	}
+	power, ok := PowUp(base, exponent, exactPow)
+	if !ok {
+		return new(big.Int), false
+	}
+
+	return MulDown(balanceOut, Complement(power)), true
+}
+
+// Pool is the state of a Balancer V2 weighted pool that a swap depends on.
+type Pool struct {
+	Balances []*big.Int
+	// ScalingFactors are 10^(18 - decimals) of each token.
+	ScalingFactors []*big.Int
+	// Weights are the normalized weights, summing to 1 in 18 decimals.
+	Weights []*big.Int
+	// SwapFee is the swap fee percentage in 18 decimals.
+	SwapFee *big.Int
+	// ExactPow selects the FixedPoint of pools that compute powers of 1, 2
+	// and 4 by multiplication.
+	ExactPow bool
+}
+
+// QuoteExactIn computes the output of selling amountIn of token i for token j,
+// as the pool's onSwap does: the fee is taken from the input rounded up, the
+// amounts are upscaled to 18 decimals and the output is downscaled rounding down.
+//
+// Returns (0, false) if the tokens are out of range or the swap is rejected.
+func (p Pool) QuoteExactIn(i, j int, amountIn *big.Int) (*big.Int, bool) {
+	n := len(p.Balances)
+	if i == j || i < 0 || j < 0 || i >= n || j >= n ||
+		len(p.ScalingFactors) != n || len(p.Weights) != n || amountIn.Sign() <= 0 {
+		return new(big.Int), false
+	}
+	if p.ScalingFactors[j].Sign() <= 0 || p.Balances[i].Sign() <= 0 {
+		return new(big.Int), false
+	}
+
+	// amountIn - amountIn * fee rounded up.
+	in := new(big.Int).Sub(amountIn, MulUp(amountIn, p.SwapFee))
+	if in.Sign() < 0 {
+		return new(big.Int), false
+	}
+
+	balanceIn := new(big.Int).Mul(p.Balances[i], p.ScalingFactors[i])
+	balanceOut := new(big.Int).Mul(p.Balances[j], p.ScalingFactors[j])
+	in.Mul(in, p.ScalingFactors[i])
+
+	out, ok := CalcOutGivenIn(balanceIn, p.Weights[i], balanceOut, p.Weights[j], in, p.ExactPow)
+	if !ok {
+		return new(big.Int), false
+	}
+	return out.Quo(out, p.ScalingFactors[j]), true
+}
@@ -0,0 +1,80 @@
+package weightedmath
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestPool_QuoteExactIn(t *testing.T) {
+	t.Parallel()
+
+	// 80/20 BAL/WETH with a 1% fee.
+	pool8020 := Pool{
+		Balances:       []*big.Int{bi("30000000000000000000000000"), bi("15000000000000000000000")},
+		ScalingFactors: []*big.Int{big.NewInt(1), big.NewInt(1)},
+		Weights:        []*big.Int{bi("800000000000000000"), bi("200000000000000000")},
+		SwapFee:        bi("10000000000000000"),
+	}
+	// 50/50 WETH/USDC with a 0.3% fee.
+	pool5050 := Pool{
+		Balances:       []*big.Int{bi("2000000000000000000000"), bi("5000000000000")},
+		ScalingFactors: []*big.Int{big.NewInt(1), bi("1000000000000")},
+		Weights:        []*big.Int{bi("500000000000000000"), bi("500000000000000000")},
+		SwapFee:        bi("3000000000000000"),
+	}
+	exact := func(p Pool) Pool {
+		p.ExactPow = true
+		return p
+	}
+
+	tests := []struct {
+		name string
+		pool Pool
+		i, j int
+		in   *big.Int
+		want *big.Int
+	}{
+		{name: "80/20 BAL to WETH", pool: pool8020, i: 0, j: 1, in: bi("1000000000000000000000"), want: bi("1979836660630425000")},
+		// weightIn / weightOut is exactly 4.
+		{
+			name: "80/20 BAL to WETH exact pow", pool: exact(pool8020), i: 0, j: 1,
+			in: bi("1000000000000000000000"), want: bi("1979836660780410000"),
+		},
+		{name: "80/20 WETH to BAL", pool: pool8020, i: 1, j: 0, in: bi("10000000000000000000"), want: bi("4947959134886370000000")},
+		{name: "50/50 USDC to WETH", pool: pool5050, i: 1, j: 0, in: bi("1000000000"), want: bi("398720495113270000")},
+		{name: "50/50 USDC to WETH exact pow", pool: exact(pool5050), i: 1, j: 0, in: bi("1000000000"), want: bi("398720495133270000")},
+		{name: "50/50 WETH to USDC", pool: pool5050, i: 0, j: 1, in: bi("1000000000000000000"), want: bi("2491258107")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := tt.pool.QuoteExactIn(tt.i, tt.j, tt.in)
+			if !ok {
+				t.Fatal("ok=false")
+			}
+			if got.Cmp(tt.want) != 0 {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPool_QuoteExactIn_MaxInRatio(t *testing.T) {
+	t.Parallel()
+
+	pool := Pool{
+		Balances:       []*big.Int{bi("1000000000000000000000"), bi("1000000000000000000000")},
+		ScalingFactors: []*big.Int{big.NewInt(1), big.NewInt(1)},
+		Weights:        []*big.Int{bi("500000000000000000"), bi("500000000000000000")},
+		SwapFee:        new(big.Int),
+	}
+
+	if _, ok := pool.QuoteExactIn(0, 1, bi("300000000000000000000")); !ok {
+		t.Fatal("30% of the balance: ok=false")
+	}
+	if _, ok := pool.QuoteExactIn(0, 1, bi("300000000000000000001")); ok {
+		t.Fatal("above 30% of the balance: ok=true")
+	}
+}